@@ -0,0 +1,297 @@
+package docker
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrUnsafeArchive is the error wrapped by the errors returned by
+	// ExtractArchive when an entry would be written outside of the
+	// destination directory.
+	ErrUnsafeArchive = errors.New("unsafe archive entry")
+
+	// ErrArchiveTooLarge is the error wrapped by the errors returned by
+	// ExtractArchive when the archive exceeds one of the limits in the
+	// options.
+	ErrArchiveTooLarge = errors.New("archive too large")
+)
+
+// ExtractArchiveOptions specify parameters to the ExtractArchive function.
+type ExtractArchiveOptions struct {
+	// MaxSize is the maximum total size of the files in the archive, in
+	// bytes. Zero means no limit.
+	MaxSize int64
+
+	// MaxEntries is the maximum number of entries in the archive. Zero
+	// means no limit.
+	MaxEntries int
+
+	// MapOwnership, when set, is called with the owner of every entry, and
+	// the entry is owned by the returned user and group once extracted.
+	// Otherwise, the entries are owned by the current user.
+	MapOwnership func(uid, gid int) (int, int)
+}
+
+// ExtractArchive extracts the tar archive in src, like the ones returned by
+// DownloadFromContainer and ExportContainer, into the dest directory, which
+// is created if needed.
+//
+// Unlike a naive extraction, it refuses entries that would escape dest:
+// absolute names, names with "..", symbolic and hard links pointing outside
+// of dest, directly or through other links, entries written through
+// previously extracted symbolic links, and links or files replacing an
+// extracted directory or symbolic link.
+// Such entries make it fail with an error wrapping ErrUnsafeArchive. Special
+// files like devices and FIFOs are skipped, and the setuid, setgid and
+// sticky bits are dropped.
+//
+// Entries extracted before an error are left in dest.
+func ExtractArchive(src io.Reader, dest string, opts ExtractArchiveOptions) error {
+	dest, err := filepath.Abs(dest)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return err
+	}
+	var size int64
+	tr := tar.NewReader(src)
+	for entries := 1; ; entries++ {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if opts.MaxEntries > 0 && entries > opts.MaxEntries {
+			return fmt.Errorf("%w: more than %d entries", ErrArchiveTooLarge, opts.MaxEntries)
+		}
+		name, err := localArchivePath(header.Name)
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			continue
+		}
+		target := filepath.Join(dest, name)
+		if err := checkArchiveParents(dest, name); err != nil {
+			return err
+		}
+		mode := header.FileInfo().Mode().Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if info, err := os.Lstat(target); err == nil && !info.IsDir() {
+				// chmod and chtimes would follow a link
+				if err := os.Remove(target); err != nil {
+					return err
+				}
+			}
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if opts.MaxSize > 0 && size+header.Size > opts.MaxSize {
+				return fmt.Errorf("%w: more than %d bytes", ErrArchiveTooLarge, opts.MaxSize)
+			}
+			size += header.Size
+			if err := checkReplaceable(target, header.Name); err != nil {
+				return err
+			}
+			if err := extractArchiveFile(tr, target, mode); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if !isLocalLink(dest, name, header.Linkname) {
+				return fmt.Errorf("%w: %q links to %q", ErrUnsafeArchive, header.Name, header.Linkname)
+			}
+			if err := checkReplaceable(target, header.Name); err != nil {
+				return err
+			}
+			os.Remove(target)
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			linkname, err := localArchivePath(header.Linkname)
+			if err != nil {
+				return err
+			}
+			if err := checkArchiveParents(dest, linkname); err != nil {
+				return err
+			}
+			// a hard link to a symbolic link is a copy of it, which must
+			// also resolve inside of dest from its new location
+			if info, err := os.Lstat(filepath.Join(dest, linkname)); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				if err := checkCopiedLink(dest, linkname, name); err != nil {
+					return err
+				}
+			}
+			if err := checkReplaceable(target, header.Name); err != nil {
+				return err
+			}
+			os.Remove(target)
+			if err := os.Link(filepath.Join(dest, linkname), target); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+		if opts.MapOwnership != nil {
+			uid, gid := opts.MapOwnership(header.Uid, header.Gid)
+			if err := os.Lchown(target, uid, gid); err != nil {
+				return err
+			}
+		}
+		if header.Typeflag == tar.TypeDir {
+			if err := os.Chmod(target, mode); err != nil {
+				return err
+			}
+		}
+		if header.Typeflag != tar.TypeSymlink && !header.ModTime.IsZero() {
+			if err := os.Chtimes(target, header.ModTime, header.ModTime); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// checkReplaceable fails if the entry named name would replace a directory
+// or a symbolic link at target, as the links already checked may resolve
+// through it.
+func checkReplaceable(target, name string) error {
+	if info, err := os.Lstat(target); err == nil && (info.IsDir() || info.Mode()&os.ModeSymlink != 0) {
+		return fmt.Errorf("%w: %q replaces a directory or a symbolic link", ErrUnsafeArchive, name)
+	}
+	return nil
+}
+
+func extractArchiveFile(r io.Reader, target string, mode os.FileMode) error {
+	// a link in place of the file would be followed by OpenFile
+	os.Remove(target)
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// the mode given to OpenFile is subject to the umask
+	return os.Chmod(target, mode)
+}
+
+// localArchivePath returns the cleaned form of the name of an entry,
+// failing if it isn't a relative path inside of the archive.
+func localArchivePath(name string) (string, error) {
+	cleaned := path.Clean(strings.TrimPrefix(name, "./"))
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") || strings.Contains(cleaned, `\`) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafeArchive, name)
+	}
+	return filepath.FromSlash(cleaned), nil
+}
+
+// maxLinkHops is the maximum number of symbolic links followed while
+// resolving the target of a link.
+const maxLinkHops = 255
+
+// isLocalLink reports whether a symbolic link named name, pointing to
+// linkname, resolves inside of dest. The target is resolved one component at
+// a time, following the links already extracted, so that a chain of links
+// can't escape dest either. As the directories that don't exist yet could
+// be extracted as links later, ".." is only allowed after an existing
+// directory.
+func isLocalLink(dest, name, linkname string) bool {
+	if path.IsAbs(linkname) {
+		return false
+	}
+	// the parents of name are directories, see checkArchiveParents
+	var dirs []string
+	if dir := filepath.Dir(name); dir != "." {
+		dirs = strings.Split(dir, string(filepath.Separator))
+	}
+	pending := strings.Split(linkname, "/")
+	for hops := 0; len(pending) > 0; {
+		part := pending[0]
+		pending = pending[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if len(dirs) == 0 {
+				return false
+			}
+			info, err := os.Lstat(filepath.Join(dest, filepath.Join(dirs...)))
+			if err != nil || !info.IsDir() {
+				return false
+			}
+			dirs = dirs[:len(dirs)-1]
+			continue
+		}
+		if strings.Contains(part, `\`) {
+			return false
+		}
+		current := filepath.Join(dest, filepath.Join(dirs...), part)
+		info, err := os.Lstat(current)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			dirs = append(dirs, part)
+			continue
+		}
+		if hops++; hops > maxLinkHops {
+			return false
+		}
+		target, err := os.Readlink(current)
+		if err != nil || path.IsAbs(filepath.ToSlash(target)) {
+			return false
+		}
+		pending = append(strings.Split(filepath.ToSlash(target), "/"), pending...)
+	}
+	return true
+}
+
+// checkCopiedLink fails if the symbolic link at linkname in dest doesn't
+// resolve inside of dest once copied to name.
+func checkCopiedLink(dest, linkname, name string) error {
+	target, err := os.Readlink(filepath.Join(dest, linkname))
+	if err != nil {
+		return err
+	}
+	if !isLocalLink(dest, name, filepath.ToSlash(target)) {
+		return fmt.Errorf("%w: %q links to %q", ErrUnsafeArchive, name, target)
+	}
+	return nil
+}
+
+// checkArchiveParents fails if one of the parent directories of name in
+// dest is a symbolic link, as writing through it could escape dest.
+func checkArchiveParents(dest, name string) error {
+	current := dest
+	parts := strings.Split(name, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, os.ErrNotExist) {
+			return os.MkdirAll(filepath.Join(dest, filepath.Dir(name)), 0o755)
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %q is written through a symbolic link", ErrUnsafeArchive, name)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%w: parent of %q isn't a directory", ErrUnsafeArchive, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestExtractArchive(t *testing.T) {
+	t.Parallel()
+	mtime := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	archive := buildTestArchive(t,
+		&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0o755},
+		&tar.Header{Name: "etc/", Typeflag: tar.TypeDir, Mode: 0o750},
+		&tar.Header{Name: "etc/app.conf", Typeflag: tar.TypeReg, Mode: 0o4640, ModTime: mtime},
+		&tar.Header{Name: "etc/current.conf", Typeflag: tar.TypeSymlink, Linkname: "app.conf"},
+		&tar.Header{Name: "etc/hard.conf", Typeflag: tar.TypeLink, Linkname: "etc/app.conf", ModTime: mtime},
+		&tar.Header{Name: "var/log/app.log", Typeflag: tar.TypeReg, Mode: 0o644},
+		&tar.Header{Name: "usr/lib/", Typeflag: tar.TypeDir, Mode: 0o755},
+		&tar.Header{Name: "lib", Typeflag: tar.TypeSymlink, Linkname: "usr/lib"},
+		&tar.Header{Name: "usr/lib64", Typeflag: tar.TypeSymlink, Linkname: "../lib/."},
+		&tar.Header{Name: "dev/null", Typeflag: tar.TypeChar, Mode: 0o666},
+	)
+	dest := filepath.Join(t.TempDir(), "out")
+	if err := ExtractArchive(bytes.NewReader(archive), dest, ExtractArchiveOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dest, "etc", "current.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "etc/app.conf" {
+		t.Errorf("ExtractArchive: wrong content. Want %q. Got %q.", "etc/app.conf", content)
+	}
+	info, err := os.Stat(filepath.Join(dest, "etc", "hard.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode() != 0o640 {
+		t.Errorf("ExtractArchive: wrong mode. Want %v. Got %v.", os.FileMode(0o640), info.Mode())
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("ExtractArchive: wrong mtime. Want %v. Got %v.", mtime, info.ModTime())
+	}
+	if _, err := os.Stat(filepath.Join(dest, "var", "log", "app.log")); err != nil {
+		t.Error(err)
+	}
+	if info, err := os.Stat(filepath.Join(dest, "usr", "lib64")); err != nil || !info.IsDir() {
+		t.Errorf("ExtractArchive: chained link not extracted: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "dev", "null")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ExtractArchive: special file extracted: %v", err)
+	}
+}
+
+func TestExtractArchiveUnsafe(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		headers []*tar.Header
+	}{
+		{"parent", []*tar.Header{{Name: "../evil", Typeflag: tar.TypeReg}}},
+		{"nested parent", []*tar.Header{{Name: "a/../../evil", Typeflag: tar.TypeReg}}},
+		{"absolute", []*tar.Header{{Name: "/tmp/evil", Typeflag: tar.TypeReg}}},
+		{"absolute symlink", []*tar.Header{{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc"}}},
+		{"escaping symlink", []*tar.Header{{Name: "a/link", Typeflag: tar.TypeSymlink, Linkname: "../../etc"}}},
+		{"escaping hard link", []*tar.Header{{Name: "link", Typeflag: tar.TypeLink, Linkname: "../etc/passwd"}}},
+		{"symlink chain", []*tar.Header{
+			{Name: "x/y", Typeflag: tar.TypeSymlink, Linkname: ".."},
+			{Name: "z", Typeflag: tar.TypeSymlink, Linkname: "x/y/.."},
+		}},
+		{"symlink through missing directory", []*tar.Header{
+			{Name: "z", Typeflag: tar.TypeSymlink, Linkname: "x/.."},
+		}},
+		{"symlink replacing directory", []*tar.Header{
+			{Name: "x/", Typeflag: tar.TypeDir},
+			{Name: "z", Typeflag: tar.TypeSymlink, Linkname: "x/.."},
+			{Name: "x", Typeflag: tar.TypeSymlink, Linkname: "."},
+		}},
+		{"hard link to symlink", []*tar.Header{
+			{Name: "a/b/link", Typeflag: tar.TypeSymlink, Linkname: "../.."},
+			{Name: "link", Typeflag: tar.TypeLink, Linkname: "a/b/link"},
+		}},
+		{"hard link replacing directory", []*tar.Header{
+			{Name: "d/", Typeflag: tar.TypeDir},
+			{Name: "d/e/", Typeflag: tar.TypeDir},
+			{Name: "x", Typeflag: tar.TypeSymlink, Linkname: "d/e/../.."},
+			{Name: "s", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "d/e", Typeflag: tar.TypeLink, Linkname: "s"},
+		}},
+		{"file replacing symlink", []*tar.Header{
+			{Name: "s", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "s", Typeflag: tar.TypeReg},
+		}},
+		{"write through symlink", []*tar.Header{
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "link/evil", Typeflag: tar.TypeReg},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			root := t.TempDir()
+			dest := filepath.Join(root, "out")
+			err := ExtractArchive(bytes.NewReader(buildTestArchive(t, tt.headers...)), dest, ExtractArchiveOptions{})
+			if !errors.Is(err, ErrUnsafeArchive) {
+				t.Errorf("ExtractArchive: wrong error. Want %v. Got %v.", ErrUnsafeArchive, err)
+			}
+			if _, err := os.Lstat(filepath.Join(root, "evil")); !errors.Is(err, os.ErrNotExist) {
+				t.Error("ExtractArchive: file written outside of the destination")
+			}
+		})
+	}
+}
+
+func TestExtractArchiveLimits(t *testing.T) {
+	t.Parallel()
+	archive := buildTestArchive(t,
+		&tar.Header{Name: "a", Typeflag: tar.TypeReg},
+		&tar.Header{Name: "b", Typeflag: tar.TypeReg},
+		&tar.Header{Name: "c", Typeflag: tar.TypeReg},
+	)
+	tests := []ExtractArchiveOptions{
+		{MaxEntries: 2},
+		{MaxSize: 2},
+	}
+	for _, opts := range tests {
+		err := ExtractArchive(bytes.NewReader(archive), t.TempDir(), opts)
+		if !errors.Is(err, ErrArchiveTooLarge) {
+			t.Errorf("ExtractArchive(%#v): wrong error. Want %v. Got %v.", opts, ErrArchiveTooLarge, err)
+		}
+	}
+	if err := ExtractArchive(bytes.NewReader(archive), t.TempDir(), ExtractArchiveOptions{MaxEntries: 3, MaxSize: 3}); err != nil {
+		t.Errorf("ExtractArchive: unexpected error: %v", err)
+	}
+}
+
+func TestExtractArchiveMapOwnership(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("ownership isn't supported on Windows")
+	}
+	archive := buildTestArchive(t, &tar.Header{Name: "a", Typeflag: tar.TypeReg, Uid: 1000, Gid: 1000})
+	var owners [][2]int
+	err := ExtractArchive(bytes.NewReader(archive), t.TempDir(), ExtractArchiveOptions{
+		MapOwnership: func(uid, gid int) (int, int) {
+			owners = append(owners, [2]int{uid, gid})
+			return os.Getuid(), os.Getgid()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owners) != 1 || owners[0] != [2]int{1000, 1000} {
+		t.Errorf("ExtractArchive: wrong owners passed to MapOwnership: %v", owners)
+	}
+}
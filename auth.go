@@ -73,6 +73,67 @@ func (c *AuthConfigurations) merge(other AuthConfigurations) {
 	}
 }
 
+// forImage returns the configuration of the registry hosting the given
+// image, or an empty configuration if there's none. Configurations are
+// looked up by the address of their registry, with or without scheme, like
+// "quay.io" or "https://index.docker.io/v1/".
+func (c AuthConfigurations) forImage(image string) AuthConfiguration {
+	ref, err := ParseImageReference(image)
+	if err != nil {
+		return AuthConfiguration{}
+	}
+	for address, config := range c.Configs {
+		if registryAddressHost(address) == ref.Registry {
+			return config
+		}
+	}
+	return AuthConfiguration{}
+}
+
+// registryAddressHost returns the host of the address of a registry, with
+// the Docker Hub aliases replaced by DefaultRegistry.
+func registryAddressHost(address string) string {
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	host, _, _ := strings.Cut(address, "/")
+	switch host {
+	case legacyDefaultRegistry, "registry-1.docker.io":
+		return DefaultRegistry
+	}
+	return host
+}
+
+// dockerHubIndexServer is the address the daemon looks up the configuration
+// of Docker Hub by.
+const dockerHubIndexServer = "https://index.docker.io/v1/"
+
+// registryConfig returns the configurations in the format of the
+// X-Registry-Config header, keyed by the addresses the daemon looks them up
+// by when pulling the images of a build: the configuration of Docker Hub,
+// given under any of its aliases, is keyed by dockerHubIndexServer. Empty
+// configurations are dropped, and ServerAddress defaults to the key.
+func (c AuthConfigurations) registryConfig() AuthConfigurations119 {
+	configs := make(AuthConfigurations119, len(c.Configs))
+	for address, config := range c.Configs {
+		if config.isEmpty() {
+			continue
+		}
+		key := address
+		if registryAddressHost(address) == DefaultRegistry {
+			key = dockerHubIndexServer
+			if _, ok := configs[key]; ok && address != dockerHubIndexServer {
+				// an alias doesn't override the configuration given
+				// under the canonical address
+				continue
+			}
+		}
+		if config.ServerAddress == "" {
+			config.ServerAddress = key
+		}
+		configs[key] = config
+	}
+	return configs
+}
+
 // AuthConfigurations119 is used to serialize a set of AuthConfigurations
 // for Docker API >= 1.19.
 type AuthConfigurations119 map[string]AuthConfiguration
@@ -373,6 +373,23 @@ func TestAuthConfigurationsMerge(t *testing.T) {
 	}
 }
 
+func TestAuthConfigurationsRegistryConfig(t *testing.T) {
+	t.Parallel()
+	configs := AuthConfigurations{Configs: map[string]AuthConfiguration{
+		"https://index.docker.io/v1/": {Username: "canonical"},
+		"index.docker.io":             {Username: "alias"},
+		"registry-1.docker.io":        {Username: "alias"},
+		"https://quay.io":             {Username: "quay", ServerAddress: "quay.io"},
+	}}
+	expected := AuthConfigurations119{
+		"https://index.docker.io/v1/": {Username: "canonical", ServerAddress: "https://index.docker.io/v1/"},
+		"https://quay.io":             {Username: "quay", ServerAddress: "quay.io"},
+	}
+	if got := configs.registryConfig(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("registryConfig: wrong configurations. Want %#v. Got %#v.", expected, got)
+	}
+}
+
 func TestGetHelperProviderFromDockerCfg(t *testing.T) {
 	t.Parallel()
 	tmpDir, err := os.MkdirTemp("", "go-dockerclient-creds-test")
@@ -0,0 +1,55 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// PruneBuildCacheOptions specify parameters to the PruneBuildCache function.
+//
+// See https://docs.docker.com/engine/api/v1.41/#operation/BuildPrune for more
+// details.
+type PruneBuildCacheOptions struct {
+	// All removes all the unused build cache, not only the dangling
+	// entries.
+	All bool `ver:"1.39"`
+
+	// KeepStorage is the amount of disk space, in bytes, to keep for the
+	// cache.
+	KeepStorage int64 `qs:"keep-storage" ver:"1.39"`
+
+	// Filters restrict the entries removed. Available filters include
+	// until=<duration>, id=<id>, parent=<id>, type=<type>, description,
+	// inuse, shared and private.
+	Filters map[string][]string `ver:"1.39"`
+
+	Context context.Context
+}
+
+// PruneBuildCacheResults specify results from the PruneBuildCache function.
+type PruneBuildCacheResults struct {
+	CachesDeleted  []string
+	SpaceReclaimed int64
+}
+
+// PruneBuildCache deletes the build cache of the builder.
+//
+// See https://docs.docker.com/engine/api/v1.41/#operation/BuildPrune for more
+// details.
+func (c *Client) PruneBuildCache(opts PruneBuildCacheOptions) (*PruneBuildCacheResults, error) {
+	qs, requiredAPIVersion := queryStringVersion(opts)
+	if err := c.checkRequiredAPIVersion("/build/prune", requiredAPIVersion); err != nil {
+		return nil, err
+	}
+	resp, err := c.do(http.MethodPost, "/build/prune?"+qs, doOptions{context: opts.Context})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var results PruneBuildCacheResults
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, err
+	}
+	return &results, nil
+}
@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestPruneBuildCache(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"CachesDeleted":["a","b"],"SpaceReclaimed":1024}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	got, err := client.PruneBuildCache(PruneBuildCacheOptions{
+		All:         true,
+		KeepStorage: 512,
+		Filters:     map[string][]string{"until": {"24h"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &PruneBuildCacheResults{CachesDeleted: []string{"a", "b"}, SpaceReclaimed: 1024}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("PruneBuildCache: Expected %#v. Got %#v.", expected, got)
+	}
+	req := fakeRT.requests[0]
+	if req.Method != http.MethodPost || req.URL.Path != "/build/prune" {
+		t.Errorf("PruneBuildCache: wrong request: %s %s.", req.Method, req.URL.Path)
+	}
+	expectedQuery := map[string][]string{
+		"all":          {"1"},
+		"keep-storage": {"512"},
+		"filters":      {`{"until":["24h"]}`},
+	}
+	if query := map[string][]string(req.URL.Query()); !reflect.DeepEqual(query, expectedQuery) {
+		t.Errorf("PruneBuildCache: wrong query. Want %#v. Got %#v.", expectedQuery, query)
+	}
+}
+
+func TestPruneBuildCacheUnsupportedVersion(t *testing.T) {
+	t.Parallel()
+	client, err := NewVersionedClient("http://localhost:4243", "1.38")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.PruneBuildCache(PruneBuildCacheOptions{All: true})
+	expected := "API /build/prune requires version 1.39, requested version 1.38 is insufficient"
+	if err == nil || err.Error() != expected {
+		t.Errorf("PruneBuildCache: wrong error. Want %q. Got %v.", expected, err)
+	}
+}
@@ -0,0 +1,241 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CallOption configures a call of the APIs taking functional options, like
+// Containers and ContainerInspect. Unlike option structs, new options can
+// be added to these APIs without changing their signatures:
+//
+//	containers, err := client.Containers(
+//		docker.WithAll(),
+//		docker.WithFilter("label", "app=web"),
+//		docker.WithTimeout(5*time.Second),
+//	)
+//
+// Options that don't apply to a call are ignored.
+type CallOption func(*CallOptions)
+
+// CallOptions holds the options of a call, as set by CallOption functions.
+type CallOptions struct {
+	Context context.Context
+	Timeout time.Duration
+	Filters map[string][]string
+	All     bool
+	Size    bool
+	Digests bool
+	Limit   int
+}
+
+// NewCallOptions returns the options set by opts.
+func NewCallOptions(opts ...CallOption) CallOptions {
+	var o CallOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// context returns the context of the call, bound by its timeout.
+func (o CallOptions) context() (context.Context, context.CancelFunc) {
+	ctx := o.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if o.Timeout > 0 {
+		return context.WithTimeout(ctx, o.Timeout)
+	}
+	return ctx, func() {}
+}
+
+// WithContext sets the context of the call.
+func WithContext(ctx context.Context) CallOption {
+	return func(o *CallOptions) { o.Context = ctx }
+}
+
+// WithTimeout bounds the duration of the call.
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(o *CallOptions) { o.Timeout = timeout }
+}
+
+// WithFilters adds the given filters to the call, keeping the ones added by
+// previous options.
+func WithFilters(filters map[string][]string) CallOption {
+	return func(o *CallOptions) {
+		for key, values := range filters {
+			WithFilter(key, values...)(o)
+		}
+	}
+}
+
+// WithFilter adds a filter to the call, like WithFilter("label", "app=web").
+func WithFilter(key string, values ...string) CallOption {
+	return func(o *CallOptions) {
+		if o.Filters == nil {
+			o.Filters = make(map[string][]string)
+		}
+		o.Filters[key] = append(o.Filters[key], values...)
+	}
+}
+
+// WithAll includes stopped containers, or intermediate images, in lists.
+func WithAll() CallOption {
+	return func(o *CallOptions) { o.All = true }
+}
+
+// WithSize includes the sizes of containers in lists and inspections.
+func WithSize() CallOption {
+	return func(o *CallOptions) { o.Size = true }
+}
+
+// WithDigests includes the digests of images in lists.
+func WithDigests() CallOption {
+	return func(o *CallOptions) { o.Digests = true }
+}
+
+// WithLimit limits the number of items returned by lists.
+func WithLimit(limit int) CallOption {
+	return func(o *CallOptions) { o.Limit = limit }
+}
+
+// Containers returns the containers matching the given options, see
+// ListContainers.
+func (c *Client) Containers(opts ...CallOption) ([]APIContainers, error) {
+	o := NewCallOptions(opts...)
+	ctx, cancel := o.context()
+	defer cancel()
+	return c.ListContainers(ListContainersOptions{
+		All:     o.All,
+		Size:    o.Size,
+		Limit:   o.Limit,
+		Filters: o.Filters,
+		Context: ctx,
+	})
+}
+
+// ContainerInspect returns information about the container with the given
+// ID or name, see InspectContainerWithOptions.
+func (c *Client) ContainerInspect(id string, opts ...CallOption) (*Container, error) {
+	o := NewCallOptions(opts...)
+	ctx, cancel := o.context()
+	defer cancel()
+	return c.InspectContainerWithOptions(InspectContainerOptions{ID: id, Size: o.Size, Context: ctx})
+}
+
+// Images returns the images matching the given options, see ListImages.
+func (c *Client) Images(opts ...CallOption) ([]APIImages, error) {
+	o := NewCallOptions(opts...)
+	ctx, cancel := o.context()
+	defer cancel()
+	return c.ListImages(ListImagesOptions{
+		All:     o.All,
+		Digests: o.Digests,
+		Filters: o.Filters,
+		Context: ctx,
+	})
+}
+
+// Volumes returns the volumes matching the given options, see ListVolumes.
+func (c *Client) Volumes(opts ...CallOption) ([]Volume, error) {
+	o := NewCallOptions(opts...)
+	ctx, cancel := o.context()
+	defer cancel()
+	return c.ListVolumes(ListVolumesOptions{Filters: o.Filters, Context: ctx})
+}
+
+// Networks returns the networks matching the given options, see
+// ListNetworks.
+func (c *Client) Networks(opts ...CallOption) ([]Network, error) {
+	o := NewCallOptions(opts...)
+	ctx, cancel := o.context()
+	defer cancel()
+	path := "/networks"
+	if len(o.Filters) > 0 {
+		filters, err := json.Marshal(o.Filters)
+		if err != nil {
+			return nil, err
+		}
+		path += "?" + url.Values{"filters": {string(filters)}}.Encode()
+	}
+	resp, err := c.do(http.MethodGet, path, doOptions{context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var networks []Network
+	if err := json.NewDecoder(resp.Body).Decode(&networks); err != nil {
+		return nil, err
+	}
+	return networks, nil
+}
+
+// CallOptions returns the functional options equivalent to opts, to move
+// calls of ListContainers to Containers. Since and Before are converted to
+// the equivalent filters.
+func (opts ListContainersOptions) CallOptions() []CallOption {
+	callOpts := []CallOption{WithFilters(opts.Filters), WithLimit(opts.Limit)}
+	if opts.All {
+		callOpts = append(callOpts, WithAll())
+	}
+	if opts.Size {
+		callOpts = append(callOpts, WithSize())
+	}
+	if opts.Since != "" {
+		callOpts = append(callOpts, WithFilter("since", opts.Since))
+	}
+	if opts.Before != "" {
+		callOpts = append(callOpts, WithFilter("before", opts.Before))
+	}
+	if opts.Context != nil {
+		callOpts = append(callOpts, WithContext(opts.Context))
+	}
+	return callOpts
+}
+
+// CallOptions returns the functional options equivalent to opts, to move
+// calls of InspectContainerWithOptions to ContainerInspect.
+func (opts InspectContainerOptions) CallOptions() []CallOption {
+	var callOpts []CallOption
+	if opts.Size {
+		callOpts = append(callOpts, WithSize())
+	}
+	if opts.Context != nil {
+		callOpts = append(callOpts, WithContext(opts.Context))
+	}
+	return callOpts
+}
+
+// CallOptions returns the functional options equivalent to opts, to move
+// calls of ListImages to Images. Filter is converted to the equivalent
+// reference filter.
+func (opts ListImagesOptions) CallOptions() []CallOption {
+	callOpts := []CallOption{WithFilters(opts.Filters)}
+	if opts.All {
+		callOpts = append(callOpts, WithAll())
+	}
+	if opts.Digests {
+		callOpts = append(callOpts, WithDigests())
+	}
+	if opts.Filter != "" {
+		callOpts = append(callOpts, WithFilter("reference", opts.Filter))
+	}
+	if opts.Context != nil {
+		callOpts = append(callOpts, WithContext(opts.Context))
+	}
+	return callOpts
+}
+
+// CallOptions returns the functional options equivalent to opts, to move
+// calls of ListVolumes to Volumes.
+func (opts ListVolumesOptions) CallOptions() []CallOption {
+	callOpts := []CallOption{WithFilters(opts.Filters)}
+	if opts.Context != nil {
+		callOpts = append(callOpts, WithContext(opts.Context))
+	}
+	return callOpts
+}
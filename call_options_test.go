@@ -0,0 +1,107 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestContainersCallOptions(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "[]", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	_, err := client.Containers(WithAll(), WithSize(), WithLimit(3), WithFilter("label", "app=web"), WithFilters(map[string][]string{"label": {"tier=front"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := fakeRT.requests[0].URL.Query()
+	if query.Get("all") != "1" || query.Get("size") != "1" || query.Get("limit") != "3" {
+		t.Errorf("Containers: wrong query string: %v", query)
+	}
+	var filters map[string][]string
+	json.Unmarshal([]byte(query.Get("filters")), &filters)
+	if expected := map[string][]string{"label": {"app=web", "tier=front"}}; !reflect.DeepEqual(filters, expected) {
+		t.Errorf("Containers: wrong filters. Want %#v. Got %#v.", expected, filters)
+	}
+}
+
+func TestCallOptionsAdapters(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		opts     []CallOption
+		expected CallOptions
+	}{
+		{
+			name: "containers",
+			opts: ListContainersOptions{All: true, Limit: 2, Since: "abc", Filters: map[string][]string{"status": {"exited"}}, Context: ctx}.CallOptions(),
+			expected: CallOptions{
+				All:     true,
+				Limit:   2,
+				Filters: map[string][]string{"status": {"exited"}, "since": {"abc"}},
+				Context: ctx,
+			},
+		},
+		{
+			name:     "inspect container",
+			opts:     InspectContainerOptions{ID: "abc", Size: true}.CallOptions(),
+			expected: CallOptions{Size: true},
+		},
+		{
+			name:     "images",
+			opts:     ListImagesOptions{Digests: true, Filter: "busybox"}.CallOptions(),
+			expected: CallOptions{Digests: true, Filters: map[string][]string{"reference": {"busybox"}}},
+		},
+		{
+			name:     "volumes",
+			opts:     ListVolumesOptions{Filters: map[string][]string{"dangling": {"true"}}}.CallOptions(),
+			expected: CallOptions{Filters: map[string][]string{"dangling": {"true"}}},
+		},
+	}
+	for _, tt := range tests {
+		if got := NewCallOptions(tt.opts...); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: wrong options. Want %#v. Got %#v.", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestNetworksCallOptions(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `[{"Name":"bridge","Id":"8dfafdbc3a40"}]`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	networks, err := client.Networks(WithFilter("driver", "bridge"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(networks) != 1 || networks[0].Name != "bridge" {
+		t.Errorf("Networks: wrong result: %#v", networks)
+	}
+	if filters := fakeRT.requests[0].URL.Query().Get("filters"); filters != `{"driver":["bridge"]}` {
+		t.Errorf("Networks: wrong filters: %q", filters)
+	}
+}
+
+func TestCallOptionsTimeout(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	_, err := client.ContainerInspect("abc", WithTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ContainerInspect: wrong error. Want %v. Got %v.", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,111 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// Capabilities describes the features supported by the Docker daemon, as
+// reported by Capabilities.
+type Capabilities struct {
+	// APIVersion is the highest API version supported by the daemon.
+	APIVersion string
+
+	// OSType is the operating system of the daemon, "linux" or "windows".
+	OSType string
+
+	// Experimental is true when the daemon has experimental features
+	// enabled.
+	Experimental bool
+
+	// BuildKitSupported is true when the daemon can build images with
+	// BuildKit, see BuilderBuildKit.
+	BuildKitSupported bool
+
+	// DefaultBuilder is the builder used by the daemon when a build
+	// doesn't choose one. It's empty when the daemon doesn't report it.
+	DefaultBuilder BuilderVersion
+
+	// SwarmActive is true when the daemon is part of a swarm, and
+	// SwarmManager when it's also one of its managers.
+	SwarmActive  bool
+	SwarmManager bool
+
+	// Rootless is true when the daemon runs as an unprivileged user.
+	Rootless bool
+
+	// CgroupVersion is the version of cgroups used by the daemon, "1" or
+	// "2". It's empty when the daemon doesn't report it.
+	CgroupVersion string
+}
+
+// Capabilities returns the features supported by the Docker daemon.
+//
+// The daemon is queried on the first successful call only, the next ones
+// return the same capabilities, so changes to the daemon, like joining a
+// swarm, aren't reported.
+func (c *Client) Capabilities() (*Capabilities, error) {
+	return c.CapabilitiesWithContext(context.TODO())
+}
+
+// CapabilitiesWithContext returns the features supported by the Docker
+// daemon. The context object can be used to cancel the requests made to the
+// daemon.
+//
+// See Capabilities for more details.
+func (c *Client) CapabilitiesWithContext(ctx context.Context) (*Capabilities, error) {
+	mu := c.locks.capabilitiesMu()
+	mu.Lock()
+	defer mu.Unlock()
+	if c.capabilities == nil {
+		capabilities, err := c.getCapabilities(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.capabilities = capabilities
+	}
+	capabilities := *c.capabilities
+	return &capabilities, nil
+}
+
+func (c *Client) getCapabilities(ctx context.Context) (*Capabilities, error) {
+	resp, err := c.do(http.MethodGet, "/_ping", doOptions{context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	version, err := c.VersionWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info, err := c.infoWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities := Capabilities{
+		APIVersion:     version.Get("ApiVersion"),
+		OSType:         version.Get("Os"),
+		Experimental:   info.ExperimentalBuild || resp.Header.Get("Docker-Experimental") == "true",
+		DefaultBuilder: BuilderVersion(resp.Header.Get("Builder-Version")),
+		SwarmActive:    info.Swarm.LocalNodeState == swarm.LocalNodeStateActive,
+		SwarmManager:   info.Swarm.ControlAvailable,
+		Rootless:       info.IsRootless(),
+		CgroupVersion:  info.CgroupVersion,
+	}
+	if capabilities.APIVersion == "" {
+		capabilities.APIVersion = resp.Header.Get("Api-Version")
+	}
+	if capabilities.OSType == "" {
+		capabilities.OSType = info.OSType
+	}
+	if capabilities.DefaultBuilder == BuilderBuildKit {
+		capabilities.BuildKitSupported = true
+	} else if apiVersion, err := NewAPIVersion(capabilities.APIVersion); err == nil {
+		// BuildKit doesn't build Windows images
+		capabilities.BuildKitSupported = apiVersion.GreaterThanOrEqualTo(apiVersion139) && capabilities.OSType != "windows"
+	}
+	return &capabilities, nil
+}
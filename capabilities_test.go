@@ -0,0 +1,114 @@
+package docker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	t.Parallel()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/_ping":
+			w.Header().Set("Api-Version", "1.43")
+			w.Header().Set("Builder-Version", "2")
+			w.Header().Set("Docker-Experimental", "false")
+			w.Write([]byte("OK"))
+		case "/version":
+			w.Write([]byte(`{"ApiVersion":"1.43","Os":"linux","Version":"24.0.7"}`))
+		case "/info":
+			w.Write([]byte(`{"OSType":"linux","CgroupVersion":"2","SecurityOptions":["name=seccomp,profile=builtin","name=rootless","name=cgroupns"],"Swarm":{"LocalNodeState":"active","ControlAvailable":true}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	capabilities, err := client.Capabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Capabilities{
+		APIVersion:        "1.43",
+		OSType:            "linux",
+		BuildKitSupported: true,
+		DefaultBuilder:    BuilderBuildKit,
+		SwarmActive:       true,
+		SwarmManager:      true,
+		Rootless:          true,
+		CgroupVersion:     "2",
+	}
+	if !reflect.DeepEqual(capabilities, expected) {
+		t.Errorf("Capabilities: wrong result. Want %#v. Got %#v.", expected, capabilities)
+	}
+	if _, err := client.Capabilities(); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("Capabilities: wrong number of requests. Want 3. Got %d.", n)
+	}
+}
+
+func TestCapabilitiesOldDaemon(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_ping":
+			w.Write([]byte("OK"))
+		case "/version":
+			w.Write([]byte(`{"ApiVersion":"1.38","Os":"linux"}`))
+		case "/info":
+			w.Write([]byte(`{"OSType":"linux","ExperimentalBuild":true,"SecurityOptions":["name=apparmor"],"Swarm":{"LocalNodeState":"inactive"}}`))
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	capabilities, err := client.Capabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Capabilities{APIVersion: "1.38", OSType: "linux", Experimental: true}
+	if !reflect.DeepEqual(capabilities, expected) {
+		t.Errorf("Capabilities: wrong result. Want %#v. Got %#v.", expected, capabilities)
+	}
+}
+
+func TestCapabilitiesError(t *testing.T) {
+	t.Parallel()
+	var fail atomic.Bool
+	fail.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info" && fail.Load() {
+			http.Error(w, "daemon is busy", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"ApiVersion":"1.43","Os":"windows"}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Capabilities(); err == nil {
+		t.Fatal("Capabilities: unexpected <nil> error")
+	}
+	fail.Store(false)
+	capabilities, err := client.Capabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capabilities.BuildKitSupported {
+		t.Error("Capabilities: BuildKit shouldn't be supported by Windows daemons")
+	}
+}
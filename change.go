@@ -4,7 +4,10 @@
 
 package docker
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
 
 // ChangeType is a type for constants indicating the type of change
 // in a container
@@ -29,15 +32,54 @@ type Change struct {
 	Kind ChangeType
 }
 
-func (change *Change) String() string {
-	var kind string
-	switch change.Kind {
+// String returns the single letter used by the Docker CLI to represent the
+// change type: "C" for modifications, "A" for additions and "D" for
+// deletions. It returns an empty string for unknown change types.
+func (kind ChangeType) String() string {
+	switch kind {
 	case ChangeModify:
-		kind = "C"
+		return "C"
 	case ChangeAdd:
-		kind = "A"
+		return "A"
 	case ChangeDelete:
-		kind = "D"
+		return "D"
 	}
-	return fmt.Sprintf("%s %s", kind, change.Path)
+	return ""
+}
+
+func (change *Change) String() string {
+	return fmt.Sprintf("%s %s", change.Kind, change.Path)
+}
+
+// FilterChanges returns the changes of the given kinds, preserving their
+// order.
+func FilterChanges(changes []Change, kinds ...ChangeType) []Change {
+	var filtered []Change
+	for _, change := range changes {
+		for _, kind := range kinds {
+			if change.Kind == kind {
+				filtered = append(filtered, change)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// GroupChanges groups the given changes by their kind, preserving their
+// order within each group.
+func GroupChanges(changes []Change) map[ChangeType][]Change {
+	groups := make(map[ChangeType][]Change)
+	for _, change := range changes {
+		groups[change.Kind] = append(groups[change.Kind], change)
+	}
+	return groups
+}
+
+// SortChanges sorts the given changes by path, in place. Changes to the same
+// path keep their order.
+func SortChanges(changes []Change) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
 }
@@ -4,7 +4,10 @@
 
 package docker
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestChangeString(t *testing.T) {
 	t.Parallel()
@@ -27,3 +30,66 @@ func TestChangeString(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterChanges(t *testing.T) {
+	t.Parallel()
+	changes := []Change{
+		{"/etc", ChangeModify},
+		{"/etc/passwd", ChangeModify},
+		{"/tmp/file", ChangeAdd},
+		{"/var/cache/apt", ChangeDelete},
+	}
+	tests := []struct {
+		kinds    []ChangeType
+		expected []Change
+	}{
+		{[]ChangeType{ChangeAdd}, []Change{{"/tmp/file", ChangeAdd}}},
+		{[]ChangeType{ChangeModify, ChangeDelete}, []Change{
+			{"/etc", ChangeModify},
+			{"/etc/passwd", ChangeModify},
+			{"/var/cache/apt", ChangeDelete},
+		}},
+		{nil, nil},
+	}
+	for _, test := range tests {
+		if got := FilterChanges(changes, test.kinds...); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("FilterChanges(%v): want %#v. Got %#v.", test.kinds, test.expected, got)
+		}
+	}
+}
+
+func TestGroupChanges(t *testing.T) {
+	t.Parallel()
+	changes := []Change{
+		{"/etc", ChangeModify},
+		{"/tmp/file", ChangeAdd},
+		{"/etc/passwd", ChangeModify},
+	}
+	expected := map[ChangeType][]Change{
+		ChangeModify: {{"/etc", ChangeModify}, {"/etc/passwd", ChangeModify}},
+		ChangeAdd:    {{"/tmp/file", ChangeAdd}},
+	}
+	if got := GroupChanges(changes); !reflect.DeepEqual(got, expected) {
+		t.Errorf("GroupChanges: want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestSortChanges(t *testing.T) {
+	t.Parallel()
+	changes := []Change{
+		{"/tmp/file", ChangeAdd},
+		{"/etc/passwd", ChangeModify},
+		{"/etc", ChangeModify},
+		{"/etc/passwd", ChangeDelete},
+	}
+	expected := []Change{
+		{"/etc", ChangeModify},
+		{"/etc/passwd", ChangeModify},
+		{"/etc/passwd", ChangeDelete},
+		{"/tmp/file", ChangeAdd},
+	}
+	SortChanges(changes)
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("SortChanges: want %#v. Got %#v.", expected, changes)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -52,6 +53,10 @@ var (
 	// ErrInactivityTimeout is returned when a streamable call has been inactive for some time.
 	ErrInactivityTimeout = errors.New("inactivity time exceeded timeout")
 
+	// ErrDaemonNotReady is returned by WaitForDaemon when the daemon doesn't
+	// answer before the context is done.
+	ErrDaemonNotReady = errors.New("docker daemon not ready")
+
 	apiVersion112, _ = NewAPIVersion("1.12")
 	apiVersion118, _ = NewAPIVersion("1.18")
 	apiVersion119, _ = NewAPIVersion("1.19")
@@ -59,6 +64,11 @@ var (
 	apiVersion124, _ = NewAPIVersion("1.24")
 	apiVersion125, _ = NewAPIVersion("1.25")
 	apiVersion135, _ = NewAPIVersion("1.35")
+	apiVersion139, _ = NewAPIVersion("1.39")
+	apiVersion142, _ = NewAPIVersion("1.42")
+	apiVersion143, _ = NewAPIVersion("1.43")
+	apiVersion144, _ = NewAPIVersion("1.44")
+	apiVersion145, _ = NewAPIVersion("1.45")
 )
 
 // APIVersion is an internal representation of a version of the Remote API.
@@ -136,18 +146,75 @@ func (version APIVersion) compare(other APIVersion) int {
 
 // Client is the basic type of this package. It provides methods for
 // interaction with the API.
+//
+// A Client is safe for concurrent use by multiple goroutines, once
+// configured: its exported fields must not be modified while it's in use.
+// The API version of the server, queried on first use, and the event
+// monitor are internally synchronized.
 type Client struct {
 	SkipServerVersionCheck bool
 	HTTPClient             *http.Client
 	TLSConfig              *tls.Config
 	Dialer                 Dialer
 
+	// WarningHandler, when set, is called with the warnings sent by the
+	// daemon, both in the Warning headers of responses and in the results
+	// of operations like CreateContainer. It may be called concurrently.
+	WarningHandler func(Warning)
+
 	endpoint            string
 	endpointURL         *url.URL
 	eventMonitor        *eventMonitoringState
+	lifecycle           *clientLifecycle
+	locks               *clientLocks
 	requestedAPIVersion APIVersion
 	serverAPIVersion    APIVersion
 	expectedAPIVersion  APIVersion
+	capabilities        *Capabilities
+	inspectCache        *inspectCache
+}
+
+// clientLocks guards the fields of a Client that are set after its
+// creation. It's held by pointer, so that Client values can be copied.
+type clientLocks struct {
+	// version guards serverAPIVersion and expectedAPIVersion, which are set
+	// lazily by checkAPIVersion.
+	version sync.RWMutex
+
+	// capabilities guards capabilities, which is set by the first
+	// successful call to Capabilities.
+	capabilities sync.Mutex
+
+	// inspectCache guards inspectCache, which is set by EnableInspectCache.
+	inspectCache sync.RWMutex
+}
+
+func newClientLocks() *clientLocks {
+	return new(clientLocks)
+}
+
+// The accessors below return a new mutex for clients built without locks
+// (as struct literals), which must not be used concurrently.
+
+func (l *clientLocks) versionMu() *sync.RWMutex {
+	if l == nil {
+		return new(sync.RWMutex)
+	}
+	return &l.version
+}
+
+func (l *clientLocks) capabilitiesMu() *sync.Mutex {
+	if l == nil {
+		return new(sync.Mutex)
+	}
+	return &l.capabilities
+}
+
+func (l *clientLocks) inspectCacheMu() *sync.RWMutex {
+	if l == nil {
+		return new(sync.RWMutex)
+	}
+	return &l.inspectCache
 }
 
 // Dialer is an interface that allows network connections to be dialed
@@ -213,14 +280,21 @@ func NewVersionedClient(endpoint string, apiVersionString string) (*Client, erro
 		endpoint:            endpoint,
 		endpointURL:         u,
 		eventMonitor:        new(eventMonitoringState),
+		lifecycle:           newClientLifecycle(),
+		locks:               newClientLocks(),
 		requestedAPIVersion: requestedAPIVersion,
 	}
+	c.SetRedirectPolicy(RedirectPolicy{})
 	c.initializeNativeClient(defaultTransport)
 	return c, nil
 }
 
 // WithTransport replaces underlying HTTP client of Docker Client by accepting
 // a function that returns pointer to a transport object.
+//
+// The transport is only used for Unix socket and named pipe endpoints. Use
+// NewClientWithOptions to customize the transport of other endpoints,
+// including TLS ones.
 func (c *Client) WithTransport(trFunc func() *http.Transport) {
 	c.initializeNativeClient(trFunc)
 }
@@ -235,28 +309,35 @@ func NewVersionnedTLSClient(endpoint string, cert, key, ca, apiVersionString str
 // NewVersionedTLSClient returns a Client instance ready for TLS communications with the givens
 // server endpoint, key and certificates, using a specific remote API version.
 func NewVersionedTLSClient(endpoint string, cert, key, ca, apiVersionString string) (*Client, error) {
-	var certPEMBlock []byte
-	var keyPEMBlock []byte
-	var caPEMCert []byte
+	certPEMBlock, keyPEMBlock, caPEMCert, err := readTLSFiles(cert, key, ca)
+	if err != nil {
+		return nil, err
+	}
+	return NewVersionedTLSClientFromBytes(endpoint, certPEMBlock, keyPEMBlock, caPEMCert, apiVersionString)
+}
+
+// readTLSFiles reads the given certificate, key and CA files, skipping the
+// ones that don't exist.
+func readTLSFiles(cert, key, ca string) (certPEMBlock, keyPEMBlock, caPEMCert []byte, err error) {
 	if _, err := os.Stat(cert); !os.IsNotExist(err) {
 		certPEMBlock, err = os.ReadFile(cert)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
 	if _, err := os.Stat(key); !os.IsNotExist(err) {
 		keyPEMBlock, err = os.ReadFile(key)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
 	if _, err := os.Stat(ca); !os.IsNotExist(err) {
 		caPEMCert, err = os.ReadFile(ca)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
-	return NewVersionedTLSClientFromBytes(endpoint, certPEMBlock, keyPEMBlock, caPEMCert, apiVersionString)
+	return certPEMBlock, keyPEMBlock, caPEMCert, nil
 }
 
 // NewClientFromEnv returns a Client instance ready for communication created from
@@ -316,6 +397,32 @@ func NewVersionedTLSClientFromBytes(endpoint string, certPEMBlock, keyPEMBlock,
 			return nil, err
 		}
 	}
+	tlsConfig, err := newTLSConfig(certPEMBlock, keyPEMBlock, caPEMCert)
+	if err != nil {
+		return nil, err
+	}
+	tr := defaultTransport()
+	tr.TLSClientConfig = tlsConfig
+	c := &Client{
+		HTTPClient:          &http.Client{Transport: tr},
+		TLSConfig:           tlsConfig,
+		Dialer:              &net.Dialer{},
+		endpoint:            endpoint,
+		endpointURL:         u,
+		eventMonitor:        new(eventMonitoringState),
+		lifecycle:           newClientLifecycle(),
+		locks:               newClientLocks(),
+		requestedAPIVersion: requestedAPIVersion,
+	}
+	c.SetRedirectPolicy(RedirectPolicy{})
+	c.initializeNativeClient(defaultTransport)
+	return c, nil
+}
+
+// newTLSConfig returns the TLS configuration for the given client
+// certificate, key and CA certificate. The server certificate isn't verified
+// when no CA certificate is given.
+func newTLSConfig(certPEMBlock, keyPEMBlock, caPEMCert []byte) (*tls.Config, error) {
 	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
 	if certPEMBlock != nil && keyPEMBlock != nil {
 		tlsCert, err := tls.X509KeyPair(certPEMBlock, keyPEMBlock)
@@ -333,22 +440,7 @@ func NewVersionedTLSClientFromBytes(endpoint string, certPEMBlock, keyPEMBlock,
 		}
 		tlsConfig.RootCAs = caPool
 	}
-	tr := defaultTransport()
-	tr.TLSClientConfig = tlsConfig
-	if err != nil {
-		return nil, err
-	}
-	c := &Client{
-		HTTPClient:          &http.Client{Transport: tr},
-		TLSConfig:           tlsConfig,
-		Dialer:              &net.Dialer{},
-		endpoint:            endpoint,
-		endpointURL:         u,
-		eventMonitor:        new(eventMonitoringState),
-		requestedAPIVersion: requestedAPIVersion,
-	}
-	c.initializeNativeClient(defaultTransport)
-	return c, nil
+	return tlsConfig, nil
 }
 
 // SetTimeout takes a timeout and applies it to the HTTPClient. It should not
@@ -364,10 +456,14 @@ func (c *Client) checkAPIVersion() error {
 	if err != nil {
 		return err
 	}
-	c.serverAPIVersion, err = NewAPIVersion(serverAPIVersionString)
+	serverAPIVersion, err := NewAPIVersion(serverAPIVersionString)
 	if err != nil {
 		return err
 	}
+	mu := c.locks.versionMu()
+	mu.Lock()
+	defer mu.Unlock()
+	c.serverAPIVersion = serverAPIVersion
 	if c.requestedAPIVersion == nil {
 		c.expectedAPIVersion = c.serverAPIVersion
 	} else {
@@ -376,6 +472,40 @@ func (c *Client) checkAPIVersion() error {
 	return nil
 }
 
+// ensureAPIVersion queries the API version of the server, unless it's known
+// already or the client skips the check.
+func (c *Client) ensureAPIVersion() error {
+	if c.SkipServerVersionCheck || c.expectedVersion() != nil {
+		return nil
+	}
+	return c.checkAPIVersion()
+}
+
+// serverVersion returns the API version of the server, querying it when it's
+// not known yet. It returns nil when the version can't be determined.
+func (c *Client) serverVersion() APIVersion {
+	mu := c.locks.versionMu()
+	mu.RLock()
+	version := c.serverAPIVersion
+	mu.RUnlock()
+	if version == nil {
+		c.checkAPIVersion()
+		mu.RLock()
+		version = c.serverAPIVersion
+		mu.RUnlock()
+	}
+	return version
+}
+
+// expectedVersion returns the API version used in requests, or nil if it's
+// not known yet.
+func (c *Client) expectedVersion() APIVersion {
+	mu := c.locks.versionMu()
+	mu.RLock()
+	defer mu.RUnlock()
+	return c.expectedAPIVersion
+}
+
 // Endpoint returns the current endpoint. It's useful for getting the endpoint
 // when using functions that get this data from the environment (like
 // NewClientFromEnv.
@@ -407,6 +537,40 @@ func (c *Client) PingWithContext(ctx context.Context) error {
 	return nil
 }
 
+// WaitForDaemon pings the docker server every interval until it answers,
+// which is useful when the daemon is still starting, returning nil once it
+// does. DefaultReadyInterval is used when interval isn't positive.
+//
+// Once ctx is done, WaitForDaemon gives up, returning an error wrapping
+// ErrDaemonNotReady, the error of the context and the error of the last
+// failed ping.
+func (c *Client) WaitForDaemon(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultReadyInterval
+	}
+	var lastErr error
+	for {
+		err := c.PingWithContext(ctx)
+		if err == nil {
+			return nil
+		}
+		// a ping interrupted by the context tells nothing about the daemon
+		if ctx.Err() == nil || lastErr == nil {
+			lastErr = err
+		}
+		if ctx.Err() == nil {
+			timer := time.NewTimer(interval)
+			select {
+			case <-timer.C:
+				continue
+			case <-ctx.Done():
+				timer.Stop()
+			}
+		}
+		return fmt.Errorf("%w: %w: %w", ErrDaemonNotReady, ctx.Err(), lastErr)
+	}
+}
+
 func (c *Client) getServerAPIVersionString() (version string, err error) {
 	resp, err := c.do(http.MethodGet, "/version", doOptions{})
 	if err != nil {
@@ -416,14 +580,13 @@ func (c *Client) getServerAPIVersionString() (version string, err error) {
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("received unexpected status %d while trying to retrieve the server version", resp.StatusCode)
 	}
-	var versionResponse map[string]any
+	var versionResponse struct {
+		APIVersion string `json:"ApiVersion"`
+	}
 	if err := json.NewDecoder(resp.Body).Decode(&versionResponse); err != nil {
 		return "", err
 	}
-	if version, ok := (versionResponse["ApiVersion"]).(string); ok {
-		return version, nil
-	}
-	return "", nil
+	return versionResponse.APIVersion, nil
 }
 
 type doOptions struct {
@@ -434,16 +597,21 @@ type doOptions struct {
 }
 
 func (c *Client) do(method, path string, doOptions doOptions) (*http.Response, error) {
+	if c.lifecycle.isClosed() {
+		return nil, ErrClientClosed
+	}
 	var params io.Reader
+	var body []byte
 	if doOptions.data != nil || doOptions.forceJSON {
-		buf, err := json.Marshal(doOptions.data)
+		var err error
+		body, err = json.Marshal(doOptions.data)
 		if err != nil {
 			return nil, err
 		}
-		params = bytes.NewBuffer(buf)
+		params = bytes.NewBuffer(body)
 	}
-	if path != "/version" && !c.SkipServerVersionCheck && c.expectedAPIVersion == nil {
-		err := c.checkAPIVersion()
+	if path != "/version" {
+		err := c.ensureAPIVersion()
 		if err != nil {
 			return nil, err
 		}
@@ -485,9 +653,11 @@ func (c *Client) do(method, path string, doOptions doOptions) (*http.Response, e
 
 		return nil, chooseError(ctx, err)
 	}
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+	c.invalidateInspectCacheAfter(method, path, body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest || isUnfollowedRedirect(resp) {
 		return nil, newError(resp)
 	}
+	c.warnHeaders(method, path, resp)
 	return resp, nil
 }
 
@@ -506,6 +676,12 @@ type streamOptions struct {
 	// arrives
 	inactivityTimeout time.Duration
 	context           context.Context
+	// jsonMessageHandler, when set, is called with every message of a JSON
+	// stream, regardless of how the stream is written to stdout.
+	jsonMessageHandler func(*JSONMessage)
+	// responseHandler, when set, is called with the response once its
+	// status is checked, before the body is read.
+	responseHandler func(*http.Response)
 }
 
 func chooseError(ctx context.Context, err error) error {
@@ -521,8 +697,8 @@ func (c *Client) stream(method, path string, streamOptions streamOptions) error
 	if (method == http.MethodPost || method == http.MethodPut) && streamOptions.in == nil {
 		streamOptions.in = bytes.NewReader(nil)
 	}
-	if path != "/version" && !c.SkipServerVersionCheck && c.expectedAPIVersion == nil {
-		err := c.checkAPIVersion()
+	if path != "/version" {
+		err := c.ensureAPIVersion()
 		if err != nil {
 			return err
 		}
@@ -534,11 +710,8 @@ func (c *Client) streamURL(method, url string, streamOptions streamOptions) erro
 	if (method == http.MethodPost || method == http.MethodPut) && streamOptions.in == nil {
 		streamOptions.in = bytes.NewReader(nil)
 	}
-	if !c.SkipServerVersionCheck && c.expectedAPIVersion == nil {
-		err := c.checkAPIVersion()
-		if err != nil {
-			return err
-		}
+	if err := c.ensureAPIVersion(); err != nil {
+		return err
 	}
 
 	// make a sub-context so that our active cancellation does not affect parent
@@ -548,11 +721,17 @@ func (c *Client) streamURL(method, url string, streamOptions streamOptions) erro
 	}
 	subCtx, cancelRequest := context.WithCancel(ctx)
 	defer cancelRequest()
+	release, err := c.track(cancelRequest)
+	if err != nil {
+		return err
+	}
+	defer release()
 
 	req, err := http.NewRequestWithContext(ctx, method, url, streamOptions.in)
 	if err != nil {
 		return err
 	}
+	defer c.invalidateInspectCacheAfter(method, req.URL.Path, nil)
 	req.Header.Set("User-Agent", userAgent)
 	if method == http.MethodPost {
 		req.Header.Set("Content-Type", "plain/text")
@@ -618,9 +797,13 @@ func (c *Client) streamURL(method, url string, streamOptions streamOptions) erro
 			close(streamOptions.reqSent)
 		}
 	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 || isUnfollowedRedirect(resp) {
 		return newError(resp)
 	}
+	c.warnHeaders(method, req.URL.Path, resp)
+	if streamOptions.responseHandler != nil {
+		streamOptions.responseHandler(resp)
+	}
 	var canceled uint32
 	if streamOptions.inactivityTimeout > 0 {
 		var ch chan<- struct{}
@@ -647,16 +830,22 @@ func handleStreamResponse(resp *http.Response, streamOptions *streamOptions) err
 		}
 		return err
 	}
+	var body io.Reader = resp.Body
+	if streamOptions.jsonMessageHandler != nil {
+		w := &jsonMessageWatcher{handler: streamOptions.jsonMessageHandler}
+		defer w.flush()
+		body = io.TeeReader(resp.Body, w)
+	}
 	// if we want to get raw json stream, just copy it back to output
 	// without decoding it
 	if streamOptions.rawJSONStream {
-		_, err = io.Copy(streamOptions.stdout, resp.Body)
+		_, err = io.Copy(streamOptions.stdout, body)
 		return err
 	}
 	if st, ok := streamOptions.stdout.(stream); ok {
-		err = jsonmessage.DisplayJSONMessagesToStream(resp.Body, st, nil)
+		err = jsonmessage.DisplayJSONMessagesToStream(body, st, nil)
 	} else {
-		err = jsonmessage.DisplayJSONMessagesStream(resp.Body, streamOptions.stdout, 0, false, nil)
+		err = jsonmessage.DisplayJSONMessagesStream(body, streamOptions.stdout, 0, false, nil)
 	}
 	return err
 }
@@ -711,6 +900,14 @@ type hijackOptions struct {
 	stdout         io.Writer
 	stderr         io.Writer
 	data           any
+	// writeTimeout is the deadline of every write of in to the connection
+	writeTimeout time.Duration
+	// keepAlive is the period of the TCP keep-alive probes of the
+	// connection
+	keepAlive time.Duration
+	// detachKeys, when set, is the key sequence that ends the session when
+	// read from in
+	detachKeys []byte
 }
 
 // CloseWaiter is an interface with methods for closing the underlying resource
@@ -729,8 +926,8 @@ type closerFunc func() error
 func (c closerFunc) Close() error { return c() }
 
 func (c *Client) hijack(method, path string, hijackOptions hijackOptions) (CloseWaiter, error) {
-	if path != "/version" && !c.SkipServerVersionCheck && c.expectedAPIVersion == nil {
-		err := c.checkAPIVersion()
+	if path != "/version" {
+		err := c.ensureAPIVersion()
 		if err != nil {
 			return nil, err
 		}
@@ -772,10 +969,22 @@ func (c *Client) hijack(method, path string, hijackOptions hijackOptions) (Close
 			return nil, err
 		}
 	}
+	if hijackOptions.keepAlive > 0 {
+		if err := setKeepAlive(dial, hijackOptions.keepAlive); err != nil {
+			dial.Close()
+			return nil, err
+		}
+	}
+	release, err := c.track(func() { dial.Close() })
+	if err != nil {
+		dial.Close()
+		return nil, err
+	}
 
 	errs := make(chan error, 1)
 	quit := make(chan struct{})
 	go func() {
+		defer release()
 		//lint:ignore SA1019 the alternative doesn't quite work, so keep using the deprecated thing.
 		clientconn := httputil.NewClientConn(dial, nil)
 		defer clientconn.Close()
@@ -825,8 +1034,11 @@ func (c *Client) hijack(method, path string, hijackOptions hijackOptions) (Close
 
 		go func() {
 			var err error
-			if hijackOptions.in != nil {
-				_, err = io.Copy(rwc, hijackOptions.in)
+			if in := hijackOptions.in; in != nil {
+				if len(hijackOptions.detachKeys) > 0 {
+					in = &detachReader{r: in, keys: hijackOptions.detachKeys}
+				}
+				_, err = io.Copy(&deadlineWriter{conn: rwc, timeout: hijackOptions.writeTimeout}, in)
 			}
 			errChanIn <- err
 			rwc.(interface {
@@ -839,6 +1051,10 @@ func (c *Client) hijack(method, path string, hijackOptions hijackOptions) (Close
 		case errIn = <-errChanIn:
 		case <-quit:
 		}
+		if errIn != nil {
+			// the peer is gone, unblocks the copy of the output
+			rwc.Close()
+		}
 
 		var errOut error
 		select {
@@ -846,7 +1062,9 @@ func (c *Client) hijack(method, path string, hijackOptions hijackOptions) (Close
 		case <-quit:
 		}
 
-		if errIn != nil {
+		if errors.Is(errIn, errDetached) {
+			errs <- nil
+		} else if errIn != nil {
 			errs <- errIn
 		} else {
 			errs <- errOut
@@ -862,6 +1080,39 @@ func (c *Client) hijack(method, path string, hijackOptions hijackOptions) (Close
 	}, nil
 }
 
+// deadlineWriter is a writer to a connection that fails when a write takes
+// longer than timeout, unless timeout is zero.
+type deadlineWriter struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (w *deadlineWriter) Write(p []byte) (int, error) {
+	if w.timeout > 0 {
+		if err := w.conn.SetWriteDeadline(time.Now().Add(w.timeout)); err != nil {
+			return 0, err
+		}
+	}
+	return w.conn.Write(p)
+}
+
+// setKeepAlive enables TCP keep-alive probes on conn, so dead peers are
+// detected. It's a no-op for connections that aren't TCP, like Unix
+// sockets.
+func setKeepAlive(conn net.Conn, period time.Duration) error {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	if err := tcpConn.SetKeepAlive(true); err != nil {
+		return err
+	}
+	return tcpConn.SetKeepAlivePeriod(period)
+}
+
 func (c *Client) getURL(path string) string {
 	urlStr := strings.TrimRight(c.endpointURL.String(), "/")
 	if c.endpointURL.Scheme == unixProtocol || c.endpointURL.Scheme == namedPipeProtocol {
@@ -878,6 +1129,32 @@ func (c *Client) getPath(basepath string, opts any) (string, error) {
 	return c.pathVersionCheck(basepath, queryStr, requiredAPIVersion)
 }
 
+// checkRequiredAPIVersion fails if the API version used by the client, the
+// requested one or, when none was requested, the version of the server, is
+// older than the version required by a request for path. The version of the
+// server is assumed to be the latest when the client skips checking it, and
+// the check is skipped when it can't be determined.
+func (c *Client) checkRequiredAPIVersion(path string, requiredAPIVersion APIVersion) error {
+	if requiredAPIVersion == nil {
+		return nil
+	}
+	if c.requestedAPIVersion != nil {
+		if c.requestedAPIVersion.LessThan(requiredAPIVersion) {
+			return fmt.Errorf("API %s requires version %s, requested version %s is insufficient",
+				path, requiredAPIVersion, c.requestedAPIVersion)
+		}
+		return nil
+	}
+	if c.SkipServerVersionCheck {
+		return nil
+	}
+	if serverAPIVersion := c.serverVersion(); serverAPIVersion != nil && serverAPIVersion.LessThan(requiredAPIVersion) {
+		return fmt.Errorf("API %s requires version %s, server version %s is insufficient",
+			path, requiredAPIVersion, serverAPIVersion)
+	}
+	return nil
+}
+
 func (c *Client) pathVersionCheck(basepath, queryStr string, requiredAPIVersion APIVersion) (string, error) {
 	urlStr := strings.TrimRight(c.endpointURL.String(), "/")
 	if c.endpointURL.Scheme == unixProtocol || c.endpointURL.Scheme == namedPipeProtocol {
@@ -912,6 +1189,45 @@ func (c *Client) getFakeNativeURL(path string) string {
 	return fmt.Sprintf("%s%s", urlStr, path)
 }
 
+// queryField is a field of an options struct that's sent in query strings.
+type queryField struct {
+	index   int
+	key     string
+	version APIVersion
+}
+
+// queryFieldsCache maps the types of options structs to their []queryField.
+var queryFieldsCache sync.Map
+
+// queryFields returns the fields of the given struct type that are sent in
+// query strings, along with their qs and ver tags. The result is cached, so
+// tags are only parsed once per type.
+func queryFields(t reflect.Type) []queryField {
+	if fields, ok := queryFieldsCache.Load(t); ok {
+		return fields.([]queryField)
+	}
+	var fields []queryField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		key := field.Tag.Get("qs")
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		} else if key == "-" {
+			continue
+		}
+		f := queryField{index: i, key: key}
+		if verstr := field.Tag.Get("ver"); verstr != "" {
+			f.version, _ = NewAPIVersion(verstr)
+		}
+		fields = append(fields, f)
+	}
+	cached, _ := queryFieldsCache.LoadOrStore(t, fields)
+	return cached.([]queryField)
+}
+
 func queryStringVersion(opts any) (string, APIVersion) {
 	if opts == nil {
 		return "", nil
@@ -925,26 +1241,10 @@ func queryStringVersion(opts any) (string, APIVersion) {
 	}
 	var apiVersion APIVersion
 	items := url.Values(map[string][]string{})
-	for i := 0; i < value.NumField(); i++ {
-		field := value.Type().Field(i)
-		if field.PkgPath != "" {
-			continue
-		}
-		key := field.Tag.Get("qs")
-		if key == "" {
-			key = strings.ToLower(field.Name)
-		} else if key == "-" {
-			continue
-		}
-		if addQueryStringValue(items, key, value.Field(i)) {
-			verstr := field.Tag.Get("ver")
-			if verstr != "" {
-				ver, _ := NewAPIVersion(verstr)
-				if apiVersion == nil {
-					apiVersion = ver
-				} else if ver.GreaterThan(apiVersion) {
-					apiVersion = ver
-				}
+	for _, field := range queryFields(value.Type()) {
+		if addQueryStringValue(items, field.key, value.Field(field.index)) && field.version != nil {
+			if apiVersion == nil || field.version.GreaterThan(apiVersion) {
+				apiVersion = field.version
 			}
 		}
 	}
@@ -991,7 +1291,7 @@ func addQueryStringValue(items url.Values, key string, v reflect.Value) bool {
 			}
 		}
 	case reflect.Map:
-		if len(v.MapKeys()) > 0 {
+		if v.Len() > 0 {
 			if b, err := json.Marshal(v.Interface()); err == nil {
 				items.Add(key, string(b))
 				return true
@@ -1030,7 +1330,10 @@ func newError(resp *http.Response) *Error {
 	var emsg ErrMsg
 	err = json.Unmarshal(data, &emsg)
 	if err != nil {
-		return &Error{Status: resp.StatusCode, Message: string(data)}
+		emsg.Message = string(data)
+	}
+	if location := resp.Header.Get("Location"); emsg.Message == "" && isUnfollowedRedirect(resp) && location != "" {
+		emsg.Message = "redirect to " + location + " not followed"
 	}
 	return &Error{Status: resp.StatusCode, Message: emsg.Message}
 }
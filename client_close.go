@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrClientClosed is the error returned by the operations started after the
+// client was closed.
+var ErrClientClosed = errors.New("docker client closed")
+
+// clientLifecycle tracks the streaming operations and internal goroutines of
+// a Client, so Close can interrupt them and wait for them to finish.
+type clientLifecycle struct {
+	mu     sync.Mutex
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newClientLifecycle() *clientLifecycle {
+	return &clientLifecycle{done: make(chan struct{})}
+}
+
+// start registers an operation, returning false if the client is closed.
+// Clients built without a lifecycle (as struct literals) never close.
+func (l *clientLifecycle) start() bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false
+	}
+	l.wg.Add(1)
+	return true
+}
+
+func (l *clientLifecycle) finish() {
+	if l != nil {
+		l.wg.Done()
+	}
+}
+
+func (l *clientLifecycle) isClosed() bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// track registers an in-flight operation, which is interrupted by calling
+// cancel when the client is closed. The returned function must be called
+// once the operation is over.
+func (c *Client) track(cancel func()) (release func(), err error) {
+	l := c.lifecycle
+	if !l.start() {
+		return nil, ErrClientClosed
+	}
+	if l == nil {
+		return func() {}, nil
+	}
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-l.done:
+			cancel()
+		case <-stop:
+		}
+	}()
+	return func() {
+		close(stop)
+		l.finish()
+	}, nil
+}
+
+// goInternal runs f in a goroutine that Close waits for. f isn't run if the
+// client is closed.
+func (c *Client) goInternal(f func()) {
+	if !c.lifecycle.start() {
+		return
+	}
+	go func() {
+		defer c.lifecycle.finish()
+		f()
+	}()
+}
+
+// Close shuts the client down: it interrupts the streaming operations in
+// progress (events, stats, logs, attach and exec sessions, among others),
+// closing the event listeners, closes the idle connections and waits for
+// the goroutines of the client to finish. Interrupted operations return an
+// error, and operations started after Close return ErrClientClosed.
+//
+// Calling Close more than once is a no-op.
+func (c *Client) Close() error {
+	if l := c.lifecycle; l != nil {
+		l.mu.Lock()
+		closed := l.closed
+		if !closed {
+			l.closed = true
+			close(l.done)
+		}
+		l.mu.Unlock()
+		if closed {
+			return nil
+		}
+	}
+	if c.eventMonitor != nil && c.eventMonitor.isEnabled() {
+		c.eventMonitor.disableEventMonitoring()
+	}
+	if c.HTTPClient != nil {
+		c.HTTPClient.CloseIdleConnections()
+	}
+	if c.lifecycle != nil {
+		c.lifecycle.wg.Wait()
+	}
+	return nil
+}
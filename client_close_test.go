@@ -0,0 +1,111 @@
+package docker
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientClose(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/abc/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"read":"2024-05-01T10:00:00Z"}`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	})
+	mux.HandleFunc("/containers/abc/attach", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		// blocks until the client closes the connection
+		io.Copy(io.Discard, conn)
+	})
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Action":"start","Type":"container","Actor":{"ID":"abc"},"time":1}`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	defer close(done)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+
+	stats := make(chan *Stats)
+	statsErr := make(chan error, 1)
+	go func() {
+		statsErr <- client.Stats(StatsOptions{ID: "abc", Stats: stats, Stream: true})
+	}()
+	<-stats
+	cw, err := client.AttachToContainerNonBlocking(AttachToContainerOptions{
+		Container:    "abc",
+		OutputStream: io.Discard,
+		Stdout:       true,
+		Stream:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener := make(chan *APIEvents, 10)
+	if err := client.AddEventListener(listener); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-listener:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the first event")
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- client.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Close to return")
+	}
+	select {
+	case <-statsErr:
+	case <-time.After(time.Second):
+		t.Error("Close: the stats stream wasn't interrupted")
+	}
+	waited := make(chan struct{})
+	go func() {
+		cw.Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Error("Close: the attach session wasn't interrupted")
+	}
+	for range listener {
+	}
+
+	if _, err := client.InspectContainerWithOptions(InspectContainerOptions{ID: "abc"}); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("InspectContainer after Close: wrong error. Want %#v. Got %#v.", ErrClientClosed, err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("Close: unexpected error on second call: %v", err)
+	}
+}
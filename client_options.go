@@ -0,0 +1,230 @@
+package docker
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ErrConflictingClientOptions is returned by NewClientWithOptions when the
+// given options can't be applied together, for instance when two options
+// configure TLS, or when TLS is requested for an http.Client whose transport
+// already has a different TLS configuration.
+var ErrConflictingClientOptions = errors.New("conflicting client options")
+
+// ClientOption configures the Client created by NewClientWithOptions.
+type ClientOption func(*clientOptions) error
+
+type clientOptions struct {
+	apiVersion string
+	httpClient *http.Client
+	wrappers   []func(http.RoundTripper) http.RoundTripper
+	dialer     Dialer
+
+	tlsConfig *tls.Config
+	tlsSource string // the option that set tlsConfig
+}
+
+// WithAPIVersion sets the API version used by the client, instead of the
+// latest version available in the server.
+func WithAPIVersion(version string) ClientOption {
+	return func(o *clientOptions) error {
+		o.apiVersion = version
+		return nil
+	}
+}
+
+// WithHTTPClient makes the client send its requests through a copy of the
+// given http.Client, keeping its timeout, cookie jar, redirect policy and
+// transport. The given client is never modified.
+//
+// When TLS is configured, or the endpoint is a Unix socket or a named pipe,
+// the transport must be an *http.Transport, which is cloned and completed
+// with the TLS configuration or the socket dialer. Other transports can't be
+// completed, so they must be added with WithTransportWrapper instead.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(o *clientOptions) error {
+		if client == nil {
+			return fmt.Errorf("%w: nil http.Client", ErrConflictingClientOptions)
+		}
+		if o.httpClient != nil {
+			return fmt.Errorf("%w: http.Client set more than once", ErrConflictingClientOptions)
+		}
+		o.httpClient = client
+		return nil
+	}
+}
+
+// WithTransportWrapper wraps the transport of the client, after it's been
+// configured for TLS and for the endpoint, so that middlewares like tracing
+// or logging see every request without replacing the transport. Wrappers are
+// applied in order, so the last one sees the requests first.
+func WithTransportWrapper(wrap func(http.RoundTripper) http.RoundTripper) ClientOption {
+	return func(o *clientOptions) error {
+		o.wrappers = append(o.wrappers, wrap)
+		return nil
+	}
+}
+
+// WithDialer sets the dialer used for hijacked connections, like the ones
+// of attach and exec, and for connecting to Unix sockets.
+func WithDialer(dialer Dialer) ClientOption {
+	return func(o *clientOptions) error {
+		o.dialer = dialer
+		return nil
+	}
+}
+
+// WithTLSConfig makes the client connect to the endpoint using the given TLS
+// configuration.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(o *clientOptions) error {
+		return o.setTLSConfig("WithTLSConfig", config)
+	}
+}
+
+// WithTLSFiles makes the client connect to the endpoint using TLS, with the
+// certificate, key and CA certificate in the given files, like
+// NewTLSClient. Files that don't exist are ignored.
+func WithTLSFiles(cert, key, ca string) ClientOption {
+	return func(o *clientOptions) error {
+		certPEMBlock, keyPEMBlock, caPEMCert, err := readTLSFiles(cert, key, ca)
+		if err != nil {
+			return err
+		}
+		return WithTLSBytes(certPEMBlock, keyPEMBlock, caPEMCert)(o)
+	}
+}
+
+// WithTLSBytes makes the client connect to the endpoint using TLS, with the
+// given PEM encoded certificate, key and CA certificate, like
+// NewTLSClientFromBytes.
+func WithTLSBytes(certPEMBlock, keyPEMBlock, caPEMCert []byte) ClientOption {
+	return func(o *clientOptions) error {
+		config, err := newTLSConfig(certPEMBlock, keyPEMBlock, caPEMCert)
+		if err != nil {
+			return err
+		}
+		return o.setTLSConfig("WithTLSBytes", config)
+	}
+}
+
+func (o *clientOptions) setTLSConfig(source string, config *tls.Config) error {
+	if config == nil {
+		return fmt.Errorf("%w: nil TLS configuration in %s", ErrConflictingClientOptions, source)
+	}
+	if o.tlsConfig != nil {
+		return fmt.Errorf("%w: TLS configured by both %s and %s", ErrConflictingClientOptions, o.tlsSource, source)
+	}
+	o.tlsConfig = config
+	o.tlsSource = source
+	return nil
+}
+
+// NewClientWithOptions returns a Client instance ready for communication with
+// the given server endpoint, configured by the given options. It's the
+// preferred way of combining a custom http.Client or transport with TLS:
+//
+//	client, err := docker.NewClientWithOptions("tcp://docker:2376",
+//		docker.WithHTTPClient(&http.Client{Timeout: time.Minute}),
+//		docker.WithTLSFiles(cert, key, ca),
+//		docker.WithTransportWrapper(logRequests),
+//	)
+//
+// Unlike setting the HTTPClient field after creating a TLS client, options
+// are never silently overridden: options that can't be honored together make
+// it fail with ErrConflictingClientOptions.
+func NewClientWithOptions(endpoint string, opts ...ClientOption) (*Client, error) {
+	var o clientOptions
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+	u, err := parseEndpoint(endpoint, o.tlsConfig != nil)
+	if err != nil {
+		return nil, err
+	}
+	var requestedAPIVersion APIVersion
+	if strings.Contains(o.apiVersion, ".") {
+		requestedAPIVersion, err = NewAPIVersion(o.apiVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+	c := &Client{
+		SkipServerVersionCheck: requestedAPIVersion == nil,
+		HTTPClient:             defaultClient(),
+		TLSConfig:              o.tlsConfig,
+		Dialer:                 o.dialer,
+		endpoint:               endpoint,
+		endpointURL:            u,
+		eventMonitor:           new(eventMonitoringState),
+		lifecycle:              newClientLifecycle(),
+		locks:                  newClientLocks(),
+		requestedAPIVersion:    requestedAPIVersion,
+	}
+	if c.Dialer == nil {
+		c.Dialer = &net.Dialer{}
+	}
+	if o.httpClient != nil {
+		httpClient := *o.httpClient
+		c.HTTPClient = &httpClient
+	}
+	if c.HTTPClient.CheckRedirect == nil {
+		c.SetRedirectPolicy(RedirectPolicy{})
+	}
+	if err := o.configureTransport(c); err != nil {
+		return nil, err
+	}
+	for _, wrap := range o.wrappers {
+		c.HTTPClient.Transport = wrap(c.HTTPClient.Transport)
+	}
+	return c, nil
+}
+
+// configureTransport completes the transport of the client with the TLS
+// configuration and the dialer of native endpoints, cloning it so that the
+// transport of an http.Client given by the caller is left untouched.
+func (o *clientOptions) configureTransport(c *Client) error {
+	scheme := c.endpointURL.Scheme
+	native := scheme == unixProtocol || scheme == namedPipeProtocol
+	if o.tlsConfig == nil && !native {
+		return nil
+	}
+	rt := c.HTTPClient.Transport
+	if rt == nil {
+		rt = defaultTransport()
+	}
+	tr, ok := rt.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("%w: the transport of the http.Client is a %T, not an *http.Transport, so it can't be configured for TLS or native sockets; use WithTransportWrapper to wrap the client's transport instead", ErrConflictingClientOptions, rt)
+	}
+	if o.tlsConfig != nil && hasTLSSettings(tr.TLSClientConfig) && tr.TLSClientConfig != o.tlsConfig {
+		return fmt.Errorf("%w: the transport of the http.Client already has a TLS configuration, which would be replaced by %s", ErrConflictingClientOptions, o.tlsSource)
+	}
+	if tr == http.DefaultTransport {
+		tr = defaultTransport()
+	} else {
+		tr = tr.Clone()
+	}
+	if o.tlsConfig != nil {
+		tr.TLSClientConfig = o.tlsConfig
+	}
+	c.HTTPClient.Transport = tr
+	if native {
+		c.initializeNativeClient(func() *http.Transport { return tr })
+	}
+	return nil
+}
+
+// hasTLSSettings reports whether the given TLS configuration of a transport
+// was set by its owner. net/http sets an empty configuration on transports
+// when enabling HTTP/2, which can be safely replaced.
+func hasTLSSettings(config *tls.Config) bool {
+	return config != nil && (len(config.Certificates) > 0 || config.GetClientCertificate != nil ||
+		config.RootCAs != nil || config.InsecureSkipVerify || config.ServerName != "")
+}
@@ -0,0 +1,144 @@
+package docker
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestNewClientWithOptionsTLSKeepsHTTPClient(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+	userTransport := &http.Transport{MaxIdleConns: 7}
+	userClient := &http.Client{Timeout: time.Minute, Transport: userTransport}
+	var wrapped atomic.Int32
+	client, err := NewClientWithOptions(server.URL,
+		WithHTTPClient(userClient),
+		WithTLSConfig(tlsConfig),
+		WithTransportWrapper(func(rt http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				wrapped.Add(1)
+				return rt.RoundTrip(r)
+			})
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if n := wrapped.Load(); n != 1 {
+		t.Errorf("NewClientWithOptions: wrapper not called. Want 1 request. Got %d.", n)
+	}
+	if client.HTTPClient == userClient || client.HTTPClient.Timeout != time.Minute {
+		t.Errorf("NewClientWithOptions: http.Client not copied: %#v", client.HTTPClient)
+	}
+	if client.TLSConfig != tlsConfig {
+		t.Error("NewClientWithOptions: TLSConfig not set")
+	}
+	if userTransport.TLSClientConfig == tlsConfig {
+		t.Error("NewClientWithOptions: the transport of the given http.Client was modified")
+	}
+}
+
+func TestNewClientWithOptionsNativeTransport(t *testing.T) {
+	t.Parallel()
+	userTransport := &http.Transport{MaxIdleConns: 7}
+	client, err := NewClientWithOptions("unix:///var/run/docker.sock", WithHTTPClient(&http.Client{Transport: userTransport}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, ok := client.HTTPClient.Transport.(*http.Transport)
+	if !ok || tr == userTransport {
+		t.Fatalf("NewClientWithOptions: transport not cloned: %#v", client.HTTPClient.Transport)
+	}
+	if tr.MaxIdleConns != 7 || tr.DialContext == nil {
+		t.Errorf("NewClientWithOptions: wrong transport configuration: %#v", tr)
+	}
+	if userTransport.DialContext != nil {
+		t.Error("NewClientWithOptions: the transport of the given http.Client was modified")
+	}
+}
+
+func TestNewClientWithOptionsConflicts(t *testing.T) {
+	t.Parallel()
+	custom := roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, errors.New("unreachable") })
+	tests := []struct {
+		name     string
+		endpoint string
+		opts     []ClientOption
+	}{
+		{
+			"two TLS options",
+			"tcp://localhost:2376",
+			[]ClientOption{WithTLSConfig(&tls.Config{}), WithTLSBytes(nil, nil, nil)},
+		},
+		{
+			"TLS with custom transport",
+			"tcp://localhost:2376",
+			[]ClientOption{WithHTTPClient(&http.Client{Transport: custom}), WithTLSConfig(&tls.Config{})},
+		},
+		{
+			"TLS with configured transport",
+			"tcp://localhost:2376",
+			[]ClientOption{
+				WithHTTPClient(&http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{ServerName: "docker"}}}),
+				WithTLSConfig(&tls.Config{}),
+			},
+		},
+		{
+			"unix socket with custom transport",
+			"unix:///var/run/docker.sock",
+			[]ClientOption{WithHTTPClient(&http.Client{Transport: custom})},
+		},
+		{
+			"two http clients",
+			"tcp://localhost:2375",
+			[]ClientOption{WithHTTPClient(&http.Client{}), WithHTTPClient(&http.Client{})},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewClientWithOptions(tt.endpoint, tt.opts...)
+			if !errors.Is(err, ErrConflictingClientOptions) {
+				t.Errorf("NewClientWithOptions: wrong error. Want %#v. Got %#v.", ErrConflictingClientOptions, err)
+			}
+		})
+	}
+}
+
+func TestNewClientWithOptionsCustomTransportWithoutTLS(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "OK", status: http.StatusOK}
+	client, err := NewClientWithOptions("tcp://localhost:2375", WithHTTPClient(&http.Client{Transport: fakeRT}), WithAPIVersion("1.41"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.SkipServerVersionCheck {
+		t.Error("NewClientWithOptions: server version check skipped with an API version")
+	}
+	client.SkipServerVersionCheck = true
+	if err := client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if len(fakeRT.requests) != 1 || fakeRT.requests[0].URL.Path != "/v1.41/_ping" {
+		t.Errorf("NewClientWithOptions: wrong requests: %#v", fakeRT.requests)
+	}
+}
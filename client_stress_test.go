@@ -7,6 +7,8 @@
 package docker
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -134,3 +136,95 @@ func TestClientDoConcurrentStress(t *testing.T) {
 		})
 	}
 }
+
+func TestClientOperationsConcurrentStress(t *testing.T) {
+	t.Parallel()
+	hijack := func(w http.ResponseWriter, payload string) {
+		w.WriteHeader(http.StatusOK)
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("cannot hijack server connection")
+			return
+		}
+		conn, _, err := hj.Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Write([]byte{1, 0, 0, 0, 0, 0, 0, byte(len(payload))})
+		conn.Write([]byte(payload))
+		conn.Close()
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/version", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"ApiVersion":"1.44"}`))
+	})
+	mux.HandleFunc("/containers/abc/exec", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id":"exec-1"}`))
+	})
+	mux.HandleFunc("/exec/exec-1/start", func(w http.ResponseWriter, _ *http.Request) {
+		hijack(w, "exec")
+	})
+	mux.HandleFunc("/exec/exec-1/json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"ID":"exec-1","Running":false,"ExitCode":0}`))
+	})
+	mux.HandleFunc("/containers/abc/attach", func(w http.ResponseWriter, _ *http.Request) {
+		hijack(w, "attach")
+	})
+	mux.HandleFunc("/containers/abc/stats", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"read":"2024-05-01T10:00:00Z"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	// the version check isn't skipped, so that the first requests race to
+	// query the server version.
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 20
+	errs := make(chan error, 3*n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			result, err := client.Exec(context.Background(), "abc", ExecSpec{Cmd: []string{"true"}, Env: []string{"A=b"}})
+			if err != nil {
+				errs <- err
+			} else if string(result.Stdout) != "exec" {
+				errs <- fmt.Errorf("wrong exec output: %q", result.Stdout)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			var stdout bytes.Buffer
+			err := client.AttachToContainer(AttachToContainerOptions{
+				Container:    "abc",
+				OutputStream: &stdout,
+				Stdout:       true,
+				Stream:       true,
+			})
+			if err != nil {
+				errs <- err
+			} else if stdout.String() != "attach" {
+				errs <- fmt.Errorf("wrong attach output: %q", stdout.String())
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			stats := make(chan *Stats, 1)
+			if err := client.Stats(StatsOptions{ID: "abc", Stats: stats}); err != nil {
+				errs <- err
+			} else if s := <-stats; s == nil || s.Read.IsZero() {
+				errs <- fmt.Errorf("wrong stats: %#v", s)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -209,6 +209,28 @@ func TestNewTLSVersionedClientInvalidCANoClientCert(t *testing.T) {
 	}
 }
 
+func TestClientCopy(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/version" {
+			w.Write([]byte(`{"ApiVersion":"1.41"}`))
+		}
+	}))
+	defer server.Close()
+	client, err := NewVersionedClient(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Client values can be copied, the copies keep their own state
+	clientCopy := *client
+	if version := clientCopy.serverVersion(); version.String() != "1.41" {
+		t.Errorf("serverVersion: wrong version. Want 1.41. Got %v.", version)
+	}
+	if client.expectedVersion() != nil {
+		t.Errorf("expectedVersion: version of the copy set in the original client: %v", client.expectedVersion())
+	}
+}
+
 func TestNewClientInvalidEndpoint(t *testing.T) {
 	t.Parallel()
 	cases := []string{
@@ -403,6 +425,28 @@ func TestQueryString(t *testing.T) {
 	}
 }
 
+func BenchmarkQueryString(b *testing.B) {
+	opts := ListContainersOptions{
+		All:     true,
+		Limit:   10,
+		Filters: map[string][]string{"status": {"running"}, "label": {"app=web"}},
+	}
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			queryString(opts)
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		t := reflect.TypeOf(opts)
+		for i := 0; i < b.N; i++ {
+			queryFieldsCache.Delete(t)
+			queryString(opts)
+		}
+	})
+}
+
 func TestAPIVersions(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -515,6 +559,56 @@ func TestPingErrorWithNativeClient(t *testing.T) {
 	}
 }
 
+func TestWaitForDaemon(t *testing.T) {
+	t.Parallel()
+	var pings int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_ping" {
+			t.Errorf("WaitForDaemon: unexpected request to %s", r.URL.Path)
+		}
+		if pings++; pings < 3 {
+			http.Error(w, "starting", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.WaitForDaemon(ctx, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if pings != 3 {
+		t.Errorf("WaitForDaemon: wrong number of pings. Want 3. Got %d.", pings)
+	}
+}
+
+func TestWaitForDaemonTimeout(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "starting", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	client, err := NewClient(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = client.WaitForDaemon(ctx, 10*time.Millisecond)
+	if !errors.Is(err, ErrDaemonNotReady) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitForDaemon: wrong error. Want %v and %v. Got %v.", ErrDaemonNotReady, context.DeadlineExceeded, err)
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusServiceUnavailable {
+		t.Errorf("WaitForDaemon: the error of the last ping should be wrapped, got %v", err)
+	}
+}
+
 func TestClientStreamTimeoutNotHit(t *testing.T) {
 	t.Parallel()
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -5,7 +5,10 @@
 package docker
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -90,6 +93,37 @@ type Health struct {
 	Log           []HealthCheck `json:"Log,omitempty" yaml:"Log,omitempty" toml:"Log,omitempty"`
 }
 
+// States of a container, as reported in State.Status, in the State of
+// listed containers and accepted by the "status" filter of ListContainers.
+const (
+	StateCreated    = "created"
+	StateRunning    = "running"
+	StatePaused     = "paused"
+	StateRestarting = "restarting"
+	StateRemoving   = "removing"
+	StateExited     = "exited"
+	StateDead       = "dead"
+)
+
+// IsRunning reports whether the given container state is StateRunning.
+// Paused and restarting containers aren't considered running, as in the
+// "status" filter of ListContainers, although the Running field of their
+// State is set.
+func IsRunning(state string) bool {
+	return state == StateRunning
+}
+
+// IsExited reports whether the given container state is one of a container
+// that stopped, StateExited or StateDead.
+func IsExited(state string) bool {
+	return state == StateExited || state == StateDead
+}
+
+// IsHealthy reports whether the given health status is HealthHealthy.
+func IsHealthy(status string) bool {
+	return status == HealthHealthy
+}
+
 // State represents the state of a container.
 type State struct {
 	Status            string    `json:"Status,omitempty" yaml:"Status,omitempty" toml:"Status,omitempty"`
@@ -143,23 +177,23 @@ func (s *State) String() string {
 func (s *State) StateString() string {
 	if s.Running {
 		if s.Paused {
-			return "paused"
+			return StatePaused
 		}
 		if s.Restarting {
-			return "restarting"
+			return StateRestarting
 		}
-		return "running"
+		return StateRunning
 	}
 
 	if s.Dead {
-		return "dead"
+		return StateDead
 	}
 
 	if s.StartedAt.IsZero() {
-		return "created"
+		return StateCreated
 	}
 
-	return "exited"
+	return StateExited
 }
 
 // PortBinding represents the host/container port mapping as returned in the
@@ -236,6 +270,67 @@ func (settings *NetworkSettings) PortMappingAPI() []APIPort {
 	return mapping
 }
 
+// ErrPortNotPublished is the error returned by HostAddress when the given
+// container port isn't published to the host.
+var ErrPortNotPublished = errors.New("port not published to the host")
+
+// PortBindingFor returns the host bindings of the given container port. Ports
+// without protocol are assumed to be TCP ports.
+func (settings *NetworkSettings) PortBindingFor(port Port) []PortBinding {
+	return settings.Ports[Port(port.Port()+"/"+port.Proto())]
+}
+
+// PublishedTCPPorts returns the TCP ports of the container that are published
+// to the host, sorted by port number.
+func (settings *NetworkSettings) PublishedTCPPorts() []Port {
+	var ports []Port
+	for port, bindings := range settings.Ports {
+		if port.Proto() == "tcp" && len(bindings) > 0 {
+			ports = append(ports, port)
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		pi, _ := parsePort(ports[i].Port())
+		pj, _ := parsePort(ports[j].Port())
+		return pi < pj
+	})
+	return ports
+}
+
+// HostAddress returns the address, in the host:port form, in which the given
+// container port can be reached from the host. Bindings to IPv4 addresses are
+// preferred over IPv6 ones, and bindings to all interfaces (0.0.0.0 or ::)
+// are translated to the corresponding loopback address.
+//
+// It returns ErrPortNotPublished if the port isn't published.
+func (settings *NetworkSettings) HostAddress(port Port) (string, error) {
+	var ipv6 string
+	for _, binding := range settings.PortBindingFor(port) {
+		if binding.HostPort == "" {
+			continue
+		}
+		ip := net.ParseIP(binding.HostIP)
+		if binding.HostIP == "" || ip.To4() != nil {
+			host := binding.HostIP
+			if host == "" || ip.IsUnspecified() {
+				host = "127.0.0.1"
+			}
+			return net.JoinHostPort(host, binding.HostPort), nil
+		}
+		if ipv6 == "" {
+			host := binding.HostIP
+			if ip.IsUnspecified() {
+				host = "::1"
+			}
+			ipv6 = net.JoinHostPort(host, binding.HostPort)
+		}
+	}
+	if ipv6 == "" {
+		return "", ErrPortNotPublished
+	}
+	return ipv6, nil
+}
+
 func parsePort(rawPort string) (int, error) {
 	port, err := strconv.ParseUint(rawPort, 10, 16)
 	if err != nil {
@@ -299,6 +394,7 @@ type HostMount struct {
 	Source        string         `json:"Source,omitempty" yaml:"Source,omitempty" toml:"Source,omitempty"`
 	Type          string         `json:"Type,omitempty" yaml:"Type,omitempty" toml:"Type,omitempty"`
 	ReadOnly      bool           `json:"ReadOnly,omitempty" yaml:"ReadOnly,omitempty" toml:"ReadOnly,omitempty"`
+	Consistency   string         `json:"Consistency,omitempty" yaml:"Consistency,omitempty" toml:"Consistency,omitempty"`
 	BindOptions   *BindOptions   `json:"BindOptions,omitempty" yaml:"BindOptions,omitempty" toml:"BindOptions,omitempty"`
 	VolumeOptions *VolumeOptions `json:"VolumeOptions,omitempty" yaml:"VolumeOptions,omitempty" toml:"VolumeOptions,omitempty"`
 	TempfsOptions *TempfsOptions `json:"TmpfsOptions,omitempty" yaml:"TmpfsOptions,omitempty" toml:"TmpfsOptions,omitempty"`
@@ -306,7 +402,11 @@ type HostMount struct {
 
 // BindOptions contains optional configuration for the bind type
 type BindOptions struct {
-	Propagation string `json:"Propagation,omitempty" yaml:"Propagation,omitempty" toml:"Propagation,omitempty"`
+	Propagation            string `json:"Propagation,omitempty" yaml:"Propagation,omitempty" toml:"Propagation,omitempty"`
+	NonRecursive           bool   `json:"NonRecursive,omitempty" yaml:"NonRecursive,omitempty" toml:"NonRecursive,omitempty"`                               // v1.40+
+	CreateMountpoint       bool   `json:"CreateMountpoint,omitempty" yaml:"CreateMountpoint,omitempty" toml:"CreateMountpoint,omitempty"`                   // v1.42+
+	ReadOnlyNonRecursive   bool   `json:"ReadOnlyNonRecursive,omitempty" yaml:"ReadOnlyNonRecursive,omitempty" toml:"ReadOnlyNonRecursive,omitempty"`       // v1.44+
+	ReadOnlyForceRecursive bool   `json:"ReadOnlyForceRecursive,omitempty" yaml:"ReadOnlyForceRecursive,omitempty" toml:"ReadOnlyForceRecursive,omitempty"` // v1.44+
 }
 
 // VolumeOptions contains optional configuration for the volume type
@@ -314,6 +414,7 @@ type VolumeOptions struct {
 	NoCopy       bool               `json:"NoCopy,omitempty" yaml:"NoCopy,omitempty" toml:"NoCopy,omitempty"`
 	Labels       map[string]string  `json:"Labels,omitempty" yaml:"Labels,omitempty" toml:"Labels,omitempty"`
 	DriverConfig VolumeDriverConfig `json:"DriverConfig,omitempty" yaml:"DriverConfig,omitempty" toml:"DriverConfig,omitempty"`
+	Subpath      string             `json:"Subpath,omitempty" yaml:"Subpath,omitempty" toml:"Subpath,omitempty"` // v1.45+
 }
 
 // TempfsOptions contains optional configuration for the tempfs type
@@ -442,6 +543,10 @@ type Container struct {
 	Platform     string `json:"Platform,omitempty" yaml:"Platform,omitempty" toml:"Platform,omitempty"`
 	SizeRw       int64  `json:"SizeRw,omitempty" yaml:"SizeRw,omitempty" toml:"SizeRw,omitempty"`
 	SizeRootFs   int64  `json:"SizeRootFs,omitempty" yaml:"SizeRootFs,omitempty" toml:"SizeRootFs,omitempty"`
+
+	// Warnings holds the warnings sent by the daemon when the container was
+	// created. It's only set in the result of CreateContainer.
+	Warnings []string `json:"Warnings,omitempty" yaml:"Warnings,omitempty" toml:"Warnings,omitempty"`
 }
 
 // KeyValuePair is a type for generic key/value pairs as used in the Lxc
@@ -503,7 +608,7 @@ type HostConfig struct {
 	NetworkMode          string                 `json:"NetworkMode,omitempty" yaml:"NetworkMode,omitempty" toml:"NetworkMode,omitempty"`
 	IpcMode              string                 `json:"IpcMode,omitempty" yaml:"IpcMode,omitempty" toml:"IpcMode,omitempty"`
 	Isolation            string                 `json:"Isolation,omitempty" yaml:"Isolation,omitempty" toml:"Isolation,omitempty"`       // Windows only
-	ConsoleSize          [2]int                 `json:"ConsoleSize,omitempty" yaml:"ConsoleSize,omitempty" toml:"ConsoleSize,omitempty"` // Windows only height x width
+	ConsoleSize          [2]int                 `json:"ConsoleSize,omitempty" yaml:"ConsoleSize,omitempty" toml:"ConsoleSize,omitempty"` // height x width, initial size of the TTY (Windows only before API v1.42)
 	PidMode              string                 `json:"PidMode,omitempty" yaml:"PidMode,omitempty" toml:"PidMode,omitempty"`
 	UTSMode              string                 `json:"UTSMode,omitempty" yaml:"UTSMode,omitempty" toml:"UTSMode,omitempty"`
 	RestartPolicy        RestartPolicy          `json:"RestartPolicy,omitempty" yaml:"RestartPolicy,omitempty" toml:"RestartPolicy,omitempty"`
@@ -557,7 +662,7 @@ type HostConfig struct {
 	PublishAllPorts      bool                   `json:"PublishAllPorts,omitempty" yaml:"PublishAllPorts,omitempty" toml:"PublishAllPorts,omitempty"`
 	ReadonlyRootfs       bool                   `json:"ReadonlyRootfs,omitempty" yaml:"ReadonlyRootfs,omitempty" toml:"ReadonlyRootfs,omitempty"`
 	AutoRemove           bool                   `json:"AutoRemove,omitempty" yaml:"AutoRemove,omitempty" toml:"AutoRemove,omitempty"`
-	Annotations          map[string]string      `json:"Annotations,omitempty" yaml:"Annotations,omitempty" toml:"Annotations,omitempty"`
+	Annotations          map[string]string      `json:"Annotations,omitempty" yaml:"Annotations,omitempty" toml:"Annotations,omitempty"` // OCI annotations passed to the runtime, API v1.43+
 }
 
 // NetworkingConfig represents the container's networking configuration for each of its interfaces
@@ -566,17 +671,49 @@ type NetworkingConfig struct {
 	EndpointsConfig map[string]*EndpointConfig `json:"EndpointsConfig" yaml:"EndpointsConfig" toml:"EndpointsConfig"` // Endpoint configs for each connecting network
 }
 
+// ErrNoSuchContainer matches, via errors.Is, every NoSuchContainer error.
+var ErrNoSuchContainer = errors.New("no such container")
+
 // NoSuchContainer is the error returned when a given container does not exist.
 type NoSuchContainer struct {
-	ID  string
+	// ID is the container reference the operation was called with, which
+	// may be a name.
+	ID string
+
+	// ResolvedID is the ID the reference was resolved to, when the
+	// container disappeared after being looked up.
+	ResolvedID string
+
+	// Op is the operation that failed, such as "inspect" or "remove".
+	Op string
+
+	// Err is the underlying error, usually the *Error returned by the
+	// daemon.
 	Err error
 }
 
 func (err *NoSuchContainer) Error() string {
+	msg := "No such container: " + err.ID
 	if err.Err != nil {
-		return err.Err.Error()
+		msg = err.Err.Error()
+	}
+	if err.ResolvedID != "" && err.ResolvedID != err.ID {
+		msg += " (" + err.ResolvedID + ")"
+	}
+	if err.Op != "" {
+		msg = err.Op + " container: " + msg
 	}
-	return "No such container: " + err.ID
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (err *NoSuchContainer) Unwrap() error {
+	return err.Err
+}
+
+// Is makes NoSuchContainer errors match ErrNoSuchContainer.
+func (err *NoSuchContainer) Is(target error) bool {
+	return target == ErrNoSuchContainer
 }
 
 // ContainerAlreadyRunning is the error returned when a given container is
@@ -589,12 +726,33 @@ func (err *ContainerAlreadyRunning) Error() string {
 	return "Container already running: " + err.ID
 }
 
+var (
+	// ErrContainerNotRunning matches, via errors.Is, every ContainerNotRunning
+	// error.
+	ErrContainerNotRunning = errors.New("container not running")
+
+	// ErrContainerAlreadyStopped matches, via errors.Is, ContainerNotRunning
+	// errors returned when the daemon reports that the container had already
+	// been stopped, so the request had no effect.
+	ErrContainerAlreadyStopped = errors.New("container already stopped")
+)
+
 // ContainerNotRunning is the error returned when a given container is not
 // running.
 type ContainerNotRunning struct {
 	ID string
+
+	// AlreadyStopped is set when the daemon answered with 304 Not Modified,
+	// meaning that the container had already been stopped.
+	AlreadyStopped bool
 }
 
 func (err *ContainerNotRunning) Error() string {
 	return "Container not running: " + err.ID
 }
+
+// Is makes ContainerNotRunning errors match ErrContainerNotRunning and, when
+// AlreadyStopped is set, ErrContainerAlreadyStopped.
+func (err *ContainerNotRunning) Is(target error) bool {
+	return target == ErrContainerNotRunning || (err.AlreadyStopped && target == ErrContainerAlreadyStopped)
+}
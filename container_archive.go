@@ -1,13 +1,85 @@
 package docker
 
 import (
+	"archive/tar"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path"
+	"strings"
 	"time"
 )
 
+// ContainerPathStat describes a path in the filesystem of a container.
+type ContainerPathStat struct {
+	Name  string      `json:"name" yaml:"name" toml:"name"`
+	Size  int64       `json:"size" yaml:"size" toml:"size"`
+	Mode  os.FileMode `json:"mode" yaml:"mode" toml:"mode"`
+	Mtime time.Time   `json:"mtime" yaml:"mtime" toml:"mtime"`
+
+	// LinkTarget is the absolute path the path resolves to, when it's a
+	// symbolic link.
+	LinkTarget string `json:"linkTarget" yaml:"linkTarget" toml:"linkTarget"`
+}
+
+// StatContainerPathOptions is the set of options that can be used when
+// getting information about a path in a container.
+type StatContainerPathOptions struct {
+	Path    string `qs:"path"`
+	Context context.Context
+}
+
+// StatContainerPath returns information about a path in the filesystem of
+// the container.
+//
+// See https://goo.gl/W49jxK for more details.
+func (c *Client) StatContainerPath(id string, opts StatContainerPathOptions) (*ContainerPathStat, error) {
+	url := fmt.Sprintf("/containers/%s/archive?", id) + queryString(opts)
+	resp, err := c.do(http.MethodHead, url, doOptions{context: opts.Context})
+	if err != nil {
+		var e *Error
+		if errors.As(err, &e) && e.Status == http.StatusNotFound {
+			// HEAD responses have no body telling a missing container
+			// from a missing path apart, so look for the container.
+			_, inspectErr := c.InspectContainerWithOptions(InspectContainerOptions{ID: id, Context: opts.Context})
+			var notFound *NoSuchContainer
+			if errors.As(inspectErr, &notFound) {
+				return nil, &NoSuchContainer{ID: id, Op: "stat path", Err: err}
+			}
+		}
+		return nil, err
+	}
+	resp.Body.Close()
+	header := resp.Header.Get("X-Docker-Container-Path-Stat")
+	data, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path stat header %q: %w", header, err)
+	}
+	var stat ContainerPathStat
+	if err := json.Unmarshal(data, &stat); err != nil {
+		return nil, err
+	}
+	return &stat, nil
+}
+
+// resolveContainerLink returns the target of the given path in the
+// container when it's a symbolic link, or the path itself.
+func (c *Client) resolveContainerLink(ctx context.Context, id, path string) (string, error) {
+	stat, err := c.StatContainerPath(id, StatContainerPathOptions{Path: path, Context: ctx})
+	if err != nil {
+		return "", err
+	}
+	if stat.Mode&os.ModeSymlink != 0 && stat.LinkTarget != "" {
+		return stat.LinkTarget, nil
+	}
+	return path, nil
+}
+
 // UploadToContainerOptions is the set of options that can be used when
 // uploading an archive into a container.
 //
@@ -16,7 +88,13 @@ type UploadToContainerOptions struct {
 	InputStream          io.Reader `json:"-" qs:"-"`
 	Path                 string    `qs:"path"`
 	NoOverwriteDirNonDir bool      `qs:"noOverwriteDirNonDir"`
-	Context              context.Context
+
+	// FollowLink makes the archive be extracted to the target of Path when
+	// it's a symbolic link, like docker cp does, instead of failing or
+	// replacing the link.
+	FollowLink bool `qs:"-"`
+
+	Context context.Context
 }
 
 // UploadToContainer uploads a tar archive to be extracted to a path in the
@@ -24,6 +102,13 @@ type UploadToContainerOptions struct {
 //
 // See https://goo.gl/g25o7u for more details.
 func (c *Client) UploadToContainer(id string, opts UploadToContainerOptions) error {
+	if opts.FollowLink {
+		target, err := c.resolveContainerLink(opts.Context, id, opts.Path)
+		if err != nil {
+			return err
+		}
+		opts.Path = target
+	}
 	url := fmt.Sprintf("/containers/%s/archive?", id) + queryString(opts)
 
 	return c.stream(http.MethodPut, url, streamOptions{
@@ -40,13 +125,28 @@ type DownloadFromContainerOptions struct {
 	OutputStream      io.Writer     `json:"-" qs:"-"`
 	Path              string        `qs:"path"`
 	InactivityTimeout time.Duration `qs:"-"`
-	Context           context.Context
+
+	// FollowLink makes the target of Path be downloaded when it's a
+	// symbolic link, like docker cp -L does. The entries of the archive are
+	// named after Path rather than after the target.
+	FollowLink bool `qs:"-"`
+
+	Context context.Context
 }
 
 // DownloadFromContainer downloads a tar archive of files or folders in a container.
 //
 // See https://goo.gl/W49jxK for more details.
 func (c *Client) DownloadFromContainer(id string, opts DownloadFromContainerOptions) error {
+	if opts.FollowLink {
+		target, err := c.resolveContainerLink(opts.Context, id, opts.Path)
+		if err != nil {
+			return err
+		}
+		if target != opts.Path {
+			return c.downloadLinkTarget(id, target, opts)
+		}
+	}
 	url := fmt.Sprintf("/containers/%s/archive?", id) + queryString(opts)
 
 	return c.stream(http.MethodGet, url, streamOptions{
@@ -56,3 +156,88 @@ func (c *Client) DownloadFromContainer(id string, opts DownloadFromContainerOpti
 		context:           opts.Context,
 	})
 }
+
+// downloadLinkTarget downloads target, rebasing the archive on the name of
+// the link in opts.Path.
+func (c *Client) downloadLinkTarget(id, target string, opts DownloadFromContainerOptions) error {
+	reader, writer := io.Pipe()
+	errC := make(chan error, 1)
+	go func() {
+		rebased := RebaseArchiveEntries(reader, archiveBase(target), archiveBase(opts.Path))
+		_, err := io.Copy(opts.OutputStream, rebased)
+		rebased.Close()
+		// unblocks the download if the copy failed
+		reader.CloseWithError(err)
+		errC <- err
+	}()
+	linkOpts := opts
+	linkOpts.Path = target
+	linkOpts.FollowLink = false
+	linkOpts.OutputStream = writer
+	err := c.DownloadFromContainer(id, linkOpts)
+	writer.CloseWithError(err)
+	if copyErr := <-errC; err == nil {
+		err = copyErr
+	}
+	return err
+}
+
+// archiveBase returns the last element of a path in a container, which
+// names the entries of the archives of the path.
+func archiveBase(p string) string {
+	return path.Base(strings.TrimSuffix(p, "/"))
+}
+
+// RebaseArchiveEntries returns a tar archive with the entries of the tar
+// archive in src, renaming the ones under oldBase to be under newBase
+// instead, like the Docker CLI does when copying files from containers.
+// Hard links to renamed entries are updated too.
+//
+// The entries of the archives returned by DownloadFromContainer are named
+// after the last element of the downloaded path, so rebasing the archive of
+// /etc/nginx on "config" renames "nginx/nginx.conf" to "config/nginx.conf":
+//
+//	RebaseArchiveEntries(archive, "nginx", "config")
+//
+// An error reading src is returned by the Read method of the result.
+func RebaseArchiveEntries(src io.Reader, oldBase, newBase string) io.ReadCloser {
+	oldBase = strings.TrimSuffix(oldBase, "/")
+	newBase = strings.TrimSuffix(newBase, "/")
+	rebase := func(name string) string {
+		if name == oldBase || name == oldBase+"/" {
+			return newBase + strings.TrimPrefix(name, oldBase)
+		}
+		if rest, ok := strings.CutPrefix(name, oldBase+"/"); ok {
+			return newBase + "/" + rest
+		}
+		return name
+	}
+	reader, writer := io.Pipe()
+	go func() {
+		tr := tar.NewReader(src)
+		tw := tar.NewWriter(writer)
+		err := func() error {
+			for {
+				header, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					return tw.Close()
+				}
+				if err != nil {
+					return err
+				}
+				header.Name = rebase(header.Name)
+				if header.Typeflag == tar.TypeLink {
+					header.Linkname = rebase(header.Linkname)
+				}
+				if err := tw.WriteHeader(header); err != nil {
+					return err
+				}
+				if _, err := io.Copy(tw, tr); err != nil {
+					return err
+				}
+			}
+		}()
+		writer.CloseWithError(err)
+	}()
+	return reader
+}
@@ -1,9 +1,18 @@
 package docker
 
 import (
+	"archive/tar"
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestUploadToContainer(t *testing.T) {
@@ -49,3 +58,181 @@ func TestDownloadFromContainer(t *testing.T) {
 		t.Errorf("DownloadFromContainer: wrong stdout. Want %#v. Got %#v.", filecontent, out.String())
 	}
 }
+
+func buildTestArchive(t *testing.T, headers ...*tar.Header) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, header := range headers {
+		content := []byte(header.Name)
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len(content))
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			tw.Write(content)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func readTestArchive(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	entries := make(map[string]string)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(tr)
+		entries[header.Name] = string(content) + header.Linkname
+	}
+}
+
+func TestRebaseArchiveEntries(t *testing.T) {
+	t.Parallel()
+	archive := buildTestArchive(t,
+		&tar.Header{Name: "nginx/", Typeflag: tar.TypeDir, Mode: 0o755},
+		&tar.Header{Name: "nginx/nginx.conf", Typeflag: tar.TypeReg, Mode: 0o644},
+		&tar.Header{Name: "nginx/hard", Typeflag: tar.TypeLink, Linkname: "nginx/nginx.conf"},
+		&tar.Header{Name: "nginx-other", Typeflag: tar.TypeReg, Mode: 0o644},
+	)
+	rebased := RebaseArchiveEntries(bytes.NewReader(archive), "nginx", "config/")
+	defer rebased.Close()
+	expected := map[string]string{
+		"config/":           "",
+		"config/nginx.conf": "nginx/nginx.conf",
+		"config/hard":       "config/nginx.conf",
+		"nginx-other":       "nginx-other",
+	}
+	if got := readTestArchive(t, rebased); !reflect.DeepEqual(got, expected) {
+		t.Errorf("RebaseArchiveEntries: wrong entries. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestRebaseArchiveEntriesInvalidArchive(t *testing.T) {
+	t.Parallel()
+	rebased := RebaseArchiveEntries(bytes.NewReader([]byte("not a tar archive, but long enough to be read as a header")), "a", "b")
+	defer rebased.Close()
+	if _, err := io.ReadAll(rebased); err == nil {
+		t.Error("RebaseArchiveEntries: expected error, got nil")
+	}
+}
+
+func newArchiveTestServer(t *testing.T, stat ContainerPathStat, handle func(w http.ResponseWriter, r *http.Request)) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			data, _ := json.Marshal(stat)
+			w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString(data))
+			return
+		}
+		handle(w, r)
+	}))
+	t.Cleanup(server.Close)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	return client
+}
+
+func TestStatContainerPath(t *testing.T) {
+	t.Parallel()
+	expected := ContainerPathStat{
+		Name:       "link.conf",
+		Size:       14,
+		Mode:       os.ModeSymlink | 0o777,
+		Mtime:      time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		LinkTarget: "/etc/real.conf",
+	}
+	client := newArchiveTestServer(t, expected, nil)
+	stat, err := client.StatContainerPath("a123456", StatContainerPathOptions{Path: "/etc/link.conf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*stat, expected) {
+		t.Errorf("StatContainerPath: wrong stat. Want %#v. Got %#v.", expected, *stat)
+	}
+}
+
+func TestStatContainerPathNoSuchContainer(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "No such container: a123456", status: http.StatusNotFound})
+	_, err := client.StatContainerPath("a123456", StatContainerPathOptions{Path: "/etc"})
+	expectNoSuchContainer(t, "a123456", err)
+}
+
+func TestStatContainerPathNoSuchPath(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"Id":"a123456"}`))
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	_, err := client.StatContainerPath("a123456", StatContainerPathOptions{Path: "/missing"})
+	var notFound *NoSuchContainer
+	if errors.As(err, &notFound) {
+		t.Fatalf("StatContainerPath: wrong error. Got %#v.", err)
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusNotFound {
+		t.Errorf("StatContainerPath: wrong error. Want 404. Got %#v.", err)
+	}
+}
+
+func TestDownloadFromContainerFollowLink(t *testing.T) {
+	t.Parallel()
+	var paths []string
+	client := newArchiveTestServer(t, ContainerPathStat{Name: "link.conf", Mode: os.ModeSymlink, LinkTarget: "/etc/real.conf"}, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Query().Get("path"))
+		w.Write(buildTestArchive(t, &tar.Header{Name: "real.conf", Typeflag: tar.TypeReg, Mode: 0o644}))
+	})
+	var out bytes.Buffer
+	err := client.DownloadFromContainer("a123456", DownloadFromContainerOptions{
+		OutputStream: &out,
+		Path:         "/etc/link.conf",
+		FollowLink:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"/etc/real.conf"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("DownloadFromContainer: wrong paths. Want %#v. Got %#v.", expected, paths)
+	}
+	expected := map[string]string{"link.conf": "real.conf"}
+	if got := readTestArchive(t, &out); !reflect.DeepEqual(got, expected) {
+		t.Errorf("DownloadFromContainer: wrong entries. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestUploadToContainerFollowLink(t *testing.T) {
+	t.Parallel()
+	var path string
+	client := newArchiveTestServer(t, ContainerPathStat{Name: "data", Mode: os.ModeSymlink, LinkTarget: "/var/lib/data"}, func(_ http.ResponseWriter, r *http.Request) {
+		path = r.URL.Query().Get("path")
+	})
+	err := client.UploadToContainer("a123456", UploadToContainerOptions{
+		InputStream: bytes.NewReader(nil),
+		Path:        "/data",
+		FollowLink:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/var/lib/data" {
+		t.Errorf("UploadToContainer: wrong path. Want %q. Got %q.", "/var/lib/data", path)
+	}
+}
@@ -1,8 +1,10 @@
 package docker
 
 import (
+	"context"
 	"io"
 	"net/http"
+	"time"
 )
 
 // AttachToContainerOptions is the set of options that can be used when
@@ -22,12 +24,22 @@ type AttachToContainerOptions struct {
 	// to unexpected behavior.
 	Success chan struct{}
 
-	// Override the key sequence for detaching a container.
-	DetachKeys string
+	// Override the key sequence for detaching a container, in the format
+	// of the --detach-keys flag of docker attach, like "ctrl-p,ctrl-q":
+	// a comma-separated list of keys, each either a single character or
+	// "ctrl-<value>", where value is a letter or one of @, [, \, ], ^
+	// and _.
+	DetachKeys string `qs:"detachKeys"`
 
 	// Use raw terminal? Usually true when the container contains a TTY.
+	// When false, the container is inspected and the raw stream is used
+	// if it has a TTY, unless SkipTerminalDetection is set.
 	RawTerminal bool `qs:"-"`
 
+	// SkipTerminalDetection makes the attachment demultiplex the stream
+	// whenever RawTerminal is false, without inspecting the container.
+	SkipTerminalDetection bool `qs:"-"`
+
 	// Get container logs, sending it to OutputStream.
 	Logs bool
 
@@ -42,6 +54,28 @@ type AttachToContainerOptions struct {
 
 	// Attach to stderr, and use ErrorStream.
 	Stderr bool
+
+	// MaxOutputBytes is the maximum number of bytes written to
+	// OutputStream and ErrorStream together. Once it's reached, the
+	// attachment stops and Wait returns ErrOutputTruncated. Zero means no
+	// limit.
+	MaxOutputBytes int64 `qs:"-"`
+
+	// WriteTimeout is the maximum duration of a write of InputStream to
+	// the connection. When a write times out, because the peer is gone or
+	// stopped reading, the attachment stops and Wait returns an error
+	// wrapping os.ErrDeadlineExceeded. Zero means no timeout.
+	WriteTimeout time.Duration `qs:"-"`
+
+	// KeepAlive enables TCP keep-alive probes on the connection with the
+	// given period, so a dead peer makes the attachment fail instead of
+	// hanging. Zero keeps the default of the Dialer.
+	KeepAlive time.Duration `qs:"-"`
+
+	// Context bounds the inspection of the container made to detect
+	// whether it has a TTY. It doesn't apply to the attachment itself,
+	// which is stopped with the Close method of the CloseWaiter.
+	Context context.Context `qs:"-"`
 }
 
 // AttachToContainer attaches to a container, using the given options.
@@ -61,14 +95,25 @@ func (c *Client) AttachToContainer(opts AttachToContainerOptions) error {
 // See https://goo.gl/NKpkFk for more details.
 func (c *Client) AttachToContainerNonBlocking(opts AttachToContainerOptions) (CloseWaiter, error) {
 	if opts.Container == "" {
-		return nil, &NoSuchContainer{ID: opts.Container}
+		return nil, &NoSuchContainer{ID: opts.Container, Op: "attach"}
+	}
+	if opts.DetachKeys != "" {
+		if _, err := parseDetachKeys(opts.DetachKeys); err != nil {
+			return nil, err
+		}
 	}
+	// without stdout and stderr there's nothing to demultiplex
+	skipDetection := opts.SkipTerminalDetection || (!opts.Stdout && !opts.Stderr)
+	rawTerminal := c.rawTerminal(opts.Context, opts.Container, opts.RawTerminal, skipDetection)
 	path := "/containers/" + opts.Container + "/attach?" + queryString(opts)
+	stdout, stderr := limitOutput(opts.MaxOutputBytes, opts.OutputStream, opts.ErrorStream)
 	return c.hijack(http.MethodPost, path, hijackOptions{
 		success:        opts.Success,
-		setRawTerminal: opts.RawTerminal,
+		setRawTerminal: rawTerminal,
 		in:             opts.InputStream,
-		stdout:         opts.OutputStream,
-		stderr:         opts.ErrorStream,
+		stdout:         stdout,
+		stderr:         stderr,
+		writeTimeout:   opts.WriteTimeout,
+		keepAlive:      opts.KeepAlive,
 	})
 }
@@ -2,9 +2,13 @@ package docker
 
 import (
 	"bytes"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"reflect"
 	"strings"
 	"testing"
@@ -294,3 +298,129 @@ func TestAttachToContainerWithoutContainer(t *testing.T) {
 	err := client.AttachToContainer(AttachToContainerOptions{})
 	expectNoSuchContainer(t, "", err)
 }
+
+func TestAttachToContainerMaxOutputBytes(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte{1, 0, 0, 0, 0, 0, 0, 19})
+		w.Write([]byte("something happened!"))
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	var buf bytes.Buffer
+	err := client.AttachToContainer(AttachToContainerOptions{
+		Container:      "a123456",
+		OutputStream:   &buf,
+		Stdout:         true,
+		Logs:           true,
+		MaxOutputBytes: 9,
+	})
+	if !errors.Is(err, ErrOutputTruncated) {
+		t.Errorf("AttachToContainer: wrong error. Want %v. Got %v.", ErrOutputTruncated, err)
+	}
+	if expected := "something"; buf.String() != expected {
+		t.Errorf("AttachToContainer: wrong output. Want %q. Got %q.", expected, buf.String())
+	}
+}
+
+// endlessReader is an InputStream that never ends.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func TestAttachToContainerWriteTimeout(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		// a peer that doesn't read the input
+		<-done
+		conn.Close()
+	}))
+	defer server.Close()
+	defer close(done)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	var stdout bytes.Buffer
+	errC := make(chan error, 1)
+	go func() {
+		errC <- client.AttachToContainer(AttachToContainerOptions{
+			Container:    "a123456",
+			InputStream:  endlessReader{},
+			OutputStream: &stdout,
+			Stdin:        true,
+			Stdout:       true,
+			Stream:       true,
+			WriteTimeout: 50 * time.Millisecond,
+			KeepAlive:    time.Second,
+		})
+	}()
+	select {
+	case err := <-errC:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("AttachToContainer: wrong error. Want %v. Got %v.", os.ErrDeadlineExceeded, err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("AttachToContainer: timed out waiting for the write timeout")
+	}
+}
+
+func TestSetKeepAlive(t *testing.T) {
+	t.Parallel()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := setKeepAlive(conn, time.Second); err != nil {
+		t.Errorf("setKeepAlive: unexpected error: %v", err)
+	}
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	if err := setKeepAlive(client, time.Second); err != nil {
+		t.Errorf("setKeepAlive: unexpected error for a connection that isn't TCP: %v", err)
+	}
+}
+
+func TestAttachToContainerDetachKeys(t *testing.T) {
+	t.Parallel()
+	var req http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		req = *r
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	err := client.AttachToContainer(AttachToContainerOptions{
+		Container:    "a123456",
+		OutputStream: io.Discard,
+		Stdin:        true,
+		Stdout:       true,
+		Stream:       true,
+		DetachKeys:   "ctrl-x,x",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.URL.Query().Get("detachKeys"); got != "ctrl-x,x" {
+		t.Errorf("AttachToContainer: wrong detachKeys param. Want %q. Got %q.", "ctrl-x,x", got)
+	}
+	err = client.AttachToContainer(AttachToContainerOptions{Container: "a123456", DetachKeys: "ctrl-1"})
+	if !errors.Is(err, ErrInvalidDetachKeys) {
+		t.Errorf("AttachToContainer: wrong error. Want %v. Got %v.", ErrInvalidDetachKeys, err)
+	}
+}
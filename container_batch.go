@@ -0,0 +1,107 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultBatchParallelism is the number of containers handled concurrently
+// by StopContainers and RemoveContainers when no parallelism is specified.
+const DefaultBatchParallelism = 8
+
+// StopContainersOptions specify parameters to the StopContainers function.
+type StopContainersOptions struct {
+	// Timeout and Signal are used to stop every container, see
+	// StopContainerOptions.
+	Timeout *int
+	Signal  string
+
+	// Parallelism is the maximum number of containers stopped
+	// concurrently, defaults to DefaultBatchParallelism.
+	Parallelism int
+
+	Context context.Context
+}
+
+// StopContainers stops the given containers concurrently, returning the
+// errors of the containers that couldn't be stopped, by ID. Containers that
+// aren't running aren't considered failures. The returned map is nil when
+// every container was stopped.
+func (c *Client) StopContainers(ids []string, opts StopContainersOptions) map[string]error {
+	return batchContainers(ids, opts.Parallelism, func(id string) error {
+		err := c.StopContainerWithOptions(StopContainerOptions{
+			ID:      id,
+			Timeout: opts.Timeout,
+			Signal:  opts.Signal,
+			Context: opts.Context,
+		})
+		var notRunning *ContainerNotRunning
+		if errors.As(err, &notRunning) {
+			return nil
+		}
+		return err
+	})
+}
+
+// RemoveContainersOptions specify parameters to the RemoveContainers function.
+type RemoveContainersOptions struct {
+	// RemoveVolumes and Force are used to remove every container, see
+	// RemoveContainerOptions.
+	RemoveVolumes bool
+	Force         bool
+
+	// Parallelism is the maximum number of containers removed
+	// concurrently, defaults to DefaultBatchParallelism.
+	Parallelism int
+
+	Context context.Context
+}
+
+// RemoveContainers removes the given containers concurrently, returning the
+// errors of the containers that couldn't be removed, by ID. The returned map
+// is nil when every container was removed.
+func (c *Client) RemoveContainers(ids []string, opts RemoveContainersOptions) map[string]error {
+	return batchContainers(ids, opts.Parallelism, func(id string) error {
+		return c.RemoveContainer(RemoveContainerOptions{
+			ID:            id,
+			RemoveVolumes: opts.RemoveVolumes,
+			Force:         opts.Force,
+			Context:       opts.Context,
+		})
+	})
+}
+
+// batchContainers calls fn for every id, running at most parallelism calls
+// at once, and collects the errors by ID.
+func batchContainers(ids []string, parallelism int, fn func(id string) error) map[string]error {
+	if parallelism <= 0 {
+		parallelism = DefaultBatchParallelism
+	}
+	var (
+		mu   sync.Mutex
+		errs map[string]error
+		wg   sync.WaitGroup
+	)
+	sem := make(chan struct{}, parallelism)
+	for _, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(id); err != nil {
+				mu.Lock()
+				if errs == nil {
+					errs = make(map[string]error)
+				}
+				errs[id] = err
+				mu.Unlock()
+			}
+		}(id)
+	}
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newBatchTestClient(t *testing.T, handler func(w http.ResponseWriter, id string)) (*Client, *int32) {
+	t.Helper()
+	var running, maxRunning int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		id := strings.TrimPrefix(r.URL.Path, "/containers/")
+		id, _, _ = strings.Cut(id, "/")
+		handler(w, id)
+	}))
+	t.Cleanup(server.Close)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	return client, &maxRunning
+}
+
+func TestStopContainers(t *testing.T) {
+	t.Parallel()
+	client, maxRunning := newBatchTestClient(t, func(w http.ResponseWriter, id string) {
+		switch id {
+		case "stopped":
+			w.WriteHeader(http.StatusNotModified)
+		case "missing":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	ids := []string{"stopped", "missing"}
+	for i := 0; i < 10; i++ {
+		ids = append(ids, "c"+strconv.Itoa(i))
+	}
+	errs := client.StopContainers(ids, StopContainersOptions{Parallelism: 3})
+	if len(errs) != 1 {
+		t.Fatalf("StopContainers: wrong errors: %v.", errs)
+	}
+	var notFound *NoSuchContainer
+	if !errors.As(errs["missing"], &notFound) {
+		t.Errorf("StopContainers: wrong error for missing container: %#v.", errs["missing"])
+	}
+	if got := atomic.LoadInt32(maxRunning); got > 3 {
+		t.Errorf("StopContainers: too many concurrent requests. Want at most 3. Got %d.", got)
+	}
+}
+
+func TestRemoveContainers(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	removed := make(map[string]bool)
+	client, _ := newBatchTestClient(t, func(w http.ResponseWriter, id string) {
+		mu.Lock()
+		removed[id] = true
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	ids := []string{"a", "b", "c"}
+	if errs := client.RemoveContainers(ids, RemoveContainersOptions{Force: true}); errs != nil {
+		t.Fatalf("RemoveContainers: unexpected errors: %v.", errs)
+	}
+	for _, id := range ids {
+		if !removed[id] {
+			t.Errorf("RemoveContainers: container %q not removed.", id)
+		}
+	}
+}
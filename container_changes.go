@@ -15,7 +15,7 @@ func (c *Client) ContainerChanges(id string) ([]Change, error) {
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return nil, &NoSuchContainer{ID: id}
+			return nil, &NoSuchContainer{ID: id, Op: "changes", Err: err}
 		}
 		return nil, err
 	}
@@ -33,7 +33,7 @@ func (c *Client) CommitContainer(opts CommitContainerOptions) (*Image, error) {
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return nil, &NoSuchContainer{ID: opts.Container}
+			return nil, &NoSuchContainer{ID: opts.Container, Op: "commit", Err: err}
 		}
 		return nil, err
 	}
@@ -24,12 +24,9 @@ type CopyFromContainerOptions struct {
 // Deprecated: Use DownloadFromContainer and DownloadFromContainer instead.
 func (c *Client) CopyFromContainer(opts CopyFromContainerOptions) error {
 	if opts.Container == "" {
-		return &NoSuchContainer{ID: opts.Container}
+		return &NoSuchContainer{ID: opts.Container, Op: "copy"}
 	}
-	if c.serverAPIVersion == nil {
-		c.checkAPIVersion()
-	}
-	if c.serverAPIVersion != nil && c.serverAPIVersion.GreaterThanOrEqualTo(apiVersion124) {
+	if serverAPIVersion := c.serverVersion(); serverAPIVersion != nil && serverAPIVersion.GreaterThanOrEqualTo(apiVersion124) {
 		return errors.New("go-dockerclient: CopyFromContainer is no longer available in Docker >= 1.12, use DownloadFromContainer instead")
 	}
 	url := fmt.Sprintf("/containers/%s/copy", opts.Container)
@@ -40,7 +37,7 @@ func (c *Client) CopyFromContainer(opts CopyFromContainerOptions) error {
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return &NoSuchContainer{ID: opts.Container}
+			return &NoSuchContainer{ID: opts.Container, Op: "copy", Err: err}
 		}
 		return err
 	}
@@ -2,8 +2,11 @@ package docker
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 )
@@ -16,14 +19,114 @@ var ErrContainerAlreadyExists = errors.New("container already exists")
 //
 // See https://goo.gl/tyzwVM for more details.
 type CreateContainerOptions struct {
-	Name             string
-	Platform         string
+	Name string
+
+	// NamePrefix, used when Name is empty, makes CreateContainer name the
+	// container after it, followed by a dash and a random suffix. The name
+	// is generated again if it's already taken.
+	NamePrefix string `qs:"-"`
+
+	// Platform selects the variant of a multi-arch image, in the
+	// os[/arch[/variant]] format (e.g. linux/arm64).
+	Platform         string            `ver:"1.41"`
 	Config           *Config           `qs:"-"`
 	HostConfig       *HostConfig       `qs:"-"`
 	NetworkingConfig *NetworkingConfig `qs:"-"`
 	Context          context.Context
 }
 
+// ErrInvalidContainerOptions is matched, via errors.Is, by the errors returned
+// by CreateContainerOptions.Validate.
+var ErrInvalidContainerOptions = errors.New("invalid container options")
+
+// Validate checks the options for common mistakes that would otherwise only
+// be reported by the daemon: invalid port specs, links combined with host or
+// container networking, a memory+swap limit smaller than the memory limit and
+// invalid restart policies. All the problems found are reported in the
+// returned error.
+func (opts CreateContainerOptions) Validate() error {
+	var errs []error
+	if opts.Config != nil {
+		for port := range opts.Config.ExposedPorts {
+			if err := validatePort(port); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if hostConfig := opts.HostConfig; hostConfig != nil {
+		for port, bindings := range hostConfig.PortBindings {
+			if err := validatePort(port); err != nil {
+				errs = append(errs, err)
+			}
+			for _, binding := range bindings {
+				if binding.HostPort == "" {
+					continue
+				}
+				if _, _, err := parsePortRange(binding.HostPort); err != nil {
+					errs = append(errs, fmt.Errorf("invalid host port %q for %s", binding.HostPort, port))
+				}
+			}
+		}
+		if len(hostConfig.Links) > 0 {
+			if mode := hostConfig.NetworkMode; mode == "host" || strings.HasPrefix(mode, "container:") {
+				errs = append(errs, fmt.Errorf("links can't be used with network mode %q", mode))
+			}
+		}
+		if hostConfig.Memory > 0 && hostConfig.MemorySwap > 0 && hostConfig.MemorySwap < hostConfig.Memory {
+			errs = append(errs, fmt.Errorf("memory+swap limit (%d) must be larger than memory limit (%d)", hostConfig.MemorySwap, hostConfig.Memory))
+		}
+		if err := validateRestartPolicy(hostConfig.RestartPolicy); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidContainerOptions, errors.Join(errs...))
+}
+
+func validatePort(port Port) error {
+	if _, _, err := parsePortRange(port.Port()); err != nil {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	switch port.Proto() {
+	case "tcp", "udp", "sctp":
+		return nil
+	}
+	return fmt.Errorf("invalid protocol in port %q", port)
+}
+
+// parsePortRange parses a port number (8080) or a range of ports (8080-8090).
+func parsePortRange(rawPort string) (start int, end int, err error) {
+	first, last, isRange := strings.Cut(rawPort, "-")
+	if start, err = parsePort(first); err != nil || start == 0 {
+		return 0, 0, fmt.Errorf("invalid port %q", rawPort)
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	if end, err = parsePort(last); err != nil || end < start {
+		return 0, 0, fmt.Errorf("invalid port range %q", rawPort)
+	}
+	return start, end, nil
+}
+
+func validateRestartPolicy(policy RestartPolicy) error {
+	switch policy.Name {
+	case "", "no", "always", "unless-stopped":
+		if policy.MaximumRetryCount != 0 {
+			return fmt.Errorf("maximum retry count can't be used with restart policy %q", policy.Name)
+		}
+	case "on-failure":
+		if policy.MaximumRetryCount < 0 {
+			return fmt.Errorf("maximum retry count can't be negative")
+		}
+	default:
+		return fmt.Errorf("invalid restart policy %q", policy.Name)
+	}
+	return nil
+}
+
 // CreateContainer creates a new container, returning the container instance,
 // or an error in case of failure.
 //
@@ -32,7 +135,71 @@ type CreateContainerOptions struct {
 //
 // See https://goo.gl/tyzwVM for more details.
 func (c *Client) CreateContainer(opts CreateContainerOptions) (*Container, error) {
-	path := "/containers/create?" + queryString(opts)
+	if opts.Name != "" || opts.NamePrefix == "" {
+		return c.createContainer(opts)
+	}
+	for attempt := 1; ; attempt++ {
+		name, err := generateContainerName(opts.NamePrefix)
+		if err != nil {
+			return nil, err
+		}
+		opts.Name = name
+		container, err := c.createContainer(opts)
+		if !errors.Is(err, ErrContainerAlreadyExists) || attempt == generatedNameAttempts {
+			return container, err
+		}
+	}
+}
+
+// generatedNameAttempts is the number of names generated from a prefix by
+// CreateContainer before giving up on name collisions.
+const generatedNameAttempts = 5
+
+// generateContainerName returns prefix followed by a dash and a random
+// suffix of 12 hex digits, like the short form of container IDs.
+func generateContainerName(prefix string) (string, error) {
+	var suffix [6]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		return "", err
+	}
+	return prefix + "-" + hex.EncodeToString(suffix[:]), nil
+}
+
+// hostConfigAPIVersion returns the API version required by the fields set in
+// hostConfig, which older daemons would silently ignore.
+func hostConfigAPIVersion(hostConfig *HostConfig) APIVersion {
+	var version APIVersion
+	require := func(v APIVersion) {
+		if v.GreaterThan(version) {
+			version = v
+		}
+	}
+	if hostConfig == nil {
+		return version
+	}
+	if len(hostConfig.Annotations) > 0 {
+		require(apiVersion143)
+	}
+	for _, mount := range hostConfig.Mounts {
+		if bind := mount.BindOptions; bind != nil && (bind.ReadOnlyNonRecursive || bind.ReadOnlyForceRecursive) {
+			require(apiVersion144)
+		}
+		if volume := mount.VolumeOptions; volume != nil && volume.Subpath != "" {
+			require(apiVersion145)
+		}
+	}
+	return version
+}
+
+func (c *Client) createContainer(opts CreateContainerOptions) (*Container, error) {
+	qs, requiredAPIVersion := queryStringVersion(opts)
+	if version := hostConfigAPIVersion(opts.HostConfig); version.GreaterThan(requiredAPIVersion) {
+		requiredAPIVersion = version
+	}
+	if err := c.checkRequiredAPIVersion("/containers/create", requiredAPIVersion); err != nil {
+		return nil, err
+	}
+	path := "/containers/create?" + qs
 	resp, err := c.do(
 		http.MethodPost,
 		path,
@@ -75,6 +242,7 @@ func (c *Client) CreateContainer(opts CreateContainerOptions) (*Container, error
 	}
 
 	container.Name = opts.Name
+	c.warn(http.MethodPost, path, container.Warnings...)
 
 	return &container, nil
 }
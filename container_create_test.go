@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -87,6 +91,47 @@ func TestCreateContainerDuplicateNameWorkaroundDocker17_09(t *testing.T) {
 	}
 }
 
+func TestCreateContainerNamePrefix(t *testing.T) {
+	t.Parallel()
+	var names []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		names = append(names, r.URL.Query().Get("name"))
+		if len(names) == 1 {
+			http.Error(w, "Conflict. The container name is already in use", http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id":"abc"}`))
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	container, err := client.CreateContainer(CreateContainerOptions{NamePrefix: "job", Config: &Config{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := regexp.MustCompile(`^job-[0-9a-f]{12}$`)
+	if len(names) != 2 || names[0] == names[1] || !pattern.MatchString(names[0]) || !pattern.MatchString(names[1]) {
+		t.Errorf("CreateContainer: wrong generated names: %#v.", names)
+	}
+	if container.Name != names[1] {
+		t.Errorf("CreateContainer: wrong name. Want %q. Got %q.", names[1], container.Name)
+	}
+}
+
+func TestCreateContainerNamePrefixExhausted(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "Conflict", status: http.StatusConflict}
+	client := newTestClient(fakeRT)
+	_, err := client.CreateContainer(CreateContainerOptions{NamePrefix: "job", Config: &Config{}})
+	if !errors.Is(err, ErrContainerAlreadyExists) {
+		t.Errorf("CreateContainer: Wrong error type. Want %#v. Got %#v.", ErrContainerAlreadyExists, err)
+	}
+	if len(fakeRT.requests) != generatedNameAttempts {
+		t.Errorf("CreateContainer: wrong number of attempts. Want %d. Got %d.", generatedNameAttempts, len(fakeRT.requests))
+	}
+}
+
 func TestCreateContainerWithHostConfig(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "{}", status: http.StatusOK}
@@ -144,3 +189,130 @@ func TestPassingPlatformOpt(t *testing.T) {
 		t.Errorf("CreateContainer: missing expected platform query string (%v)", req.URL.RequestURI())
 	}
 }
+
+func TestCreateContainerPlatformRequiresAPIVersion(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "{}", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	client.requestedAPIVersion, _ = NewAPIVersion("1.40")
+	opts := CreateContainerOptions{Platform: "linux/arm64", Config: &Config{}}
+	_, err := client.CreateContainer(opts)
+	if err == nil {
+		t.Fatal("CreateContainer: expected non-nil error, got <nil>")
+	}
+	if len(fakeRT.requests) > 0 {
+		t.Errorf("CreateContainer: expected no requests, got %d", len(fakeRT.requests))
+	}
+}
+
+func TestCreateContainerServerVersionTooOld(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "{}", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	client.SkipServerVersionCheck = false
+	hostConfig := HostConfig{Mounts: []HostMount{{Type: "volume", Source: "shared", Target: "/config", VolumeOptions: &VolumeOptions{Subpath: "app"}}}}
+	_, err := client.CreateContainer(CreateContainerOptions{Config: &Config{}, HostConfig: &hostConfig})
+	if err == nil {
+		t.Fatal("CreateContainer: expected non-nil error, got <nil>")
+	}
+	if len(fakeRT.requests) > 0 {
+		t.Errorf("CreateContainer: expected no requests, got %d", len(fakeRT.requests))
+	}
+}
+
+func TestCreateContainerEngine25Fields(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "{}", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	hostConfig := HostConfig{
+		Annotations: map[string]string{"io.kubernetes.cri.container-type": "container"},
+		ConsoleSize: [2]int{24, 80},
+		Mounts: []HostMount{
+			{Type: "bind", Source: "/data", Target: "/data", ReadOnly: true, BindOptions: &BindOptions{ReadOnlyForceRecursive: true}},
+			{Type: "volume", Source: "shared", Target: "/config", VolumeOptions: &VolumeOptions{Subpath: "app"}},
+		},
+	}
+	_, err := client.CreateContainer(CreateContainerOptions{Config: &Config{Tty: true}, HostConfig: &hostConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body struct {
+		HostConfig HostConfig
+	}
+	if err := json.NewDecoder(fakeRT.requests[0].Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(body.HostConfig, hostConfig) {
+		t.Errorf("CreateContainer: wrong HostConfig. Want %#v. Got %#v.", hostConfig, body.HostConfig)
+	}
+}
+
+func TestCreateContainerEngine25FieldsRequireAPIVersion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		hostConfig HostConfig
+		version    string
+	}{
+		{HostConfig{Annotations: map[string]string{"a": "b"}}, "1.43"},
+		{HostConfig{Mounts: []HostMount{{BindOptions: &BindOptions{ReadOnlyNonRecursive: true}}}}, "1.44"},
+		{HostConfig{Mounts: []HostMount{{VolumeOptions: &VolumeOptions{Subpath: "app"}}}}, "1.45"},
+	}
+	for _, tt := range tests {
+		fakeRT := &FakeRoundTripper{message: "{}", status: http.StatusOK}
+		client := newTestClient(fakeRT)
+		client.requestedAPIVersion, _ = NewAPIVersion("1.42")
+		_, err := client.CreateContainer(CreateContainerOptions{Config: &Config{}, HostConfig: &tt.hostConfig})
+		if err == nil || !strings.Contains(err.Error(), "requires version "+tt.version) {
+			t.Errorf("CreateContainer: wrong error. Want the requirement of version %s. Got %v.", tt.version, err)
+		}
+		if len(fakeRT.requests) > 0 {
+			t.Errorf("CreateContainer: expected no requests, got %d", len(fakeRT.requests))
+		}
+	}
+}
+
+func TestCreateContainerOptionsValidate(t *testing.T) {
+	t.Parallel()
+	opts := CreateContainerOptions{
+		Config: &Config{ExposedPorts: map[Port]struct{}{"8080/tcp": {}, "53/udp": {}, "9000-9010": {}}},
+		HostConfig: &HostConfig{
+			PortBindings:  map[Port][]PortBinding{"8080/tcp": {{HostIP: "0.0.0.0", HostPort: "8080"}, {HostPort: ""}}},
+			Links:         []string{"db:db"},
+			NetworkMode:   "bridge",
+			Memory:        1024,
+			MemorySwap:    -1,
+			RestartPolicy: RestartOnFailure(3),
+		},
+	}
+	if err := opts.Validate(); err != nil {
+		t.Errorf("Validate: unexpected error: %v", err)
+	}
+}
+
+func TestCreateContainerOptionsValidateInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts CreateContainerOptions
+	}{
+		{"invalid exposed port", CreateContainerOptions{Config: &Config{ExposedPorts: map[Port]struct{}{"http/tcp": {}}}}},
+		{"port out of range", CreateContainerOptions{Config: &Config{ExposedPorts: map[Port]struct{}{"70000/tcp": {}}}}},
+		{"invalid protocol", CreateContainerOptions{Config: &Config{ExposedPorts: map[Port]struct{}{"80/icmp": {}}}}},
+		{"invalid host port", CreateContainerOptions{HostConfig: &HostConfig{PortBindings: map[Port][]PortBinding{"80/tcp": {{HostPort: "eighty"}}}}}},
+		{"links with host networking", CreateContainerOptions{HostConfig: &HostConfig{Links: []string{"db:db"}, NetworkMode: "host"}}},
+		{"links with container networking", CreateContainerOptions{HostConfig: &HostConfig{Links: []string{"db:db"}, NetworkMode: "container:abc"}}},
+		{"swap smaller than memory", CreateContainerOptions{HostConfig: &HostConfig{Memory: 2048, MemorySwap: 1024}}},
+		{"unknown restart policy", CreateContainerOptions{HostConfig: &HostConfig{RestartPolicy: RestartPolicy{Name: "sometimes"}}}},
+		{"retry count without on-failure", CreateContainerOptions{HostConfig: &HostConfig{RestartPolicy: RestartPolicy{Name: "always", MaximumRetryCount: 3}}}},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := test.opts.Validate()
+			if !errors.Is(err, ErrInvalidContainerOptions) {
+				t.Errorf("Validate: wrong error. Want %#v. Got %#v.", ErrInvalidContainerOptions, err)
+			}
+		})
+	}
+}
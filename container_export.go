@@ -2,6 +2,7 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,6 +18,12 @@ type ExportContainerOptions struct {
 	OutputStream      io.Writer
 	InactivityTimeout time.Duration `qs:"-"`
 	Context           context.Context
+
+	// Progress, when set, is called with the total number of bytes written
+	// to OutputStream so far, every time a chunk of the archive is
+	// received, along with the size of the archive, or -1 when the daemon
+	// doesn't report it.
+	Progress func(written, total int64) `qs:"-"`
 }
 
 // ExportContainer export the contents of container id as tar archive
@@ -25,13 +32,46 @@ type ExportContainerOptions struct {
 // See https://goo.gl/yGJCIh for more details.
 func (c *Client) ExportContainer(opts ExportContainerOptions) error {
 	if opts.ID == "" {
-		return &NoSuchContainer{ID: opts.ID}
+		return &NoSuchContainer{ID: opts.ID, Op: "export"}
 	}
 	url := fmt.Sprintf("/containers/%s/export", opts.ID)
-	return c.stream(http.MethodGet, url, streamOptions{
+	options := streamOptions{
 		setRawTerminal:    true,
 		stdout:            opts.OutputStream,
 		inactivityTimeout: opts.InactivityTimeout,
 		context:           opts.Context,
-	})
+	}
+	if opts.Progress != nil {
+		progress := &progressWriter{w: opts.OutputStream, total: -1, progress: opts.Progress}
+		options.stdout = progress
+		options.responseHandler = func(resp *http.Response) {
+			progress.total = resp.ContentLength
+		}
+	}
+	err := c.stream(http.MethodGet, url, options)
+	var e *Error
+	if errors.As(err, &e) && e.Status == http.StatusNotFound {
+		return &NoSuchContainer{ID: opts.ID, Op: "export", Err: err}
+	}
+	return err
+}
+
+// progressWriter wraps an io.Writer, reporting the total number of bytes
+// written so far after every write, along with the expected total, or -1
+// when it's unknown.
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	if w.w == nil {
+		w.w = io.Discard
+	}
+	n, err := w.w.Write(p)
+	w.written += int64(n)
+	w.progress(w.written, w.total)
+	return n, err
 }
@@ -3,6 +3,8 @@ package docker
 import (
 	"bytes"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
 )
 
@@ -34,3 +36,54 @@ func TestExportContainerNoId(t *testing.T) {
 		t.Errorf("ExportContainer: wrong ID. Want %q. Got %q", "", e.ID)
 	}
 }
+
+func TestExportContainerProgress(t *testing.T) {
+	t.Parallel()
+	content := "exported container tar content"
+	for _, chunked := range []bool{false, true} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if !chunked {
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			}
+			w.Write([]byte(content[:10]))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(content[10:]))
+		}))
+		defer server.Close()
+		client, err := NewClient(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.SkipServerVersionCheck = true
+		var buf bytes.Buffer
+		var written, total int64
+		opts := ExportContainerOptions{
+			ID:           "4fa6e0f0c678",
+			OutputStream: &buf,
+			Progress:     func(w, t int64) { written, total = w, t },
+		}
+		if err := client.ExportContainer(opts); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != content {
+			t.Errorf("ExportContainer: wrong stdout. Want %#v. Got %#v.", content, buf.String())
+		}
+		if written != int64(len(content)) {
+			t.Errorf("ExportContainer: wrong progress. Want %d. Got %d.", len(content), written)
+		}
+		expectedTotal := int64(len(content))
+		if chunked {
+			expectedTotal = -1
+		}
+		if total != expectedTotal {
+			t.Errorf("ExportContainer(chunked=%v): wrong total. Want %d. Got %d.", chunked, expectedTotal, total)
+		}
+	}
+}
+
+func TestExportContainerNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	err := client.ExportContainer(ExportContainerOptions{ID: "a2334", OutputStream: &bytes.Buffer{}})
+	expectNoSuchContainer(t, "a2334", err)
+}
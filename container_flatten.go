@@ -0,0 +1,201 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FlattenContainerOptions specify parameters to the FlattenContainer
+// function.
+type FlattenContainerOptions struct {
+	// Container is the ID or name of the container to flatten.
+	Container string
+
+	// Repository and Tag name the new image.
+	Repository string
+	Tag        string
+
+	// Config, when set, holds the configuration of the new image. Only
+	// Entrypoint, Cmd, Env, ExposedPorts, Healthcheck, Labels, OnBuild,
+	// StopSignal, User, Volumes and WorkingDir are used. The image has no
+	// configuration otherwise, as exporting a container only keeps its
+	// filesystem.
+	Config *Config
+
+	// Message is the commit message of the new image.
+	Message string
+
+	Context context.Context
+}
+
+// FlattenContainer creates an image with the filesystem of a container,
+// squashed into a single layer, by streaming its export to an import,
+// and returns the new image.
+//
+// Unlike CommitContainer, the history and layers of the image of the
+// container aren't kept, making the new image smaller when files were
+// removed or overwritten.
+func (c *Client) FlattenContainer(opts FlattenContainerOptions) (*Image, error) {
+	if opts.Repository == "" {
+		return nil, ErrNoSuchImage
+	}
+	changes, err := configChanges(opts.Config)
+	if err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	exportErrCh := make(chan error, 1)
+	go func() {
+		err := c.ExportContainer(ExportContainerOptions{
+			ID:           opts.Container,
+			OutputStream: pw,
+			Context:      opts.Context,
+		})
+		pw.CloseWithError(err)
+		exportErrCh <- err
+	}()
+	importErr := c.ImportImage(ImportImageOptions{
+		Repository:  opts.Repository,
+		Tag:         opts.Tag,
+		Source:      "-",
+		Changes:     changes,
+		Message:     opts.Message,
+		InputStream: pr,
+		Context:     opts.Context,
+	})
+	// unblocks the export when the import fails before reading everything
+	pr.CloseWithError(io.ErrClosedPipe)
+	exportErr := <-exportErrCh
+	if importErr != nil && (exportErr == nil || errors.Is(exportErr, io.ErrClosedPipe)) {
+		return nil, importErr
+	}
+	if exportErr != nil {
+		return nil, exportErr
+	}
+	name := opts.Repository
+	if opts.Tag != "" {
+		name += ":" + opts.Tag
+	}
+	return c.InspectImage(name)
+}
+
+// configChanges returns the Dockerfile instructions equivalent to config,
+// in the format expected by ImportImageOptions.Changes.
+func configChanges(config *Config) ([]string, error) {
+	if config == nil {
+		return nil, nil
+	}
+	var changes []string
+	jsonChange := func(instruction string, value any) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, instruction+" "+string(data))
+		return nil
+	}
+	if len(config.Entrypoint) > 0 {
+		if err := jsonChange("ENTRYPOINT", config.Entrypoint); err != nil {
+			return nil, err
+		}
+	}
+	if len(config.Cmd) > 0 {
+		if err := jsonChange("CMD", config.Cmd); err != nil {
+			return nil, err
+		}
+	}
+	for _, env := range config.Env {
+		key, value, ok := strings.Cut(env, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid environment variable %q", env)
+		}
+		changes = append(changes, "ENV "+key+"="+strconv.Quote(value))
+	}
+	labels := make([]string, 0, len(config.Labels))
+	for key, value := range config.Labels {
+		labels = append(labels, strconv.Quote(key)+"="+strconv.Quote(value))
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		changes = append(changes, "LABEL "+label)
+	}
+	ports := make([]string, 0, len(config.ExposedPorts))
+	for port := range config.ExposedPorts {
+		ports = append(ports, port.Port()+"/"+port.Proto())
+	}
+	sort.Strings(ports)
+	for _, port := range ports {
+		changes = append(changes, "EXPOSE "+port)
+	}
+	if len(config.Volumes) > 0 {
+		volumes := make([]string, 0, len(config.Volumes))
+		for volume := range config.Volumes {
+			volumes = append(volumes, volume)
+		}
+		sort.Strings(volumes)
+		if err := jsonChange("VOLUME", volumes); err != nil {
+			return nil, err
+		}
+	}
+	if config.WorkingDir != "" {
+		changes = append(changes, "WORKDIR "+config.WorkingDir)
+	}
+	if config.User != "" {
+		changes = append(changes, "USER "+config.User)
+	}
+	if config.StopSignal != "" {
+		changes = append(changes, "STOPSIGNAL "+config.StopSignal)
+	}
+	if hc := config.Healthcheck; hc != nil && len(hc.Test) > 0 {
+		change, err := healthcheckChange(hc)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	for _, trigger := range config.OnBuild {
+		changes = append(changes, "ONBUILD "+trigger)
+	}
+	return changes, nil
+}
+
+// healthcheckChange returns the HEALTHCHECK instruction equivalent to hc.
+func healthcheckChange(hc *HealthConfig) (string, error) {
+	switch hc.Test[0] {
+	case "NONE":
+		return "HEALTHCHECK NONE", nil
+	case "CMD", "CMD-SHELL":
+	default:
+		return "", fmt.Errorf("invalid healthcheck test %q", hc.Test)
+	}
+	var flags []string
+	for flag, value := range map[string]time.Duration{
+		"interval":     hc.Interval,
+		"timeout":      hc.Timeout,
+		"start-period": hc.StartPeriod,
+	} {
+		if value > 0 {
+			flags = append(flags, "--"+flag+"="+value.String())
+		}
+	}
+	sort.Strings(flags)
+	if hc.Retries > 0 {
+		flags = append(flags, "--retries="+strconv.Itoa(hc.Retries))
+	}
+	command := strings.Join(hc.Test[1:], " ")
+	if hc.Test[0] == "CMD" {
+		data, err := json.Marshal(hc.Test[1:])
+		if err != nil {
+			return "", err
+		}
+		command = string(data)
+	}
+	return strings.Join(append(append([]string{"HEALTHCHECK"}, flags...), "CMD", command), " "), nil
+}
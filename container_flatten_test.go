@@ -0,0 +1,139 @@
+package docker
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFlattenContainer(t *testing.T) {
+	t.Parallel()
+	archive := bytes.Repeat([]byte("layer"), 1<<16)
+	var imported []byte
+	var changes []string
+	var message string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/containers/web/export":
+			w.Write(archive)
+		case r.Method == http.MethodPost && r.URL.Path == "/images/create":
+			query := r.URL.Query()
+			if query.Get("fromSrc") != "-" || query.Get("repo") != "tsuru/web" || query.Get("tag") != "flat" {
+				t.Errorf("FlattenContainer: wrong import query: %s", r.URL.RawQuery)
+			}
+			changes = query["changes"]
+			message = query.Get("message")
+			imported, _ = io.ReadAll(r.Body)
+			w.Write([]byte(`{"status":"sha256:3e1b8e6b1a9f"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/images/tsuru/web:flat/json":
+			w.Write([]byte(`{"Id":"sha256:3e1b8e6b1a9f"}`))
+		default:
+			t.Errorf("FlattenContainer: unexpected request: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	image, err := client.FlattenContainer(FlattenContainerOptions{
+		Container:  "web",
+		Repository: "tsuru/web",
+		Tag:        "flat",
+		Message:    "flattened",
+		Config: &Config{
+			Entrypoint:   []string{"/bin/web"},
+			Cmd:          []string{"--port", "8080"},
+			Env:          []string{"MODE=production", "GREETING=hello world"},
+			Labels:       map[string]string{"app": "web"},
+			ExposedPorts: map[Port]struct{}{"8080/tcp": {}},
+			WorkingDir:   "/srv",
+			User:         "nobody",
+			Healthcheck: &HealthConfig{
+				Test:     []string{"CMD", "/bin/web", "-check"},
+				Interval: 30 * time.Second,
+				Retries:  3,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.ID != "sha256:3e1b8e6b1a9f" {
+		t.Errorf("FlattenContainer: wrong image ID: %q", image.ID)
+	}
+	if !bytes.Equal(imported, archive) {
+		t.Errorf("FlattenContainer: wrong imported archive, got %d bytes, want %d", len(imported), len(archive))
+	}
+	expectedChanges := []string{
+		`ENTRYPOINT ["/bin/web"]`,
+		`CMD ["--port","8080"]`,
+		`ENV MODE="production"`,
+		`ENV GREETING="hello world"`,
+		`LABEL "app"="web"`,
+		`EXPOSE 8080/tcp`,
+		`WORKDIR /srv`,
+		`USER nobody`,
+		`HEALTHCHECK --interval=30s --retries=3 CMD ["/bin/web","-check"]`,
+	}
+	if !reflect.DeepEqual(changes, expectedChanges) {
+		t.Errorf("FlattenContainer: wrong changes.\nWant %#v.\nGot  %#v.", expectedChanges, changes)
+	}
+	if message != "flattened" {
+		t.Errorf("FlattenContainer: wrong message: %q", message)
+	}
+}
+
+func TestFlattenContainerNoSuchContainer(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/images/create" {
+			io.Copy(io.Discard, r.Body)
+			w.Write([]byte(`{"status":"sha256:3e1b8e6b1a9f"}`))
+			return
+		}
+		http.Error(w, "No such container: web", http.StatusNotFound)
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	_, err := client.FlattenContainer(FlattenContainerOptions{Container: "web", Repository: "tsuru/web"})
+	expectNoSuchContainer(t, "web", err)
+}
+
+func TestFlattenContainerImportError(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/images/create" {
+			http.Error(w, "no space left on device", http.StatusInternalServerError)
+			return
+		}
+		w.Write(bytes.Repeat([]byte("layer"), 1<<20))
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	_, err := client.FlattenContainer(FlattenContainerOptions{Container: "web", Repository: "tsuru/web"})
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusInternalServerError {
+		t.Errorf("FlattenContainer: wrong error. Want the import error. Got %v.", err)
+	}
+}
+
+func TestFlattenContainerInvalidConfig(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	_, err := client.FlattenContainer(FlattenContainerOptions{
+		Container:  "web",
+		Repository: "tsuru/web",
+		Config:     &Config{Env: []string{"MODE"}},
+	})
+	if err == nil {
+		t.Fatal("FlattenContainer: unexpected <nil> error")
+	}
+	if len(fakeRT.requests) != 0 {
+		t.Errorf("FlattenContainer: unexpected requests: %d", len(fakeRT.requests))
+	}
+}
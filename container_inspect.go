@@ -27,19 +27,20 @@ func (c *Client) InspectContainerWithContext(id string, ctx context.Context) (*C
 // See https://goo.gl/FaI5JT for more details.
 func (c *Client) InspectContainerWithOptions(opts InspectContainerOptions) (*Container, error) {
 	path := "/containers/" + opts.ID + "/json?" + queryString(opts)
-	resp, err := c.do(http.MethodGet, path, doOptions{
-		context: opts.Context,
+	body, err := c.cachedInspect(path, opts.ID, func() (*http.Response, error) {
+		return c.do(http.MethodGet, path, doOptions{
+			context: opts.Context,
+		})
 	})
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return nil, &NoSuchContainer{ID: opts.ID}
+			return nil, &NoSuchContainer{ID: opts.ID, Op: "inspect", Err: err}
 		}
 		return nil, err
 	}
-	defer resp.Body.Close()
 	var container Container
-	if err := json.NewDecoder(resp.Body).Decode(&container); err != nil {
+	if err := json.Unmarshal(body, &container); err != nil {
 		return nil, err
 	}
 	return &container, nil
@@ -34,7 +34,7 @@ func (c *Client) KillContainer(opts KillContainerOptions) error {
 		}
 		switch e.Status {
 		case http.StatusNotFound:
-			return &NoSuchContainer{ID: opts.ID}
+			return &NoSuchContainer{ID: opts.ID, Op: "kill", Err: err}
 		case http.StatusConflict:
 			return &ContainerNotRunning{ID: opts.ID}
 		default:
@@ -44,3 +44,16 @@ func (c *Client) KillContainer(opts KillContainerOptions) error {
 	resp.Body.Close()
 	return nil
 }
+
+// KillContainerWithSignal sends the given signal to a container. The signal
+// may be either numeric or symbolic, as accepted by ParseSignal. It returns
+// ContainerNotRunning when the container isn't running.
+//
+// See https://goo.gl/JnTxXZ for more details.
+func (c *Client) KillContainerWithSignal(id, signal string) error {
+	sig, err := ParseSignal(signal)
+	if err != nil {
+		return err
+	}
+	return c.KillContainer(KillContainerOptions{ID: id, Signal: sig})
+}
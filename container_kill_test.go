@@ -63,3 +63,31 @@ func TestKillContainerNotRunning(t *testing.T) {
 		t.Errorf("KillContainer: Wrong error returned. Want %#v. Got %#v.", expected, err)
 	}
 }
+
+func TestKillContainerWithSignal(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusNoContent}
+	client := newTestClient(fakeRT)
+	id := "4fa6e0f0c6786287e131c3852c58a2e01cc697a68231826813597e4994f1d6e2"
+	err := client.KillContainerWithSignal(id, "SIGUSR1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := fakeRT.requests[0]
+	if signal := req.URL.Query().Get("signal"); signal != "10" {
+		t.Errorf("KillContainerWithSignal(%q): Wrong query string in request. Want %q. Got %q.", id, "10", signal)
+	}
+}
+
+func TestKillContainerWithSignalInvalid(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusNoContent}
+	client := newTestClient(fakeRT)
+	err := client.KillContainerWithSignal("a2334", "SIGWHATEVER")
+	if err == nil {
+		t.Fatal("KillContainerWithSignal: expected non-nil error, got <nil>")
+	}
+	if len(fakeRT.requests) > 0 {
+		t.Errorf("KillContainerWithSignal: expected no requests, got %d", len(fakeRT.requests))
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"regexp"
+	"strings"
 )
 
 // ListContainersOptions specify parameters to the ListContainers function.
@@ -35,3 +37,50 @@ func (c *Client) ListContainers(opts ListContainersOptions) ([]APIContainers, er
 	}
 	return containers, nil
 }
+
+// ContainerByName returns the container with the given name, running or not.
+// The name may be given with or without the leading slash reported by the
+// API. It returns a *NoSuchContainer error when there's no such container.
+func (c *Client) ContainerByName(name string) (*APIContainers, error) {
+	name = strings.TrimPrefix(name, "/")
+	// the name filter matches substrings, so anchor it and check the names
+	// of the returned containers anyway.
+	containers, err := c.ListContainers(ListContainersOptions{
+		All:     true,
+		Filters: map[string][]string{"name": {"^/" + regexp.QuoteMeta(name) + "$"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range containers {
+		for _, n := range containers[i].Names {
+			if strings.TrimPrefix(n, "/") == name {
+				return &containers[i], nil
+			}
+		}
+	}
+	return nil, &NoSuchContainer{ID: name, Op: "lookup"}
+}
+
+// ContainersByLabel returns the containers, running or not, that have the
+// label key set to value. An empty value matches any value of the label.
+func (c *Client) ContainersByLabel(key, value string) ([]APIContainers, error) {
+	return c.ContainersByLabelWithContext(key, value, context.TODO())
+}
+
+// ContainersByLabelWithContext is like ContainersByLabel. The context object
+// can be used to cancel the request.
+func (c *Client) ContainersByLabelWithContext(key, value string, ctx context.Context) ([]APIContainers, error) {
+	return c.ListContainers(ListContainersOptions{
+		All:     true,
+		Filters: LabelFilter{key: value}.Filters(),
+		Context: ctx,
+	})
+}
+
+// RunningContainers returns the containers that are currently running.
+func (c *Client) RunningContainers() ([]APIContainers, error) {
+	return c.ListContainers(ListContainersOptions{
+		Filters: map[string][]string{"status": {StateRunning}},
+	})
+}
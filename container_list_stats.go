@@ -0,0 +1,65 @@
+package docker
+
+import "sync"
+
+// ListContainersWithStatsOptions specify parameters to the
+// ListContainersWithStats function.
+type ListContainersWithStatsOptions struct {
+	// ListContainersOptions select the containers listed, see
+	// ListContainers. Its Context is also used to gather the stats.
+	ListContainersOptions
+
+	// Parallelism is the maximum number of containers whose stats are
+	// gathered concurrently, defaults to DefaultBatchParallelism.
+	Parallelism int
+}
+
+// ContainerWithStats is a container listed by ListContainersWithStats,
+// along with a snapshot of its stats.
+type ContainerWithStats struct {
+	APIContainers
+
+	// Stats is the snapshot of the stats of the container. It's nil when
+	// the container isn't running, or when its stats couldn't be gathered,
+	// in which case StatsErr holds the reason, like a NoSuchContainer
+	// error for a container removed after being listed.
+	Stats    *Stats
+	StatsErr error
+}
+
+// ListContainersWithStats lists containers like ListContainers, attaching
+// to each running container a snapshot of its stats, gathered concurrently,
+// like docker stats --no-stream does.
+//
+// An error is only returned when the containers can't be listed, failures
+// to gather the stats of a container are reported in its StatsErr.
+func (c *Client) ListContainersWithStats(opts ListContainersWithStatsOptions) ([]ContainerWithStats, error) {
+	containers, err := c.ListContainers(opts.ListContainersOptions)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ContainerWithStats, len(containers))
+	index := make(map[string]int, len(containers))
+	var running []string
+	for i, container := range containers {
+		result[i].APIContainers = container
+		if IsRunning(container.State) {
+			index[container.ID] = i
+			running = append(running, container.ID)
+		}
+	}
+	var mu sync.Mutex
+	errs := batchContainers(running, opts.Parallelism, func(id string) error {
+		stats, err := c.statsSnapshot(opts.Context, id, id)
+		if err == nil {
+			mu.Lock()
+			result[index[id]].Stats = stats
+			mu.Unlock()
+		}
+		return err
+	})
+	for id, err := range errs {
+		result[index[id]].StatsErr = err
+	}
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestListContainersWithStats(t *testing.T) {
+	t.Parallel()
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/json":
+			w.Write([]byte(`[
+				{"Id":"running1","State":"running"},
+				{"Id":"exited","State":"exited"},
+				{"Id":"running2","State":"running"},
+				{"Id":"gone","State":"running"}
+			]`))
+		case "/containers/running1/stats", "/containers/running2/stats":
+			if r.URL.Query().Get("stream") != "false" {
+				t.Errorf("ListContainersWithStats: stats streamed for %s", r.URL.Path)
+			}
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				current := atomic.LoadInt32(&maxInFlight)
+				if n <= current || atomic.CompareAndSwapInt32(&maxInFlight, current, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			w.Write([]byte(`{"read":"2026-10-18T10:00:00Z","pids_stats":{"current":3}}`))
+		default:
+			http.Error(w, "no such container", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	containers, err := client.ListContainersWithStats(ListContainersWithStatsOptions{
+		ListContainersOptions: ListContainersOptions{All: true},
+		Parallelism:           1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 4 {
+		t.Fatalf("ListContainersWithStats: wrong number of containers. Want 4. Got %d.", len(containers))
+	}
+	for _, i := range []int{0, 2} {
+		if c := containers[i]; c.StatsErr != nil || c.Stats == nil || c.Stats.PidsStats.Current != 3 {
+			t.Errorf("ListContainersWithStats: wrong stats for %s: %#v, %v", c.ID, c.Stats, c.StatsErr)
+		}
+	}
+	if c := containers[1]; c.Stats != nil || c.StatsErr != nil {
+		t.Errorf("ListContainersWithStats: unexpected stats for a container that isn't running: %#v, %v", c.Stats, c.StatsErr)
+	}
+	if c := containers[3]; c.Stats != nil || !errors.Is(c.StatsErr, ErrNoSuchContainer) {
+		t.Errorf("ListContainersWithStats: wrong error for a removed container. Want %v. Got %v.", ErrNoSuchContainer, c.StatsErr)
+	}
+	if maxInFlight != 1 {
+		t.Errorf("ListContainersWithStats: wrong parallelism. Want 1. Got %d.", maxInFlight)
+	}
+}
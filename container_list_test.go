@@ -1,7 +1,9 @@
 package docker
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"reflect"
 	"strconv"
@@ -130,3 +132,80 @@ func TestListContainersFailure(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerByName(t *testing.T) {
+	t.Parallel()
+	body := `[{"Id":"abc","Names":["/web-1"]},{"Id":"def","Names":["/web"]}]`
+	fakeRT := &FakeRoundTripper{message: body, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	container, err := client.ContainerByName("/web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if container.ID != "def" {
+		t.Errorf("ContainerByName: wrong container. Want %q. Got %q.", "def", container.ID)
+	}
+	query := fakeRT.requests[0].URL.Query()
+	if query.Get("all") != "1" || query.Get("filters") != `{"name":["^/web$"]}` {
+		t.Errorf("ContainerByName: wrong query: %v.", query)
+	}
+}
+
+func TestContainerByNameNotFound(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: `[{"Id":"abc","Names":["/web-1"]}]`, status: http.StatusOK})
+	_, err := client.ContainerByName("web")
+	var notFound *NoSuchContainer
+	if !errors.As(err, &notFound) || notFound.ID != "web" {
+		t.Errorf("ContainerByName: wrong error. Want *NoSuchContainer. Got %#v.", err)
+	}
+}
+
+func TestContainersByLabel(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		key, value string
+		filters    string
+	}{
+		{"app", "web", `{"label":["app=web"]}`},
+		{"app", "", `{"label":["app"]}`},
+	}
+	for _, test := range tests {
+		fakeRT := &FakeRoundTripper{message: "[]", status: http.StatusOK}
+		client := newTestClient(fakeRT)
+		if _, err := client.ContainersByLabel(test.key, test.value); err != nil {
+			t.Fatal(err)
+		}
+		query := fakeRT.requests[0].URL.Query()
+		if query.Get("all") != "1" || query.Get("filters") != test.filters {
+			t.Errorf("ContainersByLabel(%q, %q): wrong query: %v.", test.key, test.value, query)
+		}
+	}
+}
+
+func TestContainersByLabelWithContext(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "[]", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	if _, err := client.ContainersByLabelWithContext("app", "web", ctx); err != nil {
+		t.Fatal(err)
+	}
+	if value := fakeRT.requests[0].Context().Value(key{}); value != "value" {
+		t.Error("ContainersByLabelWithContext: context not passed to the request")
+	}
+}
+
+func TestRunningContainers(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "[]", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	if _, err := client.RunningContainers(); err != nil {
+		t.Fatal(err)
+	}
+	query := fakeRT.requests[0].URL.Query()
+	if query.Get("all") != "" || query.Get("filters") != `{"status":["running"]}` {
+		t.Errorf("RunningContainers: wrong query: %v.", query)
+	}
+}
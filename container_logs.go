@@ -19,6 +19,11 @@ type LogsOptions struct {
 	InactivityTimeout time.Duration `qs:"-"`
 	Tail              string
 
+	// MaxOutputBytes is the maximum number of bytes written to
+	// OutputStream and ErrorStream together. Once it's reached, Logs stops
+	// and returns ErrOutputTruncated. Zero means no limit.
+	MaxOutputBytes int64 `qs:"-"`
+
 	Since      int64
 	Follow     bool
 	Stdout     bool
@@ -26,7 +31,13 @@ type LogsOptions struct {
 	Timestamps bool
 
 	// Use raw terminal? Usually true when the container contains a TTY.
+	// When false, the container is inspected and the raw stream is used
+	// if it has a TTY, unless SkipTerminalDetection is set.
 	RawTerminal bool `qs:"-"`
+
+	// SkipTerminalDetection makes Logs demultiplex the stream whenever
+	// RawTerminal is false, without inspecting the container.
+	SkipTerminalDetection bool `qs:"-"`
 }
 
 // Logs gets stdout and stderr logs from the specified container.
@@ -37,21 +48,24 @@ type LogsOptions struct {
 //
 // When LogsOptions.RawTerminal is true, callers will get the raw stream on
 // LogsOptions.OutputStream. The caller can use libraries such as dlog
-// (github.com/ahmetalpbalkan/dlog).
+// (github.com/ahmetalpbalkan/dlog). The raw stream is also used when the
+// container has a TTY, as its logs aren't multiplexed.
 //
 // See https://goo.gl/krK0ZH for more details.
 func (c *Client) Logs(opts LogsOptions) error {
 	if opts.Container == "" {
-		return &NoSuchContainer{ID: opts.Container}
+		return &NoSuchContainer{ID: opts.Container, Op: "logs"}
 	}
 	if opts.Tail == "" {
 		opts.Tail = "all"
 	}
+	rawTerminal := c.rawTerminal(opts.Context, opts.Container, opts.RawTerminal, opts.SkipTerminalDetection)
 	path := "/containers/" + opts.Container + "/logs?" + queryString(opts)
+	stdout, stderr := limitOutput(opts.MaxOutputBytes, opts.OutputStream, opts.ErrorStream)
 	return c.stream(http.MethodGet, path, streamOptions{
-		setRawTerminal:    opts.RawTerminal,
-		stdout:            opts.OutputStream,
-		stderr:            opts.ErrorStream,
+		setRawTerminal:    rawTerminal,
+		stdout:            stdout,
+		stderr:            stderr,
 		inactivityTimeout: opts.InactivityTimeout,
 		context:           opts.Context,
 	})
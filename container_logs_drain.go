@@ -0,0 +1,223 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultDrainRetryDelay is the default delay before DrainLogs subscribes
+// again to the logs of a container.
+const defaultDrainRetryDelay = time.Second
+
+// LogSink is a destination of the logs drained by DrainLogs. Either writer
+// may be nil to discard the corresponding stream.
+type LogSink struct {
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// DrainLogsOptions are the options for draining the logs of a container.
+type DrainLogsOptions struct {
+	Container string
+	Sinks     []LogSink
+
+	Stdout     bool
+	Stderr     bool
+	Timestamps bool
+
+	// Tail and Since apply to the first subscription only; the next ones
+	// resume after the last line written to the sinks.
+	Tail  string
+	Since int64
+
+	// Use raw terminal? Usually true when the container contains a TTY.
+	// When false, the container is inspected once, before the first
+	// subscription, and the raw stream is used if it has a TTY, unless
+	// SkipTerminalDetection is set.
+	RawTerminal bool
+
+	// SkipTerminalDetection makes DrainLogs demultiplex the stream
+	// whenever RawTerminal is false, without inspecting the container.
+	SkipTerminalDetection bool
+
+	// RetryDelay is the delay between the end of a subscription and the
+	// next one. Defaults to one second.
+	RetryDelay        time.Duration
+	InactivityTimeout time.Duration
+	Context           context.Context
+}
+
+// DrainLogs follows the logs of a container, writing them to every sink,
+// until the container is removed or the context is done.
+//
+// Whenever the stream ends while the container still exists, for instance
+// because the daemon rotated the log file or the container was restarted,
+// DrainLogs subscribes to the logs again. Lines are requested with
+// timestamps, so the ones already written aren't written twice; the
+// timestamps are removed before writing unless Timestamps is set.
+//
+// DrainLogs returns nil once the container is removed, and the error of
+// the context once it's done.
+func (c *Client) DrainLogs(opts DrainLogsOptions) error {
+	if opts.Container == "" {
+		return &NoSuchContainer{ID: opts.Container, Op: "logs"}
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	retryDelay := opts.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = defaultDrainRetryDelay
+	}
+	var stdoutSinks, stderrSinks []io.Writer
+	for _, sink := range opts.Sinks {
+		if sink.Stdout != nil {
+			stdoutSinks = append(stdoutSinks, sink.Stdout)
+		}
+		if sink.Stderr != nil {
+			stderrSinks = append(stderrSinks, sink.Stderr)
+		}
+	}
+	state := &drainState{timestamps: opts.Timestamps}
+	stdout := &drainWriter{state: state, sink: io.MultiWriter(stdoutSinks...)}
+	stderr := &drainWriter{state: state, sink: io.MultiWriter(stderrSinks...)}
+	rawTerminal := c.rawTerminal(ctx, opts.Container, opts.RawTerminal, opts.SkipTerminalDetection)
+	tail, since := opts.Tail, opts.Since
+	for {
+		err := c.Logs(LogsOptions{
+			Context:               ctx,
+			Container:             opts.Container,
+			OutputStream:          stdout,
+			ErrorStream:           stderr,
+			InactivityTimeout:     opts.InactivityTimeout,
+			Tail:                  tail,
+			Since:                 since,
+			Follow:                true,
+			Stdout:                opts.Stdout,
+			Stderr:                opts.Stderr,
+			Timestamps:            true,
+			RawTerminal:           rawTerminal,
+			SkipTerminalDetection: true,
+		})
+		if flushErr := stdout.flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+		if flushErr := stderr.flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			var e *Error
+			if errors.As(err, &e) && e.Status == http.StatusNotFound {
+				return nil
+			}
+			return err
+		}
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		_, err = c.InspectContainerWithOptions(InspectContainerOptions{ID: opts.Container, Context: ctx})
+		if err != nil {
+			var noSuchContainer *NoSuchContainer
+			if errors.As(err, &noSuchContainer) {
+				return nil
+			}
+			return err
+		}
+		tail = "all"
+		if last := state.lastTime(); !last.IsZero() {
+			since = last.Unix()
+		}
+	}
+}
+
+// drainState is the state shared by the writers of the streams of a
+// container whose logs are drained.
+type drainState struct {
+	timestamps bool
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+func (s *drainState) lastTime() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// accept reports whether a line with the given time wasn't written yet,
+// recording it as the last one written if so.
+func (s *drainState) accept(t time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !t.After(s.last) {
+		return false
+	}
+	s.last = t
+	return true
+}
+
+// drainWriter splits a stream into lines, writing the ones that weren't
+// written yet to the sink.
+type drainWriter struct {
+	state   *drainState
+	sink    io.Writer
+	partial []byte
+}
+
+func (w *drainWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.partial = append(w.partial, p...)
+			break
+		}
+		line := append(w.partial, p[:i+1]...)
+		w.partial = nil
+		p = p[i+1:]
+		if err := w.writeLine(line); err != nil {
+			return n - len(p), err
+		}
+	}
+	return n, nil
+}
+
+// flush writes the incomplete line kept by the writer, if any.
+func (w *drainWriter) flush() error {
+	if len(w.partial) == 0 {
+		return nil
+	}
+	line := w.partial
+	w.partial = nil
+	return w.writeLine(line)
+}
+
+func (w *drainWriter) writeLine(line []byte) error {
+	logLine, err := ParseLogLine("", string(line))
+	if err != nil {
+		// not a line of the container, such as a message of the daemon
+		_, err = w.sink.Write(line)
+		return err
+	}
+	if !w.state.accept(logLine.Time) {
+		return nil
+	}
+	if !w.state.timestamps {
+		_, message, _ := bytes.Cut(line, []byte(" "))
+		line = message
+	}
+	_, err = w.sink.Write(line)
+	return err
+}
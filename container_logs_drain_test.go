@@ -0,0 +1,106 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeLogFrame(w http.ResponseWriter, stream byte, line string) {
+	header := []byte{stream, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(header[4:], uint32(len(line)))
+	w.Write(header)
+	w.Write([]byte(line))
+}
+
+func TestDrainLogs(t *testing.T) {
+	t.Parallel()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	line := func(i int, message string) string {
+		return base.Add(time.Duration(i)*time.Millisecond).Format(time.RFC3339Nano) + " " + message + "\n"
+	}
+	var mu sync.Mutex
+	var since []string
+	inspects := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/logs"):
+			since = append(since, r.URL.Query().Get("since"))
+			writeLogFrame(w, 1, line(1, "one"))
+			writeLogFrame(w, 2, line(2, "two"))
+			if len(since) > 1 {
+				// the log file was rotated, the stream resumes
+				writeLogFrame(w, 1, line(3, "three"))
+			}
+		case strings.HasSuffix(r.URL.Path, "/json"):
+			inspects++
+			if inspects > 1 {
+				http.Error(w, "no such container", http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"Id":"a123456"}`))
+		}
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	var stdout1, stdout2, stderr1 bytes.Buffer
+	err := client.DrainLogs(DrainLogsOptions{
+		Container:             "a123456",
+		Sinks:                 []LogSink{{Stdout: &stdout1, Stderr: &stderr1}, {Stdout: &stdout2}},
+		Stdout:                true,
+		Stderr:                true,
+		RetryDelay:            time.Millisecond,
+		SkipTerminalDetection: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, buf := range []*bytes.Buffer{&stdout1, &stdout2} {
+		if expected := "one\nthree\n"; buf.String() != expected {
+			t.Errorf("DrainLogs: wrong stdout. Want %q. Got %q.", expected, buf.String())
+		}
+	}
+	if expected := "two\n"; stderr1.String() != expected {
+		t.Errorf("DrainLogs: wrong stderr. Want %q. Got %q.", expected, stderr1.String())
+	}
+	expectedSince := []string{"", strconv.FormatInt(base.Unix(), 10)}
+	if !reflect.DeepEqual(since, expectedSince) {
+		t.Errorf("DrainLogs: wrong since parameters. Want %q. Got %q.", expectedSince, since)
+	}
+}
+
+func TestDrainLogsContextCanceled(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/json") {
+			w.Write([]byte(`{"Id":"a123456"}`))
+		}
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := client.DrainLogs(DrainLogsOptions{
+		Container:             "a123456",
+		Stdout:                true,
+		RetryDelay:            time.Millisecond,
+		Context:               ctx,
+		SkipTerminalDetection: true,
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DrainLogs: wrong error. Want %v. Got %v.", context.DeadlineExceeded, err)
+	}
+}
@@ -2,6 +2,7 @@ package docker
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -189,3 +190,44 @@ func TestLogsNoContainer(t *testing.T) {
 	err := client.Logs(LogsOptions{})
 	expectNoSuchContainer(t, "", err)
 }
+
+func TestLogsMaxOutputBytes(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte{1, 0, 0, 0, 0, 0, 0, 6})
+		w.Write([]byte("hello\n"))
+		w.Write([]byte{2, 0, 0, 0, 0, 0, 0, 6})
+		w.Write([]byte("oops!\n"))
+		w.Write([]byte{1, 0, 0, 0, 0, 0, 0, 6})
+		w.Write([]byte("world\n"))
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	tests := []struct {
+		max            int64
+		stdout, stderr string
+		err            error
+	}{
+		{max: 8, stdout: "hello\n", stderr: "oo", err: ErrOutputTruncated},
+		{max: 18, stdout: "hello\nworld\n", stderr: "oops!\n"},
+		{max: 0, stdout: "hello\nworld\n", stderr: "oops!\n"},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		err := client.Logs(LogsOptions{
+			Container:      "a123456",
+			OutputStream:   &stdout,
+			ErrorStream:    &stderr,
+			Stdout:         true,
+			Stderr:         true,
+			MaxOutputBytes: tt.max,
+		})
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Logs(MaxOutputBytes: %d): wrong error. Want %v. Got %v.", tt.max, tt.err, err)
+		}
+		if stdout.String() != tt.stdout || stderr.String() != tt.stderr {
+			t.Errorf("Logs(MaxOutputBytes: %d): wrong output. Want %q and %q. Got %q and %q.", tt.max, tt.stdout, tt.stderr, stdout.String(), stderr.String())
+		}
+	}
+}
@@ -15,7 +15,7 @@ func (c *Client) PauseContainer(id string) error {
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return &NoSuchContainer{ID: id}
+			return &NoSuchContainer{ID: id, Op: "pause", Err: err}
 		}
 		return err
 	}
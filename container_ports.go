@@ -0,0 +1,125 @@
+package docker
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// WaitForPortsOptions specify parameters to the WaitForPorts function.
+type WaitForPortsOptions struct {
+	ID string
+
+	// Ports are the container ports to wait for. When empty, WaitForPorts
+	// waits for all the ports the container publishes: the ones in
+	// HostConfig.PortBindings, and all the exposed ports when
+	// HostConfig.PublishAllPorts is set.
+	Ports []Port
+
+	// Timeout defaults to DefaultReadyTimeout and Interval to
+	// DefaultReadyInterval.
+	Timeout  time.Duration
+	Interval time.Duration
+
+	Context context.Context
+}
+
+// WaitForPorts inspects the container until the host bindings of its
+// published ports are reported, which may take a moment after the container
+// starts, and returns them sorted by container port. It returns
+// ErrContainerNotReady if the bindings aren't reported within the timeout,
+// and ErrContainerExited if the container stops running.
+func (c *Client) WaitForPorts(opts WaitForPortsOptions) ([]APIPort, error) {
+	var published *Container
+	check := PortsPublishedReady(opts.Ports...)
+	err := c.WaitForContainerWithOptions(WaitForContainerOptions{
+		ID: opts.ID,
+		Checks: []ReadyCheck{func(ctx context.Context, c *Client, container *Container) (bool, error) {
+			ready, err := check(ctx, c, container)
+			if ready {
+				published = container
+			}
+			return ready, err
+		}},
+		Timeout:  opts.Timeout,
+		Interval: opts.Interval,
+		Context:  opts.Context,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var mapping []APIPort
+	for _, port := range publishedPorts(published, opts.Ports) {
+		for _, binding := range published.NetworkSettings.PortBindingFor(port) {
+			p, _ := parsePort(port.Port())
+			h, _ := parsePort(binding.HostPort)
+			mapping = append(mapping, APIPort{
+				PrivatePort: int64(p),
+				PublicPort:  int64(h),
+				Type:        port.Proto(),
+				IP:          binding.HostIP,
+			})
+		}
+	}
+	sort.SliceStable(mapping, func(i, j int) bool {
+		if mapping[i].PrivatePort != mapping[j].PrivatePort {
+			return mapping[i].PrivatePort < mapping[j].PrivatePort
+		}
+		return mapping[i].Type < mapping[j].Type
+	})
+	return mapping, nil
+}
+
+// PortsPublishedReady returns a check that passes once the host bindings of
+// the given container ports are reported. Without ports, it waits for all
+// the ports the container publishes, see WaitForPortsOptions.
+func PortsPublishedReady(ports ...Port) ReadyCheck {
+	return func(_ context.Context, _ *Client, container *Container) (bool, error) {
+		if container.NetworkSettings == nil {
+			return false, nil
+		}
+		for _, port := range publishedPorts(container, ports) {
+			bindings := container.NetworkSettings.PortBindingFor(port)
+			if len(bindings) == 0 {
+				return false, nil
+			}
+			for _, binding := range bindings {
+				if binding.HostPort == "" || binding.HostPort == "0" {
+					return false, nil
+				}
+			}
+		}
+		return true, nil
+	}
+}
+
+// publishedPorts returns the given ports, normalized to include the
+// protocol, or the ports the container publishes when none is given.
+func publishedPorts(container *Container, ports []Port) []Port {
+	seen := make(map[Port]bool)
+	var result []Port
+	add := func(port Port) {
+		port = Port(port.Port() + "/" + port.Proto())
+		if !seen[port] {
+			seen[port] = true
+			result = append(result, port)
+		}
+	}
+	for _, port := range ports {
+		add(port)
+	}
+	if len(ports) > 0 {
+		return result
+	}
+	if hc := container.HostConfig; hc != nil {
+		for port := range hc.PortBindings {
+			add(port)
+		}
+		if hc.PublishAllPorts && container.Config != nil {
+			for port := range container.Config.ExposedPorts {
+				add(port)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitForPorts(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	client := newReadyTestClient(t, func() string {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return `{"Id":"abc","State":{"Running":true},"Config":{"ExposedPorts":{"80/tcp":{},"53/udp":{}}},"HostConfig":{"PublishAllPorts":true},"NetworkSettings":{"Ports":{"80/tcp":null}}}`
+		}
+		return `{"Id":"abc","State":{"Running":true},"Config":{"ExposedPorts":{"80/tcp":{},"53/udp":{}}},"HostConfig":{"PublishAllPorts":true},"NetworkSettings":{"Ports":{"53/udp":[{"HostIp":"0.0.0.0","HostPort":"32769"}],"80/tcp":[{"HostIp":"0.0.0.0","HostPort":"32768"},{"HostIp":"::","HostPort":"32768"}]}}}`
+	}, "")
+	mapping, err := client.WaitForPorts(WaitForPortsOptions{ID: "abc", Interval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []APIPort{
+		{PrivatePort: 53, PublicPort: 32769, Type: "udp", IP: "0.0.0.0"},
+		{PrivatePort: 80, PublicPort: 32768, Type: "tcp", IP: "0.0.0.0"},
+		{PrivatePort: 80, PublicPort: 32768, Type: "tcp", IP: "::"},
+	}
+	if !reflect.DeepEqual(mapping, expected) {
+		t.Errorf("WaitForPorts: wrong mapping.\nWant %#v.\nGot  %#v.", expected, mapping)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 3 {
+		t.Errorf("WaitForPorts: wrong number of inspections. Want 3. Got %d.", calls)
+	}
+}
+
+func TestWaitForPortsSelected(t *testing.T) {
+	t.Parallel()
+	client := newReadyTestClient(t, func() string {
+		return `{"Id":"abc","State":{"Running":true},"HostConfig":{"PortBindings":{"80/tcp":[{}],"443/tcp":[{}]}},"NetworkSettings":{"Ports":{"443/tcp":[{"HostIp":"127.0.0.1","HostPort":"8443"}]}}}`
+	}, "")
+	mapping, err := client.WaitForPorts(WaitForPortsOptions{ID: "abc", Ports: []Port{"443"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []APIPort{{PrivatePort: 443, PublicPort: 8443, Type: "tcp", IP: "127.0.0.1"}}
+	if !reflect.DeepEqual(mapping, expected) {
+		t.Errorf("WaitForPorts: wrong mapping.\nWant %#v.\nGot  %#v.", expected, mapping)
+	}
+}
+
+func TestWaitForPortsTimeout(t *testing.T) {
+	t.Parallel()
+	client := newReadyTestClient(t, func() string {
+		return `{"Id":"abc","State":{"Running":true},"HostConfig":{"PortBindings":{"80/tcp":[{}]}},"NetworkSettings":{"Ports":{}}}`
+	}, "")
+	_, err := client.WaitForPorts(WaitForPortsOptions{
+		ID:       "abc",
+		Timeout:  100 * time.Millisecond,
+		Interval: 10 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrContainerNotReady) {
+		t.Errorf("WaitForPorts: wrong error. Want %#v. Got %#v.", ErrContainerNotReady, err)
+	}
+}
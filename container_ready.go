@@ -0,0 +1,349 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultReadyTimeout is how long WaitForContainer waits for a container
+	// to become ready.
+	DefaultReadyTimeout = time.Minute
+
+	// DefaultReadyInterval is the interval between two rounds of readiness
+	// checks in WaitForContainer.
+	DefaultReadyInterval = 200 * time.Millisecond
+)
+
+var (
+	// ErrContainerNotReady is the error returned by WaitForContainer when the
+	// container doesn't become ready within the timeout.
+	ErrContainerNotReady = errors.New("container not ready")
+
+	// ErrContainerExited is the error returned by WaitForContainer when the
+	// container exits before becoming ready.
+	ErrContainerExited = errors.New("container exited before becoming ready")
+
+	// ErrNoHealthcheck is the error returned by WaitForHealthStatus and the
+	// HealthyReady check when the container has no health check.
+	ErrNoHealthcheck = errors.New("container has no health check")
+)
+
+// Health statuses of a container, as reported in Health.Status and in
+// health_status events.
+const (
+	HealthStarting  = "starting"
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+)
+
+// ReadyCheck reports whether a container is ready. It's called repeatedly by
+// WaitForContainer with the latest state of the container, until it returns
+// true. Returning an error aborts the wait.
+type ReadyCheck func(ctx context.Context, c *Client, container *Container) (bool, error)
+
+// WaitForContainerOptions specify parameters to the WaitForContainerWithOptions
+// function.
+type WaitForContainerOptions struct {
+	ID     string
+	Checks []ReadyCheck
+
+	// Timeout defaults to DefaultReadyTimeout and Interval to
+	// DefaultReadyInterval.
+	Timeout  time.Duration
+	Interval time.Duration
+
+	Context context.Context
+}
+
+// WaitForContainer blocks until all the given checks pass for the container,
+// giving up after DefaultReadyTimeout.
+func (c *Client) WaitForContainer(id string, checks ...ReadyCheck) error {
+	return c.WaitForContainerWithOptions(WaitForContainerOptions{ID: id, Checks: checks})
+}
+
+// WaitForContainerWithOptions blocks until all the checks in opts pass for
+// the container. It returns ErrContainerExited if the container stops running
+// and ErrContainerNotReady if the checks don't pass within the timeout.
+func (c *Client) WaitForContainerWithOptions(opts WaitForContainerOptions) error {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultReadyInterval
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	pending := opts.Checks
+	for {
+		container, err := c.InspectContainerWithOptions(InspectContainerOptions{ID: opts.ID, Context: ctx})
+		if err != nil {
+			return readyError(ctx, opts.ID, err)
+		}
+		if !container.State.Running && !container.State.Restarting {
+			return fmt.Errorf("%w: %s (exit code %d)", ErrContainerExited, opts.ID, container.State.ExitCode)
+		}
+		var failing []ReadyCheck
+		for _, check := range pending {
+			ready, err := check(ctx, c, container)
+			if err != nil {
+				return readyError(ctx, opts.ID, err)
+			}
+			if !ready {
+				failing = append(failing, check)
+			}
+		}
+		if pending = failing; len(pending) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return readyError(ctx, opts.ID, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+func readyError(ctx context.Context, id string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s", ErrContainerNotReady, id)
+	}
+	return err
+}
+
+// publishedAddress returns the address in which the given port of the
+// container is published, or an empty string when it isn't published yet,
+// which happens right after the container starts.
+func publishedAddress(container *Container, port Port) (string, error) {
+	if container.NetworkSettings == nil {
+		return "", nil
+	}
+	addr, err := container.NetworkSettings.HostAddress(port)
+	if errors.Is(err, ErrPortNotPublished) {
+		return "", nil
+	}
+	return addr, err
+}
+
+// TCPPortReady returns a check that passes once a TCP connection can be
+// established to the given container port through its published address.
+// It doesn't pass while the port isn't published.
+func TCPPortReady(port Port) ReadyCheck {
+	return func(ctx context.Context, _ *Client, container *Container) (bool, error) {
+		addr, err := publishedAddress(container, port)
+		if addr == "" || err != nil {
+			return false, err
+		}
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return false, nil
+		}
+		conn.Close()
+		return true, nil
+	}
+}
+
+// HTTPReady returns a check that passes once a GET request for path on the
+// given container port, through its published address, returns status 200.
+// It doesn't pass while the port isn't published.
+func HTTPReady(port Port, path string) ReadyCheck {
+	return func(ctx context.Context, _ *Client, container *Container) (bool, error) {
+		addr, err := publishedAddress(container, port)
+		if addr == "" || err != nil {
+			return false, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+path, nil)
+		if err != nil {
+			return false, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false, nil
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK, nil
+	}
+}
+
+// LogMatchReady returns a check that passes once the output of the container,
+// on either stdout or stderr, matches the given regular expression. Every
+// round only fetches the lines written since the previous one, and the
+// expression is matched against them, so it shouldn't span lines written in
+// different rounds.
+func LogMatchReady(re *regexp.Regexp) ReadyCheck {
+	var (
+		mu    sync.Mutex
+		id    string
+		state *drainState
+	)
+	return func(ctx context.Context, c *Client, container *Container) (bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if state == nil || id != container.ID {
+			id, state = container.ID, &drainState{}
+		}
+		var buf bytes.Buffer
+		stdout := &drainWriter{state: state, sink: &buf}
+		stderr := &drainWriter{state: state, sink: &buf}
+		var since int64
+		if last := state.lastTime(); !last.IsZero() {
+			since = last.Unix()
+		}
+		err := c.Logs(LogsOptions{
+			Context:               ctx,
+			Container:             container.ID,
+			OutputStream:          stdout,
+			ErrorStream:           stderr,
+			Stdout:                true,
+			Stderr:                true,
+			Timestamps:            true,
+			Since:                 since,
+			RawTerminal:           container.Config != nil && container.Config.Tty,
+			SkipTerminalDetection: true,
+		})
+		if flushErr := stdout.flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+		if flushErr := stderr.flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+		if err != nil {
+			return false, err
+		}
+		return re.Match(buf.Bytes()), nil
+	}
+}
+
+// HealthyReady returns a check that passes once the health status of the
+// container is "healthy". It fails with ErrNoHealthcheck for containers
+// without a health check.
+func HealthyReady() ReadyCheck {
+	return func(_ context.Context, _ *Client, container *Container) (bool, error) {
+		if container.State.Health.Status == "" {
+			return false, fmt.Errorf("%w: %s", ErrNoHealthcheck, container.ID)
+		}
+		return container.State.Health.Status == HealthHealthy, nil
+	}
+}
+
+// WaitForHealthy blocks until the container with the given ID or name is
+// healthy, see WaitForHealthStatus.
+func (c *Client) WaitForHealthy(ctx context.Context, id string) error {
+	return c.WaitForHealthStatus(ctx, id, HealthHealthy)
+}
+
+// WaitForHealthStatus blocks until the health status of the container with
+// the given ID or name is status, like HealthUnhealthy, or until ctx is
+// done. It returns ErrContainerExited if the container stops first, unless its
+// restart policy brings it back, and ErrNoHealthcheck if it has no health
+// check.
+//
+// The status is followed through health_status events, the container is
+// only inspected again, every DefaultReadyInterval, if the events can't be
+// monitored.
+func (c *Client) WaitForHealthStatus(ctx context.Context, id, status string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// watch before inspecting, so no transition is missed in between
+	events, errs := c.WatchContainerEvents(ctx, map[string][]string{
+		"container": {id},
+		"event":     {"health_status", "die", "destroy"},
+	})
+	container, done, err := c.checkHealthStatus(ctx, id, status)
+	if done {
+		return err
+	}
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if event.ID != container.ID {
+				continue
+			}
+			switch event.Action {
+			case "die":
+				if willRestart(container) {
+					// the daemon marks the container as restarting before
+					// sending the event, so it's enough to inspect it
+					if _, done, err := c.checkHealthStatus(ctx, id, status); done {
+						return err
+					}
+					continue
+				}
+				return fmt.Errorf("%w: %s (exit code %d)", ErrContainerExited, id, event.ExitCode)
+			case "destroy":
+				return fmt.Errorf("%w: %s (exit code %d)", ErrContainerExited, id, event.ExitCode)
+			case "health_status: " + status, "health_status:" + status:
+				return nil
+			}
+		case <-errs:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return c.pollHealthStatus(ctx, id, status)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// willRestart reports whether the restart policy of container may restart it
+// when it exits.
+func willRestart(container *Container) bool {
+	if container.HostConfig == nil {
+		return false
+	}
+	name := container.HostConfig.RestartPolicy.Name
+	return name != "" && name != "no"
+}
+
+// pollHealthStatus inspects the container every DefaultReadyInterval until
+// its health status is status.
+func (c *Client) pollHealthStatus(ctx context.Context, id, status string) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(DefaultReadyInterval):
+		}
+		if _, done, err := c.checkHealthStatus(ctx, id, status); done {
+			return err
+		}
+	}
+}
+
+// checkHealthStatus inspects the container, reporting whether the wait for
+// status is over, either because the container is in that status or because
+// it never will be.
+func (c *Client) checkHealthStatus(ctx context.Context, id, status string) (*Container, bool, error) {
+	container, err := c.InspectContainerWithOptions(InspectContainerOptions{ID: id, Context: ctx})
+	if err != nil {
+		return nil, true, err
+	}
+	switch state := container.State; {
+	case state.Health.Status == "":
+		return container, true, fmt.Errorf("%w: %s", ErrNoHealthcheck, id)
+	case state.Health.Status == status:
+		return container, true, nil
+	case !state.Running && !state.Restarting:
+		return container, true, fmt.Errorf("%w: %s (exit code %d)", ErrContainerExited, id, state.ExitCode)
+	}
+	return container, false, nil
+}
@@ -0,0 +1,264 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newReadyTestClient(t *testing.T, containerJSON func() string, logs string) *Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/abc/json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(containerJSON()))
+	})
+	mux.HandleFunc("/containers/abc/logs", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte{1, 0, 0, 0, 0, 0, 0, byte(len(logs))})
+		w.Write([]byte(logs))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	return client
+}
+
+func runningContainerJSON(hostPort string) func() string {
+	return func() string {
+		return fmt.Sprintf(`{"Id":"abc","State":{"Running":true,"Health":{"Status":"healthy"}},"NetworkSettings":{"Ports":{"80/tcp":[{"HostIp":"127.0.0.1","HostPort":%q}]}}}`, hostPort)
+	}
+}
+
+func TestWaitForContainer(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	client := newReadyTestClient(t, runningContainerJSON(port), "server started\n")
+	err := client.WaitForContainer("abc",
+		TCPPortReady("80/tcp"),
+		HTTPReady("80/tcp", "/health"),
+		LogMatchReady(regexp.MustCompile(`server started`)),
+		HealthyReady(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitForContainerTimeout(t *testing.T) {
+	t.Parallel()
+	client := newReadyTestClient(t, runningContainerJSON("1"), "starting\n")
+	err := client.WaitForContainerWithOptions(WaitForContainerOptions{
+		ID:       "abc",
+		Checks:   []ReadyCheck{LogMatchReady(regexp.MustCompile(`server started`))},
+		Timeout:  100 * time.Millisecond,
+		Interval: 10 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrContainerNotReady) {
+		t.Errorf("WaitForContainer: wrong error. Want %#v. Got %#v.", ErrContainerNotReady, err)
+	}
+}
+
+func TestWaitForContainerExited(t *testing.T) {
+	t.Parallel()
+	client := newReadyTestClient(t, func() string {
+		return `{"Id":"abc","State":{"Running":false,"ExitCode":1}}`
+	}, "")
+	err := client.WaitForContainer("abc", HealthyReady())
+	if !errors.Is(err, ErrContainerExited) {
+		t.Errorf("WaitForContainer: wrong error. Want %#v. Got %#v.", ErrContainerExited, err)
+	}
+}
+
+func TestWaitForContainerPortNotPublished(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	var inspects atomic.Int32
+	client := newReadyTestClient(t, func() string {
+		switch inspects.Add(1) {
+		case 1:
+			// right after the start, the port mapping isn't populated
+			return `{"Id":"abc","State":{"Running":true}}`
+		case 2:
+			return `{"Id":"abc","State":{"Running":true},"NetworkSettings":{"Ports":{}}}`
+		}
+		return runningContainerJSON(port)()
+	}, "")
+	err := client.WaitForContainerWithOptions(WaitForContainerOptions{
+		ID:       "abc",
+		Checks:   []ReadyCheck{TCPPortReady("80/tcp"), HTTPReady("80/tcp", "/")},
+		Interval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := inspects.Load(); n != 3 {
+		t.Errorf("WaitForContainer: wrong number of inspections. Want 3. Got %d.", n)
+	}
+	err = client.WaitForContainerWithOptions(WaitForContainerOptions{
+		ID:       "abc",
+		Checks:   []ReadyCheck{TCPPortReady("8080/tcp")},
+		Timeout:  50 * time.Millisecond,
+		Interval: time.Millisecond,
+	})
+	if !errors.Is(err, ErrContainerNotReady) {
+		t.Errorf("WaitForContainer: wrong error. Want %#v. Got %#v.", ErrContainerNotReady, err)
+	}
+}
+
+func TestWaitForContainerNoHealthcheck(t *testing.T) {
+	t.Parallel()
+	client := newReadyTestClient(t, func() string {
+		return `{"Id":"abc","State":{"Running":true}}`
+	}, "")
+	err := client.WaitForContainer("abc", HealthyReady())
+	if !errors.Is(err, ErrNoHealthcheck) {
+		t.Errorf("WaitForContainer: wrong error. Want %#v. Got %#v.", ErrNoHealthcheck, err)
+	}
+}
+
+func TestLogMatchReadySince(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var since []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/abc/logs", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		since = append(since, r.URL.Query().Get("since"))
+		writeLogFrame(w, 1, "2024-05-01T10:00:00.000000001Z starting\n")
+		if len(since) > 1 {
+			writeLogFrame(w, 2, "2024-05-01T10:00:00.000000002Z server started\n")
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	check := LogMatchReady(regexp.MustCompile(`(?m)^starting$`))
+	container := &Container{ID: "abc"}
+	for i, expected := range []bool{true, false} {
+		ready, err := check(context.Background(), client, container)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ready != expected {
+			t.Errorf("LogMatchReady: round %d: want %v. Got %v.", i, expected, ready)
+		}
+	}
+	expectedSince := []string{"", "1714557600"}
+	if !reflect.DeepEqual(since, expectedSince) {
+		t.Errorf("LogMatchReady: wrong since parameters. Want %q. Got %q.", expectedSince, since)
+	}
+}
+
+func newHealthTestClient(t *testing.T, containerJSON string, events string) *Client {
+	done := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/abc/json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(containerJSON))
+	})
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(events))
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(done) })
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	return client
+}
+
+func TestWaitForHealthy(t *testing.T) {
+	t.Parallel()
+	events := `{"action":"health_status: starting","type":"container","actor":{"id":"abc"},"time":1442421700}
+{"action":"health_status: healthy","type":"container","actor":{"id":"other"},"time":1442421701}
+{"action":"health_status: healthy","type":"container","actor":{"id":"abc"},"time":1442421702}
+`
+	client := newHealthTestClient(t, `{"Id":"abc","State":{"Running":true,"Health":{"Status":"starting"}}}`, events)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.WaitForHealthy(ctx, "abc"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitForHealthStatusDie(t *testing.T) {
+	t.Parallel()
+	events := `{"action":"die","type":"container","actor":{"id":"abc","attributes":{"exitCode":"137"}},"time":1442421700}
+`
+	client := newHealthTestClient(t, `{"Id":"abc","State":{"Running":true,"Health":{"Status":"starting"}}}`, events)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := client.WaitForHealthStatus(ctx, "abc", HealthUnhealthy)
+	if !errors.Is(err, ErrContainerExited) {
+		t.Errorf("WaitForHealthStatus: wrong error. Want %#v. Got %#v.", ErrContainerExited, err)
+	}
+}
+
+func TestWaitForHealthStatusRestarted(t *testing.T) {
+	t.Parallel()
+	events := `{"action":"die","type":"container","actor":{"id":"abc","attributes":{"exitCode":"1"}},"time":1442421700}
+{"action":"health_status: healthy","type":"container","actor":{"id":"abc"},"time":1442421702}
+`
+	containerJSON := `{"Id":"abc","State":{"Running":true,"Health":{"Status":"starting"}},"HostConfig":{"RestartPolicy":{"Name":"always"}}}`
+	client := newHealthTestClient(t, containerJSON, events)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.WaitForHealthy(ctx, "abc"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitForHealthStatusInspect(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		containerJSON string
+		expected      error
+	}{
+		{"already healthy", `{"Id":"abc","State":{"Running":true,"Health":{"Status":"healthy"}}}`, nil},
+		{"no health check", `{"Id":"abc","State":{"Running":true}}`, ErrNoHealthcheck},
+		{"exited", `{"Id":"abc","State":{"Running":false,"ExitCode":1,"Health":{"Status":"unhealthy"}}}`, ErrContainerExited},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := newHealthTestClient(t, tt.containerJSON, "")
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err := client.WaitForHealthy(ctx, "abc")
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("WaitForHealthy: wrong error. Want %#v. Got %#v.", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestWaitForHealthStatusTimeout(t *testing.T) {
+	t.Parallel()
+	client := newHealthTestClient(t, `{"Id":"abc","State":{"Running":true,"Health":{"Status":"starting"}}}`, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := client.WaitForHealthy(ctx, "abc")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForHealthy: wrong error. Want %#v. Got %#v.", context.DeadlineExceeded, err)
+	}
+}
@@ -32,7 +32,7 @@ func (c *Client) RemoveContainer(opts RemoveContainerOptions) error {
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return &NoSuchContainer{ID: opts.ID}
+			return &NoSuchContainer{ID: opts.ID, Op: "remove", Err: err}
 		}
 		return err
 	}
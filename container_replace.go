@@ -0,0 +1,52 @@
+package docker
+
+import "errors"
+
+// createOrReplaceAttempts is the number of times CreateOrReplaceContainer
+// tries to create the container, as a container with the same name may be
+// created again between the removal and the creation.
+const createOrReplaceAttempts = 3
+
+// CreateOrReplaceContainerOptions specify parameters to the
+// CreateOrReplaceContainer function.
+type CreateOrReplaceContainerOptions struct {
+	CreateContainerOptions
+
+	// Replace enables the removal of an existing container with the same
+	// name. When it's false, CreateOrReplaceContainer behaves like
+	// CreateContainer.
+	Replace bool
+
+	// Force removes the existing container even if it's running.
+	// Otherwise, replacing a running container fails.
+	Force bool
+
+	// RemoveVolumes removes the anonymous volumes of the existing
+	// container along with it.
+	RemoveVolumes bool
+}
+
+// CreateOrReplaceContainer creates a container, like CreateContainer. When
+// the name of the container is taken and opts.Replace is set, the existing
+// container is removed and the creation retried. The returned flag reports
+// whether an existing container was replaced.
+func (c *Client) CreateOrReplaceContainer(opts CreateOrReplaceContainerOptions) (*Container, bool, error) {
+	replaced := false
+	for attempt := 1; ; attempt++ {
+		container, err := c.CreateContainer(opts.CreateContainerOptions)
+		if !errors.Is(err, ErrContainerAlreadyExists) || !opts.Replace || opts.Name == "" || attempt == createOrReplaceAttempts {
+			return container, replaced, err
+		}
+		err = c.RemoveContainer(RemoveContainerOptions{
+			ID:            opts.Name,
+			RemoveVolumes: opts.RemoveVolumes,
+			Force:         opts.Force,
+			Context:       opts.Context,
+		})
+		var noSuchContainer *NoSuchContainer
+		if err != nil && !errors.As(err, &noSuchContainer) {
+			return nil, replaced, err
+		}
+		replaced = replaced || err == nil
+	}
+}
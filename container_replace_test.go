@@ -0,0 +1,125 @@
+package docker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newReplaceTestServer(t *testing.T, conflicts int, removeStatus int) (*Client, *[]string) {
+	t.Helper()
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		switch r.Method {
+		case http.MethodPost:
+			if conflicts > 0 {
+				conflicts--
+				http.Error(w, "Conflict. The container name is already in use", http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"Id":"new"}`))
+		case http.MethodDelete:
+			w.WriteHeader(removeStatus)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	return client, &requests
+}
+
+func TestCreateOrReplaceContainer(t *testing.T) {
+	t.Parallel()
+	client, requests := newReplaceTestServer(t, 1, http.StatusNoContent)
+	container, replaced, err := client.CreateOrReplaceContainer(CreateOrReplaceContainerOptions{
+		CreateContainerOptions: CreateContainerOptions{Name: "web", Config: &Config{Image: "nginx"}},
+		Replace:                true,
+		Force:                  true,
+		RemoveVolumes:          true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if container.ID != "new" || container.Name != "web" || !replaced {
+		t.Errorf("CreateOrReplaceContainer: wrong result: %#v, replaced: %v.", container, replaced)
+	}
+	expected := []string{
+		"POST /containers/create?name=web",
+		"DELETE /containers/web?force=1&v=1",
+		"POST /containers/create?name=web",
+	}
+	if len(*requests) != len(expected) {
+		t.Fatalf("CreateOrReplaceContainer: wrong requests. Want %#v. Got %#v.", expected, *requests)
+	}
+	for i := range expected {
+		if (*requests)[i] != expected[i] {
+			t.Errorf("CreateOrReplaceContainer: wrong request %d. Want %q. Got %q.", i, expected[i], (*requests)[i])
+		}
+	}
+}
+
+func TestCreateOrReplaceContainerNoConflict(t *testing.T) {
+	t.Parallel()
+	client, requests := newReplaceTestServer(t, 0, http.StatusNoContent)
+	_, replaced, err := client.CreateOrReplaceContainer(CreateOrReplaceContainerOptions{
+		CreateContainerOptions: CreateContainerOptions{Name: "web"},
+		Replace:                true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced || len(*requests) != 1 {
+		t.Errorf("CreateOrReplaceContainer: unexpected replacement. Requests: %#v.", *requests)
+	}
+}
+
+func TestCreateOrReplaceContainerWithoutReplace(t *testing.T) {
+	t.Parallel()
+	client, requests := newReplaceTestServer(t, 1, http.StatusNoContent)
+	_, replaced, err := client.CreateOrReplaceContainer(CreateOrReplaceContainerOptions{
+		CreateContainerOptions: CreateContainerOptions{Name: "web"},
+	})
+	if !errors.Is(err, ErrContainerAlreadyExists) {
+		t.Errorf("CreateOrReplaceContainer: wrong error. Want %#v. Got %#v.", ErrContainerAlreadyExists, err)
+	}
+	if replaced || len(*requests) != 1 {
+		t.Errorf("CreateOrReplaceContainer: unexpected replacement. Requests: %#v.", *requests)
+	}
+}
+
+func TestCreateOrReplaceContainerRemoveFailure(t *testing.T) {
+	t.Parallel()
+	client, _ := newReplaceTestServer(t, 1, http.StatusConflict)
+	_, replaced, err := client.CreateOrReplaceContainer(CreateOrReplaceContainerOptions{
+		CreateContainerOptions: CreateContainerOptions{Name: "web"},
+		Replace:                true,
+	})
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusConflict {
+		t.Errorf("CreateOrReplaceContainer: wrong error. Want a conflict. Got %#v.", err)
+	}
+	if replaced {
+		t.Error("CreateOrReplaceContainer: unexpected replacement.")
+	}
+}
+
+func TestCreateOrReplaceContainerPersistentConflict(t *testing.T) {
+	t.Parallel()
+	client, requests := newReplaceTestServer(t, createOrReplaceAttempts, http.StatusNoContent)
+	_, replaced, err := client.CreateOrReplaceContainer(CreateOrReplaceContainerOptions{
+		CreateContainerOptions: CreateContainerOptions{Name: "web"},
+		Replace:                true,
+	})
+	if !errors.Is(err, ErrContainerAlreadyExists) {
+		t.Errorf("CreateOrReplaceContainer: wrong error. Want %#v. Got %#v.", ErrContainerAlreadyExists, err)
+	}
+	if !replaced || len(*requests) != 2*createOrReplaceAttempts-1 {
+		t.Errorf("CreateOrReplaceContainer: wrong requests: %#v.", *requests)
+	}
+}
@@ -55,7 +55,7 @@ func (c *Client) RestartContainer(id string, timeout uint) error {
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return &NoSuchContainer{ID: id}
+			return &NoSuchContainer{ID: id, Op: "restart", Err: err}
 		}
 		return err
 	}
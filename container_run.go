@@ -0,0 +1,117 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+)
+
+// RunContainerOptions specify parameters to the RunContainer function.
+type RunContainerOptions struct {
+	// CreateContainerOptions holds the configuration of the container. The
+	// image in Config.Image is pulled when it's not available locally.
+	CreateContainerOptions
+
+	// Auth is used when pulling the image.
+	Auth AuthConfiguration
+
+	// InputStream, when set, is attached to the standard input of the
+	// container, which should be created with OpenStdin and StdinOnce.
+	InputStream io.Reader
+
+	// OutputStream and ErrorStream, when set, receive the output of the
+	// container as it runs, in addition to it being collected in the
+	// result.
+	OutputStream io.Writer
+	ErrorStream  io.Writer
+
+	// Remove makes the container be removed once it exits.
+	Remove bool
+}
+
+// RunContainerResult is the result of a RunContainer call.
+type RunContainerResult struct {
+	ContainerID string
+	ExitCode    int
+
+	// Stdout and Stderr hold the output of the container. When the
+	// container has a TTY, both streams are combined in Stdout.
+	Stdout []byte
+	Stderr []byte
+}
+
+// RunContainer runs a container to completion, like "docker run" does: it
+// pulls the image when it's missing, creates and starts the container,
+// collects its output and waits for it to exit.
+//
+// A non-zero exit code isn't considered an error: callers should check
+// ExitCode in the returned result.
+func (c *Client) RunContainer(opts RunContainerOptions) (result *RunContainerResult, err error) {
+	if opts.Config == nil || opts.Config.Image == "" {
+		return nil, ErrNoSuchImage
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, err := c.EnsureImage(opts.Config.Image, opts.Auth, EnsureImageOptions{Context: ctx}); err != nil {
+		return nil, err
+	}
+	container, err := c.CreateContainer(opts.CreateContainerOptions)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Remove {
+		defer func() {
+			removeErr := c.RemoveContainer(RemoveContainerOptions{ID: container.ID, Force: true, Context: ctx})
+			if err == nil {
+				err = removeErr
+			}
+		}()
+	}
+	var stdout, stderr bytes.Buffer
+	success := make(chan struct{})
+	cw, err := c.AttachToContainerNonBlocking(AttachToContainerOptions{
+		Container:             container.ID,
+		InputStream:           opts.InputStream,
+		OutputStream:          teeWriter(&stdout, opts.OutputStream),
+		ErrorStream:           teeWriter(&stderr, opts.ErrorStream),
+		Success:               success,
+		RawTerminal:           opts.Config.Tty,
+		SkipTerminalDetection: true,
+		Stream:                true,
+		Stdin:                 opts.InputStream != nil,
+		Stdout:                true,
+		Stderr:                true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cw.Close()
+	<-success
+	success <- struct{}{}
+	if err := c.StartContainerWithContext(container.ID, nil, ctx); err != nil {
+		return nil, err
+	}
+	exitCode, err := c.WaitContainerWithContext(container.ID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := cw.Wait(); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return &RunContainerResult{
+		ContainerID: container.ID,
+		ExitCode:    exitCode,
+		Stdout:      stdout.Bytes(),
+		Stderr:      stderr.Bytes(),
+	}, nil
+}
+
+func teeWriter(buf *bytes.Buffer, w io.Writer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(buf, w)
+}
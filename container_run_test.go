@@ -0,0 +1,98 @@
+package docker
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRunContainer(t *testing.T) {
+	t.Parallel()
+	var pulled, removed bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/images/busybox/json", func(w http.ResponseWriter, _ *http.Request) {
+		if !pulled {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"Id":"sha256:abc"}`))
+	})
+	mux.HandleFunc("/images/create", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("tag"); got != "latest" {
+			t.Errorf("RunContainer: wrong tag pulled. Want %q. Got %q.", "latest", got)
+		}
+		pulled = true
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/containers/create", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id":"abc"}`))
+	})
+	mux.HandleFunc("/containers/abc/attach", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("cannot hijack server connection")
+		}
+		conn, _, err := hj.Hijack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Write([]byte{1, 0, 0, 0, 0, 0, 0, 5})
+		conn.Write([]byte("hello"))
+		conn.Write([]byte{2, 0, 0, 0, 0, 0, 0, 4})
+		conn.Write([]byte("oops"))
+		conn.Close()
+	})
+	mux.HandleFunc("/containers/abc/start", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/containers/abc/wait", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"StatusCode":3}`))
+	})
+	mux.HandleFunc("/containers/abc", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			removed = true
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	var stdout bytes.Buffer
+	result, err := client.RunContainer(RunContainerOptions{
+		CreateContainerOptions: CreateContainerOptions{Config: &Config{Image: "busybox"}},
+		OutputStream:           &stdout,
+		Remove:                 true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pulled {
+		t.Error("RunContainer: should pull the missing image")
+	}
+	if !removed {
+		t.Error("RunContainer: should remove the container")
+	}
+	if result.ContainerID != "abc" || result.ExitCode != 3 {
+		t.Errorf("RunContainer: wrong result. Got %#v.", result)
+	}
+	if string(result.Stdout) != "hello" || string(result.Stderr) != "oops" {
+		t.Errorf("RunContainer: wrong output. Got stdout %q and stderr %q.", result.Stdout, result.Stderr)
+	}
+	if stdout.String() != "hello" {
+		t.Errorf("RunContainer: wrong output stream content. Want %q. Got %q.", "hello", stdout.String())
+	}
+}
+
+func TestRunContainerWithoutImage(t *testing.T) {
+	t.Parallel()
+	var client Client
+	if _, err := client.RunContainer(RunContainerOptions{}); err != ErrNoSuchImage {
+		t.Errorf("RunContainer: wrong error. Want %#v. Got %#v.", ErrNoSuchImage, err)
+	}
+}
@@ -34,10 +34,7 @@ func (c *Client) StartContainerWithContext(id string, hostConfig *HostConfig, ct
 
 func (c *Client) startContainer(id string, hostConfig *HostConfig, opts doOptions) error {
 	path := "/containers/" + id + "/start"
-	if c.serverAPIVersion == nil {
-		c.checkAPIVersion()
-	}
-	if c.serverAPIVersion != nil && c.serverAPIVersion.LessThan(apiVersion124) {
+	if serverAPIVersion := c.serverVersion(); serverAPIVersion != nil && serverAPIVersion.LessThan(apiVersion124) {
 		opts.data = hostConfig
 		opts.forceJSON = true
 	}
@@ -45,7 +42,7 @@ func (c *Client) startContainer(id string, hostConfig *HostConfig, opts doOption
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return &NoSuchContainer{ID: id, Err: err}
+			return &NoSuchContainer{ID: id, Op: "start", Err: err}
 		}
 		return err
 	}
@@ -159,7 +159,15 @@ type StatsOptions struct {
 	// Timeout with no data is received, it's reset every time new data
 	// arrives
 	InactivityTimeout time.Duration `qs:"-"`
-	Context           context.Context
+	// LatestOnly makes Stats keep only the latest sample while the
+	// receiver of the Stats channel is busy, dropping the intermediate
+	// ones, instead of blocking the stream until the sample is received.
+	LatestOnly bool `qs:"-"`
+	// SampleInterval is the minimum interval between two samples sent to
+	// the Stats channel, by their read time. The samples received in
+	// between are skipped.
+	SampleInterval time.Duration `qs:"-"`
+	Context        context.Context
 }
 
 // Stats sends container statistics for the given container to the given channel.
@@ -203,7 +211,7 @@ func (c *Client) Stats(opts StatsOptions) (retErr error) {
 			var dockerError *Error
 			if errors.As(err, &dockerError) {
 				if dockerError.Status == http.StatusNotFound {
-					err = &NoSuchContainer{ID: opts.ID}
+					err = &NoSuchContainer{ID: opts.ID, Op: "stats", Err: err}
 				}
 			}
 		}
@@ -214,25 +222,76 @@ func (c *Client) Stats(opts StatsOptions) (retErr error) {
 	}()
 
 	quit := make(chan struct{})
+	stopped := make(chan struct{})
 	defer close(quit)
 	go func() {
 		// block here waiting for the signal to stop function
 		select {
 		case <-opts.Done:
+			close(stopped)
 			readCloser.Close()
 		case <-quit:
 			return
 		}
 	}()
 
+	send := func(stats *Stats) { opts.Stats <- stats }
+	if opts.LatestOnly {
+		var ctxDone <-chan struct{}
+		if opts.Context != nil {
+			ctxDone = opts.Context.Done()
+		}
+		latest := make(chan *Stats, 1)
+		delivered := make(chan struct{})
+		go func() {
+			defer close(delivered)
+			for stats := range latest {
+				// the receiver may be gone once the function is
+				// stopped, so the pending sample is dropped
+				select {
+				case opts.Stats <- stats:
+				case <-stopped:
+					return
+				case <-ctxDone:
+					return
+				}
+			}
+		}()
+		// this goroutine is the only sender, so after dropping the pending
+		// sample the buffer is empty and the send doesn't block
+		send = func(stats *Stats) {
+			select {
+			case <-latest:
+			default:
+			}
+			latest <- stats
+		}
+		defer func() {
+			close(latest)
+			<-delivered
+		}()
+	}
+
 	decoder := json.NewDecoder(readCloser)
 	stats := new(Stats)
+	var lastRead time.Time
 	<-reqSent
 	for err := decoder.Decode(stats); !errors.Is(err, io.EOF); err = decoder.Decode(stats) {
 		if err != nil {
 			return err
 		}
-		opts.Stats <- stats
+		if opts.SampleInterval > 0 {
+			read := stats.Read
+			if read.IsZero() {
+				read = time.Now()
+			}
+			if !lastRead.IsZero() && read.Sub(lastRead) < opts.SampleInterval {
+				stats = new(Stats)
+				continue
+			}
+			lastRead = read
+		}
+		send(stats)
 		stats = new(Stats)
 	}
 	return nil
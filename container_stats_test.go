@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 )
 
 // The test for Docker Stat API of the host uses cgroup
@@ -615,3 +616,118 @@ func TestStatsContainerNotFound(t *testing.T) {
 	err := client.Stats(StatsOptions{ID: "abef348", Stats: statsC, Stream: true, Done: done})
 	expectNoSuchContainer(t, "abef348", err)
 }
+
+func statsStreamServer(t *testing.T, reads []time.Time, written chan<- struct{}) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		for _, read := range reads {
+			encoder.Encode(Stats{Read: read})
+		}
+		w.(http.Flusher).Flush()
+		if written != nil {
+			close(written)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	return client
+}
+
+func TestStatsLatestOnlyDone(t *testing.T) {
+	t.Parallel()
+	written := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		for i := 0; ; i++ {
+			encoder.Encode(Stats{Read: time.Now()})
+			w.(http.Flusher).Flush()
+			if i == 1 {
+				close(written)
+			}
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	done := make(chan bool)
+	errC := make(chan error, 1)
+	go func() {
+		// nobody receives from the stats channel
+		errC <- client.Stats(StatsOptions{ID: "4fa6e0f0", Stats: make(chan *Stats), Stream: true, LatestOnly: true, Done: done})
+	}()
+	<-written
+	time.Sleep(50 * time.Millisecond)
+	done <- true
+	select {
+	case <-errC:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stats: timed out waiting for the function to stop")
+	}
+}
+
+func TestStatsSampleInterval(t *testing.T) {
+	t.Parallel()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var reads []time.Time
+	for i := 0; i < 5; i++ {
+		reads = append(reads, base.Add(time.Duration(i)*500*time.Millisecond))
+	}
+	client := statsStreamServer(t, reads, nil)
+	statsC := make(chan *Stats)
+	errC := make(chan error, 1)
+	go func() {
+		errC <- client.Stats(StatsOptions{ID: "4fa6e0f0", Stats: statsC, Stream: true, SampleInterval: time.Second})
+	}()
+	var got []time.Time
+	for stats := range statsC {
+		got = append(got, stats.Read)
+	}
+	if err := <-errC; err != nil {
+		t.Fatal(err)
+	}
+	expected := []time.Time{reads[0], reads[2], reads[4]}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Stats: wrong samples.\nWant %v\nGot  %v", expected, got)
+	}
+}
+
+func TestStatsLatestOnly(t *testing.T) {
+	t.Parallel()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var reads []time.Time
+	for i := 0; i < 10; i++ {
+		reads = append(reads, base.Add(time.Duration(i)*time.Second))
+	}
+	written := make(chan struct{})
+	client := statsStreamServer(t, reads, written)
+	statsC := make(chan *Stats)
+	errC := make(chan error, 1)
+	go func() {
+		errC <- client.Stats(StatsOptions{ID: "4fa6e0f0", Stats: statsC, Stream: true, LatestOnly: true})
+	}()
+	// a slow consumer: the stream is over before the first receive
+	<-written
+	time.Sleep(100 * time.Millisecond)
+	var got []time.Time
+	for stats := range statsC {
+		got = append(got, stats.Read)
+	}
+	if err := <-errC; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 || len(got) > 2 {
+		t.Fatalf("Stats: expected the intermediate samples to be dropped, got %v", got)
+	}
+	if last := got[len(got)-1]; !last.Equal(reads[len(reads)-1]) {
+		t.Errorf("Stats: wrong latest sample. Want %v. Got %v", reads[len(reads)-1], last)
+	}
+}
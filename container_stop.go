@@ -10,6 +10,11 @@ import (
 // StopContainer stops a container, killing it after the given timeout (in
 // seconds).
 //
+// When the container isn't running, the returned error is a
+// ContainerNotRunning, matching ErrContainerNotRunning. If the daemon reports
+// that the container was already stopped, the error also matches
+// ErrContainerAlreadyStopped.
+//
 // See https://goo.gl/R9dZcV for more details.
 func (c *Client) StopContainer(id string, timeout uint) error {
 	return c.stopContainer(id, timeout, doOptions{})
@@ -24,19 +29,60 @@ func (c *Client) StopContainerWithContext(id string, timeout uint, ctx context.C
 	return c.stopContainer(id, timeout, doOptions{context: ctx})
 }
 
+// StopContainerOptions specify parameters to the StopContainerWithOptions
+// function.
+//
+// See https://goo.gl/R9dZcV for more details.
+type StopContainerOptions struct {
+	ID string `qs:"-"`
+
+	// Timeout is the number of seconds to wait before killing the
+	// container. When nil, the StopTimeout of the container (set in its
+	// Config) is used, falling back to the default of the daemon.
+	Timeout *int `qs:"t"`
+
+	// Signal overrides the StopSignal of the container.
+	Signal string `ver:"1.42"`
+
+	Context context.Context `qs:"-"`
+}
+
+// StopContainerWithOptions stops a container, killing it after the timeout in
+// opts, or after the stop timeout of the container if opts doesn't specify
+// one. It returns the same errors as StopContainer.
+//
+// See https://goo.gl/R9dZcV for more details.
+func (c *Client) StopContainerWithOptions(opts StopContainerOptions) error {
+	qs, requiredAPIVersion := queryStringVersion(opts)
+	if err := c.checkRequiredAPIVersion("/containers/stop", requiredAPIVersion); err != nil {
+		return err
+	}
+	return c.stopContainerPath(opts.ID, "/containers/"+opts.ID+"/stop?"+qs, doOptions{context: opts.Context})
+}
+
 func (c *Client) stopContainer(id string, timeout uint, opts doOptions) error {
-	path := fmt.Sprintf("/containers/%s/stop?t=%d", id, timeout)
+	return c.stopContainerPath(id, fmt.Sprintf("/containers/%s/stop?t=%d", id, timeout), opts)
+}
+
+func (c *Client) stopContainerPath(id, path string, opts doOptions) error {
 	resp, err := c.do(http.MethodPost, path, opts)
 	if err != nil {
 		var e *Error
-		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return &NoSuchContainer{ID: id}
+		if !errors.As(err, &e) {
+			return err
+		}
+		switch e.Status {
+		case http.StatusNotFound:
+			return &NoSuchContainer{ID: id, Op: "stop", Err: err}
+		case http.StatusConflict:
+			return &ContainerNotRunning{ID: id}
+		default:
+			return err
 		}
-		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusNotModified {
-		return &ContainerNotRunning{ID: id}
+		return &ContainerNotRunning{ID: id, AlreadyStopped: true}
 	}
 	return nil
 }
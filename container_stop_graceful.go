@@ -0,0 +1,144 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// StopStage identifies a step of StopGracefully, reported to the Progress
+// callback in StopGracefullyOptions.
+type StopStage int
+
+const (
+	// StopStageSignaled is reported after the stop signal is sent.
+	StopStageSignaled StopStage = iota
+
+	// StopStageKilled is reported after the container is sent SIGKILL
+	// because it didn't exit within the timeout.
+	StopStageKilled
+
+	// StopStageExited is reported once the container has exited.
+	StopStageExited
+
+	// StopStageRemoved is reported after the container is removed.
+	StopStageRemoved
+)
+
+func (s StopStage) String() string {
+	switch s {
+	case StopStageSignaled:
+		return "signaled"
+	case StopStageKilled:
+		return "killed"
+	case StopStageExited:
+		return "exited"
+	case StopStageRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultStopTimeout is the time StopGracefully waits for a container to exit
+// before killing it, when no timeout is given.
+const DefaultStopTimeout = 10 * time.Second
+
+// StopGracefullyOptions specify parameters to the StopGracefully function.
+type StopGracefullyOptions struct {
+	// Signal is the signal sent to stop the container, either numeric or
+	// symbolic. When omitted, the StopSignal of the container is used,
+	// falling back to SIGTERM.
+	Signal string
+
+	// Timeout is how long to wait for the container to exit before
+	// sending SIGKILL. When omitted, the StopTimeout of the container is
+	// used, falling back to DefaultStopTimeout.
+	Timeout time.Duration
+
+	// Remove makes the container be removed after it exits, along with its
+	// anonymous volumes when RemoveVolumes is set.
+	Remove        bool
+	RemoveVolumes bool
+
+	// Progress, when set, is called as the shutdown goes through each
+	// stage.
+	Progress func(stage StopStage)
+
+	Context context.Context
+}
+
+// StopGracefully stops a container the way "docker stop" does: it sends the
+// stop signal, waits up to the timeout for the container to exit and then
+// escalates to SIGKILL. It returns the exit code of the container, and
+// succeeds when the container is already stopped.
+func (c *Client) StopGracefully(id string, opts StopGracefullyOptions) (int, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(StopStage) {}
+	}
+	signal, timeout := opts.Signal, opts.Timeout
+	if signal == "" || timeout <= 0 {
+		container, err := c.InspectContainerWithOptions(InspectContainerOptions{ID: id, Context: ctx})
+		if err != nil {
+			return 0, err
+		}
+		if signal == "" {
+			signal = "SIGTERM"
+			if container.Config != nil && container.Config.StopSignal != "" {
+				signal = container.Config.StopSignal
+			}
+		}
+		if timeout <= 0 {
+			timeout = DefaultStopTimeout
+			if container.Config != nil && container.Config.StopTimeout > 0 {
+				timeout = time.Duration(container.Config.StopTimeout) * time.Second
+			}
+		}
+	}
+	sig, err := ParseSignal(signal)
+	if err != nil {
+		return 0, err
+	}
+	var exitCode int
+	err = c.KillContainer(KillContainerOptions{ID: id, Signal: sig, Context: ctx})
+	switch {
+	case errors.Is(err, ErrContainerNotRunning):
+		if exitCode, err = c.WaitContainerWithContext(id, ctx); err != nil {
+			return 0, err
+		}
+	case err != nil:
+		return 0, err
+	default:
+		progress(StopStageSignaled)
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		exitCode, err = c.WaitContainerWithContext(id, waitCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil || !errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+				return 0, err
+			}
+			err = c.KillContainer(KillContainerOptions{ID: id, Signal: SIGKILL, Context: ctx})
+			if err != nil && !errors.Is(err, ErrContainerNotRunning) {
+				return 0, err
+			}
+			progress(StopStageKilled)
+			if exitCode, err = c.WaitContainerWithContext(id, ctx); err != nil {
+				return 0, err
+			}
+		}
+	}
+	progress(StopStageExited)
+	if opts.Remove {
+		err = c.RemoveContainer(RemoveContainerOptions{ID: id, RemoveVolumes: opts.RemoveVolumes, Context: ctx})
+		if err != nil {
+			return exitCode, err
+		}
+		progress(StopStageRemoved)
+	}
+	return exitCode, nil
+}
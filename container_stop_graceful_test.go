@@ -0,0 +1,142 @@
+package docker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStoppableContainer serves the endpoints used by StopGracefully for a
+// container that exits when it receives one of exitSignals.
+func fakeStoppableContainer(t *testing.T, running bool, exitSignals ...string) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var calls []string
+	exited := make(chan struct{})
+	if !running {
+		close(exited)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/abc/json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"Id":"abc","Config":{"StopSignal":"SIGINT"}}`))
+	})
+	mux.HandleFunc("/containers/abc/kill", func(w http.ResponseWriter, r *http.Request) {
+		signal := r.URL.Query().Get("signal")
+		mu.Lock()
+		calls = append(calls, "kill "+signal)
+		mu.Unlock()
+		select {
+		case <-exited:
+			w.WriteHeader(http.StatusConflict)
+			return
+		default:
+		}
+		for _, s := range exitSignals {
+			if s == signal {
+				close(exited)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/containers/abc/wait", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-exited:
+			w.Write([]byte(`{"StatusCode":137}`))
+		case <-r.Context().Done():
+		}
+	})
+	mux.HandleFunc("/containers/abc", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, "remove v="+r.URL.Query().Get("v"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestStopGracefully(t *testing.T) {
+	t.Parallel()
+	server, calls := fakeStoppableContainer(t, true, "2")
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	var stages []StopStage
+	exitCode, err := client.StopGracefully("abc", StopGracefullyOptions{
+		Remove:        true,
+		RemoveVolumes: true,
+		Progress:      func(stage StopStage) { stages = append(stages, stage) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 137 {
+		t.Errorf("StopGracefully: wrong exit code. Want 137. Got %d.", exitCode)
+	}
+	expectedCalls := []string{"kill 2", "remove v=1"}
+	if !reflect.DeepEqual(*calls, expectedCalls) {
+		t.Errorf("StopGracefully: wrong calls. Want %#v. Got %#v.", expectedCalls, *calls)
+	}
+	expectedStages := []StopStage{StopStageSignaled, StopStageExited, StopStageRemoved}
+	if !reflect.DeepEqual(stages, expectedStages) {
+		t.Errorf("StopGracefully: wrong stages. Want %v. Got %v.", expectedStages, stages)
+	}
+}
+
+func TestStopGracefullyEscalates(t *testing.T) {
+	t.Parallel()
+	server, calls := fakeStoppableContainer(t, true, "9")
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	var stages []StopStage
+	_, err := client.StopGracefully("abc", StopGracefullyOptions{
+		Signal:   "SIGTERM",
+		Timeout:  50 * time.Millisecond,
+		Progress: func(stage StopStage) { stages = append(stages, stage) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedCalls := []string{"kill 15", "kill 9"}
+	if !reflect.DeepEqual(*calls, expectedCalls) {
+		t.Errorf("StopGracefully: wrong calls. Want %#v. Got %#v.", expectedCalls, *calls)
+	}
+	expectedStages := []StopStage{StopStageSignaled, StopStageKilled, StopStageExited}
+	if !reflect.DeepEqual(stages, expectedStages) {
+		t.Errorf("StopGracefully: wrong stages. Want %v. Got %v.", expectedStages, stages)
+	}
+}
+
+func TestStopGracefullyNotRunning(t *testing.T) {
+	t.Parallel()
+	server, calls := fakeStoppableContainer(t, false)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	var stages []StopStage
+	_, err := client.StopGracefully("abc", StopGracefullyOptions{
+		Signal:   "TERM",
+		Progress: func(stage StopStage) { stages = append(stages, stage) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedCalls := []string{"kill 15"}
+	if !reflect.DeepEqual(*calls, expectedCalls) {
+		t.Errorf("StopGracefully: wrong calls. Want %#v. Got %#v.", expectedCalls, *calls)
+	}
+	expectedStages := []StopStage{StopStageExited}
+	if !reflect.DeepEqual(stages, expectedStages) {
+		t.Errorf("StopGracefully: wrong stages. Want %v. Got %v.", expectedStages, stages)
+	}
+}
+
+func TestStopGracefullyInvalidSignal(t *testing.T) {
+	t.Parallel()
+	var client Client
+	if _, err := client.StopGracefully("abc", StopGracefullyOptions{Signal: "SIGNOPE", Timeout: time.Second}); err == nil {
+		t.Error("StopGracefully: expected error for an invalid signal")
+	}
+}
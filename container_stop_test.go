@@ -72,10 +72,32 @@ func TestStopContainerNotRunning(t *testing.T) {
 	t.Parallel()
 	client := newTestClient(&FakeRoundTripper{message: "container not running", status: http.StatusNotModified})
 	err := client.StopContainer("a2334", 10)
+	expected := &ContainerNotRunning{ID: "a2334", AlreadyStopped: true}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("StopContainer: Wrong error returned. Want %#v. Got %#v.", expected, err)
+	}
+	if !errors.Is(err, ErrContainerAlreadyStopped) {
+		t.Errorf("StopContainer: expected error to match ErrContainerAlreadyStopped. Got %#v.", err)
+	}
+	if !errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("StopContainer: expected error to match ErrContainerNotRunning. Got %#v.", err)
+	}
+}
+
+func TestStopContainerConflict(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "container is not running", status: http.StatusConflict})
+	err := client.StopContainer("a2334", 10)
 	expected := &ContainerNotRunning{ID: "a2334"}
 	if !reflect.DeepEqual(err, expected) {
 		t.Errorf("StopContainer: Wrong error returned. Want %#v. Got %#v.", expected, err)
 	}
+	if !errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("StopContainer: expected error to match ErrContainerNotRunning. Got %#v.", err)
+	}
+	if errors.Is(err, ErrContainerAlreadyStopped) {
+		t.Errorf("StopContainer: expected error not to match ErrContainerAlreadyStopped. Got %#v.", err)
+	}
 }
 
 func TestStopContainerWhenContextTimesOut(t *testing.T) {
@@ -92,3 +114,41 @@ func TestStopContainerWhenContextTimesOut(t *testing.T) {
 		t.Errorf("Expected 'DeadlineExceededError', got: %v", err)
 	}
 }
+
+func TestStopContainerWithOptions(t *testing.T) {
+	t.Parallel()
+	timeout := 0
+	tests := []struct {
+		opts     StopContainerOptions
+		expected url.Values
+	}{
+		{StopContainerOptions{ID: "abc"}, url.Values{}},
+		{StopContainerOptions{ID: "abc", Timeout: &timeout}, url.Values{"t": {"0"}}},
+		{StopContainerOptions{ID: "abc", Signal: "SIGINT"}, url.Values{"signal": {"SIGINT"}}},
+	}
+	for _, test := range tests {
+		fakeRT := &FakeRoundTripper{message: "", status: http.StatusNoContent}
+		client := newTestClient(fakeRT)
+		if err := client.StopContainerWithOptions(test.opts); err != nil {
+			t.Fatal(err)
+		}
+		req := fakeRT.requests[0]
+		if req.URL.Path != "/containers/abc/stop" {
+			t.Errorf("StopContainerWithOptions: wrong path %q.", req.URL.Path)
+		}
+		if got := req.URL.Query(); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("StopContainerWithOptions: wrong query string. Want %#v. Got %#v.", test.expected, got)
+		}
+	}
+}
+
+func TestStopContainerWithOptionsSignalRequiresAPIVersion(t *testing.T) {
+	t.Parallel()
+	client, err := NewVersionedClient("http://localhost:4243", "1.41")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.StopContainerWithOptions(StopContainerOptions{ID: "abc", Signal: "SIGINT"}); err == nil {
+		t.Error("StopContainerWithOptions: expected error when the requested API version doesn't support signal")
+	}
+}
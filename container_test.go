@@ -7,6 +7,7 @@ package docker
 import (
 	"errors"
 	"net/http"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -64,6 +65,34 @@ func TestStateStateString(t *testing.T) {
 	}
 }
 
+func TestStatePredicates(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		state   string
+		running bool
+		exited  bool
+	}{
+		{StateCreated, false, false},
+		{StateRunning, true, false},
+		{StatePaused, false, false},
+		{StateRestarting, false, false},
+		{StateRemoving, false, false},
+		{StateExited, false, true},
+		{StateDead, false, true},
+	}
+	for _, tt := range tests {
+		if got := IsRunning(tt.state); got != tt.running {
+			t.Errorf("IsRunning(%q): wrong result. Want %v. Got %v.", tt.state, tt.running, got)
+		}
+		if got := IsExited(tt.state); got != tt.exited {
+			t.Errorf("IsExited(%q): wrong result. Want %v. Got %v.", tt.state, tt.exited, got)
+		}
+	}
+	if !IsHealthy(HealthHealthy) || IsHealthy(HealthStarting) || IsHealthy(HealthUnhealthy) {
+		t.Error("IsHealthy: wrong result")
+	}
+}
+
 // sleepyRoundTripper implements the http.RoundTripper interface. It sleeps
 // for the 'sleep' duration and then returns an error for RoundTrip method.
 type sleepyRoudTripper struct {
@@ -92,3 +121,123 @@ func TestNoSuchContainerErrorMessage(t *testing.T) {
 		t.Errorf("NoSuchContainer: wrong message. Want %q. Got %q.", expected, got)
 	}
 }
+
+func TestNoSuchContainerErrorResolved(t *testing.T) {
+	t.Parallel()
+	err := &NoSuchContainer{ID: "web", ResolvedID: "i345", Op: "stats"}
+	expected := "stats container: No such container: web (i345)"
+	if got := err.Error(); got != expected {
+		t.Errorf("NoSuchContainer: wrong message. Want %q. Got %q.", expected, got)
+	}
+}
+
+func TestNoSuchContainerErrorResolvedWrapped(t *testing.T) {
+	t.Parallel()
+	err := &NoSuchContainer{
+		ID:         "web",
+		ResolvedID: "i345",
+		Op:         "stats",
+		Err:        &Error{Status: http.StatusNotFound, Message: "No such container: web"},
+	}
+	expected := "stats container: API error (404): No such container: web (i345)"
+	if got := err.Error(); got != expected {
+		t.Errorf("NoSuchContainer: wrong message. Want %q. Got %q.", expected, got)
+	}
+}
+
+func TestNoSuchContainerErrorUnwrap(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "No such container: web", status: http.StatusNotFound})
+	err := client.RemoveContainer(RemoveContainerOptions{ID: "web"})
+	if !errors.Is(err, ErrNoSuchContainer) {
+		t.Errorf("RemoveContainer: wrong error. Want %#v. Got %#v.", ErrNoSuchContainer, err)
+	}
+	var containerErr *NoSuchContainer
+	if !errors.As(err, &containerErr) || containerErr.Op != "remove" || containerErr.ID != "web" {
+		t.Fatalf("RemoveContainer: wrong error: %#v.", err)
+	}
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+		t.Errorf("RemoveContainer: the API error should be wrapped. Got %#v.", containerErr.Err)
+	}
+	expected := "remove container: API error (404): No such container: web"
+	if got := err.Error(); got != expected {
+		t.Errorf("NoSuchContainer: wrong message. Want %q. Got %q.", expected, got)
+	}
+}
+
+func TestNetworkSettingsPortBindingFor(t *testing.T) {
+	t.Parallel()
+	settings := NetworkSettings{
+		Ports: map[Port][]PortBinding{
+			"8080/tcp": {{HostIP: "0.0.0.0", HostPort: "32768"}},
+			"53/udp":   {{HostIP: "0.0.0.0", HostPort: "32769"}},
+		},
+	}
+	expected := []PortBinding{{HostIP: "0.0.0.0", HostPort: "32768"}}
+	if got := settings.PortBindingFor("8080"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("PortBindingFor(8080): wrong bindings. Want %#v. Got %#v.", expected, got)
+	}
+	if got := settings.PortBindingFor("53"); got != nil {
+		t.Errorf("PortBindingFor(53): wrong bindings. Want <nil>. Got %#v.", got)
+	}
+}
+
+func TestNetworkSettingsPublishedTCPPorts(t *testing.T) {
+	t.Parallel()
+	settings := NetworkSettings{
+		Ports: map[Port][]PortBinding{
+			"8080/tcp": {{HostIP: "0.0.0.0", HostPort: "32768"}},
+			"443/tcp":  {{HostIP: "0.0.0.0", HostPort: "32770"}},
+			"9000/tcp": nil,
+			"53/udp":   {{HostIP: "0.0.0.0", HostPort: "32769"}},
+		},
+	}
+	expected := []Port{"443/tcp", "8080/tcp"}
+	if got := settings.PublishedTCPPorts(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("PublishedTCPPorts(): wrong ports. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestNetworkSettingsHostAddress(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		bindings []PortBinding
+		expected string
+	}{
+		{"all IPv4 interfaces", []PortBinding{{HostIP: "0.0.0.0", HostPort: "32768"}}, "127.0.0.1:32768"},
+		{"empty host IP", []PortBinding{{HostPort: "32768"}}, "127.0.0.1:32768"},
+		{"specific IPv4 address", []PortBinding{{HostIP: "192.168.50.4", HostPort: "32768"}}, "192.168.50.4:32768"},
+		{"all IPv6 interfaces", []PortBinding{{HostIP: "::", HostPort: "32768"}}, "[::1]:32768"},
+		{"specific IPv6 address", []PortBinding{{HostIP: "fd00::4", HostPort: "32768"}}, "[fd00::4]:32768"},
+		{
+			"dual stack",
+			[]PortBinding{{HostIP: "::", HostPort: "32769"}, {HostIP: "0.0.0.0", HostPort: "32768"}},
+			"127.0.0.1:32768",
+		},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			settings := NetworkSettings{Ports: map[Port][]PortBinding{"8080/tcp": test.bindings}}
+			got, err := settings.HostAddress("8080/tcp")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.expected {
+				t.Errorf("HostAddress: wrong address. Want %q. Got %q.", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestNetworkSettingsHostAddressNotPublished(t *testing.T) {
+	t.Parallel()
+	settings := NetworkSettings{Ports: map[Port][]PortBinding{"8080/tcp": nil}}
+	_, err := settings.HostAddress("8080/tcp")
+	if !errors.Is(err, ErrPortNotPublished) {
+		t.Errorf("HostAddress: wrong error. Want %#v. Got %#v.", ErrPortNotPublished, err)
+	}
+}
@@ -30,7 +30,7 @@ func (c *Client) TopContainer(id string, psArgs string) (TopResult, error) {
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return result, &NoSuchContainer{ID: id}
+			return result, &NoSuchContainer{ID: id, Op: "top", Err: err}
 		}
 		return result, err
 	}
@@ -0,0 +1,34 @@
+package docker
+
+import (
+	"context"
+	"time"
+)
+
+// terminalDetectionTimeout is the maximum duration of the inspection of a
+// container made to detect whether it has a TTY.
+const terminalDetectionTimeout = 5 * time.Second
+
+// rawTerminal returns whether the output of the container must be copied
+// as is, instead of being demultiplexed into stdout and stderr. It's true
+// when requested by the caller, or when the container has a TTY, in which
+// case the daemon doesn't multiplex the output. The container is only
+// inspected when rawTerminal and skipDetection are both false, for at most
+// terminalDetectionTimeout. The detection is best effort: the output is
+// demultiplexed when the inspection fails, leaving the errors to the request
+// that follows.
+func (c *Client) rawTerminal(ctx context.Context, id string, rawTerminal, skipDetection bool) bool {
+	if rawTerminal || skipDetection {
+		return rawTerminal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, terminalDetectionTimeout)
+	defer cancel()
+	container, err := c.InspectContainerWithOptions(InspectContainerOptions{ID: id, Context: ctx})
+	if err != nil {
+		return false
+	}
+	return container.Config != nil && container.Config.Tty
+}
@@ -0,0 +1,204 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTTYTestClient returns a client for a server with the container "abc",
+// whose logs are "hello" written as a raw stream when tty is set, and
+// multiplexed otherwise.
+func newTTYTestClient(t *testing.T, tty bool) (*Client, *atomic.Int32) {
+	t.Helper()
+	var inspects atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/abc/json", func(w http.ResponseWriter, _ *http.Request) {
+		inspects.Add(1)
+		if tty {
+			w.Write([]byte(`{"Id":"abc","Config":{"Tty":true}}`))
+		} else {
+			w.Write([]byte(`{"Id":"abc","Config":{"Tty":false}}`))
+		}
+	})
+	mux.HandleFunc("/containers/abc/logs", func(w http.ResponseWriter, _ *http.Request) {
+		if tty {
+			w.Write([]byte("hello\n"))
+		} else {
+			writeLogFrame(w, 1, "hello\n")
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	return client, &inspects
+}
+
+func TestLogsDetectTerminal(t *testing.T) {
+	t.Parallel()
+	for _, tty := range []bool{true, false} {
+		client, inspects := newTTYTestClient(t, tty)
+		var stdout bytes.Buffer
+		err := client.Logs(LogsOptions{Container: "abc", OutputStream: &stdout, Stdout: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stdout.String() != "hello\n" {
+			t.Errorf("Logs(tty=%v): wrong output. Want %q. Got %q.", tty, "hello\n", stdout.String())
+		}
+		if n := inspects.Load(); n != 1 {
+			t.Errorf("Logs(tty=%v): wrong number of inspections. Want 1. Got %d.", tty, n)
+		}
+	}
+}
+
+func TestLogsSkipTerminalDetection(t *testing.T) {
+	t.Parallel()
+	client, inspects := newTTYTestClient(t, true)
+	var stdout bytes.Buffer
+	err := client.Logs(LogsOptions{Container: "abc", OutputStream: &stdout, Stdout: true, RawTerminal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "hello\n" {
+		t.Errorf("Logs: wrong output. Want %q. Got %q.", "hello\n", stdout.String())
+	}
+	// the raw stream is demultiplexed as requested, so it's not copied
+	stdout.Reset()
+	client.Logs(LogsOptions{Container: "abc", OutputStream: &stdout, Stdout: true, SkipTerminalDetection: true})
+	if stdout.String() == "hello\n" {
+		t.Error("Logs: raw stream copied with terminal detection disabled")
+	}
+	if n := inspects.Load(); n != 0 {
+		t.Errorf("Logs: container inspected %d times with terminal detection disabled", n)
+	}
+}
+
+func TestLogsDetectTerminalInspectFails(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/json") {
+			http.Error(w, "server error", http.StatusInternalServerError)
+			return
+		}
+		writeLogFrame(w, 1, "hello\n")
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	var stdout bytes.Buffer
+	err = client.Logs(LogsOptions{Container: "abc", OutputStream: &stdout, Stdout: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "hello\n" {
+		t.Errorf("Logs: wrong output. Want %q. Got %q.", "hello\n", stdout.String())
+	}
+}
+
+func TestLogsDetectTerminalNoSuchContainer(t *testing.T) {
+	t.Parallel()
+	client, _ := newTTYTestClient(t, true)
+	err := client.Logs(LogsOptions{Container: "missing", Stdout: true})
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusNotFound {
+		t.Errorf("Logs: wrong error. Want status %d. Got %#v.", http.StatusNotFound, err)
+	}
+}
+
+func TestAttachToContainerDetectTerminalTimeout(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/json") {
+			// a daemon that doesn't answer the inspection
+			<-done
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Type: application/vnd.docker.raw-stream\r\n\r\n"))
+		conn.Write([]byte{1, 0, 0, 0, 0, 0, 0, 6})
+		conn.Write([]byte("hello\n"))
+	}))
+	defer server.Close()
+	defer close(done)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var stdout bytes.Buffer
+	err = client.AttachToContainer(AttachToContainerOptions{
+		Container:    "abc",
+		OutputStream: &stdout,
+		Stdout:       true,
+		Stream:       true,
+		Context:      ctx,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "hello\n" {
+		t.Errorf("AttachToContainer: wrong output. Want %q. Got %q.", "hello\n", stdout.String())
+	}
+}
+
+func TestAttachToContainerDetectTerminalSlowInspect(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/json") {
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte(`{"Id":"abc","Config":{"Tty":true}}`))
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Type: application/vnd.docker.raw-stream\r\n\r\n"))
+		conn.Write([]byte("hello\n"))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	var stdout bytes.Buffer
+	err = client.AttachToContainer(AttachToContainerOptions{
+		Container:    "abc",
+		OutputStream: &stdout,
+		Stdout:       true,
+		Stream:       true,
+		WriteTimeout: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the write timeout doesn't bound the detection
+	if stdout.String() != "hello\n" {
+		t.Errorf("AttachToContainer: wrong output. Want %q. Got %q.", "hello\n", stdout.String())
+	}
+}
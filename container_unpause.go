@@ -15,7 +15,7 @@ func (c *Client) UnpauseContainer(id string) error {
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return &NoSuchContainer{ID: id}
+			return &NoSuchContainer{ID: id, Op: "unpause", Err: err}
 		}
 		return err
 	}
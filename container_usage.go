@@ -0,0 +1,160 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ContainerUsage is a snapshot of the resource limits of a container and of
+// its current usage of those resources.
+type ContainerUsage struct {
+	ID      string
+	Name    string
+	Running bool
+
+	// Read is the time the usage was sampled by the daemon. It's zero when
+	// the container isn't running.
+	Read time.Time
+
+	// CPULimit is the number of CPUs the container may use, derived from
+	// NanoCPUs or CPUQuota and CPUPeriod. Zero means no limit.
+	CPULimit float64
+
+	// CPUPercent is the CPU usage of the container since the previous
+	// sample of the daemon, where 100 means a full CPU.
+	CPUPercent float64
+
+	// MemoryLimit is the memory limit configured for the container. Zero
+	// means no limit.
+	MemoryLimit int64
+
+	// MemoryUsage is the memory used by the container, excluding the page
+	// cache that the kernel can reclaim.
+	MemoryUsage uint64
+
+	// MemoryPercent is MemoryUsage relative to the effective memory limit
+	// of the container: MemoryLimit, or the memory of the host when the
+	// container has no limit.
+	MemoryPercent float64
+
+	PidsLimit   int64
+	PidsCurrent uint64
+
+	// Stats holds the raw statistics the usage was computed from. It's nil
+	// when the container isn't running.
+	Stats *Stats
+}
+
+// ContainerUsageOptions specify parameters to the ContainerUsageWithOptions
+// function.
+type ContainerUsageOptions struct {
+	ID      string
+	Context context.Context
+}
+
+// ContainerUsage returns a snapshot of the limits and current resource usage
+// of the given container, combining its configuration with a single stats
+// sample.
+func (c *Client) ContainerUsage(id string) (*ContainerUsage, error) {
+	return c.ContainerUsageWithOptions(ContainerUsageOptions{ID: id})
+}
+
+// ContainerUsageWithOptions returns a snapshot of the limits and current
+// resource usage of a container, combining its configuration with a single
+// stats sample. It's cheap enough to be called for many containers at a
+// regular interval, as it doesn't keep any stream open.
+func (c *Client) ContainerUsageWithOptions(opts ContainerUsageOptions) (*ContainerUsage, error) {
+	ctx := opts.Context
+	container, err := c.InspectContainerWithOptions(InspectContainerOptions{ID: opts.ID, Context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	usage := ContainerUsage{
+		ID:      container.ID,
+		Name:    container.Name,
+		Running: container.State.Running,
+	}
+	if hostConfig := container.HostConfig; hostConfig != nil {
+		usage.MemoryLimit = hostConfig.Memory
+		switch {
+		case hostConfig.NanoCPUs > 0:
+			usage.CPULimit = float64(hostConfig.NanoCPUs) / 1e9
+		case hostConfig.CPUQuota > 0 && hostConfig.CPUPeriod > 0:
+			usage.CPULimit = float64(hostConfig.CPUQuota) / float64(hostConfig.CPUPeriod)
+		}
+		if hostConfig.PidsLimit != nil && *hostConfig.PidsLimit > 0 {
+			usage.PidsLimit = *hostConfig.PidsLimit
+		}
+	}
+	if !usage.Running {
+		return &usage, nil
+	}
+	stats, err := c.statsSnapshot(ctx, opts.ID, container.ID)
+	if err != nil {
+		return nil, err
+	}
+	usage.Stats = stats
+	usage.Read = stats.Read
+	usage.CPUPercent = cpuPercent(stats)
+	usage.MemoryUsage = memoryUsage(stats)
+	usage.PidsCurrent = stats.PidsStats.Current
+	limit := uint64(usage.MemoryLimit)
+	if limit == 0 || (stats.MemoryStats.Limit > 0 && stats.MemoryStats.Limit < limit) {
+		limit = stats.MemoryStats.Limit
+	}
+	if limit > 0 {
+		usage.MemoryPercent = float64(usage.MemoryUsage) / float64(limit) * 100
+	}
+	return &usage, nil
+}
+
+// statsSnapshot returns a single stats sample of the container with the
+// given ID, which ref was resolved to.
+func (c *Client) statsSnapshot(ctx context.Context, ref, id string) (*Stats, error) {
+	resp, err := c.do(http.MethodGet, "/containers/"+id+"/stats?stream=false", doOptions{context: ctx})
+	if err != nil {
+		var e *Error
+		if errors.As(err, &e) && e.Status == http.StatusNotFound {
+			return nil, &NoSuchContainer{ID: ref, ResolvedID: id, Op: "stats", Err: err}
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var stats Stats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// cpuPercent computes the CPU usage between the two samples in stats, the
+// same way the docker CLI does.
+func cpuPercent(stats *Stats) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemCPUUsage) - float64(stats.PreCPUStats.SystemCPUUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+	cpus := float64(stats.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	return cpuDelta / systemDelta * cpus * 100
+}
+
+// memoryUsage returns the memory used in stats minus the inactive page
+// cache, for both cgroup v1 and v2 hosts.
+func memoryUsage(stats *Stats) uint64 {
+	usage := stats.MemoryStats.Usage
+	cache := stats.MemoryStats.Stats.TotalInactiveFile
+	if cache == 0 {
+		cache = stats.MemoryStats.Stats.InactiveFile
+	}
+	if cache < usage {
+		return usage - cache
+	}
+	return usage
+}
@@ -0,0 +1,76 @@
+package docker
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContainerUsage(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/abc/json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"Id":"abc","Name":"/web","State":{"Running":true},"HostConfig":{"Memory":1000,"NanoCpus":1500000000,"PidsLimit":100}}`))
+	})
+	mux.HandleFunc("/containers/abc/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("stream") != "false" {
+			t.Errorf("ContainerUsage: stats should not be streamed: %s", r.URL)
+		}
+		w.Write([]byte(`{
+			"read":"2024-05-01T10:00:00Z",
+			"pids_stats":{"current":7},
+			"memory_stats":{"usage":600,"limit":1000,"stats":{"inactive_file":100}},
+			"cpu_stats":{"cpu_usage":{"total_usage":300},"system_cpu_usage":2000,"online_cpus":4},
+			"precpu_stats":{"cpu_usage":{"total_usage":100},"system_cpu_usage":1000,"online_cpus":4}
+		}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	usage, err := client.ContainerUsage("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.ID != "abc" || usage.Name != "/web" || !usage.Running || usage.Read.Year() != 2024 || usage.Stats == nil {
+		t.Errorf("ContainerUsage: wrong usage: %#v.", usage)
+	}
+	if usage.CPULimit != 1.5 || math.Abs(usage.CPUPercent-80) > 1e-9 {
+		t.Errorf("ContainerUsage: wrong CPU usage. Want 1.5 CPUs at 80%%. Got %v CPUs at %v%%.", usage.CPULimit, usage.CPUPercent)
+	}
+	if usage.MemoryLimit != 1000 || usage.MemoryUsage != 500 || math.Abs(usage.MemoryPercent-50) > 1e-9 {
+		t.Errorf("ContainerUsage: wrong memory usage: %#v.", usage)
+	}
+	if usage.PidsLimit != 100 || usage.PidsCurrent != 7 {
+		t.Errorf("ContainerUsage: wrong pids usage: %#v.", usage)
+	}
+}
+
+func TestContainerUsageNotRunning(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"Id":"abc","State":{"Running":false},"HostConfig":{"CpuQuota":50000,"CpuPeriod":100000}}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	usage, err := client.ContainerUsage("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Running || usage.Stats != nil || usage.CPULimit != 0.5 || usage.CPUPercent != 0 {
+		t.Errorf("ContainerUsage: wrong usage: %#v.", usage)
+	}
+	if len(fakeRT.requests) != 1 {
+		t.Errorf("ContainerUsage: expected a single request, got %d.", len(fakeRT.requests))
+	}
+}
+
+func TestContainerUsageNoSuchContainer(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "no such container", status: http.StatusNotFound})
+	_, err := client.ContainerUsage("abc")
+	if _, ok := err.(*NoSuchContainer); !ok {
+		t.Errorf("ContainerUsage: wrong error. Want *NoSuchContainer. Got %#v.", err)
+	}
+}
@@ -29,7 +29,7 @@ func (c *Client) waitContainer(id string, opts doOptions) (int, error) {
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return 0, &NoSuchContainer{ID: id}
+			return 0, &NoSuchContainer{ID: id, Op: "wait", Err: err}
 		}
 		return 0, err
 	}
@@ -0,0 +1,301 @@
+// Package contexts reads the contexts of the Docker CLI, as managed by
+// "docker context create" and "docker context use", and creates clients for
+// them, so that tools built on go-dockerclient connect to the same daemon as
+// the CLI.
+package contexts
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// DefaultContext is the name of the context defined by the environment
+// variables DOCKER_HOST, DOCKER_TLS_VERIFY and DOCKER_CERT_PATH, which isn't
+// stored in the context store.
+const DefaultContext = "default"
+
+// dockerEndpoint is the name of the endpoint of contexts pointing to Docker
+// daemons.
+const dockerEndpoint = "docker"
+
+var (
+	// ErrContextNotFound is the error returned when there's no context with
+	// the given name in the store.
+	ErrContextNotFound = errors.New("context not found")
+
+	// ErrUnsupportedHost is the error returned when creating a client for a
+	// context whose host can't be reached by go-dockerclient, like ssh://
+	// hosts.
+	ErrUnsupportedHost = errors.New("unsupported context host")
+)
+
+// Context is a Docker CLI context, describing how to reach a Docker daemon.
+type Context struct {
+	Name        string
+	Description string
+
+	// Host is the endpoint of the daemon, like "unix:///var/run/docker.sock"
+	// or "tcp://docker.example.com:2376".
+	Host string
+
+	// SkipTLSVerify disables the verification of the certificate of the
+	// daemon.
+	SkipTLSVerify bool
+
+	// TLS holds the TLS material stored with the context, or nil when the
+	// context has none.
+	TLS *TLSData
+}
+
+// TLSData is the TLS material of a context, PEM encoded. Any of the fields
+// may be empty.
+type TLSData struct {
+	CA   []byte
+	Cert []byte
+	Key  []byte
+}
+
+// Store is the context store of the Docker CLI, kept in its configuration
+// directory.
+type Store struct {
+	configDir string
+}
+
+// NewStore returns the context store in the given Docker CLI configuration
+// directory, like "~/.docker".
+func NewStore(configDir string) *Store {
+	return &Store{configDir: configDir}
+}
+
+// DefaultStore returns the context store used by the Docker CLI: the one in
+// $DOCKER_CONFIG when it's set, or in ~/.docker.
+func DefaultStore() (*Store, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return NewStore(dir), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(filepath.Join(home, ".docker")), nil
+}
+
+// NewClient returns a client for the context with the given name in the
+// default store, or for the current context when name is empty, like the
+// Docker CLI does with and without the --context flag.
+func NewClient(name string) (*docker.Client, error) {
+	store, err := DefaultStore()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name, err = store.Current()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store.NewClient(name)
+}
+
+// Current returns the name of the context selected for the Docker CLI. As
+// in the CLI, setting DOCKER_HOST selects the default context, and
+// DOCKER_CONTEXT takes precedence over the context chosen with
+// "docker context use", which is kept in config.json.
+func (s *Store) Current() (string, error) {
+	if os.Getenv("DOCKER_HOST") != "" {
+		return DefaultContext, nil
+	}
+	if name := os.Getenv("DOCKER_CONTEXT"); name != "" {
+		return name, nil
+	}
+	data, err := os.ReadFile(filepath.Join(s.configDir, "config.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultContext, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var config struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", filepath.Join(s.configDir, "config.json"), err)
+	}
+	if config.CurrentContext == "" {
+		return DefaultContext, nil
+	}
+	return config.CurrentContext, nil
+}
+
+// List returns the contexts in the store, sorted by name. The default
+// context isn't included.
+func (s *Store) List() ([]Context, error) {
+	dirs, err := os.ReadDir(filepath.Join(s.configDir, "contexts", "meta"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var contexts []Context
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		context, err := s.load(dir.Name())
+		if errors.Is(err, ErrContextNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		contexts = append(contexts, *context)
+	}
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Name < contexts[j].Name })
+	return contexts, nil
+}
+
+// Inspect returns the context with the given name, or ErrContextNotFound.
+func (s *Store) Inspect(name string) (*Context, error) {
+	context, err := s.load(contextDir(name))
+	if errors.Is(err, ErrContextNotFound) {
+		return nil, fmt.Errorf("%w: %q", ErrContextNotFound, name)
+	}
+	return context, err
+}
+
+// NewClient returns a client for the context with the given name. The
+// default context is resolved from the environment, like
+// docker.NewClientFromEnv does. DOCKER_API_VERSION sets the API version of
+// the client, as in the CLI.
+func (s *Store) NewClient(name string) (*docker.Client, error) {
+	if name == DefaultContext {
+		return docker.NewClientFromEnv()
+	}
+	context, err := s.Inspect(name)
+	if err != nil {
+		return nil, err
+	}
+	return context.NewClient()
+}
+
+// NewClient returns a client for the daemon of the context.
+func (c *Context) NewClient() (*docker.Client, error) {
+	if strings.HasPrefix(c.Host, "ssh://") || c.Host == "" {
+		return nil, fmt.Errorf("%w: %q in context %q", ErrUnsupportedHost, c.Host, c.Name)
+	}
+	opts := []docker.ClientOption{docker.WithAPIVersion(os.Getenv("DOCKER_API_VERSION"))}
+	if c.TLS != nil || c.SkipTLSVerify {
+		config, err := c.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, docker.WithTLSConfig(config))
+	}
+	return docker.NewClientWithOptions(c.Host, opts...)
+}
+
+// tlsConfig returns the TLS configuration of the context. Unlike the TLS
+// constructors of go-dockerclient, the certificate of the daemon is verified
+// against the system roots when the context has no CA, as the CLI does.
+func (c *Context) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.SkipTLSVerify,
+	}
+	if c.TLS == nil {
+		return config, nil
+	}
+	if len(c.TLS.CA) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(c.TLS.CA) {
+			return nil, fmt.Errorf("invalid CA certificate in context %q", c.Name)
+		}
+		config.RootCAs = pool
+	}
+	if len(c.TLS.Cert) > 0 && len(c.TLS.Key) > 0 {
+		cert, err := tls.X509KeyPair(c.TLS.Cert, c.TLS.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate in context %q: %w", c.Name, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// contextDir returns the name of the directories holding the metadata and
+// the TLS material of a context, which is the hex encoded SHA-256 digest of
+// its name.
+func contextDir(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+type metadata struct {
+	Name     string
+	Metadata struct {
+		Description string
+	}
+	Endpoints map[string]struct {
+		Host          string
+		SkipTLSVerify bool
+	}
+}
+
+func (s *Store) load(dir string) (*Context, error) {
+	data, err := os.ReadFile(filepath.Join(s.configDir, "contexts", "meta", dir, "meta.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrContextNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var meta metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("invalid metadata of context in %s: %w", dir, err)
+	}
+	endpoint := meta.Endpoints[dockerEndpoint]
+	context := Context{
+		Name:          meta.Name,
+		Description:   meta.Metadata.Description,
+		Host:          endpoint.Host,
+		SkipTLSVerify: endpoint.SkipTLSVerify,
+	}
+	context.TLS, err = s.loadTLS(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &context, nil
+}
+
+func (s *Store) loadTLS(dir string) (*TLSData, error) {
+	tlsDir := filepath.Join(s.configDir, "contexts", "tls", dir, dockerEndpoint)
+	var data TLSData
+	var found bool
+	for file, dst := range map[string]*[]byte{"ca.pem": &data.CA, "cert.pem": &data.Cert, "key.pem": &data.Key} {
+		content, err := os.ReadFile(filepath.Join(tlsDir, file))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		*dst = content
+		found = true
+	}
+	if !found {
+		return nil, nil
+	}
+	return &data, nil
+}
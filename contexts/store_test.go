@@ -0,0 +1,167 @@
+package contexts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTestStore creates a store with the "remote" context, using the TLS
+// material of the testing package, and the "ssh" context.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	dir := t.TempDir()
+	writeContext(t, dir, "remote", `{"Name":"remote","Metadata":{"Description":"remote daemon"},"Endpoints":{"docker":{"Host":"tcp://docker.example.com:2376","SkipTLSVerify":false}}}`)
+	writeContext(t, dir, "ssh", `{"Name":"ssh","Metadata":{},"Endpoints":{"docker":{"Host":"ssh://user@docker.example.com"}}}`)
+	tlsDir := filepath.Join(dir, "contexts", "tls", contextDir("remote"), "docker")
+	if err := os.MkdirAll(tlsDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"ca.pem", "cert.pem", "key.pem"} {
+		data, err := os.ReadFile(filepath.Join("..", "testing", "data", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tlsDir, file), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewStore(dir)
+}
+
+func writeContext(t *testing.T, dir, name, meta string) {
+	t.Helper()
+	metaDir := filepath.Join(dir, "contexts", "meta", contextDir(name))
+	if err := os.MkdirAll(metaDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	t.Parallel()
+	contexts, err := newTestStore(t).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, context := range contexts {
+		names = append(names, context.Name)
+	}
+	if expected := []string{"remote", "ssh"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("List: wrong contexts. Want %#v. Got %#v.", expected, names)
+	}
+	if contexts[1].TLS != nil {
+		t.Errorf("List: unexpected TLS material in context without it: %#v", contexts[1].TLS)
+	}
+}
+
+func TestStoreListEmpty(t *testing.T) {
+	t.Parallel()
+	contexts, err := NewStore(t.TempDir()).List()
+	if err != nil || len(contexts) != 0 {
+		t.Errorf("List: want no contexts. Got %#v, %v.", contexts, err)
+	}
+}
+
+func TestStoreInspect(t *testing.T) {
+	t.Parallel()
+	store := newTestStore(t)
+	context, err := store.Inspect("remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if context.Host != "tcp://docker.example.com:2376" || context.Description != "remote daemon" {
+		t.Errorf("Inspect: wrong context: %#v", context)
+	}
+	if context.TLS == nil || len(context.TLS.CA) == 0 || len(context.TLS.Cert) == 0 || len(context.TLS.Key) == 0 {
+		t.Errorf("Inspect: missing TLS material: %#v", context.TLS)
+	}
+	if _, err := store.Inspect("missing"); !errors.Is(err, ErrContextNotFound) {
+		t.Errorf("Inspect: wrong error. Want %#v. Got %#v.", ErrContextNotFound, err)
+	}
+}
+
+func TestStoreNewClient(t *testing.T) {
+	t.Parallel()
+	store := newTestStore(t)
+	client, err := store.NewClient("remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint := client.Endpoint(); endpoint != "tcp://docker.example.com:2376" {
+		t.Errorf("NewClient: wrong endpoint. Want %q. Got %q.", "tcp://docker.example.com:2376", endpoint)
+	}
+	if client.TLSConfig == nil || client.TLSConfig.RootCAs == nil || len(client.TLSConfig.Certificates) != 1 {
+		t.Errorf("NewClient: wrong TLS configuration: %#v", client.TLSConfig)
+	}
+	if client.TLSConfig.InsecureSkipVerify {
+		t.Error("NewClient: server certificate not verified")
+	}
+	if _, err := store.NewClient("ssh"); !errors.Is(err, ErrUnsupportedHost) {
+		t.Errorf("NewClient: wrong error. Want %#v. Got %#v.", ErrUnsupportedHost, err)
+	}
+	if _, err := store.NewClient("missing"); !errors.Is(err, ErrContextNotFound) {
+		t.Errorf("NewClient: wrong error. Want %#v. Got %#v.", ErrContextNotFound, err)
+	}
+}
+
+func TestStoreCurrent(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("DOCKER_CONTEXT", "")
+	tests := []struct {
+		name     string
+		config   string
+		env      map[string]string
+		expected string
+	}{
+		{"no config", "", nil, DefaultContext},
+		{"config", `{"currentContext":"remote"}`, nil, "remote"},
+		{"config without context", `{"auths":{}}`, nil, DefaultContext},
+		{"DOCKER_CONTEXT", `{"currentContext":"remote"}`, map[string]string{"DOCKER_CONTEXT": "other"}, "other"},
+		{"DOCKER_HOST", `{"currentContext":"remote"}`, map[string]string{"DOCKER_HOST": "tcp://localhost:2375", "DOCKER_CONTEXT": "other"}, DefaultContext},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(filepath.Join(dir, "config.json"))
+			if tt.config != "" {
+				if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(tt.config), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			current, err := store.Current()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if current != tt.expected {
+				t.Errorf("Current: wrong context. Want %q. Got %q.", tt.expected, current)
+			}
+		})
+	}
+}
+
+func TestNewClientCurrentContext(t *testing.T) {
+	dir := newTestStore(t).configDir
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"currentContext":"remote"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_CONFIG", dir)
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("DOCKER_CONTEXT", "")
+	client, err := NewClient("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint := client.Endpoint(); endpoint != "tcp://docker.example.com:2376" {
+		t.Errorf("NewClient: wrong endpoint. Want %q. Got %q.", "tcp://docker.example.com:2376", endpoint)
+	}
+}
@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidDetachKeys is the error wrapped by the errors returned when the
+// DetachKeys of AttachToContainerOptions or StartExecOptions are malformed.
+var ErrInvalidDetachKeys = errors.New("invalid detach keys")
+
+// errDetached is returned by detachReader once the detach key sequence is
+// read.
+var errDetached = errors.New("read detach key sequence")
+
+// parseDetachKeys parses a detach key sequence in the format of the
+// --detach-keys flag of docker attach: a comma-separated list of keys,
+// each either a single character or "ctrl-<value>", where value is a
+// letter or one of @, [, \, ], ^ and _. For example, "ctrl-p,ctrl-q".
+func parseDetachKeys(keys string) ([]byte, error) {
+	var sequence []byte
+	for _, key := range strings.Split(keys, ",") {
+		switch {
+		case len(key) == 1:
+			sequence = append(sequence, key[0])
+		case len(key) == 6 && strings.HasPrefix(strings.ToLower(key), "ctrl-"):
+			value := strings.ToLower(key)[5]
+			switch {
+			case value >= 'a' && value <= 'z':
+				sequence = append(sequence, value-'a'+1)
+			case value == '@':
+				sequence = append(sequence, 0)
+			case value >= '[' && value <= '_':
+				sequence = append(sequence, value-'['+27)
+			default:
+				return nil, fmt.Errorf("%w %q: unknown key %q", ErrInvalidDetachKeys, keys, key)
+			}
+		default:
+			return nil, fmt.Errorf("%w %q: unknown key %q", ErrInvalidDetachKeys, keys, key)
+		}
+	}
+	return sequence, nil
+}
+
+// detachReader reads from r until the detach key sequence is read, then
+// returns errDetached. The bytes of the sequence aren't returned, while
+// the ones of a partial match are once it turns out not to be the
+// sequence.
+type detachReader struct {
+	r        io.Reader
+	keys     []byte
+	matched  int
+	pending  []byte
+	detached bool
+}
+
+func (d *detachReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(d.pending) == 0 {
+		if d.detached {
+			return 0, errDetached
+		}
+		buf := make([]byte, len(p))
+		n, err := d.r.Read(buf)
+		for _, b := range buf[:n] {
+			if d.detached {
+				// anything typed after the sequence is dropped
+				break
+			}
+			if b != d.keys[d.matched] && d.matched > 0 {
+				d.pending = append(d.pending, d.keys[:d.matched]...)
+				d.matched = 0
+			}
+			if b != d.keys[d.matched] {
+				d.pending = append(d.pending, b)
+				continue
+			}
+			d.matched++
+			if d.matched == len(d.keys) {
+				d.detached = true
+			}
+		}
+		if err != nil && !d.detached && d.matched > 0 {
+			d.pending = append(d.pending, d.keys[:d.matched]...)
+			d.matched = 0
+		}
+		if err != nil {
+			if len(d.pending) > 0 || d.detached {
+				break
+			}
+			return 0, err
+		}
+	}
+	if len(d.pending) == 0 {
+		return 0, errDetached
+	}
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,71 @@
+package docker
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestParseDetachKeys(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		keys     string
+		expected []byte
+	}{
+		{"ctrl-p,ctrl-q", []byte{16, 17}},
+		{"ctrl-@,ctrl-[,ctrl-\\,ctrl-],ctrl-^,ctrl-_", []byte{0, 27, 28, 29, 30, 31}},
+		{"CTRL-A,a", []byte{1, 'a'}},
+		{"ctrl-e,e", []byte{5, 'e'}},
+	}
+	for _, tt := range tests {
+		got, err := parseDetachKeys(tt.keys)
+		if err != nil {
+			t.Errorf("parseDetachKeys(%q): unexpected error: %v", tt.keys, err)
+			continue
+		}
+		if string(got) != string(tt.expected) {
+			t.Errorf("parseDetachKeys(%q): wrong sequence. Want %v. Got %v.", tt.keys, tt.expected, got)
+		}
+	}
+}
+
+func TestParseDetachKeysInvalid(t *testing.T) {
+	t.Parallel()
+	for _, keys := range []string{"", "ctrl-p,", "ctrl-1", "ctrl-", "ab", "alt-a"} {
+		if _, err := parseDetachKeys(keys); !errors.Is(err, ErrInvalidDetachKeys) {
+			t.Errorf("parseDetachKeys(%q): wrong error. Want %v. Got %v.", keys, ErrInvalidDetachKeys, err)
+		}
+	}
+}
+
+func TestDetachReader(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected string
+		detached bool
+	}{
+		{"ls\x10\x11exit", "ls", true},
+		{"\x10\x11", "", true},
+		{"ls\x10a\x10\x10\x11", "ls\x10a\x10", true},
+		{"ls\x10", "ls\x10", false},
+		{"no keys", "no keys", false},
+	}
+	for _, tt := range tests {
+		for _, oneByte := range []bool{false, true} {
+			var r io.Reader = strings.NewReader(tt.input)
+			if oneByte {
+				r = iotest.OneByteReader(r)
+			}
+			got, err := io.ReadAll(&detachReader{r: r, keys: []byte{16, 17}})
+			if string(got) != tt.expected {
+				t.Errorf("detachReader(%q): wrong output. Want %q. Got %q.", tt.input, tt.expected, got)
+			}
+			if detached := errors.Is(err, errDetached); detached != tt.detached || (!detached && err != nil) {
+				t.Errorf("detachReader(%q): wrong error: %v", tt.input, err)
+			}
+		}
+	}
+}
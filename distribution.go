@@ -13,8 +13,27 @@ import (
 
 // InspectDistribution returns image digest and platform information by contacting the registry
 func (c *Client) InspectDistribution(name string) (*registry.DistributionInspect, error) {
-	path := "/distribution/" + name + "/json"
-	resp, err := c.do(http.MethodGet, path, doOptions{})
+	return c.inspectDistribution(name, AuthConfiguration{})
+}
+
+// ResolveImageDigest returns the digest the given reference currently points
+// to in the registry (e.g. "sha256:..."), without pulling the image. The auth
+// configuration is used to access private registries.
+func (c *Client) ResolveImageDigest(ref string, auth AuthConfiguration) (string, error) {
+	distributionInspect, err := c.inspectDistribution(ref, auth)
+	if err != nil {
+		return "", err
+	}
+	return distributionInspect.Descriptor.Digest.String(), nil
+}
+
+func (c *Client) inspectDistribution(name string, auth AuthConfiguration) (*registry.DistributionInspect, error) {
+	headers, err := headersWithAuth(auth)
+	if err != nil {
+		return nil, err
+	}
+	path := "/distribution/" + normalizeDigestRef(name) + "/json"
+	resp, err := c.do(http.MethodGet, path, doOptions{headers: headers})
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@
 package docker
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"reflect"
@@ -56,3 +57,34 @@ func TestInspectDistribution(t *testing.T) {
 		t.Errorf("InspectDistribution(%q): Expected %#v. Got %#v.", "", expected, distributionInspect)
 	}
 }
+
+func TestResolveImageDigest(t *testing.T) {
+	t.Parallel()
+	const digest = "sha256:c0537ff6a5218ef531ece93d4984efc99bbf3f7497c0a7726c88e2bb7584dc96"
+	fakeRT := &FakeRoundTripper{message: `{"Descriptor":{"Digest":"` + digest + `"}}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	auth := AuthConfiguration{Username: "gopher", Password: "gopher123"}
+	got, err := client.ResolveImageDigest("registry.example.com/app:v1@sha256:abc", auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != digest {
+		t.Errorf("ResolveImageDigest: wrong digest. Want %q. Got %q.", digest, got)
+	}
+	req := fakeRT.requests[0]
+	expectedPath := "/distribution/registry.example.com/app@sha256:abc/json"
+	if req.URL.Path != expectedPath {
+		t.Errorf("ResolveImageDigest: wrong path. Want %q. Got %q.", expectedPath, req.URL.Path)
+	}
+	data, err := base64.URLEncoding.DecodeString(req.Header.Get("X-Registry-Auth"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotAuth AuthConfiguration
+	if err := json.Unmarshal(data, &gotAuth); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotAuth, auth) {
+		t.Errorf("ResolveImageDigest: wrong auth. Want %#v. Got %#v.", auth, gotAuth)
+	}
+}
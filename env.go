@@ -8,8 +8,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Env represents a list of key-pair represented in the form KEY=VALUE.
@@ -76,6 +78,42 @@ func (env *Env) SetInt64(key string, value int64) {
 	env.Set(key, strconv.FormatInt(value, 10))
 }
 
+// GetFloat64 returns the value of the provided key, converted to float64.
+//
+// It the value cannot be represented as a float, it returns -1.
+func (env *Env) GetFloat64(key string) float64 {
+	s := strings.Trim(env.Get(key), " \t")
+	val, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return -1
+	}
+	return val
+}
+
+// SetFloat64 defines a float value to the given key.
+func (env *Env) SetFloat64(key string, value float64) {
+	env.Set(key, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+// GetDuration returns the value of the provided key, parsed with
+// time.ParseDuration (e.g. "1m30s").
+//
+// It the value cannot be represented as a duration, it returns -1.
+func (env *Env) GetDuration(key string) time.Duration {
+	s := strings.Trim(env.Get(key), " \t")
+	val, err := time.ParseDuration(s)
+	if err != nil {
+		return -1
+	}
+	return val
+}
+
+// SetDuration defines a duration value to the given key, in the format
+// accepted by time.ParseDuration.
+func (env *Env) SetDuration(key string, value time.Duration) {
+	env.Set(key, value.String())
+}
+
 // GetJSON unmarshals the value of the provided key in the provided iface.
 //
 // iface is a value that can be provided to the json.Unmarshal function.
@@ -130,9 +168,13 @@ func (env *Env) Set(key, value string) {
 // pair to the environment.
 //
 // If `src` cannot be decoded as a json dictionary, an error is returned.
+// Numbers are decoded as json.Number, so integers that don't fit in a
+// float64, like 64-bit counters, are kept intact.
 func (env *Env) Decode(src io.Reader) error {
 	m := make(map[string]any)
-	if err := json.NewDecoder(src).Decode(&m); err != nil {
+	decoder := json.NewDecoder(src)
+	decoder.UseNumber()
+	if err := decoder.Decode(&m); err != nil {
 		return err
 	}
 	for k, v := range m {
@@ -143,7 +185,9 @@ func (env *Env) Decode(src io.Reader) error {
 
 // SetAuto will try to define the Set* method to call based on the given value.
 func (env *Env) SetAuto(key string, value any) {
-	if fval, ok := value.(float64); ok {
+	if nval, ok := value.(json.Number); ok {
+		env.setNumber(key, nval)
+	} else if fval, ok := value.(float64); ok {
 		env.SetInt64(key, int64(fval))
 	} else if sval, ok := value.(string); ok {
 		env.Set(key, sval)
@@ -154,6 +198,36 @@ func (env *Env) SetAuto(key string, value any) {
 	}
 }
 
+// setNumber defines the value of a key to the given number, truncating
+// it to an integer as SetAuto does with float64 values. Integers out of the
+// int64 range are stored as is.
+func (env *Env) setNumber(key string, value json.Number) {
+	if ival, err := value.Int64(); err == nil {
+		env.SetInt64(key, ival)
+	} else if _, err := strconv.ParseUint(value.String(), 10, 64); err == nil {
+		env.Set(key, value.String())
+	} else if fval, err := value.Float64(); err == nil {
+		env.SetInt64(key, int64(fval))
+	} else {
+		env.Set(key, value.String())
+	}
+}
+
+// EnvFromMap returns the Env representation of the given map. Variables are
+// sorted by key, so the result is stable across calls.
+func EnvFromMap(m map[string]string) Env {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := make(Env, 0, len(m))
+	for _, k := range keys {
+		env.Set(k, m[k])
+	}
+	return env
+}
+
 // Map returns the map representation of the env.
 func (env *Env) Map() map[string]string {
 	if env == nil || len(*env) == 0 {
@@ -6,10 +6,12 @@ package docker
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"reflect"
 	"slices"
 	"testing"
+	"time"
 )
 
 func TestGet(t *testing.T) {
@@ -212,6 +214,90 @@ func TestSetInt64(t *testing.T) {
 	}
 }
 
+func TestGetFloat64(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"NEGATIVE_FLOAT", -10.5},
+		{"NON_FLOAT", -1},
+		{"ONE", 1},
+		{"PI", 3.14159},
+	}
+	env := Env([]string{"NEGATIVE_FLOAT=-10.5", "NON_FLOAT=wat", "ONE=1", "PI=3.14159"})
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.input, func(t *testing.T) {
+			t.Parallel()
+			got := env.GetFloat64(test.input)
+			if got != test.expected {
+				t.Errorf("Env.GetFloat64(%q): wrong result. Want %v. Got %v", test.input, test.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetFloat64(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    float64
+		expected string
+	}{
+		{10, "10"},
+		{0.5, "0.5"},
+		{-3.25, "-3.25"},
+		{0, "0"},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.expected, func(t *testing.T) {
+			t.Parallel()
+			var env Env
+			env.SetFloat64("SOME", test.input)
+			if got := env.Get("SOME"); got != test.expected {
+				t.Errorf("Env.SetFloat64(%v): wrong result. Want %q. Got %q", test.input, test.expected, got)
+			}
+		})
+	}
+}
+
+func TestGetDuration(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"TIMEOUT", 90 * time.Second},
+		{"INTERVAL", 250 * time.Millisecond},
+		{"NON_DURATION", -1},
+		{"MISSING", -1},
+	}
+	env := Env([]string{"TIMEOUT=1m30s", "INTERVAL=250ms", "NON_DURATION=10"})
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.input, func(t *testing.T) {
+			t.Parallel()
+			got := env.GetDuration(test.input)
+			if got != test.expected {
+				t.Errorf("Env.GetDuration(%q): wrong result. Want %v. Got %v", test.input, test.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetDuration(t *testing.T) {
+	t.Parallel()
+	var env Env
+	env.SetDuration("TIMEOUT", 90*time.Second)
+	if got := env.Get("TIMEOUT"); got != "1m30s" {
+		t.Errorf("Env.SetDuration: wrong result. Want %q. Got %q", "1m30s", got)
+	}
+	if got := env.GetDuration("TIMEOUT"); got != 90*time.Second {
+		t.Errorf("Env.GetDuration: wrong result. Want %v. Got %v", 90*time.Second, got)
+	}
+}
+
 func TestGetJSON(t *testing.T) {
 	t.Parallel()
 	var p struct {
@@ -349,6 +435,11 @@ func TestDecode(t *testing.T) {
 			[]string{"PATH=/usr/bin:/bin", "containers=54", `wat=["123","345"]`},
 			false,
 		},
+		{
+			`{"MemTotal":9007199254740993,"Max":18446744073709551615,"Ratio":10.5,"Nested":{"Bytes":9007199254740993}}`,
+			[]string{"MemTotal=9007199254740993", "Max=18446744073709551615", "Ratio=10", `Nested={"Bytes":9007199254740993}`},
+			false,
+		},
 		{"}}", nil, true},
 		{`{}`, nil, false},
 	}
@@ -382,6 +473,8 @@ func TestSetAuto(t *testing.T) {
 	}{
 		{10, "10"},
 		{10.3, "10"},
+		{json.Number("9007199254740993"), "9007199254740993"},
+		{json.Number("10.3"), "10"},
 		{"oi", "oi"},
 		{buf, "{}"},
 		{unmarshable{}, "{}"},
@@ -418,6 +511,21 @@ func TestMap(t *testing.T) {
 	}
 }
 
+func TestEnvFromMap(t *testing.T) {
+	t.Parallel()
+	m := map[string]string{"PYTHONPATH": "/usr/local", "PATH": "/usr/bin:/bin", "DEBUG": ""}
+	expected := Env{"DEBUG=", "PATH=/usr/bin:/bin", "PYTHONPATH=/usr/local"}
+	for i := 0; i < 10; i++ {
+		got := EnvFromMap(m)
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("EnvFromMap: wrong result. Want %#v. Got %#v", expected, got)
+		}
+	}
+	if got := expected.Map(); !reflect.DeepEqual(got, m) {
+		t.Errorf("Env.Map(): wrong round-trip result. Want %#v. Got %#v", m, got)
+	}
+}
+
 type unmarshable struct{}
 
 func (unmarshable) MarshalJSON() ([]byte, error) {
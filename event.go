@@ -27,7 +27,7 @@ type EventsOptions struct {
 	// Show events created until this timestamp then stop streaming.
 	Until string
 
-	// Filter for events. For example:
+	// Filter for events, see EventFilters. For example:
 	//  map[string][]string{"type": {"container"}, "event": {"start", "die"}}
 	// will return events when container was started and stopped or killed
 	//
@@ -44,7 +44,7 @@ type EventsOptions struct {
 	//  scope= local or swarm
 	//  secret=<string> secret name or ID
 	//  service=<string> service name or ID
-	//  type=<string> container, image, volume, network, daemon, plugin, node, service, secret or config
+	//  type=<string> container, image, volume, network, daemon, plugin, node, service, secret, config or builder
 	//  volume=<string> volume name
 	Filters map[string][]string
 }
@@ -67,6 +67,9 @@ type APIEvents struct {
 	Type   string   `json:"type,omitempty"`
 	Actor  APIActor `json:"actor,omitempty"`
 
+	// Scope is either EventScopeLocal or EventScopeSwarm, since API 1.30.
+	Scope string `json:"scope,omitempty"`
+
 	// Old API fields for < 1.22
 	Status string `json:"status,omitempty"`
 	ID     string `json:"id,omitempty"`
@@ -93,6 +96,7 @@ type eventMonitoringState struct {
 	C         chan *APIEvents
 	errC      chan error
 	listeners []chan<- *APIEvents
+	buffered  map[chan<- *APIEvents]*bufferedEventListener
 	closeConn func()
 }
 
@@ -166,6 +170,21 @@ func (eventState *eventMonitoringState) addListener(listener chan<- *APIEvents)
 	return nil
 }
 
+func (eventState *eventMonitoringState) addBufferedListener(listener chan<- *APIEvents, opts EventListenerOptions) error {
+	eventState.Lock()
+	defer eventState.Unlock()
+	if listenerExists(listener, &eventState.listeners) {
+		return ErrListenerAlreadyExists
+	}
+	if eventState.buffered == nil {
+		eventState.buffered = make(map[chan<- *APIEvents]*bufferedEventListener)
+	}
+	eventState.Add(1)
+	eventState.listeners = append(eventState.listeners, listener)
+	eventState.buffered[listener] = newBufferedEventListener(listener, opts)
+	return nil
+}
+
 func (eventState *eventMonitoringState) removeListener(listener chan<- *APIEvents) error {
 	eventState.Lock()
 	defer eventState.Unlock()
@@ -177,6 +196,10 @@ func (eventState *eventMonitoringState) removeListener(listener chan<- *APIEvent
 			}
 		}
 		eventState.listeners = newListeners
+		if buffered, ok := eventState.buffered[listener]; ok {
+			buffered.close()
+			delete(eventState.buffered, listener)
+		}
 		eventState.Add(-1)
 	}
 	return nil
@@ -184,6 +207,10 @@ func (eventState *eventMonitoringState) removeListener(listener chan<- *APIEvent
 
 func (eventState *eventMonitoringState) closeListeners() {
 	for _, l := range eventState.listeners {
+		if buffered, ok := eventState.buffered[l]; ok {
+			buffered.close()
+			delete(eventState.buffered, l)
+		}
 		close(l)
 		eventState.Add(-1)
 	}
@@ -213,7 +240,7 @@ func (eventState *eventMonitoringState) enableEventMonitoring(c *Client, opts Ev
 		atomic.StoreInt64(&eventState.lastSeen, 0)
 		eventState.C = make(chan *APIEvents, 100)
 		eventState.errC = make(chan error, 1)
-		go eventState.monitorEvents(c, opts)
+		c.goInternal(func() { eventState.monitorEvents(c, opts) })
 	}
 	return nil
 }
@@ -246,7 +273,7 @@ func (eventState *eventMonitoringState) monitorEvents(c *Client, opts EventsOpti
 	)
 
 	var err error
-	for i := time.Duration(0); i < noListenersMaxTries && eventState.noListeners(); i++ {
+	for i := time.Duration(0); i < noListenersMaxTries && eventState.noListeners() && !c.lifecycle.isClosed(); i++ {
 		time.Sleep(10 * time.Millisecond)
 	}
 
@@ -281,7 +308,7 @@ func (eventState *eventMonitoringState) monitorEvents(c *Client, opts EventsOpti
 				eventState.disableEventMonitoring()
 				return
 			} else if err != nil {
-				defer func() { go eventState.monitorEvents(c, opts) }()
+				defer c.goInternal(func() { eventState.monitorEvents(c, opts) })
 				return
 			}
 		case <-timeout:
@@ -336,6 +363,10 @@ func (eventState *eventMonitoringState) sendEvent(event *APIEvents) {
 		}
 
 		for _, listener := range eventState.listeners {
+			if buffered, ok := eventState.buffered[listener]; ok {
+				buffered.send(event)
+				continue
+			}
 			select {
 			case listener <- event:
 			default:
@@ -377,26 +408,40 @@ func (c *Client) eventHijack(opts EventsOptions, startTime int64, eventChan chan
 	if err != nil {
 		return nil, err
 	}
+	keepRunning := int32(1)
+	release, err := c.track(func() {
+		atomic.StoreInt32(&keepRunning, 0)
+		dial.Close()
+	})
+	if err != nil {
+		dial.Close()
+		return nil, err
+	}
 	//lint:ignore SA1019 the alternative doesn't quite work, so keep using the deprecated thing.
 	conn := httputil.NewClientConn(dial, nil)
 	req, err := http.NewRequest(http.MethodGet, uri, nil)
 	if err != nil {
+		release()
 		return nil, err
 	}
 	res, err := conn.Do(req)
 	if err != nil {
+		release()
 		return nil, err
 	}
 
-	keepRunning := int32(1)
 	//lint:ignore SA1019 the alternative doesn't quite work, so keep using the deprecated thing.
 	go func(res *http.Response, conn *httputil.ClientConn) {
+		defer release()
 		defer conn.Close()
 		defer res.Body.Close()
 		decoder := json.NewDecoder(res.Body)
 		for atomic.LoadInt32(&keepRunning) == 1 {
 			var event APIEvents
 			if err := decoder.Decode(&event); err != nil {
+				if atomic.LoadInt32(&keepRunning) == 0 {
+					break
+				}
 				if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
 					c.eventMonitor.RLock()
 					if c.eventMonitor.enabled && c.eventMonitor.C == eventChan {
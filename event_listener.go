@@ -0,0 +1,152 @@
+package docker
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultEventListenerBufferSize is the number of events buffered for a
+// listener added with AddBufferedEventListener when BufferSize is zero.
+const DefaultEventListenerBufferSize = 100
+
+// EventOverflowPolicy defines what happens to an event that doesn't fit in
+// the buffer of a listener.
+type EventOverflowPolicy int
+
+const (
+	// EventOverflowDropNewest discards the new event.
+	EventOverflowDropNewest EventOverflowPolicy = iota
+
+	// EventOverflowDropOldest discards the oldest buffered event, making
+	// room for the new one.
+	EventOverflowDropOldest
+
+	// EventOverflowBlock waits up to BlockTimeout for room in the buffer,
+	// then discards the new event. It delays the delivery of events to
+	// all the listeners while waiting.
+	EventOverflowBlock
+)
+
+// EventListenerOptions specify parameters to the AddBufferedEventListener
+// function.
+type EventListenerOptions struct {
+	// EventsOptions is used to connect to the daemon, when the listener is
+	// the one starting the event monitor.
+	EventsOptions
+
+	// BufferSize is the number of events buffered for the listener, when
+	// it doesn't keep up with the events. It defaults to
+	// DefaultEventListenerBufferSize.
+	BufferSize int
+
+	Overflow     EventOverflowPolicy
+	BlockTimeout time.Duration
+
+	// OnDrop, when set, is called with each event discarded because the
+	// buffer of the listener is full. It's called by the goroutine
+	// dispatching events, so it must not block nor call any event listener
+	// method of the client.
+	OnDrop func(*APIEvents)
+}
+
+// AddBufferedEventListener adds a new listener to events in the Docker API,
+// like AddEventListenerWithOptions, with a buffer between the monitor and
+// the listener: a listener that stalls only loses its own events, according
+// to the overflow policy in opts, without delaying the other listeners.
+//
+// RemoveEventListener discards the events still buffered for the listener.
+func (c *Client) AddBufferedEventListener(listener chan<- *APIEvents, opts EventListenerOptions) error {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = DefaultEventListenerBufferSize
+	}
+	if !c.eventMonitor.isEnabled() {
+		err := c.eventMonitor.enableEventMonitoring(c, opts.EventsOptions)
+		if err != nil {
+			return err
+		}
+	}
+	return c.eventMonitor.addBufferedListener(listener, opts)
+}
+
+// bufferedEventListener forwards events from its queue to a listener, in a
+// dedicated goroutine.
+type bufferedEventListener struct {
+	listener chan<- *APIEvents
+	queue    chan *APIEvents
+	opts     EventListenerOptions
+	stop     chan struct{}
+	stopped  sync.WaitGroup
+}
+
+func newBufferedEventListener(listener chan<- *APIEvents, opts EventListenerOptions) *bufferedEventListener {
+	l := &bufferedEventListener{
+		listener: listener,
+		queue:    make(chan *APIEvents, opts.BufferSize),
+		opts:     opts,
+		stop:     make(chan struct{}),
+	}
+	l.stopped.Add(1)
+	go l.forward()
+	return l
+}
+
+func (l *bufferedEventListener) forward() {
+	defer l.stopped.Done()
+	for {
+		select {
+		case event := <-l.queue:
+			select {
+			case l.listener <- event:
+			case <-l.stop:
+				return
+			}
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// send queues event, applying the overflow policy when the queue is full.
+func (l *bufferedEventListener) send(event *APIEvents) {
+	select {
+	case l.queue <- event:
+		return
+	default:
+	}
+	switch l.opts.Overflow {
+	case EventOverflowDropOldest:
+		select {
+		case oldest := <-l.queue:
+			l.drop(oldest)
+		default:
+		}
+		select {
+		case l.queue <- event:
+		default:
+			l.drop(event)
+		}
+	case EventOverflowBlock:
+		timer := time.NewTimer(l.opts.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case l.queue <- event:
+		case <-timer.C:
+			l.drop(event)
+		}
+	default:
+		l.drop(event)
+	}
+}
+
+func (l *bufferedEventListener) drop(event *APIEvents) {
+	if l.opts.OnDrop != nil {
+		l.opts.OnDrop(event)
+	}
+}
+
+// close stops the forwarding goroutine, discarding the queued events. Once
+// it returns, the listener channel can be safely closed.
+func (l *bufferedEventListener) close() {
+	close(l.stop)
+	l.stopped.Wait()
+}
@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBufferedEventListenerOverflow(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		policy  EventOverflowPolicy
+		queued  []string
+		dropped []string
+	}{
+		{EventOverflowDropNewest, []string{"1", "2"}, []string{"3", "4"}},
+		{EventOverflowDropOldest, []string{"3", "4"}, []string{"1", "2"}},
+		{EventOverflowBlock, []string{"1", "2"}, []string{"3", "4"}},
+	}
+	for _, test := range tests {
+		var dropped []string
+		// the forwarding goroutine isn't started, so that the queue
+		// fills up deterministically.
+		l := &bufferedEventListener{
+			queue: make(chan *APIEvents, 2),
+			opts: EventListenerOptions{
+				Overflow:     test.policy,
+				BlockTimeout: time.Millisecond,
+				OnDrop:       func(e *APIEvents) { dropped = append(dropped, e.ID) },
+			},
+		}
+		for _, id := range []string{"1", "2", "3", "4"} {
+			l.send(&APIEvents{ID: id})
+		}
+		close(l.queue)
+		var queued []string
+		for e := range l.queue {
+			queued = append(queued, e.ID)
+		}
+		if !reflect.DeepEqual(queued, test.queued) || !reflect.DeepEqual(dropped, test.dropped) {
+			t.Errorf("policy %d: want queued %v and dropped %v. Got %v and %v.", test.policy, test.queued, test.dropped, queued, dropped)
+		}
+	}
+}
+
+func TestAddBufferedEventListener(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 1; i <= 5; i++ {
+			w.Write([]byte(`{"Action":"start","Type":"container","Actor":{"ID":"c` + strconv.Itoa(i) + `"},"time":` + strconv.Itoa(i) + `}`))
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	var mu sync.Mutex
+	var dropped []string
+	stalled := make(chan *APIEvents)
+	err = client.AddBufferedEventListener(stalled, EventListenerOptions{
+		BufferSize: 2,
+		Overflow:   EventOverflowDropOldest,
+		OnDrop: func(e *APIEvents) {
+			mu.Lock()
+			dropped = append(dropped, e.Actor.ID)
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.RemoveEventListener(stalled)
+	fast := make(chan *APIEvents, 10)
+	if err := client.AddEventListener(fast); err != nil {
+		t.Fatal(err)
+	}
+	defer client.RemoveEventListener(fast)
+	for i := 1; i <= 5; i++ {
+		select {
+		case e := <-fast:
+			if want := "c" + strconv.Itoa(i); e.Actor.ID != want {
+				t.Errorf("AddBufferedEventListener: wrong event. Want %q. Got %q.", want, e.Actor.ID)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events: the stalled listener blocked the others")
+		}
+	}
+	// the stalled listener holds at most one event in the forwarding
+	// goroutine and two in the buffer, the oldest of the others are dropped.
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dropped) < 2 || len(dropped) > 3 || dropped[len(dropped)-1] == "c5" {
+		t.Errorf("AddBufferedEventListener: wrong dropped events: %v.", dropped)
+	}
+}
@@ -0,0 +1,84 @@
+package docker
+
+// Types of the events sent by the daemon, found in APIEvents.Type and used in
+// the "type" filter.
+const (
+	EventTypeContainer = "container"
+	EventTypeImage     = "image"
+	EventTypeVolume    = "volume"
+	EventTypeNetwork   = "network"
+	EventTypeDaemon    = "daemon"
+	EventTypePlugin    = "plugin"
+	EventTypeBuilder   = "builder"
+	EventTypeNode      = "node"
+	EventTypeService   = "service"
+	EventTypeSecret    = "secret"
+	EventTypeConfig    = "config"
+)
+
+// Scopes of the events sent by the daemon, found in APIEvents.Scope and used
+// in the "scope" filter. Events of swarm objects, like services and nodes,
+// have the swarm scope.
+const (
+	EventScopeLocal = "local"
+	EventScopeSwarm = "swarm"
+)
+
+// EventFilters describes the filters supported by the events endpoint, see
+// EventsOptions. Empty fields are ignored, and values in the same field are
+// alternatives: Types: []string{EventTypeService, EventTypeNode} matches the
+// events of both services and nodes.
+type EventFilters struct {
+	// Types are event types, like EventTypeContainer.
+	Types []string
+
+	// Actions are event actions, like "create" or "die".
+	Actions []string
+
+	// Scope is either EventScopeLocal or EventScopeSwarm.
+	Scope string
+
+	// Labels filters by the labels of containers, images, volumes and
+	// networks.
+	Labels LabelFilter
+
+	// The remaining fields hold names or IDs of the objects of the events.
+	Containers []string
+	Images     []string
+	Volumes    []string
+	Networks   []string
+	Daemons    []string
+	Plugins    []string
+	Nodes      []string
+	Services   []string
+	Secrets    []string
+	Configs    []string
+}
+
+// Filters returns the filters in the format expected by
+// EventsOptions.Filters and the Watch*Events functions.
+func (f EventFilters) Filters() map[string][]string {
+	filters := make(map[string][]string)
+	for key, values := range map[string][]string{
+		"type":      f.Types,
+		"event":     f.Actions,
+		"container": f.Containers,
+		"image":     f.Images,
+		"volume":    f.Volumes,
+		"network":   f.Networks,
+		"daemon":    f.Daemons,
+		"plugin":    f.Plugins,
+		"node":      f.Nodes,
+		"service":   f.Services,
+		"secret":    f.Secrets,
+		"config":    f.Configs,
+	} {
+		if len(values) > 0 {
+			filters[key] = values
+		}
+	}
+	if f.Scope != "" {
+		filters["scope"] = []string{f.Scope}
+	}
+	return f.Labels.AddTo(filters)
+}
@@ -0,0 +1,381 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrEventMonitorStopped is the error sent by the Watch*Events functions when
+// the stream of events stops, for example because the connection to the
+// daemon was lost.
+var ErrEventMonitorStopped = errors.New("event monitor stopped")
+
+// ContainerEvent is a container event, with the attributes of the actor
+// decoded.
+type ContainerEvent struct {
+	Action string
+	ID     string
+	Name   string
+	Image  string
+
+	// ExitCode is set in "die" events.
+	ExitCode int
+
+	// Signal is set in "kill" events.
+	Signal string
+
+	// Labels holds the labels of the container.
+	Labels map[string]string
+
+	Time time.Time
+	Raw  *APIEvents
+}
+
+// ImageEvent is an image event, with the attributes of the actor decoded.
+type ImageEvent struct {
+	Action string
+	ID     string
+	Name   string
+
+	// Labels holds the labels of the image.
+	Labels map[string]string
+
+	Time time.Time
+	Raw  *APIEvents
+}
+
+// NetworkEvent is a network event, with the attributes of the actor decoded.
+type NetworkEvent struct {
+	Action string
+	ID     string
+	Name   string
+	Driver string
+
+	// Container is the ID of the container connected to or disconnected
+	// from the network in "connect" and "disconnect" events.
+	Container string
+
+	Time time.Time
+	Raw  *APIEvents
+}
+
+// VolumeEvent is a volume event, with the attributes of the actor decoded.
+type VolumeEvent struct {
+	Action string
+
+	// Name is the name of the volume, which is also the ID of the actor.
+	Name   string
+	Driver string
+
+	// Container and Destination are the ID of the container the volume
+	// is mounted in or unmounted from, and the mount point, in "mount"
+	// and "unmount" events.
+	Container   string
+	Destination string
+
+	Time time.Time
+	Raw  *APIEvents
+}
+
+// EventChange is the change of an attribute of a swarm object reported in
+// "update" events, like the state of a node going from "down" to "ready".
+type EventChange struct {
+	Old string
+	New string
+}
+
+// ServiceEvent is a service event, with the attributes of the actor decoded.
+type ServiceEvent struct {
+	Action string
+	ID     string
+	Name   string
+
+	// Changes holds the attributes changed by "update" events, like
+	// "image" and "replicas", by name.
+	Changes map[string]EventChange
+
+	Time time.Time
+	Raw  *APIEvents
+}
+
+// NodeEvent is a swarm node event, with the attributes of the actor decoded.
+type NodeEvent struct {
+	Action string
+	ID     string
+	Name   string
+
+	// Changes holds the attributes changed by "update" events, like
+	// "state", "availability" and "role", by name.
+	Changes map[string]EventChange
+
+	Time time.Time
+	Raw  *APIEvents
+}
+
+// DaemonEvent is a daemon event, like "reload", with the attributes of the
+// actor decoded.
+type DaemonEvent struct {
+	Action string
+	ID     string
+	Name   string
+
+	// Attributes holds the other attributes of the event, like the
+	// configuration of the daemon in "reload" events.
+	Attributes map[string]string
+
+	Time time.Time
+	Raw  *APIEvents
+}
+
+// ObjectEvent is an event of any type, like secret, config, plugin and
+// builder events, with the common attributes of the actor decoded.
+type ObjectEvent struct {
+	Type   string
+	Action string
+	ID     string
+	Name   string
+
+	// Attributes holds the other attributes of the event.
+	Attributes map[string]string
+
+	Time time.Time
+	Raw  *APIEvents
+}
+
+// WatchContainerEvents subscribes to container events matching the given
+// filters (see EventsOptions), until ctx is done or the client is closed.
+// Errors are sent on the second channel, after which both channels are
+// closed.
+//
+// Each watch has its own connection to the daemon, independent from the
+// event listeners of the client, so the filters are always applied by the
+// daemon. The subscription is active once the function returns.
+func (c *Client) WatchContainerEvents(ctx context.Context, filters map[string][]string) (<-chan ContainerEvent, <-chan error) {
+	return watchEvents(ctx, c, EventTypeContainer, filters, func(event *APIEvents) ContainerEvent {
+		attrs := event.Actor.Attributes
+		exitCode, _ := strconv.Atoi(attrs["exitCode"])
+		return ContainerEvent{
+			Action:   event.Action,
+			ID:       event.Actor.ID,
+			Name:     attrs["name"],
+			Image:    attrs["image"],
+			ExitCode: exitCode,
+			Signal:   attrs["signal"],
+			Labels:   eventLabels(attrs, "name", "image", "exitCode", "signal"),
+			Time:     eventTime(event),
+			Raw:      event,
+		}
+	})
+}
+
+// WatchImageEvents subscribes to image events matching the given filters,
+// until ctx is done. See WatchContainerEvents for details.
+func (c *Client) WatchImageEvents(ctx context.Context, filters map[string][]string) (<-chan ImageEvent, <-chan error) {
+	return watchEvents(ctx, c, EventTypeImage, filters, func(event *APIEvents) ImageEvent {
+		attrs := event.Actor.Attributes
+		return ImageEvent{
+			Action: event.Action,
+			ID:     event.Actor.ID,
+			Name:   attrs["name"],
+			Labels: eventLabels(attrs, "name"),
+			Time:   eventTime(event),
+			Raw:    event,
+		}
+	})
+}
+
+// WatchNetworkEvents subscribes to network events matching the given
+// filters, until ctx is done. See WatchContainerEvents for details.
+func (c *Client) WatchNetworkEvents(ctx context.Context, filters map[string][]string) (<-chan NetworkEvent, <-chan error) {
+	return watchEvents(ctx, c, EventTypeNetwork, filters, func(event *APIEvents) NetworkEvent {
+		attrs := event.Actor.Attributes
+		return NetworkEvent{
+			Action:    event.Action,
+			ID:        event.Actor.ID,
+			Name:      attrs["name"],
+			Driver:    attrs["type"],
+			Container: attrs["container"],
+			Time:      eventTime(event),
+			Raw:       event,
+		}
+	})
+}
+
+// WatchVolumeEvents subscribes to volume events matching the given filters,
+// until ctx is done. See WatchContainerEvents for details.
+func (c *Client) WatchVolumeEvents(ctx context.Context, filters map[string][]string) (<-chan VolumeEvent, <-chan error) {
+	return watchEvents(ctx, c, EventTypeVolume, filters, func(event *APIEvents) VolumeEvent {
+		attrs := event.Actor.Attributes
+		return VolumeEvent{
+			Action:      event.Action,
+			Name:        event.Actor.ID,
+			Driver:      attrs["driver"],
+			Container:   attrs["container"],
+			Destination: attrs["destination"],
+			Time:        eventTime(event),
+			Raw:         event,
+		}
+	})
+}
+
+// WatchServiceEvents subscribes to swarm service events matching the given
+// filters, until ctx is done. See WatchContainerEvents for details.
+func (c *Client) WatchServiceEvents(ctx context.Context, filters map[string][]string) (<-chan ServiceEvent, <-chan error) {
+	return watchEvents(ctx, c, EventTypeService, filters, func(event *APIEvents) ServiceEvent {
+		return ServiceEvent{
+			Action:  event.Action,
+			ID:      event.Actor.ID,
+			Name:    event.Actor.Attributes["name"],
+			Changes: eventChanges(event.Actor.Attributes),
+			Time:    eventTime(event),
+			Raw:     event,
+		}
+	})
+}
+
+// WatchNodeEvents subscribes to swarm node events matching the given
+// filters, until ctx is done. See WatchContainerEvents for details.
+func (c *Client) WatchNodeEvents(ctx context.Context, filters map[string][]string) (<-chan NodeEvent, <-chan error) {
+	return watchEvents(ctx, c, EventTypeNode, filters, func(event *APIEvents) NodeEvent {
+		return NodeEvent{
+			Action:  event.Action,
+			ID:      event.Actor.ID,
+			Name:    event.Actor.Attributes["name"],
+			Changes: eventChanges(event.Actor.Attributes),
+			Time:    eventTime(event),
+			Raw:     event,
+		}
+	})
+}
+
+// WatchDaemonEvents subscribes to daemon events matching the given filters,
+// until ctx is done. See WatchContainerEvents for details.
+func (c *Client) WatchDaemonEvents(ctx context.Context, filters map[string][]string) (<-chan DaemonEvent, <-chan error) {
+	return watchEvents(ctx, c, EventTypeDaemon, filters, func(event *APIEvents) DaemonEvent {
+		return DaemonEvent{
+			Action:     event.Action,
+			ID:         event.Actor.ID,
+			Name:       event.Actor.Attributes["name"],
+			Attributes: eventLabels(event.Actor.Attributes, "name"),
+			Time:       eventTime(event),
+			Raw:        event,
+		}
+	})
+}
+
+// WatchObjectEvents subscribes to the events of the given type, like
+// EventTypeSecret or EventTypeBuilder, matching the given filters, until ctx
+// is done. See WatchContainerEvents for details.
+func (c *Client) WatchObjectEvents(ctx context.Context, eventType string, filters map[string][]string) (<-chan ObjectEvent, <-chan error) {
+	return watchEvents(ctx, c, eventType, filters, func(event *APIEvents) ObjectEvent {
+		return ObjectEvent{
+			Type:       event.Type,
+			Action:     event.Action,
+			ID:         event.Actor.ID,
+			Name:       event.Actor.Attributes["name"],
+			Attributes: eventLabels(event.Actor.Attributes, "name"),
+			Time:       eventTime(event),
+			Raw:        event,
+		}
+	})
+}
+
+func watchEvents[T any](ctx context.Context, c *Client, eventType string, filters map[string][]string, convert func(*APIEvents) T) (<-chan T, <-chan error) {
+	events := make(chan T)
+	errs := make(chan error, 1)
+	opts := EventsOptions{Filters: map[string][]string{"type": {eventType}}}
+	for key, values := range filters {
+		if key != "type" {
+			opts.Filters[key] = values
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	release, err := c.track(cancel)
+	if err != nil {
+		cancel()
+		errs <- err
+		close(events)
+		close(errs)
+		return events, errs
+	}
+	resp, err := c.do(http.MethodGet, "/events?"+queryString(opts), doOptions{context: ctx})
+	if err != nil {
+		release()
+		cancel()
+		errs <- err
+		close(events)
+		close(errs)
+		return events, errs
+	}
+	go func() {
+		defer close(errs)
+		defer close(events)
+		defer release()
+		defer cancel()
+		defer resp.Body.Close()
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var event APIEvents
+			if err := decoder.Decode(&event); err != nil {
+				if ctx.Err() == nil {
+					errs <- ErrEventMonitorStopped
+				}
+				return
+			}
+			transformEvent(&event)
+			// events of daemons before API 1.22 have no type to filter on
+			if event.Type != eventType {
+				continue
+			}
+			select {
+			case events <- convert(&event):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, errs
+}
+
+// eventLabels returns the attributes of an event actor that aren't in the
+// given list of known attributes, which are the labels of the actor.
+func eventLabels(attrs map[string]string, known ...string) map[string]string {
+	labels := make(map[string]string, len(attrs))
+	for key, value := range attrs {
+		labels[key] = value
+	}
+	for _, key := range known {
+		delete(labels, key)
+	}
+	return labels
+}
+
+// eventChanges returns the changes reported in the attributes of an event
+// actor as pairs of "<name>.old" and "<name>.new" attributes.
+func eventChanges(attrs map[string]string) map[string]EventChange {
+	changes := make(map[string]EventChange)
+	for key, value := range attrs {
+		if name, ok := strings.CutSuffix(key, ".old"); ok {
+			change := changes[name]
+			change.Old = value
+			changes[name] = change
+		} else if name, ok := strings.CutSuffix(key, ".new"); ok {
+			change := changes[name]
+			change.New = value
+			changes[name] = change
+		}
+	}
+	return changes
+}
+
+func eventTime(event *APIEvents) time.Time {
+	if event.TimeNano != 0 {
+		return time.Unix(0, event.TimeNano)
+	}
+	return time.Unix(event.Time, 0)
+}
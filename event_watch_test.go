@@ -0,0 +1,309 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatchContainerEvents(t *testing.T) {
+	t.Parallel()
+	response := `{"action":"pull","type":"image","actor":{"id":"busybox:latest","attributes":{"name":"busybox"}},"time":1442421700}
+{"action":"die","type":"container","actor":{"id":"5745704abe9caa5","attributes":{"image":"busybox","name":"web","exitCode":"137","app":"web"}},"time":1442421716,"timeNano":1442421716853979870}
+`
+	query := make(chan string, 1)
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query <- r.URL.Query().Get("filters")
+		w.Write([]byte(response))
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, errs := client.WatchContainerEvents(ctx, LabelFilter{"app": "web"}.Filters())
+	var event ContainerEvent
+	select {
+	case event = <-events:
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchContainerEvents: timed out waiting for event")
+	}
+	expectedFilters := `{"label":["app=web"],"type":["container"]}`
+	if got := <-query; got != expectedFilters {
+		t.Errorf("WatchContainerEvents: wrong filters. Want %q. Got %q.", expectedFilters, got)
+	}
+	expected := ContainerEvent{
+		Action:   "die",
+		ID:       "5745704abe9caa5",
+		Name:     "web",
+		Image:    "busybox",
+		ExitCode: 137,
+		Labels:   map[string]string{"app": "web"},
+		Time:     time.Unix(0, 1442421716853979870),
+		Raw:      event.Raw,
+	}
+	if !reflect.DeepEqual(event, expected) {
+		t.Errorf("WatchContainerEvents: wrong event.\nWant %#v.\nGot  %#v.", expected, event)
+	}
+	cancel()
+	for range events {
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("WatchContainerEvents: unexpected error after cancellation: %v", err)
+	}
+}
+
+func TestWatchContainerEventsWithListener(t *testing.T) {
+	t.Parallel()
+	queries := make(chan string, 10)
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.Query().Get("filters")
+		w.Write([]byte(`{"action":"start","type":"container","actor":{"id":"5745704abe9caa5","attributes":{"name":"web"}},"time":1442421716}` + "\n"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	defer client.Close()
+	listener := make(chan *APIEvents, 10)
+	if err := client.AddEventListenerWithOptions(EventsOptions{Filters: map[string][]string{"type": {"image"}}}, listener); err != nil {
+		t.Fatal(err)
+	}
+	defer client.RemoveEventListener(listener)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, errs := client.WatchContainerEvents(ctx, map[string][]string{"container": {"web"}})
+	select {
+	case event := <-events:
+		if event.ID != "5745704abe9caa5" {
+			t.Errorf("WatchContainerEvents: wrong event: %#v", event)
+		}
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchContainerEvents: timed out waiting for event")
+	}
+	expectedFilters := `{"container":["web"],"type":["container"]}`
+	for {
+		select {
+		case got := <-queries:
+			if got == expectedFilters {
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("WatchContainerEvents: no request with filters %q", expectedFilters)
+		}
+	}
+}
+
+func TestWatchEventsClientClose(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	events, errs := client.WatchContainerEvents(context.Background(), nil)
+	closed := make(chan struct{})
+	go func() {
+		client.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close: timed out waiting for the watch to stop")
+	}
+	for range events {
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("WatchContainerEvents: unexpected error after Close: %v", err)
+	}
+}
+
+func TestWatchNetworkEventsDecode(t *testing.T) {
+	t.Parallel()
+	event := &APIEvents{
+		Action: "connect",
+		Type:   "network",
+		Actor: APIActor{
+			ID:         "7dc8ac97d5d2",
+			Attributes: map[string]string{"container": "3e1b8e6b1a9f", "name": "bridge", "type": "bridge"},
+		},
+		Time: 1442421716,
+	}
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"action":"connect","type":"network","actor":{"id":"7dc8ac97d5d2","attributes":{"container":"3e1b8e6b1a9f","name":"bridge","type":"bridge"}},"time":1442421716}`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, errs := client.WatchNetworkEvents(ctx, nil)
+	select {
+	case got := <-events:
+		expected := NetworkEvent{
+			Action:    "connect",
+			ID:        "7dc8ac97d5d2",
+			Name:      "bridge",
+			Driver:    "bridge",
+			Container: "3e1b8e6b1a9f",
+			Time:      time.Unix(event.Time, 0),
+			Raw:       got.Raw,
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("WatchNetworkEvents: wrong event.\nWant %#v.\nGot  %#v.", expected, got)
+		}
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchNetworkEvents: timed out waiting for event")
+	}
+}
+
+func TestWatchServiceEvents(t *testing.T) {
+	t.Parallel()
+	response := `{"action":"create","type":"volume","actor":{"id":"data","attributes":{"driver":"local"}},"scope":"local","time":1442421700}
+{"action":"update","type":"service","actor":{"id":"ozkvd7k5nj4v","attributes":{"name":"web","image.old":"nginx:1.24","image.new":"nginx:1.25","replicas.new":"3"}},"scope":"swarm","time":1442421716}
+`
+	query := make(chan string, 1)
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query <- r.URL.Query().Get("filters")
+		w.Write([]byte(response))
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+	client, _ := NewClient(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	filters := EventFilters{Actions: []string{"update"}, Scope: EventScopeSwarm}.Filters()
+	events, errs := client.WatchServiceEvents(ctx, filters)
+	var event ServiceEvent
+	select {
+	case event = <-events:
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchServiceEvents: timed out waiting for event")
+	}
+	expectedFilters := `{"event":["update"],"scope":["swarm"],"type":["service"]}`
+	if got := <-query; got != expectedFilters {
+		t.Errorf("WatchServiceEvents: wrong filters. Want %q. Got %q.", expectedFilters, got)
+	}
+	expected := ServiceEvent{
+		Action: "update",
+		ID:     "ozkvd7k5nj4v",
+		Name:   "web",
+		Changes: map[string]EventChange{
+			"image":    {Old: "nginx:1.24", New: "nginx:1.25"},
+			"replicas": {New: "3"},
+		},
+		Time: time.Unix(1442421716, 0),
+		Raw:  event.Raw,
+	}
+	if !reflect.DeepEqual(event, expected) {
+		t.Errorf("WatchServiceEvents: wrong event.\nWant %#v.\nGot  %#v.", expected, event)
+	}
+	if event.Raw.Scope != EventScopeSwarm {
+		t.Errorf("WatchServiceEvents: wrong scope. Want %q. Got %q.", EventScopeSwarm, event.Raw.Scope)
+	}
+}
+
+func TestWatchObjectEvents(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"action":"create","type":"secret","actor":{"id":"ktnbjxoalbkvbvedmg1urrz8h","attributes":{"name":"db-password"}},"scope":"swarm","time":1442421716}`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+	client, _ := NewClient(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, errs := client.WatchObjectEvents(ctx, EventTypeSecret, nil)
+	select {
+	case got := <-events:
+		expected := ObjectEvent{
+			Type:       EventTypeSecret,
+			Action:     "create",
+			ID:         "ktnbjxoalbkvbvedmg1urrz8h",
+			Name:       "db-password",
+			Attributes: map[string]string{},
+			Time:       time.Unix(1442421716, 0),
+			Raw:        got.Raw,
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("WatchObjectEvents: wrong event.\nWant %#v.\nGot  %#v.", expected, got)
+		}
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchObjectEvents: timed out waiting for event")
+	}
+}
+
+func TestEventFilters(t *testing.T) {
+	t.Parallel()
+	filters := EventFilters{
+		Types:    []string{EventTypeNode, EventTypeService},
+		Services: []string{"web"},
+		Nodes:    []string{"node-1"},
+		Labels:   LabelFilter{"app": "web"},
+	}.Filters()
+	expected := map[string][]string{
+		"type":    {"node", "service"},
+		"service": {"web"},
+		"node":    {"node-1"},
+		"label":   {"app=web"},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("EventFilters: wrong filters. Want %#v. Got %#v.", expected, filters)
+	}
+	if filters := (EventFilters{}).Filters(); len(filters) != 0 {
+		t.Errorf("EventFilters: empty filters should be empty, got %#v", filters)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 // Exec is the type representing a `docker exec` instance and containing the
@@ -37,6 +38,9 @@ type CreateExecOptions struct {
 	AttachStderr bool            `json:"AttachStderr,omitempty" yaml:"AttachStderr,omitempty" toml:"AttachStderr,omitempty"`
 	Tty          bool            `json:"Tty,omitempty" yaml:"Tty,omitempty" toml:"Tty,omitempty"`
 	Privileged   bool            `json:"Privileged,omitempty" yaml:"Privileged,omitempty" toml:"Privileged,omitempty"`
+
+	// ConsoleSize is the initial size of the TTY, as height and width.
+	ConsoleSize *[2]uint `json:"ConsoleSize,omitempty" yaml:"ConsoleSize,omitempty" toml:"ConsoleSize,omitempty"`
 }
 
 // CreateExec sets up an exec instance in a running container `id`, returning the exec
@@ -44,21 +48,22 @@ type CreateExecOptions struct {
 //
 // See https://goo.gl/60TeBP for more details
 func (c *Client) CreateExec(opts CreateExecOptions) (*Exec, error) {
-	if c.serverAPIVersion == nil {
-		c.checkAPIVersion()
-	}
-	if len(opts.Env) > 0 && c.serverAPIVersion.LessThan(apiVersion125) {
+	serverAPIVersion := c.serverVersion()
+	if len(opts.Env) > 0 && serverAPIVersion.LessThan(apiVersion125) {
 		return nil, errors.New("exec configuration Env is only supported in API#1.25 and above")
 	}
-	if len(opts.WorkingDir) > 0 && c.serverAPIVersion.LessThan(apiVersion135) {
+	if len(opts.WorkingDir) > 0 && serverAPIVersion.LessThan(apiVersion135) {
 		return nil, errors.New("exec configuration WorkingDir is only supported in API#1.35 and above")
 	}
+	if opts.ConsoleSize != nil && serverAPIVersion.LessThan(apiVersion142) {
+		return nil, errors.New("exec configuration ConsoleSize is only supported in API#1.42 and above")
+	}
 	path := fmt.Sprintf("/containers/%s/exec", opts.Container)
 	resp, err := c.do(http.MethodPost, path, doOptions{data: opts, context: opts.Context})
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return nil, &NoSuchContainer{ID: opts.Container}
+			return nil, &NoSuchContainer{ID: opts.Container, Op: "exec", Err: err}
 		}
 		return nil, err
 	}
@@ -92,6 +97,25 @@ type StartExecOptions struct {
 	// to unexpected behavior.
 	Success chan struct{} `json:"-"`
 
+	// WriteTimeout is the maximum duration of a write of InputStream to
+	// the connection. When a write times out, because the peer is gone or
+	// stopped reading, the session stops and Wait returns an error
+	// wrapping os.ErrDeadlineExceeded. Zero means no timeout.
+	WriteTimeout time.Duration `json:"-"`
+
+	// KeepAlive enables TCP keep-alive probes on the connection with the
+	// given period, so a dead peer makes the session fail instead of
+	// hanging. Zero keeps the default of the Dialer.
+	KeepAlive time.Duration `json:"-"`
+
+	// DetachKeys, when set, is a key sequence that ends the session when
+	// read from InputStream, leaving the exec command running, in the
+	// format of AttachToContainerOptions.DetachKeys. Wait returns nil
+	// after detaching. The sequence is detected by the client, as the
+	// daemon only takes it when creating the exec instance, see
+	// CreateExecOptions.DetachKeys.
+	DetachKeys string `json:"-"`
+
 	Context context.Context `json:"-"`
 }
 
@@ -123,6 +147,14 @@ func (c *Client) StartExecNonBlocking(id string, opts StartExecOptions) (CloseWa
 
 	path := fmt.Sprintf("/exec/%s/start", id)
 
+	var detachKeys []byte
+	if opts.DetachKeys != "" {
+		var err error
+		if detachKeys, err = parseDetachKeys(opts.DetachKeys); err != nil {
+			return nil, err
+		}
+	}
+
 	if opts.Detach {
 		resp, err := c.do(http.MethodPost, path, doOptions{data: opts, context: opts.Context})
 		if err != nil {
@@ -143,6 +175,9 @@ func (c *Client) StartExecNonBlocking(id string, opts StartExecOptions) (CloseWa
 		stdout:         opts.OutputStream,
 		stderr:         opts.ErrorStream,
 		data:           opts,
+		writeTimeout:   opts.WriteTimeout,
+		keepAlive:      opts.KeepAlive,
+		detachKeys:     detachKeys,
 	})
 }
 
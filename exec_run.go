@@ -0,0 +1,151 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// DefaultExecMaxOutputSize is the maximum number of bytes of each output
+// stream collected by Exec when ExecSpec.MaxOutputSize is zero.
+const DefaultExecMaxOutputSize = 10 << 20
+
+// execInspectInterval is the interval between two inspections of an exec
+// instance whose output is closed, but which isn't reported as exited yet.
+const execInspectInterval = 50 * time.Millisecond
+
+// ExecSpec describes a command run by Exec.
+type ExecSpec struct {
+	Cmd        []string
+	Env        []string
+	WorkingDir string
+	User       string
+	Privileged bool
+
+	// Tty allocates a TTY for the command, in which case its stdout and
+	// stderr are combined in the stdout of the result.
+	Tty bool
+
+	// Stdin, when set, is sent to the standard input of the command.
+	Stdin io.Reader
+
+	// MaxOutputSize is the maximum number of bytes of each output stream
+	// kept in the result. The rest of the output is discarded and the
+	// result is marked as truncated. It defaults to
+	// DefaultExecMaxOutputSize, a negative value means no limit.
+	MaxOutputSize int64
+
+	// FailOnTruncation makes Exec stop reading the output of the command
+	// once a stream exceeds MaxOutputSize, returning the output collected
+	// so far along with ErrOutputTruncated, instead of discarding the rest
+	// of the output until the command exits. The exit code is -1 in this
+	// case, as Exec doesn't wait for the command.
+	FailOnTruncation bool
+}
+
+// ExecResult is the result of an Exec call.
+type ExecResult struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+
+	// Truncated reports whether some output was discarded because it
+	// exceeded ExecSpec.MaxOutputSize.
+	Truncated bool
+}
+
+// Exec runs a command in a running container and waits for it to finish,
+// capturing its output and exit code. A non-zero exit code isn't considered
+// an error: callers should check ExitCode in the returned result.
+func (c *Client) Exec(ctx context.Context, containerID string, spec ExecSpec) (*ExecResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	exec, err := c.CreateExec(CreateExecOptions{
+		Container:    containerID,
+		Cmd:          spec.Cmd,
+		Env:          spec.Env,
+		WorkingDir:   spec.WorkingDir,
+		User:         spec.User,
+		Privileged:   spec.Privileged,
+		Tty:          spec.Tty,
+		AttachStdin:  spec.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Context:      ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	limit := spec.MaxOutputSize
+	if limit == 0 {
+		limit = DefaultExecMaxOutputSize
+	}
+	stdout := &limitedBuffer{limit: limit, fail: spec.FailOnTruncation}
+	stderr := &limitedBuffer{limit: limit, fail: spec.FailOnTruncation}
+	err = c.StartExec(exec.ID, StartExecOptions{
+		InputStream:  spec.Stdin,
+		OutputStream: stdout,
+		ErrorStream:  stderr,
+		Tty:          spec.Tty,
+		RawTerminal:  spec.Tty,
+		Context:      ctx,
+	})
+	if errors.Is(err, ErrOutputTruncated) {
+		return &ExecResult{
+			Stdout:    stdout.Bytes(),
+			Stderr:    stderr.Bytes(),
+			ExitCode:  -1,
+			Truncated: true,
+		}, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	for {
+		inspect, err := c.InspectExec(exec.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !inspect.Running {
+			return &ExecResult{
+				Stdout:    stdout.Bytes(),
+				Stderr:    stderr.Bytes(),
+				ExitCode:  inspect.ExitCode,
+				Truncated: stdout.truncated || stderr.truncated,
+			}, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(execInspectInterval):
+		}
+	}
+}
+
+// limitedBuffer is a bytes.Buffer that discards writes beyond limit bytes,
+// unless limit is negative. The writes are silently discarded, unless fail
+// is set, in which case they fail with ErrOutputTruncated.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int64
+	fail      bool
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if b.limit >= 0 {
+		if room := b.limit - int64(b.Len()); int64(len(p)) > room {
+			p = p[:max(room, 0)]
+			b.truncated = true
+		}
+	}
+	b.Buffer.Write(p)
+	if b.truncated && b.fail {
+		return len(p), ErrOutputTruncated
+	}
+	return n, nil
+}
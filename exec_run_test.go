@@ -0,0 +1,96 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func newExecTestServer(t *testing.T, stdout string, exitCode int) (*Client, *CreateExecOptions) {
+	t.Helper()
+	var created CreateExecOptions
+	inspections := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/abc/exec", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&created)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id":"exec-1"}`))
+	})
+	mux.HandleFunc("/exec/exec-1/start", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("cannot hijack server connection")
+		}
+		conn, _, err := hj.Hijack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Write([]byte{1, 0, 0, 0, 0, 0, 0, byte(len(stdout))})
+		conn.Write([]byte(stdout))
+		conn.Write([]byte{2, 0, 0, 0, 0, 0, 0, 4})
+		conn.Write([]byte("oops"))
+		conn.Close()
+	})
+	mux.HandleFunc("/exec/exec-1/json", func(w http.ResponseWriter, _ *http.Request) {
+		// the first inspection reports the exec as still running
+		inspections++
+		json.NewEncoder(w).Encode(ExecInspect{ID: "exec-1", Running: inspections == 1, ExitCode: exitCode})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	return client, &created
+}
+
+func TestExec(t *testing.T) {
+	t.Parallel()
+	client, created := newExecTestServer(t, "hello", 2)
+	result, err := client.Exec(context.Background(), "abc", ExecSpec{
+		Cmd:  []string{"sh", "-c", "echo hello; echo oops >&2; exit 2"},
+		User: "nobody",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &ExecResult{Stdout: []byte("hello"), Stderr: []byte("oops"), ExitCode: 2}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Exec: wrong result. Want %#v. Got %#v.", expected, result)
+	}
+	if created.User != "nobody" || !created.AttachStdout || !created.AttachStderr || created.AttachStdin {
+		t.Errorf("Exec: wrong exec options: %#v.", created)
+	}
+}
+
+func TestExecOutputLimit(t *testing.T) {
+	t.Parallel()
+	client, _ := newExecTestServer(t, "hello world", 0)
+	result, err := client.Exec(context.Background(), "abc", ExecSpec{Cmd: []string{"true"}, MaxOutputSize: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result.Stdout) != "hello" || string(result.Stderr) != "oops" || !result.Truncated {
+		t.Errorf("Exec: wrong result: %#v.", result)
+	}
+}
+
+func TestExecFailOnTruncation(t *testing.T) {
+	t.Parallel()
+	client, _ := newExecTestServer(t, "hello world", 0)
+	result, err := client.Exec(context.Background(), "abc", ExecSpec{Cmd: []string{"true"}, MaxOutputSize: 5, FailOnTruncation: true})
+	if !errors.Is(err, ErrOutputTruncated) {
+		t.Fatalf("Exec: wrong error. Want %v. Got %v.", ErrOutputTruncated, err)
+	}
+	if string(result.Stdout) != "hello" || !result.Truncated || result.ExitCode != -1 {
+		t.Errorf("Exec: wrong result: %#v", result)
+	}
+}
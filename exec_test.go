@@ -7,6 +7,8 @@ package docker
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +16,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestExecCreate(t *testing.T) {
@@ -182,6 +185,53 @@ func TestExecCreateWithWorkingDir(t *testing.T) {
 	}
 }
 
+func TestExecCreateWithConsoleSizeErr(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: `{"Id": "4fa6e0f0c678"}`, status: http.StatusOK})
+	config := CreateExecOptions{
+		Container:   "test",
+		Tty:         true,
+		Cmd:         []string{"sh"},
+		ConsoleSize: &[2]uint{24, 80},
+	}
+	_, err := client.CreateExec(config)
+	if err == nil || err.Error() != "exec configuration ConsoleSize is only supported in API#1.42 and above" {
+		t.Error("CreateExec: options contain ConsoleSize for unsupported api version")
+	}
+}
+
+func TestExecCreateWithConsoleSize(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"Id": "4fa6e0f0c678"}`, status: http.StatusOK}
+	u, _ := parseEndpoint("http://localhost:4243", false)
+	testAPIVersion, _ := NewAPIVersion("1.42")
+	client := Client{
+		HTTPClient:             &http.Client{Transport: fakeRT},
+		Dialer:                 &net.Dialer{},
+		endpoint:               "http://localhost:4243",
+		endpointURL:            u,
+		SkipServerVersionCheck: true,
+		serverAPIVersion:       testAPIVersion,
+	}
+	config := CreateExecOptions{
+		Container:   "test",
+		Tty:         true,
+		Cmd:         []string{"sh"},
+		User:        "nobody",
+		ConsoleSize: &[2]uint{24, 80},
+	}
+	if _, err := client.CreateExec(config); err != nil {
+		t.Fatal(err)
+	}
+	var gotBody map[string]any
+	if err := json.NewDecoder(fakeRT.requests[0].Body).Decode(&gotBody); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotBody["ConsoleSize"], []any{24.0, 80.0}) || gotBody["User"] != "nobody" {
+		t.Errorf("CreateExec: wrong body: %#v.", gotBody)
+	}
+}
+
 func TestExecStartDetached(t *testing.T) {
 	t.Parallel()
 	execID := "4fa6e0f0c6786287e131c3852c58a2e01cc697a68231826813597e4994f1d6e2"
@@ -240,6 +290,58 @@ func TestExecStartAndAttach(t *testing.T) {
 	<-success
 }
 
+func TestExecStartDetachKeys(t *testing.T) {
+	t.Parallel()
+	input := make(chan []byte, 1)
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		conn, br, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		// a command that keeps running, only detaching ends the session
+		data, _ := io.ReadAll(br)
+		input <- data
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	errC := make(chan error, 1)
+	go func() {
+		errC <- client.StartExec("4fa6e0f0c678", StartExecOptions{
+			InputStream:  strings.NewReader("ls\x10\x11exit"),
+			OutputStream: io.Discard,
+			RawTerminal:  true,
+			DetachKeys:   "ctrl-p,ctrl-q",
+		})
+	}()
+	select {
+	case err := <-errC:
+		if err != nil {
+			t.Errorf("StartExec: unexpected error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("StartExec: timed out waiting for the session to detach")
+	}
+	if got := string(<-input); got != "ls" {
+		t.Errorf("StartExec: wrong input sent. Want %q. Got %q.", "ls", got)
+	}
+}
+
+func TestExecStartInvalidDetachKeys(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "", status: http.StatusOK})
+	err := client.StartExec("4fa6e0f0c678", StartExecOptions{DetachKeys: "ctrl-p,"})
+	if !errors.Is(err, ErrInvalidDetachKeys) {
+		t.Errorf("StartExec: wrong error. Want %v. Got %v.", ErrInvalidDetachKeys, err)
+	}
+}
+
 func TestExecResize(t *testing.T) {
 	t.Parallel()
 	execID := "4fa6e0f0c6786287e131c3852c58a2e01cc697a68231826813597e4994f1d6e2"
@@ -0,0 +1,131 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GarbageCollectOptions specify parameters to the GarbageCollect function.
+type GarbageCollectOptions struct {
+	// Containers, Images and Volumes select the kinds of resources to
+	// collect: exited containers, dangling images and unused volumes.
+	Containers bool
+	Images     bool
+	Volumes    bool
+
+	// Labels restricts the collection to resources matching the filter.
+	Labels LabelFilter
+
+	// OlderThan restricts the collection to resources created at least
+	// this long ago.
+	OlderThan time.Duration
+
+	// DryRun makes GarbageCollect report what would be removed, without
+	// removing anything.
+	DryRun bool
+
+	Context context.Context
+}
+
+// GarbageCollectReport lists the resources removed by GarbageCollect (or that
+// would be removed, in dry-run mode).
+type GarbageCollectReport struct {
+	Containers []string
+	Images     []string
+	Volumes    []string
+
+	// SpaceReclaimed is the disk space, in bytes, used by the removed
+	// containers and images. The size of volumes isn't accounted for, as
+	// it's not reported when listing them.
+	SpaceReclaimed int64
+}
+
+// GarbageCollect removes exited containers, dangling images and unused
+// volumes matching the label selector and the age threshold in opts. Unlike
+// the prune endpoints, it supports dry runs and reports every removed
+// resource.
+//
+// The anonymous volumes of removed containers are only removed along with them
+// when opts.Volumes is set, and they aren't listed in the report.
+//
+// Failures to remove a resource don't stop the collection: they're returned,
+// joined, along with the report of what was removed.
+func (c *Client) GarbageCollect(opts GarbageCollectOptions) (*GarbageCollectReport, error) {
+	var cutoff time.Time
+	if opts.OlderThan > 0 {
+		cutoff = time.Now().Add(-opts.OlderThan)
+	}
+	var report GarbageCollectReport
+	var errs []error
+	if opts.Containers {
+		containers, err := c.ListContainers(ListContainersOptions{
+			All:     true,
+			Size:    true,
+			Filters: opts.Labels.AddTo(map[string][]string{"status": {StateExited}}),
+			Context: opts.Context,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, container := range containers {
+			if !cutoff.IsZero() && time.Unix(container.Created, 0).After(cutoff) {
+				continue
+			}
+			if !opts.DryRun {
+				err := c.RemoveContainer(RemoveContainerOptions{ID: container.ID, RemoveVolumes: opts.Volumes, Context: opts.Context})
+				if err != nil {
+					errs = append(errs, fmt.Errorf("removing container %s: %w", container.ID, err))
+					continue
+				}
+			}
+			report.Containers = append(report.Containers, container.ID)
+			report.SpaceReclaimed += container.SizeRw
+		}
+	}
+	if opts.Images {
+		images, err := c.ListImages(ListImagesOptions{
+			Filters: opts.Labels.AddTo(map[string][]string{"dangling": {"true"}}),
+			Context: opts.Context,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range images {
+			if !cutoff.IsZero() && time.Unix(image.Created, 0).After(cutoff) {
+				continue
+			}
+			if !opts.DryRun {
+				if err := c.RemoveImageExtended(image.ID, RemoveImageOptions{Context: opts.Context}); err != nil {
+					errs = append(errs, fmt.Errorf("removing image %s: %w", image.ID, err))
+					continue
+				}
+			}
+			report.Images = append(report.Images, image.ID)
+			report.SpaceReclaimed += image.Size
+		}
+	}
+	if opts.Volumes {
+		volumes, err := c.ListVolumes(ListVolumesOptions{
+			Filters: opts.Labels.AddTo(map[string][]string{"dangling": {"true"}}),
+			Context: opts.Context,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, volume := range volumes {
+			if !cutoff.IsZero() && volume.CreatedAt.After(cutoff) {
+				continue
+			}
+			if !opts.DryRun {
+				if err := c.RemoveVolumeWithOptions(RemoveVolumeOptions{Name: volume.Name, Context: opts.Context}); err != nil {
+					errs = append(errs, fmt.Errorf("removing volume %s: %w", volume.Name, err))
+					continue
+				}
+			}
+			report.Volumes = append(report.Volumes, volume.Name)
+		}
+	}
+	return &report, errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+package docker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newGarbageCollectTestClient(t *testing.T) (*Client, func() []string) {
+	t.Helper()
+	old := time.Now().Add(-48 * time.Hour)
+	recent := time.Now().Add(-time.Minute)
+	var mu sync.Mutex
+	var removed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if r.Method == http.MethodDelete {
+			mu.Lock()
+			if r.URL.RawQuery != "" {
+				path += "?" + r.URL.RawQuery
+			}
+			removed = append(removed, path)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		var filters map[string][]string
+		json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)
+		if !reflect.DeepEqual(filters["label"], []string{"app=web"}) {
+			t.Errorf("GarbageCollect: wrong label filter for %s: %v", path, filters["label"])
+		}
+		switch {
+		case strings.HasSuffix(path, "/containers/json"):
+			if filters["status"][0] != "exited" {
+				t.Errorf("GarbageCollect: wrong status filter: %v", filters["status"])
+			}
+			json.NewEncoder(w).Encode([]APIContainers{
+				{ID: "old", Created: old.Unix(), SizeRw: 100},
+				{ID: "recent", Created: recent.Unix(), SizeRw: 1000},
+			})
+		case strings.HasSuffix(path, "/images/json"):
+			json.NewEncoder(w).Encode([]APIImages{
+				{ID: "sha256:old", Created: old.Unix(), Size: 20},
+				{ID: "sha256:recent", Created: recent.Unix(), Size: 2000},
+			})
+		case strings.HasSuffix(path, "/volumes"):
+			json.NewEncoder(w).Encode(map[string][]Volume{"Volumes": {
+				{Name: "old", CreatedAt: old},
+				{Name: "recent", CreatedAt: recent},
+			}})
+		default:
+			http.Error(w, "unexpected request", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	return client, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return removed
+	}
+}
+
+func TestGarbageCollect(t *testing.T) {
+	t.Parallel()
+	client, removed := newGarbageCollectTestClient(t)
+	report, err := client.GarbageCollect(GarbageCollectOptions{
+		Containers: true,
+		Images:     true,
+		Volumes:    true,
+		Labels:     LabelFilter{"app": "web"},
+		OlderThan:  24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &GarbageCollectReport{
+		Containers:     []string{"old"},
+		Images:         []string{"sha256:old"},
+		Volumes:        []string{"old"},
+		SpaceReclaimed: 120,
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("GarbageCollect: wrong report. Want %#v. Got %#v.", expected, report)
+	}
+	expectedRemoved := []string{"/containers/old?v=1", "/images/sha256:old", "/volumes/old"}
+	if got := removed(); !reflect.DeepEqual(got, expectedRemoved) {
+		t.Errorf("GarbageCollect: wrong removals. Want %v. Got %v.", expectedRemoved, got)
+	}
+}
+
+func TestGarbageCollectKeepsVolumes(t *testing.T) {
+	t.Parallel()
+	client, removed := newGarbageCollectTestClient(t)
+	_, err := client.GarbageCollect(GarbageCollectOptions{
+		Containers: true,
+		Labels:     LabelFilter{"app": "web"},
+		OlderThan:  24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRemoved := []string{"/containers/old"}
+	if got := removed(); !reflect.DeepEqual(got, expectedRemoved) {
+		t.Errorf("GarbageCollect: wrong removals. Want %v. Got %v.", expectedRemoved, got)
+	}
+}
+
+func TestGarbageCollectDryRun(t *testing.T) {
+	t.Parallel()
+	client, removed := newGarbageCollectTestClient(t)
+	report, err := client.GarbageCollect(GarbageCollectOptions{
+		Containers: true,
+		Images:     true,
+		Labels:     LabelFilter{"app": "web"},
+		DryRun:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Containers) != 2 || len(report.Images) != 2 || report.Volumes != nil {
+		t.Errorf("GarbageCollect: wrong report: %#v.", report)
+	}
+	if report.SpaceReclaimed != 3120 {
+		t.Errorf("GarbageCollect: wrong space reclaimed. Want 3120. Got %d.", report.SpaceReclaimed)
+	}
+	if got := removed(); len(got) != 0 {
+		t.Errorf("GarbageCollect: dry run removed resources: %v.", got)
+	}
+}
@@ -5,6 +5,7 @@
 package docker
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -36,24 +37,65 @@ type RootFS struct {
 	Layers []string `json:"Layers,omitempty" yaml:"Layers,omitempty" toml:"Layers,omitempty"`
 }
 
+// ImagePlatform describes the platform an image was built for, as defined by
+// the OCI image spec.
+type ImagePlatform struct {
+	Architecture string   `json:"architecture,omitempty" yaml:"architecture,omitempty" toml:"architecture,omitempty"`
+	OS           string   `json:"os,omitempty" yaml:"os,omitempty" toml:"os,omitempty"`
+	OSVersion    string   `json:"os.version,omitempty" yaml:"os.version,omitempty" toml:"os.version,omitempty"`
+	OSFeatures   []string `json:"os.features,omitempty" yaml:"os.features,omitempty" toml:"os.features,omitempty"`
+	Variant      string   `json:"variant,omitempty" yaml:"variant,omitempty" toml:"variant,omitempty"`
+}
+
+// ImageDescriptor is an OCI content descriptor, identifying the manifest or
+// index an image was created from.
+type ImageDescriptor struct {
+	MediaType   string            `json:"mediaType,omitempty" yaml:"mediaType,omitempty" toml:"mediaType,omitempty"`
+	Digest      string            `json:"digest,omitempty" yaml:"digest,omitempty" toml:"digest,omitempty"`
+	Size        int64             `json:"size,omitempty" yaml:"size,omitempty" toml:"size,omitempty"`
+	URLs        []string          `json:"urls,omitempty" yaml:"urls,omitempty" toml:"urls,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty" toml:"annotations,omitempty"`
+	Platform    *ImagePlatform    `json:"platform,omitempty" yaml:"platform,omitempty" toml:"platform,omitempty"`
+}
+
+// ImageMetadata contains information about the image that is local to the
+// daemon, and not part of the image itself.
+type ImageMetadata struct {
+	LastTagTime time.Time `json:"LastTagTime,omitempty" yaml:"LastTagTime,omitempty" toml:"LastTagTime,omitempty"`
+}
+
 // Image is the type representing a docker image and its various properties
 type Image struct {
-	ID              string    `json:"Id" yaml:"Id" toml:"Id"`
-	RepoTags        []string  `json:"RepoTags,omitempty" yaml:"RepoTags,omitempty" toml:"RepoTags,omitempty"`
-	Parent          string    `json:"Parent,omitempty" yaml:"Parent,omitempty" toml:"Parent,omitempty"`
-	Comment         string    `json:"Comment,omitempty" yaml:"Comment,omitempty" toml:"Comment,omitempty"`
-	Created         time.Time `json:"Created,omitempty" yaml:"Created,omitempty" toml:"Created,omitempty"`
-	Container       string    `json:"Container,omitempty" yaml:"Container,omitempty" toml:"Container,omitempty"`
-	ContainerConfig Config    `json:"ContainerConfig,omitempty" yaml:"ContainerConfig,omitempty" toml:"ContainerConfig,omitempty"`
-	DockerVersion   string    `json:"DockerVersion,omitempty" yaml:"DockerVersion,omitempty" toml:"DockerVersion,omitempty"`
-	Author          string    `json:"Author,omitempty" yaml:"Author,omitempty" toml:"Author,omitempty"`
-	Config          *Config   `json:"Config,omitempty" yaml:"Config,omitempty" toml:"Config,omitempty"`
-	Architecture    string    `json:"Architecture,omitempty" yaml:"Architecture,omitempty"`
-	Size            int64     `json:"Size,omitempty" yaml:"Size,omitempty" toml:"Size,omitempty"`
-	VirtualSize     int64     `json:"VirtualSize,omitempty" yaml:"VirtualSize,omitempty" toml:"VirtualSize,omitempty"`
-	RepoDigests     []string  `json:"RepoDigests,omitempty" yaml:"RepoDigests,omitempty" toml:"RepoDigests,omitempty"`
-	RootFS          *RootFS   `json:"RootFS,omitempty" yaml:"RootFS,omitempty" toml:"RootFS,omitempty"`
-	OS              string    `json:"Os,omitempty" yaml:"Os,omitempty" toml:"Os,omitempty"`
+	ID              string           `json:"Id" yaml:"Id" toml:"Id"`
+	RepoTags        []string         `json:"RepoTags,omitempty" yaml:"RepoTags,omitempty" toml:"RepoTags,omitempty"`
+	Parent          string           `json:"Parent,omitempty" yaml:"Parent,omitempty" toml:"Parent,omitempty"`
+	Comment         string           `json:"Comment,omitempty" yaml:"Comment,omitempty" toml:"Comment,omitempty"`
+	Created         time.Time        `json:"Created,omitempty" yaml:"Created,omitempty" toml:"Created,omitempty"`
+	Container       string           `json:"Container,omitempty" yaml:"Container,omitempty" toml:"Container,omitempty"`
+	ContainerConfig Config           `json:"ContainerConfig,omitempty" yaml:"ContainerConfig,omitempty" toml:"ContainerConfig,omitempty"`
+	DockerVersion   string           `json:"DockerVersion,omitempty" yaml:"DockerVersion,omitempty" toml:"DockerVersion,omitempty"`
+	Author          string           `json:"Author,omitempty" yaml:"Author,omitempty" toml:"Author,omitempty"`
+	Config          *Config          `json:"Config,omitempty" yaml:"Config,omitempty" toml:"Config,omitempty"`
+	Architecture    string           `json:"Architecture,omitempty" yaml:"Architecture,omitempty"`
+	Variant         string           `json:"Variant,omitempty" yaml:"Variant,omitempty" toml:"Variant,omitempty"`
+	Size            int64            `json:"Size,omitempty" yaml:"Size,omitempty" toml:"Size,omitempty"`
+	VirtualSize     int64            `json:"VirtualSize,omitempty" yaml:"VirtualSize,omitempty" toml:"VirtualSize,omitempty"`
+	RepoDigests     []string         `json:"RepoDigests,omitempty" yaml:"RepoDigests,omitempty" toml:"RepoDigests,omitempty"`
+	RootFS          *RootFS          `json:"RootFS,omitempty" yaml:"RootFS,omitempty" toml:"RootFS,omitempty"`
+	OS              string           `json:"Os,omitempty" yaml:"Os,omitempty" toml:"Os,omitempty"`
+	OSVersion       string           `json:"OsVersion,omitempty" yaml:"OsVersion,omitempty" toml:"OsVersion,omitempty"`
+	GraphDriver     *GraphDriver     `json:"GraphDriver,omitempty" yaml:"GraphDriver,omitempty" toml:"GraphDriver,omitempty"`
+	Metadata        *ImageMetadata   `json:"Metadata,omitempty" yaml:"Metadata,omitempty" toml:"Metadata,omitempty"`
+	Descriptor      *ImageDescriptor `json:"Descriptor,omitempty" yaml:"Descriptor,omitempty" toml:"Descriptor,omitempty"`
+}
+
+// Labels returns the labels defined in the image configuration, or nil if
+// the image has no configuration.
+func (img *Image) Labels() map[string]string {
+	if img.Config == nil {
+		return nil
+	}
+	return img.Config.Labels
 }
 
 // ImagePre012 serves the same purpose as the Image type except that it is for
@@ -91,8 +133,35 @@ var (
 	// ErrMustSpecifyNames is the error returned when the Names field on
 	// ExportImagesOptions is nil or empty
 	ErrMustSpecifyNames = errors.New("must specify at least one name to export")
+
+	// ErrImageInUse matches, via errors.Is, every ImageInUse error.
+	ErrImageInUse = errors.New("image in use")
 )
 
+// ImageInUse is the error returned when the daemon refuses to remove an
+// image because of a conflict, like the image being used by a container or
+// being referenced by several repositories without Force being set.
+type ImageInUse struct {
+	Name string
+	Err  error
+}
+
+func (err *ImageInUse) Error() string {
+	if err.Err != nil {
+		return err.Err.Error()
+	}
+	return "Image in use: " + err.Name
+}
+
+func (err *ImageInUse) Unwrap() error {
+	return err.Err
+}
+
+// Is makes ImageInUse errors match ErrImageInUse.
+func (err *ImageInUse) Is(target error) bool {
+	return target == ErrImageInUse
+}
+
 // ListImagesOptions specify parameters to the ListImages function.
 //
 // See https://goo.gl/BVzauZ for more details.
@@ -156,16 +225,7 @@ func (c *Client) ImageHistory(name string) ([]ImageHistory, error) {
 //
 // See https://goo.gl/Vd2Pck for more details.
 func (c *Client) RemoveImage(name string) error {
-	resp, err := c.do(http.MethodDelete, "/images/"+name, doOptions{})
-	if err != nil {
-		var e *Error
-		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return ErrNoSuchImage
-		}
-		return err
-	}
-	resp.Body.Close()
-	return nil
+	return c.RemoveImageExtended(name, RemoveImageOptions{})
 }
 
 // RemoveImageOptions present the set of options available for removing an image
@@ -178,29 +238,80 @@ type RemoveImageOptions struct {
 	Context context.Context
 }
 
+// RemovedImage is an item of the report of RemoveImageWithResult: either a
+// tag removed from the image, or an image layer deleted.
+type RemovedImage struct {
+	Untagged string `json:"Untagged,omitempty" yaml:"Untagged,omitempty" toml:"Untagged,omitempty"`
+	Deleted  string `json:"Deleted,omitempty" yaml:"Deleted,omitempty" toml:"Deleted,omitempty"`
+}
+
 // RemoveImageExtended removes an image by its name or ID.
 // Extra params can be passed, see RemoveImageOptions
 //
 // See https://goo.gl/Vd2Pck for more details.
 func (c *Client) RemoveImageExtended(name string, opts RemoveImageOptions) error {
+	resp, err := c.removeImage(name, opts)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// RemoveImageWithResult is like RemoveImageExtended, but also returns the
+// tags removed and the layers deleted, as reported by the daemon. When the
+// daemon refuses to remove the image because of a conflict, it returns an
+// ImageInUse error. The result is empty when the daemon doesn't send a body.
+//
+// See https://goo.gl/Vd2Pck for more details.
+func (c *Client) RemoveImageWithResult(name string, opts RemoveImageOptions) ([]RemovedImage, error) {
+	resp, err := c.removeImage(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var removed []RemovedImage
+	if err := json.NewDecoder(resp.Body).Decode(&removed); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return removed, nil
+}
+
+func (c *Client) removeImage(name string, opts RemoveImageOptions) (*http.Response, error) {
 	uri := fmt.Sprintf("/images/%s?%s", name, queryString(&opts))
 	resp, err := c.do(http.MethodDelete, uri, doOptions{context: opts.Context})
 	if err != nil {
 		var e *Error
-		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return ErrNoSuchImage
+		if errors.As(err, &e) {
+			switch e.Status {
+			case http.StatusNotFound:
+				return nil, ErrNoSuchImage
+			case http.StatusConflict:
+				return nil, &ImageInUse{Name: name, Err: err}
+			}
 		}
-		return err
+		return nil, err
 	}
-	resp.Body.Close()
-	return nil
+	return resp, nil
 }
 
-// InspectImage returns an image by its name or ID.
+// InspectImage returns an image by its name or ID. The name may be a
+// reference by digest (name@sha256:...).
 //
 // See https://goo.gl/ncLTG8 for more details.
 func (c *Client) InspectImage(name string) (*Image, error) {
-	resp, err := c.do(http.MethodGet, "/images/"+name+"/json", doOptions{})
+	return c.InspectImageWithContext(name, context.TODO())
+}
+
+// InspectImageWithContext returns an image by its name or ID. The context
+// object can be used to cancel the inspect request.
+//
+// See https://goo.gl/ncLTG8 for more details.
+func (c *Client) InspectImageWithContext(name string, ctx context.Context) (*Image, error) {
+	path := "/images/" + normalizeDigestRef(name) + "/json"
+	body, err := c.cachedInspect(path, name, func() (*http.Response, error) {
+		return c.do(http.MethodGet, path, doOptions{context: ctx})
+	})
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
@@ -208,18 +319,17 @@ func (c *Client) InspectImage(name string) (*Image, error) {
 		}
 		return nil, err
 	}
-	defer resp.Body.Close()
 
 	var image Image
 
 	// if the caller elected to skip checking the server's version, assume it's the latest
-	if c.SkipServerVersionCheck || c.expectedAPIVersion.GreaterThanOrEqualTo(apiVersion112) {
-		if err := json.NewDecoder(resp.Body).Decode(&image); err != nil {
+	if c.SkipServerVersionCheck || c.expectedVersion().GreaterThanOrEqualTo(apiVersion112) {
+		if err := json.Unmarshal(body, &image); err != nil {
 			return nil, err
 		}
 	} else {
 		var imagePre012 ImagePre012
-		if err := json.NewDecoder(resp.Body).Decode(&imagePre012); err != nil {
+		if err := json.Unmarshal(body, &imagePre012); err != nil {
 			return nil, err
 		}
 
@@ -246,7 +356,7 @@ type PushImageOptions struct {
 	// Name of the image
 	Name string
 
-	// Tag of the image
+	// Tag of the image. When empty, all tags of the repository are pushed.
 	Tag string
 
 	// Registry server to push the image
@@ -257,6 +367,18 @@ type PushImageOptions struct {
 	InactivityTimeout time.Duration `qs:"-"`
 
 	Context context.Context
+
+	// JSONMessageHandler, when set, is called with every message of the
+	// JSON progress stream sent by the daemon.
+	JSONMessageHandler func(*JSONMessage) `qs:"-"`
+}
+
+// PushImageResult holds information about a pushed image, collected from the
+// progress messages sent by the daemon.
+type PushImageResult struct {
+	Tag    string `json:"Tag,omitempty" yaml:"Tag,omitempty" toml:"Tag,omitempty"`
+	Digest string `json:"Digest,omitempty" yaml:"Digest,omitempty" toml:"Digest,omitempty"`
+	Size   int64  `json:"Size,omitempty" yaml:"Size,omitempty" toml:"Size,omitempty"`
 }
 
 // PushImage pushes an image to a remote registry, logging progress to w.
@@ -266,24 +388,49 @@ type PushImageOptions struct {
 //
 // See https://goo.gl/BZemGg for more details.
 func (c *Client) PushImage(opts PushImageOptions, auth AuthConfiguration) error {
+	_, err := c.PushImageWithResult(opts, auth)
+	return err
+}
+
+// PushImageWithResult is like PushImage, but also returns the tag, digest and
+// size of the pushed manifest, as reported by the daemon in the progress
+// stream. When pushing all tags of a repository, the result describes the
+// last pushed tag.
+//
+// See https://goo.gl/BZemGg for more details.
+func (c *Client) PushImageWithResult(opts PushImageOptions, auth AuthConfiguration) (*PushImageResult, error) {
 	if opts.Name == "" {
-		return ErrNoSuchImage
+		return nil, ErrNoSuchImage
 	}
 	headers, err := headersWithAuth(auth)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	name := opts.Name
 	opts.Name = ""
 	path := "/images/" + name + "/push?" + queryString(&opts)
-	return c.stream(http.MethodPost, path, streamOptions{
+	var result PushImageResult
+	err = c.stream(http.MethodPost, path, streamOptions{
 		setRawTerminal:    true,
 		rawJSONStream:     opts.RawJSONStream,
 		headers:           headers,
 		stdout:            opts.OutputStream,
 		inactivityTimeout: opts.InactivityTimeout,
 		context:           opts.Context,
+		jsonMessageHandler: chainJSONMessageHandlers(func(msg *JSONMessage) {
+			if msg.Aux == nil {
+				return
+			}
+			var aux PushImageResult
+			if err := json.Unmarshal(*msg.Aux, &aux); err == nil && aux.Digest != "" {
+				result = aux
+			}
+		}, opts.JSONMessageHandler),
 	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
 // PullImageOptions present the set of options available for pulling an image
@@ -305,63 +452,104 @@ type PullImageOptions struct {
 	RawJSONStream     bool          `qs:"-"`
 	InactivityTimeout time.Duration `qs:"-"`
 	Context           context.Context
+
+	// JSONMessageHandler, when set, is called with every message of the
+	// JSON progress stream sent by the daemon.
+	JSONMessageHandler func(*JSONMessage) `qs:"-"`
+}
+
+// PullImageResult holds information about a pulled image, collected from the
+// progress messages sent by the daemon.
+type PullImageResult struct {
+	// Digest is the digest of the pulled manifest (e.g. sha256:...). It's
+	// empty when the daemon doesn't report it, like when pulling all tags
+	// of a repository.
+	Digest string
 }
 
 // PullImage pulls an image from a remote registry, logging progress to
-// opts.OutputStream.
+// opts.OutputStream. When neither opts.Tag nor opts.Repository specify a tag
+// or digest, the daemon pulls every tag of the repository.
 //
 // See https://goo.gl/qkoSsn for more details.
 func (c *Client) PullImage(opts PullImageOptions, auth AuthConfiguration) error {
+	_, err := c.PullImageWithResult(opts, auth)
+	return err
+}
+
+// PullImageWithResult is like PullImage, but also returns the digest of the
+// pulled image, as reported by the daemon in the progress stream.
+//
+// See https://goo.gl/qkoSsn for more details.
+func (c *Client) PullImageWithResult(opts PullImageOptions, auth AuthConfiguration) (*PullImageResult, error) {
 	if opts.Repository == "" {
-		return ErrNoSuchImage
+		return nil, ErrNoSuchImage
 	}
-
 	headers, err := headersWithAuth(auth)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if opts.Tag == "" && strings.Contains(opts.Repository, "@") {
-		parts := strings.SplitN(opts.Repository, "@", 2)
+		// name:tag@digest is pulled by digest, as InspectImage does
+		parts := strings.SplitN(normalizeDigestRef(opts.Repository), "@", 2)
 		opts.Repository = parts[0]
 		opts.Tag = parts[1]
 	}
-	return c.createImage(&opts, headers, nil, opts.OutputStream, opts.RawJSONStream, opts.InactivityTimeout, opts.Context)
+	var result PullImageResult
+	err = c.createImage(&opts, streamOptions{
+		setRawTerminal:    true,
+		headers:           headers,
+		stdout:            opts.OutputStream,
+		rawJSONStream:     opts.RawJSONStream,
+		inactivityTimeout: opts.InactivityTimeout,
+		context:           opts.Context,
+		jsonMessageHandler: chainJSONMessageHandlers(func(msg *JSONMessage) {
+			if digest, ok := strings.CutPrefix(msg.Status, "Digest: "); ok {
+				result.Digest = digest
+			}
+		}, opts.JSONMessageHandler),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
-func (c *Client) createImage(opts any, headers map[string]string, in io.Reader, w io.Writer, rawJSONStream bool, timeout time.Duration, context context.Context) error {
+func (c *Client) createImage(opts any, streamOptions streamOptions) error {
 	url, err := c.getPath("/images/create", opts)
 	if err != nil {
 		return err
 	}
-	return c.streamURL(http.MethodPost, url, streamOptions{
-		setRawTerminal:    true,
-		headers:           headers,
-		in:                in,
-		stdout:            w,
-		rawJSONStream:     rawJSONStream,
-		inactivityTimeout: timeout,
-		context:           context,
-	})
+	return c.streamURL(http.MethodPost, url, streamOptions)
 }
 
 // LoadImageOptions represents the options for LoadImage Docker API Call
 //
 // See https://goo.gl/rEsBV3 for more details.
 type LoadImageOptions struct {
-	InputStream  io.Reader
-	OutputStream io.Writer
-	Context      context.Context
+	InputStream  io.Reader       `qs:"-"`
+	OutputStream io.Writer       `qs:"-"`
+	Context      context.Context `qs:"-"`
+
+	// Quiet suppresses the progress details sent by the daemon while the
+	// image is loaded.
+	Quiet bool `qs:"quiet"`
+
+	// JSONMessageHandler, when set, is called with every message of the
+	// JSON progress stream sent by the daemon.
+	JSONMessageHandler func(*JSONMessage) `qs:"-"`
 }
 
 // LoadImage imports a tarball docker image
 //
 // See https://goo.gl/rEsBV3 for more details.
 func (c *Client) LoadImage(opts LoadImageOptions) error {
-	return c.stream(http.MethodPost, "/images/load", streamOptions{
-		setRawTerminal: true,
-		in:             opts.InputStream,
-		stdout:         opts.OutputStream,
-		context:        opts.Context,
+	return c.stream(http.MethodPost, "/images/load?"+queryString(opts), streamOptions{
+		setRawTerminal:     true,
+		in:                 opts.InputStream,
+		stdout:             opts.OutputStream,
+		context:            opts.Context,
+		jsonMessageHandler: opts.JSONMessageHandler,
 	})
 }
 
@@ -373,18 +561,27 @@ type ExportImageOptions struct {
 	OutputStream      io.Writer
 	InactivityTimeout time.Duration
 	Context           context.Context
+
+	// Compress makes the tar archive be gzip-compressed before being
+	// written to OutputStream.
+	Compress bool
 }
 
 // ExportImage exports an image (as a tar file) into the stream.
 //
 // See https://goo.gl/AuySaA for more details.
 func (c *Client) ExportImage(opts ExportImageOptions) error {
-	return c.stream(http.MethodGet, fmt.Sprintf("/images/%s/get", opts.Name), streamOptions{
+	stdout, flush := compressWriter(opts.OutputStream, opts.Compress)
+	err := c.stream(http.MethodGet, fmt.Sprintf("/images/%s/get", opts.Name), streamOptions{
 		setRawTerminal:    true,
-		stdout:            opts.OutputStream,
+		stdout:            stdout,
 		inactivityTimeout: opts.InactivityTimeout,
 		context:           opts.Context,
 	})
+	if flushErr := flush(); err == nil {
+		err = flushErr
+	}
+	return err
 }
 
 // ExportImagesOptions represent the options for ExportImages Docker API call
@@ -395,6 +592,10 @@ type ExportImagesOptions struct {
 	OutputStream      io.Writer     `qs:"-"`
 	InactivityTimeout time.Duration `qs:"-"`
 	Context           context.Context
+
+	// Compress makes the tar archive be gzip-compressed before being
+	// written to OutputStream.
+	Compress bool `qs:"-"`
 }
 
 // ExportImages exports one or more images (as a tar file) into the stream
@@ -425,11 +626,46 @@ func (c *Client) ExportImages(opts ExportImagesOptions) error {
 	if err != nil {
 		return err
 	}
-	return c.streamURL(http.MethodGet, exporturl, streamOptions{
+	stdout, flush := compressWriter(opts.OutputStream, opts.Compress)
+	err = c.streamURL(http.MethodGet, exporturl, streamOptions{
 		setRawTerminal:    true,
-		stdout:            opts.OutputStream,
+		stdout:            stdout,
 		inactivityTimeout: opts.InactivityTimeout,
 	})
+	if flushErr := flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// compressWriter wraps w in a gzip writer when compress is true. The returned
+// function must be called once all data has been written, to flush the gzip
+// stream.
+func compressWriter(w io.Writer, compress bool) (io.Writer, func() error) {
+	if !compress {
+		return w, func() error { return nil }
+	}
+	if w == nil {
+		w = io.Discard
+	}
+	gz := gzip.NewWriter(w)
+	return gz, gz.Close
+}
+
+// compressReader returns a reader with the gzip-compressed content of r. The
+// reader must be closed once the request is done, so that the goroutine
+// compressing r stops even if the content wasn't read.
+func compressReader(r io.Reader) *io.PipeReader {
+	pr, pw := io.Pipe()
+	go func() {
+		gz := gzip.NewWriter(pw)
+		_, err := io.Copy(gz, r)
+		if closeErr := gz.Close(); err == nil {
+			err = closeErr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
 }
 
 // ImportImageOptions present the set of informations available for importing
@@ -441,11 +677,25 @@ type ImportImageOptions struct {
 	Source     string `qs:"fromSrc"`
 	Tag        string `qs:"tag"`
 
+	// Changes are Dockerfile instructions applied to the imported image,
+	// like "CMD [\"/bin/sh\"]" or "ENV PATH=/usr/bin". Only CMD,
+	// ENTRYPOINT, ENV, EXPOSE, HEALTHCHECK, LABEL, ONBUILD, STOPSIGNAL,
+	// USER, VOLUME and WORKDIR are supported.
+	Changes []string `qs:"changes"`
+
+	// Message is the commit message of the imported image.
+	Message string `qs:"message"`
+
 	InputStream       io.Reader     `qs:"-"`
 	OutputStream      io.Writer     `qs:"-"`
 	RawJSONStream     bool          `qs:"-"`
 	InactivityTimeout time.Duration `qs:"-"`
 	Context           context.Context
+
+	// Compress makes the tar archive be gzip-compressed before being sent
+	// to the daemon, which transparently decompresses it. It has no effect
+	// when importing from a URL.
+	Compress bool `qs:"-"`
 }
 
 // ImportImage imports an image from a url, a file or stdin
@@ -466,7 +716,23 @@ func (c *Client) ImportImage(opts ImportImageOptions) error {
 		opts.InputStream = f
 		opts.Source = "-"
 	}
-	return c.createImage(&opts, nil, opts.InputStream, opts.OutputStream, opts.RawJSONStream, opts.InactivityTimeout, opts.Context)
+	var compressed *io.PipeReader
+	if opts.Compress && opts.InputStream != nil {
+		compressed = compressReader(opts.InputStream)
+		opts.InputStream = compressed
+	}
+	err := c.createImage(&opts, streamOptions{
+		setRawTerminal:    true,
+		in:                opts.InputStream,
+		stdout:            opts.OutputStream,
+		rawJSONStream:     opts.RawJSONStream,
+		inactivityTimeout: opts.InactivityTimeout,
+		context:           opts.Context,
+	})
+	if compressed != nil {
+		compressed.CloseWithError(err)
+	}
+	return err
 }
 
 // BuilderVersion represents either the BuildKit or V1 ("classic") builder.
@@ -485,6 +751,7 @@ const (
 type BuildImageOptions struct {
 	Context             context.Context
 	Name                string   `qs:"t"`
+	Tags                []string `qs:"-"` // names given to the image besides Name
 	Dockerfile          string   `ver:"1.25"`
 	ExtraHosts          string   `ver:"1.28"`
 	CacheFrom           []string `qs:"-" ver:"1.25"`
@@ -500,7 +767,7 @@ type BuildImageOptions struct {
 	OutputStream        io.Writer `qs:"-"`
 	Remote              string
 	Auth                AuthConfiguration  `qs:"-"` // for older docker X-Registry-Auth header
-	AuthConfigs         AuthConfigurations `qs:"-"` // for newer docker X-Registry-Config header
+	AuthConfigs         AuthConfigurations `qs:"-"` // for newer docker X-Registry-Config header, keyed by registry address
 	ContextDir          string             `qs:"-"`
 	Ulimits             []ULimit           `qs:"-" ver:"1.18"`
 	BuildArgs           []BuildArg         `qs:"-" ver:"1.21"`
@@ -518,6 +785,20 @@ type BuildImageOptions struct {
 	ForceRmTmpContainer bool           `qs:"forcerm" ver:"1.12"`
 	RawJSONStream       bool           `qs:"-"`
 	Version             BuilderVersion `qs:"version" ver:"1.39"`
+
+	// Squash squashes the layers created by the build into a single layer.
+	// It requires a daemon with experimental features enabled.
+	Squash bool `ver:"1.25"`
+
+	// InlineCache embeds the build cache metadata in the built image, so
+	// it can be used in CacheFrom by later builds once pushed. It requires
+	// BuildKit (see Version). The /build endpoint doesn't support
+	// exporting the cache anywhere else.
+	InlineCache bool `qs:"-"`
+
+	// JSONMessageHandler, when set, is called with every message of the
+	// JSON progress stream sent by the daemon.
+	JSONMessageHandler func(*JSONMessage) `qs:"-"`
 }
 
 // BuildArg represents arguments that can be passed to the image when building
@@ -530,17 +811,39 @@ type BuildArg struct {
 	Value string `json:"Value,omitempty" yaml:"Value,omitempty" toml:"Value,omitempty"`
 }
 
+// BuildImageResult holds information about a built image, collected from
+// the progress messages sent by the daemon.
+type BuildImageResult struct {
+	// ImageID is the ID of the built image. It's empty when the daemon
+	// doesn't report it.
+	ImageID string
+
+	// Tags are the names applied to the image, as reported by the daemon,
+	// or as requested in Name and Tags when the daemon doesn't report
+	// them.
+	Tags []string
+}
+
 // BuildImage builds an image from a tarball's url or a Dockerfile in the input
 // stream.
 //
 // See https://goo.gl/4nYHwV for more details.
 func (c *Client) BuildImage(opts BuildImageOptions) error {
+	_, err := c.BuildImageWithResult(opts)
+	return err
+}
+
+// BuildImageWithResult is like BuildImage, but also returns the ID of the
+// built image and the names applied to it.
+//
+// See https://goo.gl/4nYHwV for more details.
+func (c *Client) BuildImageWithResult(opts BuildImageOptions) (*BuildImageResult, error) {
 	if opts.OutputStream == nil {
-		return ErrMissingOutputStream
+		return nil, ErrMissingOutputStream
 	}
 	headers, err := headersWithAuth(opts.Auth, c.versionedAuthConfigs(opts.AuthConfigs))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if opts.Remote != "" && opts.Name == "" {
@@ -549,15 +852,15 @@ func (c *Client) BuildImage(opts BuildImageOptions) error {
 	if opts.InputStream != nil || opts.ContextDir != "" {
 		headers["Content-Type"] = "application/tar"
 	} else if opts.Remote == "" {
-		return ErrMissingRepo
+		return nil, ErrMissingRepo
 	}
 	if opts.ContextDir != "" {
 		if opts.InputStream != nil {
-			return ErrMultipleContexts
+			return nil, ErrMultipleContexts
 		}
 		var err error
 		if opts.InputStream, err = createTarStream(opts.ContextDir, opts.Dockerfile); err != nil {
-			return err
+			return nil, err
 		}
 	}
 	qs, ver := queryStringVersion(&opts)
@@ -584,11 +887,14 @@ func (c *Client) BuildImage(opts BuildImageOptions) error {
 		}
 	}
 
-	if len(opts.BuildArgs) > 0 {
+	if len(opts.BuildArgs) > 0 || opts.InlineCache {
 		v := make(map[string]string)
 		for _, arg := range opts.BuildArgs {
 			v[arg.Name] = arg.Value
 		}
+		if opts.InlineCache {
+			v["BUILDKIT_INLINE_CACHE"] = "1"
+		}
 		if b, err := json.Marshal(v); err == nil {
 			item := url.Values(map[string][]string{})
 			item.Add("buildargs", string(b))
@@ -599,12 +905,18 @@ func (c *Client) BuildImage(opts BuildImageOptions) error {
 		}
 	}
 
+	if len(opts.Tags) > 0 {
+		item := url.Values{"t": opts.Tags}
+		qs = fmt.Sprintf("%s&%s", qs, item.Encode())
+	}
+
 	buildURL, err := c.pathVersionCheck("/build", qs, ver)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return c.streamURL(http.MethodPost, buildURL, streamOptions{
+	var result BuildImageResult
+	err = c.streamURL(http.MethodPost, buildURL, streamOptions{
 		setRawTerminal:    true,
 		rawJSONStream:     opts.RawJSONStream,
 		headers:           headers,
@@ -612,17 +924,49 @@ func (c *Client) BuildImage(opts BuildImageOptions) error {
 		stdout:            opts.OutputStream,
 		inactivityTimeout: opts.InactivityTimeout,
 		context:           opts.Context,
+		jsonMessageHandler: chainJSONMessageHandlers(func(msg *JSONMessage) {
+			result.collect(msg)
+		}, opts.JSONMessageHandler),
 	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Tags) == 0 {
+		for _, name := range append([]string{opts.Name}, opts.Tags...) {
+			if name != "" {
+				result.Tags = append(result.Tags, name)
+			}
+		}
+	}
+	return &result, nil
 }
 
-func (c *Client) versionedAuthConfigs(authConfigs AuthConfigurations) registryAuth {
-	if c.serverAPIVersion == nil {
-		c.checkAPIVersion()
+// collect records the image ID and the tags reported in a message of the
+// progress of a build.
+func (r *BuildImageResult) collect(msg *JSONMessage) {
+	if msg.Aux != nil {
+		var aux struct{ ID string }
+		if json.Unmarshal(*msg.Aux, &aux) == nil && aux.ID != "" {
+			r.ImageID = aux.ID
+		}
 	}
-	if c.serverAPIVersion != nil && c.serverAPIVersion.GreaterThanOrEqualTo(apiVersion119) {
-		return AuthConfigurations119(authConfigs.Configs)
+	line := strings.TrimSpace(msg.Stream)
+	if id, ok := strings.CutPrefix(line, "Successfully built "); ok && r.ImageID == "" {
+		r.ImageID = id
+	}
+	if tag, ok := strings.CutPrefix(line, "Successfully tagged "); ok {
+		r.Tags = append(r.Tags, tag)
+	}
+}
+
+// versionedAuthConfigs returns the configurations to send in the
+// X-Registry-Config header. The format of API < 1.19 is only used when the
+// daemon is known to be that old.
+func (c *Client) versionedAuthConfigs(authConfigs AuthConfigurations) registryAuth {
+	if serverAPIVersion := c.serverVersion(); serverAPIVersion != nil && serverAPIVersion.LessThan(apiVersion119) {
+		return authConfigs
 	}
-	return authConfigs
+	return authConfigs.registryConfig()
 }
 
 // TagImageOptions present the set of options to tag an image.
@@ -0,0 +1,198 @@
+package docker
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+)
+
+// ErrInvalidImageArchive is the error returned by ReadImageArchive when the
+// archive isn't a valid image archive, or when it's incomplete.
+var ErrInvalidImageArchive = errors.New("invalid image archive")
+
+// maxArchiveMetadataSize is the size of the largest file of an image archive
+// kept in memory by ReadImageArchive, as it may be a manifest or config.
+const maxArchiveMetadataSize = 1 << 20
+
+// ImageArchive describes the content of an archive created by ExportImage,
+// ExportImages or "docker save".
+type ImageArchive struct {
+	Images []ArchiveImage
+}
+
+// ArchiveImage is an image of an ImageArchive, with the metadata found in
+// its config.
+type ArchiveImage struct {
+	// ID is the digest of the image config, which is also the ID of the
+	// image once loaded.
+	ID           string
+	RepoTags     []string
+	Architecture string
+	OS           string
+	Variant      string
+	Created      time.Time
+	Author       string
+	Config       *Config
+	Layers       []ArchiveLayer
+}
+
+// ArchiveLayer is a layer of an ArchiveImage.
+type ArchiveLayer struct {
+	// Path is the path of the layer in the archive.
+	Path string
+
+	// Size and Digest are the size and sha256 digest of the layer file.
+	Size   int64
+	Digest string
+
+	// DiffID is the digest of the uncompressed layer, as listed in the
+	// image config.
+	DiffID string
+}
+
+// Verify checks that the layers of the image match the diff IDs listed in
+// its config. It only applies to archives with uncompressed layers, like the
+// ones created by the Docker daemon.
+func (img *ArchiveImage) Verify() error {
+	for _, layer := range img.Layers {
+		if layer.Digest != layer.DiffID {
+			return fmt.Errorf("%w: layer %s of image %s has digest %s, want %s", ErrInvalidImageArchive, layer.Path, img.ID, layer.Digest, layer.DiffID)
+		}
+	}
+	return nil
+}
+
+type archiveFile struct {
+	size   int64
+	digest string
+	data   []byte
+}
+
+// ReadImageArchive parses an image archive, as created by ExportImage or
+// "docker save", without loading it into the daemon. The whole archive is
+// read, to compute the digests of its layers, but only its metadata is kept
+// in memory. It returns an error wrapping ErrInvalidImageArchive if the
+// archive lacks manifest.json or any of the files it references.
+func ReadImageArchive(r io.Reader) (*ImageArchive, error) {
+	files := make(map[string]*archiveFile)
+	links := make(map[string]string)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := path.Clean(header.Name)
+		switch header.Typeflag {
+		case tar.TypeSymlink:
+			links[name] = path.Join(path.Dir(name), header.Linkname)
+		case tar.TypeLink:
+			links[name] = path.Clean(header.Linkname)
+		case tar.TypeReg:
+			file, err := readArchiveFile(tr, header.Size)
+			if err != nil {
+				return nil, err
+			}
+			files[name] = file
+		}
+	}
+	lookup := func(name string) (*archiveFile, error) {
+		name = path.Clean(name)
+		for i := 0; i < 10; i++ {
+			if file, ok := files[name]; ok {
+				return file, nil
+			}
+			target, ok := links[name]
+			if !ok {
+				break
+			}
+			name = target
+		}
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidImageArchive, name)
+	}
+	manifestFile, err := lookup("manifest.json")
+	if err != nil {
+		return nil, err
+	}
+	var manifest []struct {
+		Config   string
+		RepoTags []string
+		Layers   []string
+	}
+	if err := json.Unmarshal(manifestFile.data, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImageArchive, err)
+	}
+	var archive ImageArchive
+	for _, entry := range manifest {
+		configFile, err := lookup(entry.Config)
+		if err != nil {
+			return nil, err
+		}
+		var config struct {
+			Architecture string
+			OS           string
+			Variant      string
+			Created      time.Time
+			Author       string
+			Config       *Config
+			RootFS       struct {
+				DiffIDs []string `json:"diff_ids"`
+			}
+		}
+		if err := json.Unmarshal(configFile.data, &config); err != nil {
+			return nil, fmt.Errorf("%w: invalid config %s: %w", ErrInvalidImageArchive, entry.Config, err)
+		}
+		if len(config.RootFS.DiffIDs) != len(entry.Layers) {
+			return nil, fmt.Errorf("%w: config %s lists %d layers, manifest lists %d", ErrInvalidImageArchive, entry.Config, len(config.RootFS.DiffIDs), len(entry.Layers))
+		}
+		image := ArchiveImage{
+			ID:           configFile.digest,
+			RepoTags:     entry.RepoTags,
+			Architecture: config.Architecture,
+			OS:           config.OS,
+			Variant:      config.Variant,
+			Created:      config.Created,
+			Author:       config.Author,
+			Config:       config.Config,
+		}
+		for i, layerPath := range entry.Layers {
+			layerFile, err := lookup(layerPath)
+			if err != nil {
+				return nil, err
+			}
+			image.Layers = append(image.Layers, ArchiveLayer{
+				Path:   layerPath,
+				Size:   layerFile.size,
+				Digest: layerFile.digest,
+				DiffID: config.RootFS.DiffIDs[i],
+			})
+		}
+		archive.Images = append(archive.Images, image)
+	}
+	return &archive, nil
+}
+
+// readArchiveFile computes the digest of a file of the archive, keeping its
+// content when it's small enough to be metadata.
+func readArchiveFile(r io.Reader, size int64) (*archiveFile, error) {
+	h := sha256.New()
+	var data []byte
+	if size <= maxArchiveMetadataSize {
+		var err error
+		if data, err = io.ReadAll(io.TeeReader(r, h)); err != nil {
+			return nil, err
+		}
+	} else if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return &archiveFile{size: size, digest: "sha256:" + hex.EncodeToString(h.Sum(nil)), data: data}, nil
+}
@@ -0,0 +1,105 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+type testArchiveEntry struct {
+	name string
+	data []byte
+	link string
+}
+
+func testImageArchive(t *testing.T, entries []testArchiveEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.data)), Typeflag: tar.TypeReg}
+		if entry.link != "" {
+			header = &tar.Header{Name: entry.name, Linkname: entry.link, Typeflag: tar.TypeSymlink}
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(entry.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestReadImageArchive(t *testing.T) {
+	t.Parallel()
+	layer := []byte("layer content")
+	config := []byte(`{"architecture":"amd64","os":"linux","created":"2024-05-01T10:00:00Z","config":{"Cmd":["sh"]},"rootfs":{"type":"layers","diff_ids":["` + testDigest(layer) + `"]}}`)
+	configHex := testDigest(config)[len("sha256:"):]
+	layerHex := testDigest(layer)[len("sha256:"):]
+	manifest := []byte(`[{"Config":"blobs/sha256/` + configHex + `","RepoTags":["busybox:latest"],"Layers":["abc/layer.tar"]}]`)
+	archive := testImageArchive(t, []testArchiveEntry{
+		{name: "blobs/sha256/" + layerHex, data: layer},
+		{name: "blobs/sha256/" + configHex, data: config},
+		{name: "abc/layer.tar", link: "../blobs/sha256/" + layerHex},
+		{name: "manifest.json", data: manifest},
+	})
+	result, err := ReadImageArchive(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Images) != 1 {
+		t.Fatalf("ReadImageArchive: wrong number of images: %d.", len(result.Images))
+	}
+	image := result.Images[0]
+	if image.ID != "sha256:"+configHex || image.OS != "linux" || image.Architecture != "amd64" || image.Created.Year() != 2024 {
+		t.Errorf("ReadImageArchive: wrong image: %#v.", image)
+	}
+	if !reflect.DeepEqual(image.RepoTags, []string{"busybox:latest"}) || !reflect.DeepEqual(image.Config.Cmd, []string{"sh"}) {
+		t.Errorf("ReadImageArchive: wrong image: %#v.", image)
+	}
+	expectedLayers := []ArchiveLayer{{Path: "abc/layer.tar", Size: int64(len(layer)), Digest: testDigest(layer), DiffID: testDigest(layer)}}
+	if !reflect.DeepEqual(image.Layers, expectedLayers) {
+		t.Errorf("ReadImageArchive: wrong layers. Want %#v. Got %#v.", expectedLayers, image.Layers)
+	}
+	if err := image.Verify(); err != nil {
+		t.Errorf("Verify: unexpected error: %v", err)
+	}
+	image.Layers[0].DiffID = "sha256:other"
+	if err := image.Verify(); !errors.Is(err, ErrInvalidImageArchive) {
+		t.Errorf("Verify: wrong error. Want %#v. Got %#v.", ErrInvalidImageArchive, err)
+	}
+}
+
+func TestReadImageArchiveIncomplete(t *testing.T) {
+	t.Parallel()
+	config := []byte(`{"rootfs":{"type":"layers","diff_ids":["sha256:abc"]}}`)
+	tests := map[string][]testArchiveEntry{
+		"no manifest": {{name: "abc.json", data: config}},
+		"missing layer": {
+			{name: "abc.json", data: config},
+			{name: "manifest.json", data: []byte(`[{"Config":"abc.json","Layers":["abc/layer.tar"]}]`)},
+		},
+		"missing config": {
+			{name: "manifest.json", data: []byte(`[{"Config":"def.json","Layers":[]}]`)},
+		},
+	}
+	for name, entries := range tests {
+		_, err := ReadImageArchive(testImageArchive(t, entries))
+		if !errors.Is(err, ErrInvalidImageArchive) {
+			t.Errorf("ReadImageArchive (%s): wrong error. Want %#v. Got %#v.", name, ErrInvalidImageArchive, err)
+		}
+	}
+}
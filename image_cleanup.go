@@ -0,0 +1,47 @@
+package docker
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fullImageIDPattern matches the full ID of an image, with or without its
+// algorithm prefix.
+var fullImageIDPattern = regexp.MustCompile(`^(sha256:)?[0-9a-f]{64}$`)
+
+// DanglingImages returns the dangling images: the untagged images that
+// aren't the parent of another image, left behind when a tag is moved to a
+// new image. They are safe to remove unless used by a container.
+func (c *Client) DanglingImages(opts ...CallOption) ([]APIImages, error) {
+	return c.Images(append(opts, WithFilter("dangling", "true"))...)
+}
+
+// ChildrenOf returns the images whose parent is the image with the given ID
+// or name, including intermediate images. An image can't be removed while
+// it has children.
+//
+// Only images built locally have a parent, images pulled from a registry
+// never are the children of another image.
+func (c *Client) ChildrenOf(imageID string, opts ...CallOption) ([]APIImages, error) {
+	if !fullImageIDPattern.MatchString(imageID) {
+		image, err := c.InspectImage(imageID)
+		if err != nil {
+			return nil, err
+		}
+		imageID = image.ID
+	}
+	if !strings.HasPrefix(imageID, "sha256:") {
+		imageID = "sha256:" + imageID
+	}
+	images, err := c.Images(append(opts, WithAll())...)
+	if err != nil {
+		return nil, err
+	}
+	var children []APIImages
+	for _, image := range images {
+		if image.ParentID == imageID {
+			children = append(children, image)
+		}
+	}
+	return children, nil
+}
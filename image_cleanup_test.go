@@ -0,0 +1,96 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDanglingImages(t *testing.T) {
+	t.Parallel()
+	body := `[{"Id":"sha256:3e2f21a89f","RepoTags":["<none>:<none>"]}]`
+	fakeRT := &FakeRoundTripper{message: body, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	images, err := client.DanglingImages(WithFilter("label", "app=web"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 1 || images[0].ID != "sha256:3e2f21a89f" {
+		t.Errorf("DanglingImages: wrong images: %#v", images)
+	}
+	var filters map[string][]string
+	if err := json.Unmarshal([]byte(fakeRT.requests[0].URL.Query().Get("filters")), &filters); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{"dangling": {"true"}, "label": {"app=web"}}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("DanglingImages: wrong filters. Want %#v. Got %#v.", expected, filters)
+	}
+}
+
+const parentImageID = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+const imageGraph = `[
+	{"Id":"sha256:` + parentImageID + `","RepoTags":["base:latest"]},
+	{"Id":"sha256:1111111111111111111111111111111111111111111111111111111111111111","ParentId":"sha256:` + parentImageID + `"},
+	{"Id":"sha256:2222222222222222222222222222222222222222222222222222222222222222","ParentId":"sha256:` + parentImageID + `","RepoTags":["app:latest"]},
+	{"Id":"sha256:3333333333333333333333333333333333333333333333333333333333333333","ParentId":"sha256:1111111111111111111111111111111111111111111111111111111111111111"}
+]`
+
+func TestChildrenOf(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: imageGraph, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	children, err := client.ChildrenOf(parentImageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, child := range children {
+		ids = append(ids, child.ID)
+	}
+	expected := []string{
+		"sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		"sha256:2222222222222222222222222222222222222222222222222222222222222222",
+	}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("ChildrenOf: wrong children. Want %#v. Got %#v.", expected, ids)
+	}
+	if len(fakeRT.requests) != 1 {
+		t.Fatalf("ChildrenOf: wrong number of requests. Want 1. Got %d.", len(fakeRT.requests))
+	}
+	if all := fakeRT.requests[0].URL.Query().Get("all"); all != "1" {
+		t.Errorf("ChildrenOf: intermediate images not listed, all=%q", all)
+	}
+}
+
+func TestChildrenOfName(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/images/json":
+			w.Write([]byte(imageGraph))
+		case strings.HasPrefix(r.URL.Path, "/images/base:latest/json"):
+			w.Write([]byte(`{"Id":"sha256:` + parentImageID + `"}`))
+		default:
+			http.Error(w, "no such image", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	children, err := client.ChildrenOf("base:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 2 {
+		t.Errorf("ChildrenOf: wrong number of children. Want 2. Got %d.", len(children))
+	}
+	if _, err := client.ChildrenOf("missing"); !errors.Is(err, ErrNoSuchImage) {
+		t.Errorf("ChildrenOf: wrong error. Want %#v. Got %#v.", ErrNoSuchImage, err)
+	}
+}
@@ -0,0 +1,95 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PullPolicy defines when EnsureImage pulls an image.
+type PullPolicy int
+
+const (
+	// PullIfNotPresent pulls the image only when it's not available
+	// locally. This is the default policy.
+	PullIfNotPresent PullPolicy = iota
+
+	// PullAlways pulls the image even when it's available locally, so the
+	// local copy is refreshed from the registry.
+	PullAlways
+
+	// PullIfNotPresentByDigest behaves like PullIfNotPresent, but when the
+	// reference includes a digest, a local image is only used if one of its
+	// RepoDigests matches the reference exactly.
+	PullIfNotPresentByDigest
+)
+
+// EnsureImageOptions specify parameters to the EnsureImage function.
+type EnsureImageOptions struct {
+	Policy PullPolicy
+
+	// OutputStream, when set, receives the progress of the pull.
+	OutputStream      io.Writer
+	RawJSONStream     bool
+	InactivityTimeout time.Duration
+	Context           context.Context
+}
+
+// EnsureImage makes sure the image identified by ref is available locally,
+// pulling it according to the policy in opts, and returns the ID of the
+// image.
+func (c *Client) EnsureImage(ref string, auth AuthConfiguration, opts EnsureImageOptions) (string, error) {
+	if ref == "" {
+		return "", ErrNoSuchImage
+	}
+	if opts.Policy != PullAlways {
+		img, err := c.InspectImageWithContext(ref, opts.Context)
+		if err == nil && (opts.Policy != PullIfNotPresentByDigest || matchesDigest(img, ref)) {
+			return img.ID, nil
+		}
+		if err != nil && !errors.Is(err, ErrNoSuchImage) {
+			return "", err
+		}
+	}
+	repository, tag := ParseRepositoryTag(ref)
+	if i := strings.Index(ref, "@"); i >= 0 {
+		repository, tag = ref[:i], ref[i+1:]
+	} else if tag == "" {
+		tag = DefaultTag
+	}
+	err := c.PullImage(PullImageOptions{
+		Repository:        repository,
+		Tag:               tag,
+		OutputStream:      opts.OutputStream,
+		RawJSONStream:     opts.RawJSONStream,
+		InactivityTimeout: opts.InactivityTimeout,
+		Context:           opts.Context,
+	}, auth)
+	if err != nil {
+		return "", err
+	}
+	img, err := c.InspectImageWithContext(ref, opts.Context)
+	if err != nil {
+		return "", err
+	}
+	return img.ID, nil
+}
+
+// matchesDigest reports whether img was pulled from the repository and with
+// the digest in ref. It returns true for references without a digest.
+func matchesDigest(img *Image, ref string) bool {
+	if !strings.Contains(ref, "@") {
+		return true
+	}
+	want, err := ParseImageReference(ref)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(img.RepoDigests, func(repoDigest string) bool {
+		got, err := ParseImageReference(repoDigest)
+		return err == nil && got.Name() == want.Name() && got.Digest == want.Digest
+	})
+}
@@ -0,0 +1,105 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestEnsureImage(t *testing.T) {
+	t.Parallel()
+	const digest = "sha256:4a731fb46adc5cefe3ae374a8b6020fc1b6ad667a279647766e9a3cd89f6fa92"
+	tests := []struct {
+		name       string
+		ref        string
+		policy     PullPolicy
+		present    bool
+		repoDigest string
+		wantPull   bool
+		wantTag    string
+		wantRepo   string
+	}{
+		{name: "present", ref: "busybox", present: true},
+		{name: "missing", ref: "busybox", wantPull: true, wantRepo: "busybox", wantTag: "latest"},
+		{name: "missing with tag", ref: "busybox:1.36", wantPull: true, wantRepo: "busybox", wantTag: "1.36"},
+		{name: "missing with digest", ref: "busybox@" + digest, wantPull: true, wantRepo: "busybox", wantTag: digest},
+		{name: "always", ref: "busybox", policy: PullAlways, present: true, wantPull: true, wantRepo: "busybox", wantTag: "latest"},
+		{name: "digest mismatch", ref: "busybox@" + digest, policy: PullIfNotPresentByDigest, present: true, wantPull: true, wantRepo: "busybox", wantTag: digest},
+		{name: "by digest without digest", ref: "busybox", policy: PullIfNotPresentByDigest, present: true},
+		{name: "digest match", ref: "busybox@" + digest, policy: PullIfNotPresentByDigest, present: true, repoDigest: "docker.io/library/busybox@" + digest},
+		{name: "digest of another repository", ref: "busybox@" + digest, policy: PullIfNotPresentByDigest, present: true, repoDigest: "alpine@" + digest, wantPull: true, wantRepo: "busybox", wantTag: digest},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			present := test.present
+			repoDigest := test.repoDigest
+			if repoDigest == "" {
+				repoDigest = "busybox@sha256:other"
+			}
+			var pullQuery url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					pullQuery = r.URL.Query()
+					present = true
+					return
+				}
+				if !present {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				fmt.Fprintf(w, `{"Id":"sha256:abc","RepoDigests":[%q]}`, repoDigest)
+			}))
+			defer server.Close()
+			client, _ := NewClient(server.URL)
+			client.SkipServerVersionCheck = true
+			id, err := client.EnsureImage(test.ref, AuthConfiguration{}, EnsureImageOptions{Policy: test.policy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != "sha256:abc" {
+				t.Errorf("EnsureImage: wrong ID. Want %q. Got %q.", "sha256:abc", id)
+			}
+			if (pullQuery != nil) != test.wantPull {
+				t.Fatalf("EnsureImage: wrong pull behavior. Want pull: %v.", test.wantPull)
+			}
+			if test.wantPull {
+				if got := pullQuery.Get("fromImage"); got != test.wantRepo {
+					t.Errorf("EnsureImage: wrong repository. Want %q. Got %q.", test.wantRepo, got)
+				}
+				if got := pullQuery.Get("tag"); got != test.wantTag {
+					t.Errorf("EnsureImage: wrong tag. Want %q. Got %q.", test.wantTag, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEnsureImageContext(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"Id":"sha256:abc"}`))
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.EnsureImage("busybox", AuthConfiguration{}, EnsureImageOptions{Context: ctx})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("EnsureImage: wrong error. Want %#v. Got %#v.", context.Canceled, err)
+	}
+}
+
+func TestEnsureImageNoName(t *testing.T) {
+	t.Parallel()
+	var client Client
+	if _, err := client.EnsureImage("", AuthConfiguration{}, EnsureImageOptions{}); err != ErrNoSuchImage {
+		t.Errorf("EnsureImage: wrong error. Want %#v. Got %#v.", ErrNoSuchImage, err)
+	}
+}
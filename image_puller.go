@@ -0,0 +1,230 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultImagePullParallelism is the number of images an ImagePuller pulls
+// at once when no limit is given.
+const DefaultImagePullParallelism = 3
+
+// ImagePullProgress is a snapshot of the progress of a pull managed by an
+// ImagePuller.
+type ImagePullProgress struct {
+	// Reference is the normalized reference of the image being pulled.
+	Reference string
+
+	// Queued is true while the pull waits for one of the slots of the
+	// puller.
+	Queued bool
+
+	// Status is the status of the last message sent by the daemon, like
+	// "Downloading" or "Pull complete".
+	Status string
+
+	// Layers holds the progress of every layer reported by the daemon, by
+	// layer ID. Current and Total are the sums of the layers' progress.
+	Layers  map[string]JSONProgress
+	Current int64
+	Total   int64
+
+	// Waiters is the number of callers waiting for the pull.
+	Waiters int
+}
+
+// ImagePuller pulls images on behalf of many goroutines, deduplicating
+// concurrent pulls of the same image and limiting the number of pulls
+// running at once. References are normalized before being compared, so
+// "busybox" and "docker.io/library/busybox:latest" share a single pull.
+// Pulls of the same image with different credentials aren't shared.
+//
+// It's safe for concurrent use.
+type ImagePuller struct {
+	client *Client
+	sem    chan struct{}
+
+	mu    sync.Mutex
+	pulls map[string]*imagePull
+}
+
+// imagePull is a pull shared by the callers asking for the same image.
+type imagePull struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	// progress is guarded by the mutex of the puller.
+	progress ImagePullProgress
+
+	// result and err are set before done is closed.
+	result *PullImageResult
+	err    error
+}
+
+// NewImagePuller returns an ImagePuller that pulls at most parallelism
+// images at once using client. DefaultImagePullParallelism is used when
+// parallelism isn't positive.
+func NewImagePuller(client *Client, parallelism int) *ImagePuller {
+	if parallelism <= 0 {
+		parallelism = DefaultImagePullParallelism
+	}
+	return &ImagePuller{
+		client: client,
+		sem:    make(chan struct{}, parallelism),
+		pulls:  make(map[string]*imagePull),
+	}
+}
+
+// Pull pulls the image described by opts, joining the pull of the same
+// image started by another caller, if any, and returns its result.
+//
+// The options of the caller that starts a pull are the ones used, so only
+// its OutputStream and JSONMessageHandler receive the progress of the pull.
+// The Context of every caller only applies to its own wait: the pull is
+// canceled once all of its callers are gone.
+func (p *ImagePuller) Pull(opts PullImageOptions, auth AuthConfiguration) (*PullImageResult, error) {
+	if opts.Repository == "" {
+		return nil, ErrNoSuchImage
+	}
+	key := imagePullKey(opts, auth)
+	p.mu.Lock()
+	pull, ok := p.pulls[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		pull = &imagePull{
+			done:     make(chan struct{}),
+			cancel:   cancel,
+			progress: ImagePullProgress{Reference: imagePullReference(opts), Queued: true},
+		}
+		p.pulls[key] = pull
+		go p.run(ctx, key, pull, opts, auth)
+	}
+	pull.waiters++
+	p.mu.Unlock()
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	select {
+	case <-pull.done:
+		return pull.result, pull.err
+	case <-ctx.Done():
+		p.mu.Lock()
+		pull.waiters--
+		if pull.waiters == 0 {
+			// new callers start a new pull instead of joining a canceled one
+			if p.pulls[key] == pull {
+				delete(p.pulls, key)
+			}
+			pull.cancel()
+		}
+		p.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// Progress returns the progress of the running pull of the given
+// reference. ok is false if the image isn't being pulled. When the image is
+// being pulled with different credentials, the progress of any of those pulls
+// is returned.
+func (p *ImagePuller) Progress(ref string) (progress ImagePullProgress, ok bool) {
+	ref = imagePullReference(PullImageOptions{Repository: ref})
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, pull := range p.pulls {
+		if pull.progress.Reference != ref {
+			continue
+		}
+		progress = pull.progress
+		progress.Waiters = pull.waiters
+		progress.Layers = make(map[string]JSONProgress, len(pull.progress.Layers))
+		for id, layer := range pull.progress.Layers {
+			progress.Layers[id] = layer
+		}
+		return progress, true
+	}
+	return ImagePullProgress{}, false
+}
+
+func (p *ImagePuller) run(ctx context.Context, key string, pull *imagePull, opts PullImageOptions, auth AuthConfiguration) {
+	defer func() {
+		p.mu.Lock()
+		if p.pulls[key] == pull {
+			delete(p.pulls, key)
+		}
+		p.mu.Unlock()
+		pull.cancel()
+		close(pull.done)
+	}()
+	select {
+	case p.sem <- struct{}{}:
+		defer func() { <-p.sem }()
+	case <-ctx.Done():
+		pull.err = ctx.Err()
+		return
+	}
+	p.mu.Lock()
+	pull.progress.Queued = false
+	p.mu.Unlock()
+	opts.Context = ctx
+	opts.JSONMessageHandler = chainJSONMessageHandlers(func(msg *JSONMessage) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		pull.progress.update(msg)
+	}, opts.JSONMessageHandler)
+	pull.result, pull.err = p.client.PullImageWithResult(opts, auth)
+}
+
+func (progress *ImagePullProgress) update(msg *JSONMessage) {
+	if msg.Status != "" {
+		progress.Status = msg.Status
+	}
+	if msg.ID == "" || msg.Progress == nil {
+		return
+	}
+	if progress.Layers == nil {
+		progress.Layers = make(map[string]JSONProgress)
+	}
+	previous := progress.Layers[msg.ID]
+	progress.Layers[msg.ID] = *msg.Progress
+	progress.Current += msg.Progress.Current - previous.Current
+	progress.Total += msg.Progress.Total - previous.Total
+}
+
+// imagePullReference returns the reference of the image described by opts,
+// normalized when it's valid.
+func imagePullReference(opts PullImageOptions) string {
+	ref := opts.Repository
+	if opts.Tag != "" {
+		if strings.Contains(opts.Tag, ":") {
+			ref += "@" + opts.Tag
+		} else {
+			ref += ":" + opts.Tag
+		}
+	}
+	ref = normalizeDigestRef(ref)
+	if normalized, err := NormalizeImageReference(ref); err == nil {
+		return normalized
+	}
+	return ref
+}
+
+// imagePullKey identifies the pulls that can be shared. Pulls made with
+// different credentials aren't shared, as they may not have the same outcome.
+func imagePullKey(opts PullImageOptions, auth AuthConfiguration) string {
+	key := imagePullReference(opts)
+	if opts.Platform != "" {
+		key += " " + opts.Platform
+	}
+	if !auth.isEmpty() {
+		data, _ := json.Marshal(auth)
+		key += fmt.Sprintf(" %x", sha256.Sum256(data))
+	}
+	return key
+}
@@ -0,0 +1,218 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newImagePullerTestServer(t *testing.T, release <-chan struct{}, handle func(w http.ResponseWriter, r *http.Request)) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if handle != nil {
+			handle(w, r)
+		}
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	return client
+}
+
+func TestImagePullerDeduplicates(t *testing.T) {
+	t.Parallel()
+	var requests atomic.Int32
+	release := make(chan struct{})
+	client := newImagePullerTestServer(t, release, func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"status":"Digest: sha256:abc"}` + "\n"))
+	})
+	puller := NewImagePuller(client, 0)
+	refs := []PullImageOptions{
+		{Repository: "busybox"},
+		{Repository: "busybox", Tag: "latest"},
+		{Repository: "docker.io/library/busybox:latest"},
+		{Repository: "index.docker.io/library/busybox"},
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(refs))
+	digests := make([]string, len(refs))
+	for i, opts := range refs {
+		wg.Add(1)
+		go func(i int, opts PullImageOptions) {
+			defer wg.Done()
+			result, err := puller.Pull(opts, AuthConfiguration{})
+			errs[i] = err
+			if result != nil {
+				digests[i] = result.Digest
+			}
+		}(i, opts)
+	}
+	waitForPullWaiters(t, puller, "busybox", len(refs))
+	close(release)
+	wg.Wait()
+	for i := range refs {
+		if errs[i] != nil {
+			t.Errorf("Pull(%#v): unexpected error: %v", refs[i], errs[i])
+		}
+		if digests[i] != "sha256:abc" {
+			t.Errorf("Pull(%#v): wrong digest %q", refs[i], digests[i])
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("ImagePuller: wrong number of pulls. Want 1. Got %d.", n)
+	}
+	if _, ok := puller.Progress("busybox"); ok {
+		t.Error("ImagePuller: finished pull still reported")
+	}
+}
+
+func TestImagePullerDifferentCredentials(t *testing.T) {
+	t.Parallel()
+	var requests atomic.Int32
+	release := make(chan struct{})
+	client := newImagePullerTestServer(t, release, func(http.ResponseWriter, *http.Request) {
+		requests.Add(1)
+	})
+	puller := NewImagePuller(client, 0)
+	auths := []AuthConfiguration{{Username: "alice", Password: "a"}, {Username: "bob", Password: "b"}}
+	var wg sync.WaitGroup
+	for _, auth := range auths {
+		wg.Add(1)
+		go func(auth AuthConfiguration) {
+			defer wg.Done()
+			if _, err := puller.Pull(PullImageOptions{Repository: "busybox"}, auth); err != nil {
+				t.Errorf("Pull(%s): unexpected error: %v", auth.Username, err)
+			}
+		}(auth)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for requests.Load() < int32(len(auths)) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if n := requests.Load(); n != int32(len(auths)) {
+		t.Errorf("ImagePuller: wrong number of pulls. Want %d. Got %d.", len(auths), n)
+	}
+}
+
+func TestImagePullerParallelism(t *testing.T) {
+	t.Parallel()
+	var running, maxRunning atomic.Int32
+	release := make(chan struct{})
+	client := newImagePullerTestServer(t, release, func(http.ResponseWriter, *http.Request) {
+		n := running.Add(1)
+		for {
+			current := maxRunning.Load()
+			if n <= current || maxRunning.CompareAndSwap(current, n) {
+				break
+			}
+		}
+	})
+	puller := NewImagePuller(client, 1)
+	var wg sync.WaitGroup
+	for _, repo := range []string{"busybox", "alpine"} {
+		wg.Add(1)
+		go func(repo string) {
+			defer wg.Done()
+			if _, err := puller.Pull(PullImageOptions{Repository: repo}, AuthConfiguration{}); err != nil {
+				t.Error(err)
+			}
+			running.Add(-1)
+		}(repo)
+	}
+	waitForPullWaiters(t, puller, "busybox", 1)
+	waitForPullWaiters(t, puller, "alpine", 1)
+	queued := 0
+	for _, repo := range []string{"busybox", "alpine"} {
+		if progress, _ := puller.Progress(repo); progress.Queued {
+			queued++
+		}
+	}
+	if queued != 1 {
+		t.Errorf("ImagePuller: wrong number of queued pulls. Want 1. Got %d.", queued)
+	}
+	close(release)
+	wg.Wait()
+	if n := maxRunning.Load(); n != 1 {
+		t.Errorf("ImagePuller: wrong number of concurrent pulls. Want 1. Got %d.", n)
+	}
+}
+
+func TestImagePullerProgress(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	client := newImagePullerTestServer(t, release, func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"status":"Downloading","id":"layer1","progressDetail":{"current":10,"total":100}}` + "\n"))
+		w.Write([]byte(`{"status":"Downloading","id":"layer2","progressDetail":{"current":5,"total":50}}` + "\n"))
+		w.Write([]byte(`{"status":"Downloading","id":"layer1","progressDetail":{"current":40,"total":100}}` + "\n"))
+	})
+	puller := NewImagePuller(client, 0)
+	done := make(chan error, 1)
+	go func() {
+		_, err := puller.Pull(PullImageOptions{Repository: "quay.io/coreos/etcd", Tag: "v3"}, AuthConfiguration{})
+		done <- err
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	var progress ImagePullProgress
+	for time.Now().Before(deadline) {
+		progress, _ = puller.Progress("quay.io/coreos/etcd:v3")
+		if progress.Current == 45 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if progress.Current != 45 || progress.Total != 150 || len(progress.Layers) != 2 || progress.Status != "Downloading" {
+		t.Errorf("ImagePuller: wrong progress: %#v", progress)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImagePullerCancel(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	defer close(release)
+	client := newImagePullerTestServer(t, release, nil)
+	puller := NewImagePuller(client, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := puller.Pull(PullImageOptions{Repository: "busybox", Context: ctx}, AuthConfiguration{})
+		done <- err
+	}()
+	waitForPullWaiters(t, puller, "busybox", 1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Pull: wrong error. Want %v. Got %v.", context.Canceled, err)
+	}
+	if _, ok := puller.Progress("busybox"); ok {
+		t.Error("ImagePuller: canceled pull still reported")
+	}
+}
+
+func waitForPullWaiters(t *testing.T, puller *ImagePuller, ref string, waiters int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if progress, ok := puller.Progress(ref); ok && progress.Waiters == waiters {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d callers pulling %s", waiters, ref)
+}
@@ -6,15 +6,18 @@ package docker
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -243,6 +246,65 @@ func TestRemoveImageExtended(t *testing.T) {
 	}
 }
 
+func TestRemoveImageWithResult(t *testing.T) {
+	t.Parallel()
+	body := `[{"Untagged":"test:latest"},{"Deleted":"sha256:3e2f21a89f"},{"Deleted":"sha256:53b4f83ac9"}]`
+	client := newTestClient(&FakeRoundTripper{message: body, status: http.StatusOK})
+	removed, err := client.RemoveImageWithResult("test", RemoveImageOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []RemovedImage{
+		{Untagged: "test:latest"},
+		{Deleted: "sha256:3e2f21a89f"},
+		{Deleted: "sha256:53b4f83ac9"},
+	}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Errorf("RemoveImageWithResult: wrong result. Want %#v. Got %#v.", expected, removed)
+	}
+}
+
+func TestRemoveImageNonJSONBody(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "ok", status: http.StatusOK})
+	if err := client.RemoveImage("test"); err != nil {
+		t.Errorf("RemoveImage: unexpected error: %v", err)
+	}
+	if err := client.RemoveImageExtended("test", RemoveImageOptions{Force: true}); err != nil {
+		t.Errorf("RemoveImageExtended: unexpected error: %v", err)
+	}
+}
+
+func TestRemoveImageWithResultEmptyBody(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{status: http.StatusOK})
+	removed, err := client.RemoveImageWithResult("test", RemoveImageOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("RemoveImageWithResult: wrong result. Want empty. Got %#v.", removed)
+	}
+}
+
+func TestRemoveImageInUse(t *testing.T) {
+	t.Parallel()
+	message := "conflict: unable to remove repository reference \"test\" (must force) - container 1a2b3c is using its referenced image 3e2f21a89f"
+	client := newTestClient(&FakeRoundTripper{message: message, status: http.StatusConflict})
+	err := client.RemoveImageExtended("test", RemoveImageOptions{})
+	if !errors.Is(err, ErrImageInUse) {
+		t.Fatalf("RemoveImageExtended: wrong error. Want %#v. Got %#v.", ErrImageInUse, err)
+	}
+	var inUse *ImageInUse
+	if !errors.As(err, &inUse) || inUse.Name != "test" {
+		t.Errorf("RemoveImageExtended: wrong error. Want an ImageInUse error for %q. Got %#v.", "test", err)
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Message != message {
+		t.Errorf("RemoveImageExtended: the error of the daemon isn't wrapped. Got %#v.", err)
+	}
+}
+
 func TestInspectImage(t *testing.T) {
 	t.Parallel()
 	body := `{
@@ -302,6 +364,63 @@ func TestInspectImage(t *testing.T) {
 	}
 }
 
+func TestInspectImageDescriptor(t *testing.T) {
+	t.Parallel()
+	body := `{
+     "Id":"sha256:b750fe79269d2ec9a3c593ef05b4332b1d1a02a62b4accb2c21d589ff2f5f2dc",
+     "RepoTags":["example/image:1.0"],
+     "RepoDigests":["example/image@sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580"],
+     "Architecture":"arm64",
+     "Variant":"v8",
+     "Os":"linux",
+     "Config":{"Labels":{"maintainer":"gopher"},"ExposedPorts":{"8080/tcp":{}}},
+     "GraphDriver":{"Name":"overlay2","Data":{"MergedDir":"/var/lib/docker/overlay2/abc/merged"}},
+     "Metadata":{"LastTagTime":"2024-01-02T03:04:05Z"},
+     "Descriptor":{
+       "mediaType":"application/vnd.oci.image.index.v1+json",
+       "digest":"sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580",
+       "size":1024,
+       "platform":{"architecture":"arm64","os":"linux","variant":"v8"}
+     }
+}`
+	fakeRT := &FakeRoundTripper{message: body, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	image, err := client.InspectImage("example/image:1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastTagTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expected := Image{
+		ID:           "sha256:b750fe79269d2ec9a3c593ef05b4332b1d1a02a62b4accb2c21d589ff2f5f2dc",
+		RepoTags:     []string{"example/image:1.0"},
+		RepoDigests:  []string{"example/image@sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580"},
+		Architecture: "arm64",
+		Variant:      "v8",
+		OS:           "linux",
+		Config: &Config{
+			Labels:       map[string]string{"maintainer": "gopher"},
+			ExposedPorts: map[Port]struct{}{"8080/tcp": {}},
+		},
+		GraphDriver: &GraphDriver{
+			Name: "overlay2",
+			Data: map[string]string{"MergedDir": "/var/lib/docker/overlay2/abc/merged"},
+		},
+		Metadata: &ImageMetadata{LastTagTime: lastTagTime},
+		Descriptor: &ImageDescriptor{
+			MediaType: "application/vnd.oci.image.index.v1+json",
+			Digest:    "sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580",
+			Size:      1024,
+			Platform:  &ImagePlatform{Architecture: "arm64", OS: "linux", Variant: "v8"},
+		},
+	}
+	if !reflect.DeepEqual(*image, expected) {
+		t.Errorf("InspectImage: Wrong image returned. Want %#v. Got %#v.", expected, *image)
+	}
+	if labels := image.Labels(); !reflect.DeepEqual(labels, expected.Config.Labels) {
+		t.Errorf("Labels: wrong labels. Want %#v. Got %#v.", expected.Config.Labels, labels)
+	}
+}
+
 func TestInspectImageNotFound(t *testing.T) {
 	t.Parallel()
 	client := newTestClient(&FakeRoundTripper{message: "no such image", status: http.StatusNotFound})
@@ -315,6 +434,20 @@ func TestInspectImageNotFound(t *testing.T) {
 	}
 }
 
+func TestInspectImageByDigest(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"Id":"b750fe79269d"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	const digest = "sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580"
+	if _, err := client.InspectImage("tsuru/bs:latest@" + digest); err != nil {
+		t.Fatal(err)
+	}
+	expectedPath := "/images/tsuru/bs@" + digest + "/json"
+	if path := fakeRT.requests[0].URL.Path; path != expectedPath {
+		t.Errorf("InspectImage: wrong path. Want %q. Got %q.", expectedPath, path)
+	}
+}
+
 func TestPushImage(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "Pushing 1/100", status: http.StatusOK}
@@ -428,6 +561,34 @@ func TestPushImageCustomRegistry(t *testing.T) {
 	}
 }
 
+func TestPushImageWithResult(t *testing.T) {
+	t.Parallel()
+	message := `{"status":"The push refers to repository [docker.io/fsouza/go-dockerclient]"}
+{"status":"latest: digest: sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580 size: 528"}
+{"progressDetail":{},"aux":{"Tag":"latest","Digest":"sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580","Size":528}}`
+	fakeRT := &FakeRoundTripper{message: message, status: http.StatusOK, header: map[string]string{"Content-Type": "application/json"}}
+	client := newTestClient(fakeRT)
+	var buf bytes.Buffer
+	opts := PushImageOptions{Name: "fsouza/go-dockerclient", Tag: "latest", OutputStream: &buf}
+	result, err := client.PushImageWithResult(opts, AuthConfiguration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := PushImageResult{
+		Tag:    "latest",
+		Digest: "sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580",
+		Size:   528,
+	}
+	if *result != expected {
+		t.Errorf("PushImageWithResult: wrong result. Want %#v. Got %#v.", expected, *result)
+	}
+	expectedQuery := map[string][]string{"tag": {"latest"}}
+	got := map[string][]string(fakeRT.requests[0].URL.Query())
+	if !reflect.DeepEqual(got, expectedQuery) {
+		t.Errorf("PushImageWithResult: wrong query string. Want %#v. Got %#v.", expectedQuery, got)
+	}
+}
+
 func TestPushImageNoName(t *testing.T) {
 	t.Parallel()
 	client := Client{}
@@ -490,7 +651,7 @@ func TestPullImageWithDigest(t *testing.T) {
 		t.Errorf("PullImage: Wrong request path. Want %q. Got %q.", u.Path, req.URL.Path)
 	}
 	expectedQuery := url.Values{
-		"fromImage": {"tsuru/bs:latest"},
+		"fromImage": {"tsuru/bs"},
 		"tag":       {"sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580"},
 	}
 	if !reflect.DeepEqual(req.URL.Query(), expectedQuery) {
@@ -498,6 +659,31 @@ func TestPullImageWithDigest(t *testing.T) {
 	}
 }
 
+func TestPullImageWithDigestOnly(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		repository string
+		fromImage  string
+	}{
+		{"tsuru/bs@sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580", "tsuru/bs"},
+		{"localhost:5000/tsuru/bs:1.0@sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580", "localhost:5000/tsuru/bs"},
+	}
+	for _, test := range tests {
+		fakeRT := &FakeRoundTripper{message: "Pulling 1/100", status: http.StatusOK}
+		client := newTestClient(fakeRT)
+		if _, err := client.PullImageWithResult(PullImageOptions{Repository: test.repository}, AuthConfiguration{}); err != nil {
+			t.Fatal(err)
+		}
+		expectedQuery := url.Values{
+			"fromImage": {test.fromImage},
+			"tag":       {"sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580"},
+		}
+		if query := fakeRT.requests[0].URL.Query(); !reflect.DeepEqual(query, expectedQuery) {
+			t.Errorf("PullImage(%q): Wrong query string\nWant %#v\nGot  %#v", test.repository, expectedQuery, query)
+		}
+	}
+}
+
 func TestPullImageWithDigestAndTag(t *testing.T) {
 	// This is probably a wrong use of the Docker API, but let's let users
 	// send the request to the API. And also changing this behavior would
@@ -628,6 +814,28 @@ func TestPullImageTag(t *testing.T) {
 	}
 }
 
+func TestPullImageWithResult(t *testing.T) {
+	t.Parallel()
+	message := `{"status":"Pulling from library/base","id":"latest"}
+{"status":"Digest: sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580"}
+{"status":"Status: Downloaded newer image for base:latest"}`
+	fakeRT := &FakeRoundTripper{message: message, status: http.StatusOK, header: map[string]string{"Content-Type": "application/json"}}
+	client := newTestClient(fakeRT)
+	var buf bytes.Buffer
+	opts := PullImageOptions{Repository: "base", Tag: "latest", OutputStream: &buf, RawJSONStream: true}
+	result, err := client.PullImageWithResult(opts, AuthConfiguration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedDigest := "sha256:504a2f04aa5d07768e4f7467ddd2618b07dd6013cfabca7dc527a3d9fa786580"
+	if result.Digest != expectedDigest {
+		t.Errorf("PullImageWithResult: wrong digest. Want %q. Got %q.", expectedDigest, result.Digest)
+	}
+	if buf.String() != message {
+		t.Errorf("PullImageWithResult: wrong output. Want %q. Got %q.", message, buf.String())
+	}
+}
+
 func TestPullImageNoRepository(t *testing.T) {
 	t.Parallel()
 	var opts PullImageOptions
@@ -753,6 +961,64 @@ func TestImportImageShouldPassTarContentToBodyWhenSourceIsFilePath(t *testing.T)
 	}
 }
 
+func TestImportImageCompress(t *testing.T) {
+	t.Parallel()
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if body, err = io.ReadAll(gz); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	content := "tar content"
+	opts := ImportImageOptions{
+		Source: "-", Repository: "testimage",
+		InputStream: strings.NewReader(content),
+		Compress:    true,
+	}
+	err := client.ImportImage(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != content {
+		t.Errorf("ImportImage: wrong decompressed body. Want %q. Got %q.", content, body)
+	}
+}
+
+func TestImportImageCompressClosedClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	client.Close()
+	opts := ImportImageOptions{
+		Source: "-", Repository: "testimage",
+		InputStream: bytes.NewReader(make([]byte, 1<<20)),
+		Compress:    true,
+	}
+	if err := client.ImportImage(opts); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("ImportImage: wrong error. Want %v. Got %v.", ErrClientClosed, err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		buf := make([]byte, 1<<20)
+		if !bytes.Contains(buf[:runtime.Stack(buf, true)], []byte("compressReader")) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("ImportImage: the compression goroutine leaked")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 func TestImportImageShouldChangeSourceToDashWhenItsAFilePath(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
@@ -775,6 +1041,41 @@ func TestImportImageShouldChangeSourceToDashWhenItsAFilePath(t *testing.T) {
 	}
 }
 
+func TestBuildImageJSONMessageHandler(t *testing.T) {
+	t.Parallel()
+	message := `{"stream":"Step 1/1 : FROM busybox"}
+{"aux":{"ID":"sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4"}}
+{"stream":"Successfully built a3ed95caeb02"}`
+	fakeRT := &FakeRoundTripper{message: message, status: http.StatusOK, header: map[string]string{"Content-Type": "application/json"}}
+	client := newTestClient(fakeRT)
+	var streams []string
+	var aux string
+	opts := BuildImageOptions{
+		Name:         "testImage",
+		InputStream:  &bytes.Buffer{},
+		OutputStream: &bytes.Buffer{},
+		JSONMessageHandler: func(msg *JSONMessage) {
+			if msg.Stream != "" {
+				streams = append(streams, msg.Stream)
+			}
+			if msg.Aux != nil {
+				aux = string(*msg.Aux)
+			}
+		},
+	}
+	if err := client.BuildImage(opts); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Step 1/1 : FROM busybox", "Successfully built a3ed95caeb02"}
+	if !reflect.DeepEqual(streams, expected) {
+		t.Errorf("BuildImage: wrong messages. Want %#v. Got %#v.", expected, streams)
+	}
+	expectedAux := `{"ID":"sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4"}`
+	if aux != expectedAux {
+		t.Errorf("BuildImage: wrong aux message. Want %q. Got %q.", expectedAux, aux)
+	}
+}
+
 func TestBuildImageParameters(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
@@ -839,6 +1140,88 @@ func TestBuildImageParameters(t *testing.T) {
 	}
 }
 
+func TestBuildImageInlineCache(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	var buf bytes.Buffer
+	opts := BuildImageOptions{
+		Name:         "registry.example.com/app:latest",
+		CacheFrom:    []string{"registry.example.com/app:latest"},
+		InlineCache:  true,
+		BuildArgs:    []BuildArg{{Name: "SOME_VAR", Value: "some_value"}},
+		Version:      BuilderBuildKit,
+		InputStream:  &buf,
+		OutputStream: &buf,
+	}
+	if err := client.BuildImage(opts); err != nil {
+		t.Fatal(err)
+	}
+	query := fakeRT.requests[0].URL.Query()
+	if got := query.Get("buildargs"); got != `{"BUILDKIT_INLINE_CACHE":"1","SOME_VAR":"some_value"}` {
+		t.Errorf("BuildImage: wrong build args: %s", got)
+	}
+	if got := query.Get("cachefrom"); got != `["registry.example.com/app:latest"]` {
+		t.Errorf("BuildImage: wrong cache sources: %s", got)
+	}
+}
+
+func TestBuildImageWithResult(t *testing.T) {
+	t.Parallel()
+	message := `{"stream":"Step 1/1 : FROM busybox\n"}
+{"aux":{"ID":"sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4"}}
+{"stream":"Successfully built a3ed95caeb02\n"}
+{"stream":"Successfully tagged app:latest\n"}
+{"stream":"Successfully tagged app:1.0\n"}`
+	fakeRT := &FakeRoundTripper{message: message, status: http.StatusOK, header: map[string]string{"Content-Type": "application/json"}}
+	client := newTestClient(fakeRT)
+	opts := BuildImageOptions{
+		Name:         "app:latest",
+		Tags:         []string{"app:1.0"},
+		Squash:       true,
+		InputStream:  &bytes.Buffer{},
+		OutputStream: &bytes.Buffer{},
+	}
+	result, err := client.BuildImageWithResult(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &BuildImageResult{
+		ImageID: "sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4",
+		Tags:    []string{"app:latest", "app:1.0"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("BuildImageWithResult: wrong result. Want %#v. Got %#v.", expected, result)
+	}
+	query := fakeRT.requests[0].URL.Query()
+	if got := query["t"]; !reflect.DeepEqual(got, []string{"app:latest", "app:1.0"}) {
+		t.Errorf("BuildImageWithResult: wrong tags: %#v", got)
+	}
+	if got := query.Get("squash"); got != "1" {
+		t.Errorf("BuildImageWithResult: wrong squash: %q", got)
+	}
+}
+
+func TestBuildImageWithResultRequestedTags(t *testing.T) {
+	t.Parallel()
+	message := `{"stream":"Successfully built a3ed95caeb02\n"}`
+	fakeRT := &FakeRoundTripper{message: message, status: http.StatusOK, header: map[string]string{"Content-Type": "application/json"}}
+	client := newTestClient(fakeRT)
+	opts := BuildImageOptions{
+		Tags:         []string{"app:1.0", "app:stable"},
+		InputStream:  &bytes.Buffer{},
+		OutputStream: &bytes.Buffer{},
+	}
+	result, err := client.BuildImageWithResult(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &BuildImageResult{ImageID: "a3ed95caeb02", Tags: []string{"app:1.0", "app:stable"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("BuildImageWithResult: wrong result. Want %#v. Got %#v.", expected, result)
+	}
+}
+
 func TestBuildImageParametersForRemoteBuild(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
@@ -862,6 +1245,42 @@ func TestBuildImageParametersForRemoteBuild(t *testing.T) {
 	}
 }
 
+func TestBuildImageRegistryConfig(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	client.serverAPIVersion = apiVersion124
+	var buf bytes.Buffer
+	opts := BuildImageOptions{
+		Name:         "testImage",
+		Remote:       "testing/data/container.tar",
+		OutputStream: &buf,
+		AuthConfigs: AuthConfigurations{Configs: map[string]AuthConfiguration{
+			"docker.io":      {Username: "hub", Password: "secret"},
+			"quay.io":        {Username: "quay", Password: "secret"},
+			"localhost:5000": {},
+		}},
+	}
+	if err := client.BuildImage(opts); err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.URLEncoding.DecodeString(fakeRT.requests[0].Header.Get("X-Registry-Config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]AuthConfiguration
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]AuthConfiguration{
+		"https://index.docker.io/v1/": {Username: "hub", Password: "secret", ServerAddress: "https://index.docker.io/v1/"},
+		"quay.io":                     {Username: "quay", Password: "secret", ServerAddress: "quay.io"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("BuildImage: wrong X-Registry-Config header. Want %#v. Got %#v.", expected, got)
+	}
+}
+
 func TestBuildImageMissingRepoAndNilInput(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
@@ -1037,6 +1456,29 @@ func TestExportImage(t *testing.T) {
 	}
 }
 
+func TestExportImageCompress(t *testing.T) {
+	t.Parallel()
+	content := "exported image tar content"
+	var buf bytes.Buffer
+	client := newTestClient(&FakeRoundTripper{message: content, status: http.StatusOK})
+	opts := ExportImageOptions{Name: "testimage", OutputStream: &buf, Compress: true}
+	err := client.ExportImage(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != content {
+		t.Errorf("ExportImage: wrong decompressed content. Want %q. Got %q.", content, got)
+	}
+}
+
 func TestExportImages(t *testing.T) {
 	t.Parallel()
 	var buf bytes.Buffer
@@ -1194,3 +1636,20 @@ func TestPruneImages(t *testing.T) {
 		t.Errorf("PruneImages: Expected %#v. Got %#v.", expected, got)
 	}
 }
+
+func TestLoadImageQuiet(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	err := client.LoadImage(LoadImageOptions{InputStream: strings.NewReader("tar"), Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := fakeRT.requests[0]
+	if req.URL.Path != "/images/load" {
+		t.Errorf("LoadImage: wrong URL. Expected %q. Got %q.", "/images/load", req.URL.Path)
+	}
+	if quiet := req.URL.Query().Get("quiet"); quiet != "1" {
+		t.Errorf("LoadImage: wrong quiet parameter. Want %q. Got %q.", "1", quiet)
+	}
+}
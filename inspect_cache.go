@@ -0,0 +1,287 @@
+package docker
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultInspectCacheTTL is how long the responses of inspect calls are
+// cached, unless another TTL is given to EnableInspectCache.
+const DefaultInspectCacheTTL = 30 * time.Second
+
+// InspectCacheOptions specify parameters to the EnableInspectCache function.
+type InspectCacheOptions struct {
+	// TTL is how long a response is served from the cache, defaulting to
+	// DefaultInspectCacheTTL.
+	TTL time.Duration
+
+	// WatchEvents invalidates the cached responses about containers and
+	// images as events about them, or about the connection of containers
+	// to networks, are received. Events may be lost while
+	// the client reconnects to the event stream, so the TTL still bounds
+	// how stale a response can be.
+	WatchEvents bool
+}
+
+type inspectCacheEntry struct {
+	body    []byte
+	names   []string
+	expires time.Time
+}
+
+// inspectCache is a read-through cache of the response bodies of the inspect
+// calls. Bodies are decoded on every hit, so callers never share values.
+type inspectCache struct {
+	ttl      time.Duration
+	listener chan *APIEvents
+	stop     chan struct{}
+
+	mu      sync.Mutex
+	entries map[string]inspectCacheEntry // by request path
+
+	// generation is incremented by every invalidation, so that responses
+	// fetched while one happens aren't cached
+	generation uint64
+}
+
+// EnableInspectCache makes InspectContainerWithOptions and InspectImage
+// serve their responses from a cache, for schedulers and other callers that
+// inspect the same containers and images repeatedly. Errors aren't cached.
+//
+// Responses are cached for the TTL in opts, and can be dropped earlier with
+// InvalidateInspectCache or, when opts.WatchEvents is set, as events about
+// the containers and images are received. Enabling the cache again replaces
+// the previous one.
+func (c *Client) EnableInspectCache(opts InspectCacheOptions) error {
+	cache := &inspectCache{
+		ttl:     opts.TTL,
+		entries: make(map[string]inspectCacheEntry),
+	}
+	if cache.ttl <= 0 {
+		cache.ttl = DefaultInspectCacheTTL
+	}
+	if opts.WatchEvents {
+		// events are filtered here, as the filters of a listener apply to
+		// the event monitor shared by all the listeners of the client
+		cache.listener = make(chan *APIEvents, 100)
+		cache.stop = make(chan struct{})
+		if err := c.AddEventListener(cache.listener); err != nil {
+			return err
+		}
+		c.goInternal(func() {
+			for {
+				select {
+				case event, ok := <-cache.listener:
+					if !ok {
+						return
+					}
+					switch event.Type {
+					case EventTypeContainer:
+						cache.invalidate(event.Actor.ID, event.Actor.Attributes["name"])
+					case EventTypeImage:
+						// pull and tag events name the image by the moved tag
+						cache.invalidate(withNormalizedReferences(event.Actor.ID, event.Actor.Attributes["name"])...)
+					case EventTypeNetwork:
+						// connect and disconnect events change the network
+						// settings of the container
+						if container := event.Actor.Attributes["container"]; container != "" {
+							cache.invalidate(container)
+						}
+					}
+				case <-cache.stop:
+					return
+				}
+			}
+		})
+	}
+	c.swapInspectCache(cache)
+	return nil
+}
+
+// DisableInspectCache disables the cache enabled by EnableInspectCache,
+// discarding the cached responses.
+func (c *Client) DisableInspectCache() {
+	c.swapInspectCache(nil)
+}
+
+func (c *Client) loadInspectCache() *inspectCache {
+	mu := c.locks.inspectCacheMu()
+	mu.RLock()
+	defer mu.RUnlock()
+	return c.inspectCache
+}
+
+func (c *Client) swapInspectCache(cache *inspectCache) {
+	mu := c.locks.inspectCacheMu()
+	mu.Lock()
+	previous := c.inspectCache
+	c.inspectCache = cache
+	mu.Unlock()
+	if previous != nil && previous.listener != nil {
+		c.RemoveEventListener(previous.listener)
+		close(previous.stop)
+	}
+}
+
+// InvalidateInspectCache drops the cached responses about the containers
+// and images with the given IDs or names, or all the cached responses when
+// no ID is given. It's a no-op when the cache isn't enabled.
+func (c *Client) InvalidateInspectCache(ids ...string) {
+	cache := c.loadInspectCache()
+	if cache == nil {
+		return
+	}
+	if len(ids) == 0 {
+		cache.mu.Lock()
+		cache.entries = make(map[string]inspectCacheEntry)
+		cache.generation++
+		cache.mu.Unlock()
+		return
+	}
+	cache.invalidate(ids...)
+}
+
+func (cache *inspectCache) invalidate(names ...string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.generation++
+	for key, entry := range cache.entries {
+		if matchesAny(entry.names, names) {
+			delete(cache.entries, key)
+		}
+	}
+}
+
+func matchesAny(names, candidates []string) bool {
+	for _, name := range names {
+		for _, candidate := range candidates {
+			if candidate != "" && name == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cachedInspect returns the body of the inspect response for path, from the
+// cache when it's enabled and has a fresh response, calling fetch otherwise.
+// name is the ID or name the caller used to refer to the object.
+func (c *Client) cachedInspect(path, name string, fetch func() (*http.Response, error)) ([]byte, error) {
+	cache := c.loadInspectCache()
+	var generation uint64
+	if cache != nil {
+		cache.mu.Lock()
+		entry, ok := cache.entries[path]
+		generation = cache.generation
+		cache.mu.Unlock()
+		if ok && time.Now().Before(entry.expires) {
+			return entry.body, nil
+		}
+	}
+	resp, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		var object struct {
+			ID   string `json:"Id"`
+			Name string
+		}
+		json.Unmarshal(body, &object)
+		names := []string{name, object.ID, strings.TrimPrefix(object.Name, "/")}
+		if strings.HasPrefix(path, "/images/") {
+			names = withNormalizedReferences(names...)
+		}
+		cache.mu.Lock()
+		// the response may predate an invalidation received meanwhile
+		if cache.generation == generation {
+			cache.entries[path] = inspectCacheEntry{
+				body:    body,
+				names:   names,
+				expires: time.Now().Add(cache.ttl),
+			}
+		}
+		cache.mu.Unlock()
+	}
+	return body, nil
+}
+
+// withNormalizedReferences returns names along with the normalized form of
+// the ones that are image references, so that "nginx" matches
+// "nginx:latest".
+func withNormalizedReferences(names ...string) []string {
+	result := append([]string(nil), names...)
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if normalized, err := NormalizeImageReference(name); err == nil && normalized != name {
+			result = append(result, normalized)
+		}
+	}
+	return result
+}
+
+// invalidateInspectCacheAfter drops the cached responses that may be made
+// stale by a request the client sent, with the given JSON body, so callers
+// see the effect of their own changes without waiting for events or the TTL.
+// Image references can't be told apart from the rest of the path, so any
+// request about images drops all the cached images.
+func (c *Client) invalidateInspectCacheAfter(method, path string, body []byte) {
+	if method == http.MethodGet || method == http.MethodHead {
+		return
+	}
+	cache := c.loadInspectCache()
+	if cache == nil {
+		return
+	}
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(parts) > 1 && strings.HasPrefix(parts[0], "v") {
+		if _, err := NewAPIVersion(parts[0][1:]); err == nil {
+			parts = parts[1:]
+		}
+	}
+	switch parts[0] {
+	case "containers":
+		if len(parts) < 2 || parts[1] == "create" {
+			return
+		}
+		if parts[1] == "prune" {
+			cache.invalidatePrefix("/containers/")
+			return
+		}
+		cache.invalidate(parts[1])
+	case "images", "build", "commit":
+		cache.invalidatePrefix("/images/")
+	case "networks":
+		if len(parts) == 3 && (parts[2] == "connect" || parts[2] == "disconnect") {
+			var opts struct{ Container string }
+			if json.Unmarshal(body, &opts) == nil && opts.Container != "" {
+				cache.invalidate(opts.Container)
+			}
+		}
+	}
+}
+
+func (cache *inspectCache) invalidatePrefix(prefix string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.generation++
+	for key := range cache.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(cache.entries, key)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package docker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInspectCacheHit(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"Id":"4fa6e0f0c678","Name":"/web"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	if err := client.EnableInspectCache(InspectCacheOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	first, err := client.InspectContainer("4fa6e0f0c678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Name = "changed"
+	second, err := client.InspectContainer("4fa6e0f0c678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fakeRT.requests) != 1 {
+		t.Errorf("InspectContainer: wrong number of requests. Want 1. Got %d.", len(fakeRT.requests))
+	}
+	if second.Name != "/web" {
+		t.Errorf("InspectContainer: cached value shared between calls. Got name %q.", second.Name)
+	}
+	// the cache is keyed by path, so the name is a different entry, but it's
+	// invalidated along with the ID
+	if _, err := client.InspectContainer("web"); err != nil {
+		t.Fatal(err)
+	}
+	client.InvalidateInspectCache("4fa6e0f0c678")
+	if _, err := client.InspectContainer("4fa6e0f0c678"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.InspectContainer("web"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fakeRT.requests) != 4 {
+		t.Errorf("InspectContainer: wrong number of requests after invalidation. Want 4. Got %d.", len(fakeRT.requests))
+	}
+}
+
+func TestInspectCacheTTL(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"Id":"a1b2c3"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	if err := client.EnableInspectCache(InspectCacheOptions{TTL: time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.InspectImage("base"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := client.InspectImage("base"); err != nil {
+		t.Fatal(err)
+	}
+	if len(fakeRT.requests) != 2 {
+		t.Errorf("InspectImage: wrong number of requests. Want 2. Got %d.", len(fakeRT.requests))
+	}
+}
+
+func TestInspectCacheDisabled(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"Id":"a1b2c3"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	if err := client.EnableInspectCache(InspectCacheOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	client.DisableInspectCache()
+	for i := 0; i < 2; i++ {
+		if _, err := client.InspectImage("base"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(fakeRT.requests) != 2 {
+		t.Errorf("InspectImage: wrong number of requests. Want 2. Got %d.", len(fakeRT.requests))
+	}
+}
+
+func TestInspectCacheInvalidatedByRequests(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		inspect func(*Client) error
+		mutate  func(*Client) error
+		cached  bool
+	}{
+		{
+			"stop container",
+			func(c *Client) error { _, err := c.InspectContainer("web"); return err },
+			func(c *Client) error { return c.StopContainer("web", 10) },
+			false,
+		},
+		{
+			"stop other container",
+			func(c *Client) error { _, err := c.InspectContainer("web"); return err },
+			func(c *Client) error { return c.StopContainer("db", 10) },
+			true,
+		},
+		{
+			"tag image",
+			func(c *Client) error { _, err := c.InspectImage("base"); return err },
+			func(c *Client) error { return c.TagImage("other", TagImageOptions{Repo: "base"}) },
+			false,
+		},
+		{
+			"connect network",
+			func(c *Client) error { _, err := c.InspectContainer("web"); return err },
+			func(c *Client) error { return c.ConnectNetwork("front", NetworkConnectionOptions{Container: "web"}) },
+			false,
+		},
+		{
+			"disconnect other container",
+			func(c *Client) error { _, err := c.InspectContainer("web"); return err },
+			func(c *Client) error { return c.DisconnectNetwork("front", NetworkConnectionOptions{Container: "db"}) },
+			true,
+		},
+		{
+			"list containers",
+			func(c *Client) error { _, err := c.InspectContainer("web"); return err },
+			func(c *Client) error { _, err := c.ListContainers(ListContainersOptions{}); return err },
+			true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fakeRT := &FakeRoundTripper{message: "[]", status: http.StatusOK}
+			client := newTestClient(fakeRT)
+			if err := client.EnableInspectCache(InspectCacheOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			fakeRT.message = `{"Id":"4fa6e0f0c678"}`
+			if err := tt.inspect(&client); err != nil {
+				t.Fatal(err)
+			}
+			fakeRT.message = "[]"
+			if err := tt.mutate(&client); err != nil {
+				t.Fatal(err)
+			}
+			fakeRT.message = `{"Id":"4fa6e0f0c678"}`
+			fakeRT.Reset()
+			if err := tt.inspect(&client); err != nil {
+				t.Fatal(err)
+			}
+			if cached := len(fakeRT.requests) == 0; cached != tt.cached {
+				t.Errorf("wrong cache state after the request. Want cached=%v. Got cached=%v.", tt.cached, cached)
+			}
+		})
+	}
+}
+
+func TestInspectCacheWatchEvents(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		inspect func(*Client) error
+		event   string
+	}{
+		{
+			"container",
+			"/containers/web/json",
+			`{"Id":"4fa6e0f0c678","Name":"/web"}`,
+			func(c *Client) error { _, err := c.InspectContainer("web"); return err },
+			`{"status":"start","id":"4fa6e0f0c678","from":"base:latest","time":1442421700}`,
+		},
+		{
+			"network connection",
+			"/containers/web/json",
+			`{"Id":"4fa6e0f0c678","Name":"/web"}`,
+			func(c *Client) error { _, err := c.InspectContainer("web"); return err },
+			`{"Type":"network","Action":"connect","Actor":{"ID":"7d86d31b1478","Attributes":{"container":"4fa6e0f0c678","name":"front"}},"time":1442421700}`,
+		},
+		{
+			"moved tag",
+			"/images/nginx/json",
+			`{"Id":"sha256:a2b1d0a5e0c9"}`,
+			func(c *Client) error { _, err := c.InspectImage("nginx"); return err },
+			`{"Type":"image","Action":"tag","Actor":{"ID":"sha256:0d17b565c37b","Attributes":{"name":"nginx:latest"}},"time":1442421700}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var inspects atomic.Int32
+			sendEvent := make(chan struct{})
+			done := make(chan struct{})
+			mux := http.NewServeMux()
+			mux.HandleFunc(tt.path, func(w http.ResponseWriter, _ *http.Request) {
+				inspects.Add(1)
+				w.Write([]byte(tt.body))
+			})
+			mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+				w.(http.Flusher).Flush()
+				select {
+				case <-sendEvent:
+				case <-done:
+					return
+				case <-r.Context().Done():
+					return
+				}
+				w.Write([]byte(tt.event + "\n"))
+				w.(http.Flusher).Flush()
+				select {
+				case <-done:
+				case <-r.Context().Done():
+				}
+			})
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+			t.Cleanup(func() { close(done) })
+			client, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client.SkipServerVersionCheck = true
+			t.Cleanup(func() { client.Close() })
+			if err := client.EnableInspectCache(InspectCacheOptions{WatchEvents: true}); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 2; i++ {
+				if err := tt.inspect(client); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if n := inspects.Load(); n != 1 {
+				t.Fatalf("wrong number of inspect requests. Want 1. Got %d.", n)
+			}
+			close(sendEvent)
+			deadline := time.Now().Add(5 * time.Second)
+			for inspects.Load() < 2 {
+				if time.Now().After(deadline) {
+					t.Fatal("cached response not invalidated by the event")
+				}
+				time.Sleep(5 * time.Millisecond)
+				if err := tt.inspect(client); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestInspectCacheCloseAfterDisable(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(done) })
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	if err := client.EnableInspectCache(InspectCacheOptions{WatchEvents: true}); err != nil {
+		t.Fatal(err)
+	}
+	client.DisableInspectCache()
+	closed := make(chan struct{})
+	go func() {
+		client.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close: timed out waiting for the cache to stop watching events")
+	}
+}
+
+func TestInspectCacheInvalidatedDuringFetch(t *testing.T) {
+	t.Parallel()
+	var inspects atomic.Int32
+	var client *Client
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if inspects.Add(1) == 1 {
+			// the container changes while the first response is sent
+			client.InvalidateInspectCache("web")
+		}
+		w.Write([]byte(`{"Id":"4fa6e0f0c678","Name":"/web"}`))
+	}))
+	t.Cleanup(server.Close)
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	if err := client.EnableInspectCache(InspectCacheOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := client.InspectContainer("web"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := inspects.Load(); n != 2 {
+		t.Errorf("InspectContainer: wrong number of requests. Want 2. Got %d.", n)
+	}
+}
@@ -0,0 +1,128 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// JSONProgress describes the progress of an operation reported in a
+// JSONMessage, like the download of an image layer.
+type JSONProgress struct {
+	Current    int64  `json:"current,omitempty" yaml:"current,omitempty" toml:"current,omitempty"`
+	Total      int64  `json:"total,omitempty" yaml:"total,omitempty" toml:"total,omitempty"`
+	Start      int64  `json:"start,omitempty" yaml:"start,omitempty" toml:"start,omitempty"`
+	HideCounts bool   `json:"hidecounts,omitempty" yaml:"hidecounts,omitempty" toml:"hidecounts,omitempty"`
+	Units      string `json:"units,omitempty" yaml:"units,omitempty" toml:"units,omitempty"`
+}
+
+// JSONError is the error reported by the daemon in a JSONMessage.
+type JSONError struct {
+	Code    int    `json:"code,omitempty" yaml:"code,omitempty" toml:"code,omitempty"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty" toml:"message,omitempty"`
+}
+
+func (e *JSONError) Error() string {
+	return e.Message
+}
+
+// JSONMessage is a message of the JSON progress stream sent by the daemon in
+// long operations, like pulling, pushing, building and loading images.
+type JSONMessage struct {
+	Stream          string           `json:"stream,omitempty" yaml:"stream,omitempty" toml:"stream,omitempty"`
+	Status          string           `json:"status,omitempty" yaml:"status,omitempty" toml:"status,omitempty"`
+	Progress        *JSONProgress    `json:"progressDetail,omitempty" yaml:"progressDetail,omitempty" toml:"progressDetail,omitempty"`
+	ProgressMessage string           `json:"progress,omitempty" yaml:"progress,omitempty" toml:"progress,omitempty"`
+	ID              string           `json:"id,omitempty" yaml:"id,omitempty" toml:"id,omitempty"`
+	From            string           `json:"from,omitempty" yaml:"from,omitempty" toml:"from,omitempty"`
+	Time            int64            `json:"time,omitempty" yaml:"time,omitempty" toml:"time,omitempty"`
+	TimeNano        int64            `json:"timeNano,omitempty" yaml:"timeNano,omitempty" toml:"timeNano,omitempty"`
+	Error           *JSONError       `json:"errorDetail,omitempty" yaml:"errorDetail,omitempty" toml:"errorDetail,omitempty"`
+	ErrorMessage    string           `json:"error,omitempty" yaml:"error,omitempty" toml:"error,omitempty"`
+	Aux             *json.RawMessage `json:"aux,omitempty" yaml:"aux,omitempty" toml:"aux,omitempty"`
+}
+
+// Err returns the error reported in the message, if any.
+func (m *JSONMessage) Err() error {
+	if m.Error != nil {
+		return m.Error
+	}
+	if m.ErrorMessage != "" {
+		return &JSONError{Message: m.ErrorMessage}
+	}
+	return nil
+}
+
+// DisplayJSONMessagesStream reads the JSON progress stream from in and
+// renders it to out the same way the Docker CLI does, drawing progress bars
+// when isTerminal is true (termFd must then be the file descriptor of the
+// terminal). When handler isn't nil, it's called with every decoded message.
+//
+// It returns the first error reported in the stream.
+func DisplayJSONMessagesStream(in io.Reader, out io.Writer, termFd uintptr, isTerminal bool, handler func(*JSONMessage)) error {
+	if handler != nil {
+		w := &jsonMessageWatcher{handler: handler}
+		defer w.flush()
+		in = io.TeeReader(in, w)
+	}
+	return jsonmessage.DisplayJSONMessagesStream(in, out, termFd, isTerminal, nil)
+}
+
+// jsonMessageWatcher is an io.Writer that decodes the newline-delimited JSON
+// messages written to it and hands each of them to handler. Lines that aren't
+// valid JSON messages are ignored.
+type jsonMessageWatcher struct {
+	handler func(*JSONMessage)
+	buf     bytes.Buffer
+}
+
+func (w *jsonMessageWatcher) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		w.handle(w.buf.Next(i + 1))
+	}
+	return len(p), nil
+}
+
+func (w *jsonMessageWatcher) flush() {
+	if w.buf.Len() > 0 {
+		w.handle(w.buf.Bytes())
+		w.buf.Reset()
+	}
+}
+
+func (w *jsonMessageWatcher) handle(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	var msg JSONMessage
+	if err := json.Unmarshal(line, &msg); err == nil {
+		w.handler(&msg)
+	}
+}
+
+// chainJSONMessageHandlers returns a handler calling each of the non-nil
+// given handlers in order, or nil if there are none.
+func chainJSONMessageHandlers(handlers ...func(*JSONMessage)) func(*JSONMessage) {
+	var chain []func(*JSONMessage)
+	for _, h := range handlers {
+		if h != nil {
+			chain = append(chain, h)
+		}
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+	return func(msg *JSONMessage) {
+		for _, h := range chain {
+			h(msg)
+		}
+	}
+}
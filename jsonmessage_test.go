@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDisplayJSONMessagesStream(t *testing.T) {
+	t.Parallel()
+	input := `{"status":"Pulling fs layer","id":"a3ed95caeb02"}
+{"status":"Downloading","progressDetail":{"current":512,"total":1024},"progress":"[=====>     ]","id":"a3ed95caeb02"}
+{"status":"Download complete","progressDetail":{},"id":"a3ed95caeb02"}
+`
+	var messages []JSONMessage
+	var out bytes.Buffer
+	err := DisplayJSONMessagesStream(strings.NewReader(input), &out, 0, false, func(msg *JSONMessage) {
+		messages = append(messages, *msg)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []JSONMessage{
+		{Status: "Pulling fs layer", ID: "a3ed95caeb02"},
+		{
+			Status:          "Downloading",
+			Progress:        &JSONProgress{Current: 512, Total: 1024},
+			ProgressMessage: "[=====>     ]",
+			ID:              "a3ed95caeb02",
+		},
+		{Status: "Download complete", Progress: &JSONProgress{}, ID: "a3ed95caeb02"},
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("DisplayJSONMessagesStream: wrong messages.\nWant %#v.\nGot  %#v.", expected, messages)
+	}
+	if !strings.Contains(out.String(), "a3ed95caeb02: Download complete") {
+		t.Errorf("DisplayJSONMessagesStream: wrong output. Got %q.", out.String())
+	}
+}
+
+func TestDisplayJSONMessagesStreamError(t *testing.T) {
+	t.Parallel()
+	input := `{"status":"Pulling repository"}
+{"errorDetail":{"code":404,"message":"manifest unknown"},"error":"manifest unknown"}`
+	var last JSONMessage
+	err := DisplayJSONMessagesStream(strings.NewReader(input), &bytes.Buffer{}, 0, false, func(msg *JSONMessage) {
+		last = *msg
+	})
+	if err == nil {
+		t.Fatal("DisplayJSONMessagesStream: expected non-nil error, got <nil>")
+	}
+	var jerr *JSONError
+	if msgErr := last.Err(); !errors.As(msgErr, &jerr) || jerr.Code != 404 {
+		t.Errorf("JSONMessage.Err(): wrong error. Got %#v.", msgErr)
+	}
+}
@@ -0,0 +1,88 @@
+package docker
+
+import (
+	"sort"
+	"strings"
+)
+
+// LabelFilter describes a set of labels to filter resources by, for use in
+// the Filters of ListContainersOptions, ListImagesOptions,
+// NetworkFilterOpts, EventsOptions and friends. Keys mapped to an empty value
+// match resources that have the label, regardless of its value.
+//
+// Example:
+//
+//	opts := docker.ListContainersOptions{
+//		Filters: docker.LabelFilter{"app": "web", "tier": ""}.Filters(),
+//	}
+type LabelFilter map[string]string
+
+// Values returns the filter as the sorted list of values expected by the
+// "label" filter of the API, like "app=web" or "tier".
+func (f LabelFilter) Values() []string {
+	values := make([]string, 0, len(f))
+	for key, value := range f {
+		if value == "" {
+			values = append(values, key)
+		} else {
+			values = append(values, key+"="+value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// Filters returns a new filters map containing only the label filter.
+func (f LabelFilter) Filters() map[string][]string {
+	return f.AddTo(nil)
+}
+
+// AddTo adds the label filter to the given filters map, returning the
+// resulting map, which is allocated when filters is nil.
+func (f LabelFilter) AddTo(filters map[string][]string) map[string][]string {
+	if filters == nil {
+		filters = make(map[string][]string)
+	}
+	if len(f) > 0 {
+		filters["label"] = append(filters["label"], f.Values()...)
+	}
+	return filters
+}
+
+// Matches reports whether the given labels satisfy the filter.
+func (f LabelFilter) Matches(labels map[string]string) bool {
+	for key, value := range f {
+		got, ok := labels[key]
+		if !ok || (value != "" && got != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLabelFilter parses values in the format used by the "label" filter of
+// the API, like "app=web" or "tier", into a LabelFilter.
+func ParseLabelFilter(values []string) LabelFilter {
+	f := make(LabelFilter, len(values))
+	for _, v := range values {
+		key, value, _ := strings.Cut(v, "=")
+		f[key] = value
+	}
+	return f
+}
+
+// Labels returns the labels defined in the container configuration, or nil if
+// the container has no configuration.
+func (c *Container) Labels() map[string]string {
+	if c.Config == nil {
+		return nil
+	}
+	return c.Config.Labels
+}
+
+// Label returns the value of the given label of the container, and whether
+// the container has the label.
+func (c *Container) Label(key string) (string, bool) {
+	value, ok := c.Labels()[key]
+	return value, ok
+}
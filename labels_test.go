@@ -0,0 +1,71 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabelFilterValues(t *testing.T) {
+	t.Parallel()
+	f := LabelFilter{"tier": "", "app": "web"}
+	expected := []string{"app=web", "tier"}
+	if got := f.Values(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("LabelFilter.Values(): wrong result. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestLabelFilterAddTo(t *testing.T) {
+	t.Parallel()
+	filters := map[string][]string{"status": {"running"}, "label": {"env=prod"}}
+	got := LabelFilter{"app": "web"}.AddTo(filters)
+	expected := map[string][]string{"status": {"running"}, "label": {"env=prod", "app=web"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("LabelFilter.AddTo(): wrong result. Want %#v. Got %#v.", expected, got)
+	}
+	got = LabelFilter{"app": "web"}.Filters()
+	expected = map[string][]string{"label": {"app=web"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("LabelFilter.Filters(): wrong result. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestLabelFilterMatches(t *testing.T) {
+	t.Parallel()
+	labels := map[string]string{"app": "web", "tier": "front"}
+	tests := []struct {
+		filter   LabelFilter
+		expected bool
+	}{
+		{LabelFilter{}, true},
+		{LabelFilter{"app": "web"}, true},
+		{LabelFilter{"tier": ""}, true},
+		{LabelFilter{"app": "db"}, false},
+		{LabelFilter{"env": ""}, false},
+	}
+	for _, test := range tests {
+		if got := test.filter.Matches(labels); got != test.expected {
+			t.Errorf("LabelFilter(%v).Matches(): want %v. Got %v.", test.filter, test.expected, got)
+		}
+	}
+}
+
+func TestParseLabelFilter(t *testing.T) {
+	t.Parallel()
+	got := ParseLabelFilter([]string{"app=web", "tier", "expr=a=b"})
+	expected := LabelFilter{"app": "web", "tier": "", "expr": "a=b"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseLabelFilter(): wrong result. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestContainerLabel(t *testing.T) {
+	t.Parallel()
+	var container Container
+	if _, ok := container.Label("app"); ok {
+		t.Error("Container.Label(): unexpected label in container without config")
+	}
+	container.Config = &Config{Labels: map[string]string{"app": "web"}}
+	if value, ok := container.Label("app"); !ok || value != "web" {
+		t.Errorf("Container.Label(): wrong result. Want %q. Got %q (%v).", "web", value, ok)
+	}
+}
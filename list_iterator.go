@@ -0,0 +1,138 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ListIterator iterates over the items of a listing, decoding them one at a
+// time as they're read from the response. The request is only sent on the
+// first call to Next, and stopping early with Close discards the rest of the
+// response without decoding it:
+//
+//	it := client.IterateContainers(docker.ListContainersOptions{All: true})
+//	defer it.Close()
+//	for it.Next() {
+//		container := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// Callers don't depend on how the items are fetched, so listings can be
+// paginated transparently if the API starts paginating them.
+type ListIterator[T any] struct {
+	fetch   func() (*http.Response, error)
+	body    io.ReadCloser
+	decoder *json.Decoder
+	value   T
+	err     error
+	done    bool
+}
+
+func newListIterator[T any](fetch func() (*http.Response, error)) *ListIterator[T] {
+	return &ListIterator[T]{fetch: fetch}
+}
+
+// Next advances the iterator to the next item, which is then available
+// through Value. It returns false when there are no more items, or when an
+// error happens, in which case Err returns it.
+func (it *ListIterator[T]) Next() bool {
+	if it.done {
+		return false
+	}
+	if it.decoder == nil {
+		if err := it.start(); err != nil {
+			return it.fail(err)
+		}
+		if it.done {
+			return false
+		}
+	}
+	if !it.decoder.More() {
+		if _, err := it.decoder.Token(); err != nil {
+			return it.fail(err)
+		}
+		it.Close()
+		return false
+	}
+	var value T
+	if err := it.decoder.Decode(&value); err != nil {
+		return it.fail(err)
+	}
+	it.value = value
+	return true
+}
+
+func (it *ListIterator[T]) start() error {
+	resp, err := it.fetch()
+	if err != nil {
+		return err
+	}
+	it.body = resp.Body
+	it.decoder = json.NewDecoder(resp.Body)
+	token, err := it.decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		// a null listing has no items
+		return it.Close()
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("unexpected %v in listing, expected an array", token)
+	}
+	return nil
+}
+
+func (it *ListIterator[T]) fail(err error) bool {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	it.err = err
+	it.Close()
+	return false
+}
+
+// Value returns the current item, set by the last call to Next that returned
+// true.
+func (it *ListIterator[T]) Value() T {
+	return it.value
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ListIterator[T]) Err() error {
+	return it.err
+}
+
+// Close stops the iteration, releasing the response. It's safe to call it
+// more than once, and after Next returns false.
+func (it *ListIterator[T]) Close() error {
+	it.done = true
+	if it.body == nil {
+		return nil
+	}
+	body := it.body
+	it.body = nil
+	return body.Close()
+}
+
+// IterateContainers returns an iterator over the containers matching the
+// given criteria, see ListContainers and ListIterator.
+func (c *Client) IterateContainers(opts ListContainersOptions) *ListIterator[APIContainers] {
+	return newListIterator[APIContainers](func() (*http.Response, error) {
+		return c.do(http.MethodGet, "/containers/json?"+queryString(opts), doOptions{context: opts.Context})
+	})
+}
+
+// IterateImages returns an iterator over the images matching the given
+// criteria, see ListImages and ListIterator.
+func (c *Client) IterateImages(opts ListImagesOptions) *ListIterator[APIImages] {
+	return newListIterator[APIImages](func() (*http.Response, error) {
+		return c.do(http.MethodGet, "/images/json?"+queryString(opts), doOptions{context: opts.Context})
+	})
+}
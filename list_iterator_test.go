@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestIterateContainers(t *testing.T) {
+	t.Parallel()
+	body := `[{"Id":"8dfafdbc3a40","Image":"base:latest"},{"Id":"9cd87474be90","Image":"base:latest"}]`
+	fakeRT := &FakeRoundTripper{message: body, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	it := client.IterateContainers(ListContainersOptions{All: true})
+	defer it.Close()
+	if len(fakeRT.requests) != 0 {
+		t.Fatal("IterateContainers: request sent before calling Next")
+	}
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"8dfafdbc3a40", "9cd87474be90"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("IterateContainers: wrong containers. Want %#v. Got %#v.", expected, ids)
+	}
+	if got := fakeRT.requests[0].URL.Query().Get("all"); got != "1" {
+		t.Errorf("IterateContainers: wrong all parameter. Want %q. Got %q.", "1", got)
+	}
+	if it.Next() {
+		t.Error("IterateContainers: Next returned true after the end")
+	}
+}
+
+func TestIterateImagesStopEarly(t *testing.T) {
+	t.Parallel()
+	// the rest of the listing is truncated, but it's never decoded
+	body := `[{"Id":"sha256:a1b2"},{"Id":"sha256:c3d4"},{"Id":`
+	client := newTestClient(&FakeRoundTripper{message: body, status: http.StatusOK})
+	it := client.IterateImages(ListImagesOptions{})
+	if !it.Next() {
+		t.Fatalf("IterateImages: unexpected end of iteration: %v", it.Err())
+	}
+	if id := it.Value().ID; id != "sha256:a1b2" {
+		t.Errorf("IterateImages: wrong image. Want %q. Got %q.", "sha256:a1b2", id)
+	}
+	if err := it.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if it.Next() {
+		t.Error("IterateImages: Next returned true after Close")
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("IterateImages: unexpected error: %v", err)
+	}
+}
+
+func TestIterateImagesErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		check  func(error) bool
+	}{
+		{"null", "null", http.StatusOK, func(err error) bool { return err == nil }},
+		{"truncated", `[{"Id":"sha256:a1b2"}`, http.StatusOK, func(err error) bool { return err != nil }},
+		{"empty", "", http.StatusOK, func(err error) bool { return errors.Is(err, io.ErrUnexpectedEOF) }},
+		{"not an array", `{"message":"oops"}`, http.StatusOK, func(err error) bool { return err != nil }},
+		{"server error", "oops", http.StatusInternalServerError, func(err error) bool {
+			var e *Error
+			return errors.As(err, &e) && e.Status == http.StatusInternalServerError
+		}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := newTestClient(&FakeRoundTripper{message: tt.body, status: tt.status})
+			it := client.IterateImages(ListImagesOptions{})
+			defer it.Close()
+			for it.Next() {
+			}
+			if !tt.check(it.Err()) {
+				t.Errorf("IterateImages: unexpected error: %v", it.Err())
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package docker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the streams a LogLine may come from.
+const (
+	LogStreamStdout = "stdout"
+	LogStreamStderr = "stderr"
+)
+
+// ErrInvalidLogLine is the error returned by ParseLogLine when the line
+// doesn't start with a timestamp.
+var ErrInvalidLogLine = errors.New("invalid log line: missing timestamp")
+
+// LogLine is a line of the output of a container, as returned by Logs or
+// AttachToContainer with Timestamps enabled.
+type LogLine struct {
+	Time    time.Time
+	Stream  string
+	Message string
+}
+
+// ParseLogLine splits a line of output requested with timestamps into its
+// time and message. The message doesn't include the line terminator.
+func ParseLogLine(stream, line string) (LogLine, error) {
+	line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	timestamp, message, _ := strings.Cut(line, " ")
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return LogLine{}, fmt.Errorf("%w: %q", ErrInvalidLogLine, line)
+	}
+	return LogLine{Time: t, Stream: stream, Message: message}, nil
+}
+
+// LogLineWriter is an io.Writer that parses the output of a container,
+// requested with timestamps, into log lines, passing each line to a handler.
+// Lines split across writes are reassembled before being parsed.
+//
+// A pair of LogLineWriters can be used as the OutputStream and ErrorStream
+// of LogsOptions, for instance:
+//
+//	handler := func(line docker.LogLine) error { ... }
+//	err := client.Logs(docker.LogsOptions{
+//		Container:    id,
+//		Stdout:       true,
+//		Stderr:       true,
+//		Timestamps:   true,
+//		OutputStream: docker.NewLogLineWriter(docker.LogStreamStdout, handler),
+//		ErrorStream:  docker.NewLogLineWriter(docker.LogStreamStderr, handler),
+//	})
+type LogLineWriter struct {
+	stream  string
+	handler func(LogLine) error
+
+	mu      sync.Mutex
+	partial []byte
+}
+
+// NewLogLineWriter returns a LogLineWriter for the given stream. An error
+// returned by handler, or by the parsing of a line, is returned by Write,
+// interrupting the operation writing the output.
+func NewLogLineWriter(stream string, handler func(LogLine) error) *LogLineWriter {
+	return &LogLineWriter{stream: stream, handler: handler}
+}
+
+// Write parses the complete lines in p, keeping an incomplete last line
+// until the rest of it is written.
+func (w *LogLineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.partial = append(w.partial, p...)
+			break
+		}
+		line := p[:i]
+		if len(w.partial) > 0 {
+			line = append(w.partial, line...)
+			w.partial = w.partial[:0]
+		}
+		p = p[i+1:]
+		if err := w.handle(line); err != nil {
+			return n - len(p), err
+		}
+	}
+	return n, nil
+}
+
+// Flush parses the incomplete line kept by the writer, if any. It should be
+// called once the output is over, as the last line of a container isn't
+// necessarily terminated.
+func (w *LogLineWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) == 0 {
+		return nil
+	}
+	line := w.partial
+	w.partial = nil
+	return w.handle(line)
+}
+
+func (w *LogLineWriter) handle(line []byte) error {
+	logLine, err := ParseLogLine(w.stream, string(line))
+	if err != nil {
+		return err
+	}
+	return w.handler(logLine)
+}
@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+	t.Parallel()
+	line, err := ParseLogLine(LogStreamStderr, "2024-05-01T10:00:00.123456789Z something happened\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := LogLine{
+		Time:    time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC),
+		Stream:  LogStreamStderr,
+		Message: "something happened",
+	}
+	if !line.Time.Equal(expected.Time) || line.Stream != expected.Stream || line.Message != expected.Message {
+		t.Errorf("ParseLogLine: wrong line. Want %#v. Got %#v.", expected, line)
+	}
+	if _, err := ParseLogLine(LogStreamStdout, "something happened"); !errors.Is(err, ErrInvalidLogLine) {
+		t.Errorf("ParseLogLine: wrong error. Want %#v. Got %#v.", ErrInvalidLogLine, err)
+	}
+}
+
+func TestLogLineWriterWithLogs(t *testing.T) {
+	t.Parallel()
+	frame := func(stream byte, data string) []byte {
+		return append([]byte{stream, 0, 0, 0, 0, 0, 0, byte(len(data))}, data...)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(frame(1, "2024-05-01T10:00:00.1Z first\n2024-05-01T10:00:00.2Z sec"))
+		w.Write(frame(2, "2024-05-01T10:00:00.3Z oops\n"))
+		w.Write(frame(1, "ond\n2024-05-01T10:00:00.4Z last"))
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	var lines []string
+	handler := func(line LogLine) error {
+		lines = append(lines, line.Stream+" "+line.Time.Format("05.0")+" "+line.Message)
+		return nil
+	}
+	stdout := NewLogLineWriter(LogStreamStdout, handler)
+	err := client.Logs(LogsOptions{
+		Container:    "a123456",
+		Stdout:       true,
+		Stderr:       true,
+		Timestamps:   true,
+		OutputStream: stdout,
+		ErrorStream:  NewLogLineWriter(LogStreamStderr, handler),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stdout.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"stdout 00.1 first", "stderr 00.3 oops", "stdout 00.2 second", "stdout 00.4 last"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("LogLineWriter: wrong lines. Want %#v. Got %#v.", expected, lines)
+	}
+}
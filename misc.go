@@ -55,6 +55,7 @@ type DockerInfo struct {
 	ExecutionDriver    string
 	LoggingDriver      string
 	CgroupDriver       string
+	CgroupVersion      string
 	NEventsListener    int
 	KernelVersion      string
 	OperatingSystem    string
@@ -161,7 +162,11 @@ type IndexInfo struct {
 //
 // See https://goo.gl/ElTHi2 for more details.
 func (c *Client) Info() (*DockerInfo, error) {
-	resp, err := c.do(http.MethodGet, "/info", doOptions{})
+	return c.infoWithContext(context.TODO())
+}
+
+func (c *Client) infoWithContext(ctx context.Context) (*DockerInfo, error) {
+	resp, err := c.do(http.MethodGet, "/info", doOptions{context: ctx})
 	if err != nil {
 		return nil, err
 	}
@@ -194,3 +199,15 @@ func ParseRepositoryTag(repoTag string) (repository string, tag string) {
 	}
 	return repoTag, ""
 }
+
+// normalizeDigestRef drops the tag from references that have both a tag and a
+// digest (name:tag@sha256:...), as the digest alone identifies the image and
+// not every API version accepts both.
+func normalizeDigestRef(ref string) string {
+	name, digest, ok := strings.Cut(ref, "@")
+	if !ok {
+		return ref
+	}
+	repository, _ := ParseRepositoryTag(name)
+	return repository + "@" + digest
+}
@@ -234,3 +234,23 @@ func TestParseRepositoryTag(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeDigestRef(t *testing.T) {
+	t.Parallel()
+	const digest = "sha256:4a731fb46adc5cefe3ae374a8b6020fc1b6ad667a279647766e9a3cd89f6fa92"
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"busybox", "busybox"},
+		{"busybox:latest", "busybox:latest"},
+		{"busybox@" + digest, "busybox@" + digest},
+		{"busybox:latest@" + digest, "busybox@" + digest},
+		{"localhost:5000/samalba/hipache:v1@" + digest, "localhost:5000/samalba/hipache@" + digest},
+	}
+	for _, test := range tests {
+		if got := normalizeDigestRef(test.input); got != test.expected {
+			t.Errorf("normalizeDigestRef(%q): want %q. Got %q.", test.input, test.expected, got)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 )
@@ -31,6 +32,10 @@ type Network struct {
 	Internal   bool
 	EnableIPv6 bool `json:"EnableIPv6"`
 	Labels     map[string]string
+
+	// Warnings holds the warnings sent by the daemon when the network was
+	// created. It's only set in the result of CreateNetwork.
+	Warnings []string `json:",omitempty"`
 }
 
 // Endpoint contains network resources allocated and used for a container in a network
@@ -68,6 +73,14 @@ type NetworkFilterOpts map[string]map[string]bool
 //
 // See goo.gl/zd2mx4 for more details.
 func (c *Client) FilteredListNetworks(opts NetworkFilterOpts) ([]Network, error) {
+	return c.FilteredListNetworksWithContext(opts, context.TODO())
+}
+
+// FilteredListNetworksWithContext returns all networks with the filters
+// applied. The context object can be used to cancel the request.
+//
+// See goo.gl/zd2mx4 for more details.
+func (c *Client) FilteredListNetworksWithContext(opts NetworkFilterOpts, ctx context.Context) ([]Network, error) {
 	params, err := json.Marshal(opts)
 	if err != nil {
 		return nil, err
@@ -75,7 +88,7 @@ func (c *Client) FilteredListNetworks(opts NetworkFilterOpts) ([]Network, error)
 	qs := make(url.Values)
 	qs.Add("filters", string(params))
 	path := "/networks?" + qs.Encode()
-	resp, err := c.do(http.MethodGet, path, doOptions{})
+	resp, err := c.do(http.MethodGet, path, doOptions{context: ctx})
 	if err != nil {
 		return nil, err
 	}
@@ -154,11 +167,97 @@ type IPAMConfig struct {
 	AuxAddress map[string]string `json:"AuxiliaryAddresses,omitempty"`
 }
 
+// IsIPv6 reports whether the subnet of the IPAM configuration is an IPv6
+// subnet.
+func (config IPAMConfig) IsIPv6() bool {
+	ip, _, err := net.ParseCIDR(config.Subnet)
+	return err == nil && ip.To4() == nil
+}
+
+// Validate checks the addresses of the IPAM configuration: the subnet and
+// the IP range must be valid CIDRs, and the gateway a valid IP address. When
+// the subnet is set, the IP range and the gateway must lie inside it.
+func (config IPAMConfig) Validate() error {
+	var subnet *net.IPNet
+	if config.Subnet != "" {
+		_, ipNet, err := net.ParseCIDR(config.Subnet)
+		if err != nil {
+			return fmt.Errorf("invalid subnet %q: %w", config.Subnet, err)
+		}
+		subnet = ipNet
+	}
+	if config.IPRange != "" {
+		_, ipRange, err := net.ParseCIDR(config.IPRange)
+		if err != nil {
+			return fmt.Errorf("invalid IP range %q: %w", config.IPRange, err)
+		}
+		if subnet != nil {
+			rangeOnes, _ := ipRange.Mask.Size()
+			subnetOnes, _ := subnet.Mask.Size()
+			if !subnet.Contains(ipRange.IP) || rangeOnes < subnetOnes {
+				return fmt.Errorf("IP range %s is not inside subnet %s", config.IPRange, config.Subnet)
+			}
+		}
+	}
+	if config.Gateway != "" {
+		gateway := net.ParseIP(config.Gateway)
+		if gateway == nil {
+			return fmt.Errorf("invalid gateway %q", config.Gateway)
+		}
+		if subnet != nil && !subnet.Contains(gateway) {
+			return fmt.Errorf("gateway %s is not inside subnet %s", config.Gateway, config.Subnet)
+		}
+	}
+	return nil
+}
+
+// Validate checks the addresses of each IPAM configuration in the options,
+// as described in IPAMConfig.Validate.
+func (opts *IPAMOptions) Validate() error {
+	if opts == nil {
+		return nil
+	}
+	for _, config := range opts.Config {
+		if err := config.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewDualStackIPAMOptions returns the IPAM options for a network with both
+// an IPv4 and an IPv6 subnet, using the default IPAM driver. Networks using
+// these options must also be created with EnableIPv6 set.
+func NewDualStackIPAMOptions(ipv4Subnet, ipv6Subnet string) (*IPAMOptions, error) {
+	ipv4, _, err := net.ParseCIDR(ipv4Subnet)
+	if err != nil {
+		return nil, err
+	}
+	if ipv4.To4() == nil {
+		return nil, fmt.Errorf("%s is not an IPv4 subnet", ipv4Subnet)
+	}
+	ipv6, _, err := net.ParseCIDR(ipv6Subnet)
+	if err != nil {
+		return nil, err
+	}
+	if ipv6.To4() != nil {
+		return nil, fmt.Errorf("%s is not an IPv6 subnet", ipv6Subnet)
+	}
+	return &IPAMOptions{
+		Driver: "default",
+		Config: []IPAMConfig{{Subnet: ipv4Subnet}, {Subnet: ipv6Subnet}},
+	}, nil
+}
+
 // CreateNetwork creates a new network, returning the network instance,
-// or an error in case of failure.
+// or an error in case of failure. The IPAM configurations are validated
+// before sending the request.
 //
 // See https://goo.gl/6GugX3 for more details.
 func (c *Client) CreateNetwork(opts CreateNetworkOptions) (*Network, error) {
+	if err := opts.IPAM.Validate(); err != nil {
+		return nil, err
+	}
 	resp, err := c.do(
 		http.MethodPost,
 		"/networks/create",
@@ -173,7 +272,8 @@ func (c *Client) CreateNetwork(opts CreateNetworkOptions) (*Network, error) {
 	defer resp.Body.Close()
 
 	type createNetworkResponse struct {
-		ID string
+		ID      string
+		Warning string
 	}
 	var (
 		network Network
@@ -186,6 +286,10 @@ func (c *Client) CreateNetwork(opts CreateNetworkOptions) (*Network, error) {
 	network.Name = opts.Name
 	network.ID = cnr.ID
 	network.Driver = opts.Driver
+	if cnr.Warning != "" {
+		network.Warnings = []string{cnr.Warning}
+		c.warn(http.MethodPost, "/networks/create", cnr.Warning)
+	}
 
 	return &network, nil
 }
@@ -213,10 +317,14 @@ func (c *Client) RemoveNetwork(id string) error {
 type NetworkConnectionOptions struct {
 	Container string
 
-	// EndpointConfig is only applicable to the ConnectNetwork call
+	// EndpointConfig is only applicable to the ConnectNetwork call. Its
+	// Aliases are the DNS names of the container in the network, and its
+	// IPAMConfig overrides the addresses assigned by the network IPAM
+	// driver.
 	EndpointConfig *EndpointConfig `json:"EndpointConfig,omitempty"`
 
-	// Force is only applicable to the DisconnectNetwork call
+	// Force is only applicable to the DisconnectNetwork call, and
+	// disconnects the container even if it's not running or was removed.
 	Force bool
 
 	Context context.Context `json:"-"`
@@ -239,6 +347,7 @@ type EndpointConfig struct {
 	GlobalIPv6PrefixLen int                 `json:"GlobalIPv6PrefixLen,omitempty" yaml:"GlobalIPv6PrefixLen,omitempty" toml:"GlobalIPv6PrefixLen,omitempty"`
 	MacAddress          string              `json:"MacAddress,omitempty" yaml:"MacAddress,omitempty" toml:"MacAddress,omitempty"`
 	DriverOpts          map[string]string   `json:"DriverOpts,omitempty" yaml:"DriverOpts,omitempty" toml:"DriverOpts,omitempty"`
+	DNSNames            []string            `json:"DNSNames,omitempty" yaml:"DNSNames,omitempty" toml:"DNSNames,omitempty"` // v1.44+, set by the daemon
 }
 
 // EndpointIPAMConfig represents IPAM configurations for an
@@ -246,8 +355,9 @@ type EndpointConfig struct {
 //
 // See https://goo.gl/RV7BJU for more details.
 type EndpointIPAMConfig struct {
-	IPv4Address string `json:",omitempty" yaml:"IPv4Address,omitempty"`
-	IPv6Address string `json:",omitempty" yaml:"IPv6Address,omitempty"`
+	IPv4Address  string   `json:",omitempty" yaml:"IPv4Address,omitempty"`
+	IPv6Address  string   `json:",omitempty" yaml:"IPv6Address,omitempty"`
+	LinkLocalIPs []string `json:",omitempty" yaml:"LinkLocalIPs,omitempty"`
 }
 
 // ConnectNetwork adds a container to a network or returns an error in case of
@@ -275,7 +385,10 @@ func (c *Client) ConnectNetwork(id string, opts NetworkConnectionOptions) error
 //
 // See https://goo.gl/6GugX3 for more details.
 func (c *Client) DisconnectNetwork(id string, opts NetworkConnectionOptions) error {
-	resp, err := c.do(http.MethodPost, "/networks/"+id+"/disconnect", doOptions{data: opts})
+	resp, err := c.do(http.MethodPost, "/networks/"+id+"/disconnect", doOptions{
+		data:    opts,
+		context: opts.Context,
+	})
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
@@ -6,6 +6,7 @@ package docker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -81,6 +82,20 @@ func TestFilteredListNetworks(t *testing.T) {
 	}
 }
 
+func TestFilteredListNetworksWithContext(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "[]", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	if _, err := client.FilteredListNetworksWithContext(NetworkFilterOpts{"name": {"blah": true}}, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if value := fakeRT.requests[0].Context().Value(key{}); value != "value" {
+		t.Error("FilteredListNetworksWithContext: context not passed to the request")
+	}
+}
+
 func TestNetworkInfo(t *testing.T) {
 	t.Parallel()
 	jsonNetwork := `{
@@ -135,6 +150,42 @@ func TestNetworkCreate(t *testing.T) {
 	}
 }
 
+func TestNewDualStackIPAMOptions(t *testing.T) {
+	t.Parallel()
+	ipam, err := NewDualStackIPAMOptions("172.28.0.0/16", "fd00:dead:beef::/48")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &IPAMOptions{
+		Driver: "default",
+		Config: []IPAMConfig{{Subnet: "172.28.0.0/16"}, {Subnet: "fd00:dead:beef::/48"}},
+	}
+	if !reflect.DeepEqual(ipam, expected) {
+		t.Errorf("NewDualStackIPAMOptions: wrong options. Want %#v. Got %#v.", expected, ipam)
+	}
+	if ipam.Config[0].IsIPv6() {
+		t.Errorf("IsIPv6(%q): want false, got true", ipam.Config[0].Subnet)
+	}
+	if !ipam.Config[1].IsIPv6() {
+		t.Errorf("IsIPv6(%q): want true, got false", ipam.Config[1].Subnet)
+	}
+}
+
+func TestNewDualStackIPAMOptionsInvalid(t *testing.T) {
+	t.Parallel()
+	tests := [][2]string{
+		{"fd00:dead:beef::/48", "fd00:dead:beef::/48"},
+		{"172.28.0.0/16", "172.29.0.0/16"},
+		{"172.28.0.0", "fd00:dead:beef::/48"},
+		{"172.28.0.0/16", "fd00:dead:beef::"},
+	}
+	for _, test := range tests {
+		if _, err := NewDualStackIPAMOptions(test[0], test[1]); err == nil {
+			t.Errorf("NewDualStackIPAMOptions(%q, %q): expected non-nil error, got <nil>", test[0], test[1])
+		}
+	}
+}
+
 func TestNetworkRemove(t *testing.T) {
 	t.Parallel()
 	id := "8dfafdbc3a40"
@@ -214,6 +265,42 @@ func TestNetworkConnectWithEndpoint(t *testing.T) {
 	}
 }
 
+func TestNetworkConnectWithStaticAddresses(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusNoContent}
+	client := newTestClient(fakeRT)
+	opts := NetworkConnectionOptions{
+		Container: "foobar",
+		EndpointConfig: &EndpointConfig{
+			MacAddress: "02:42:ac:11:00:02",
+			IPAMConfig: &EndpointIPAMConfig{
+				IPv4Address:  "172.18.0.10",
+				IPv6Address:  "fd00::10",
+				LinkLocalIPs: []string{"169.254.0.10", "fe80::10"},
+			},
+		},
+	}
+	err := client.ConnectNetwork("8dfafdbc3a40", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var in map[string]any
+	if err := json.NewDecoder(fakeRT.requests[0].Body).Decode(&in); err != nil {
+		t.Fatal(err)
+	}
+	expectedEndpoint := map[string]any{
+		"MacAddress": "02:42:ac:11:00:02",
+		"IPAMConfig": map[string]any{
+			"IPv4Address":  "172.18.0.10",
+			"IPv6Address":  "fd00::10",
+			"LinkLocalIPs": []any{"169.254.0.10", "fe80::10"},
+		},
+	}
+	if !reflect.DeepEqual(in["EndpointConfig"], expectedEndpoint) {
+		t.Errorf("ConnectNetwork: wrong endpoint config sent. Want %#v. Got %#v", expectedEndpoint, in["EndpointConfig"])
+	}
+}
+
 func TestNetworkConnectNotFound(t *testing.T) {
 	t.Parallel()
 	client := newTestClient(&FakeRoundTripper{message: "no such network container", status: http.StatusNotFound})
@@ -246,6 +333,24 @@ func TestNetworkDisconnect(t *testing.T) {
 	}
 }
 
+func TestNetworkDisconnectForce(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	err := client.DisconnectNetwork("8dfafdbc3a40", NetworkConnectionOptions{Container: "foobar", Force: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var in map[string]any
+	if err := json.NewDecoder(fakeRT.requests[0].Body).Decode(&in); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{"Container": "foobar", "Force": true}
+	if !reflect.DeepEqual(in, expected) {
+		t.Errorf("DisconnectNetwork: wrong body sent. Want %#v. Got %#v.", expected, in)
+	}
+}
+
 func TestNetworkDisconnectNotFound(t *testing.T) {
 	t.Parallel()
 	client := newTestClient(&FakeRoundTripper{message: "no such network container", status: http.StatusNotFound})
@@ -279,3 +384,42 @@ func TestPruneNetworks(t *testing.T) {
 		t.Errorf("PruneNetworks: Expected %#v. Got %#v.", expected, got)
 	}
 }
+
+func TestIPAMConfigValidate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		config IPAMConfig
+		valid  bool
+	}{
+		{IPAMConfig{Subnet: "172.28.0.0/16", IPRange: "172.28.5.0/24", Gateway: "172.28.5.254"}, true},
+		{IPAMConfig{Subnet: "fd00:dead:beef::/48", Gateway: "fd00:dead:beef::1"}, true},
+		{IPAMConfig{Gateway: "10.0.0.1", IPRange: "10.0.0.0/24"}, true},
+		{IPAMConfig{Subnet: "172.28.0.0"}, false},
+		{IPAMConfig{Subnet: "172.28.0.0/16", Gateway: "172.28.0.300"}, false},
+		{IPAMConfig{Subnet: "172.28.0.0/16", Gateway: "172.29.0.1"}, false},
+		{IPAMConfig{Subnet: "172.28.0.0/16", IPRange: "172.28.5.0"}, false},
+		{IPAMConfig{Subnet: "172.28.0.0/16", IPRange: "172.29.5.0/24"}, false},
+		{IPAMConfig{Subnet: "172.28.0.0/16", IPRange: "172.0.0.0/8"}, false},
+	}
+	for _, test := range tests {
+		if err := test.config.Validate(); (err == nil) != test.valid {
+			t.Errorf("Validate(%#v): wrong result. Want valid=%v. Got %v.", test.config, test.valid, err)
+		}
+	}
+}
+
+func TestCreateNetworkInvalidIPAM(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"ID": "8dfafdbc3a40"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	opts := CreateNetworkOptions{
+		Name: "foobar",
+		IPAM: &IPAMOptions{Config: []IPAMConfig{{Subnet: "172.28.0.0/16", Gateway: "10.0.0.1"}}},
+	}
+	if _, err := client.CreateNetwork(opts); err == nil {
+		t.Error("CreateNetwork: expected non-nil error, got <nil>")
+	}
+	if len(fakeRT.requests) != 0 {
+		t.Errorf("CreateNetwork: unexpected requests sent: %d", len(fakeRT.requests))
+	}
+}
@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrOutputTruncated is the error returned when the output of a container
+// exceeds the maximum size given in the options of the operation, like
+// LogsOptions.MaxOutputBytes. The output up to the limit is written before
+// the operation stops.
+var ErrOutputTruncated = errors.New("output truncated: maximum output size reached")
+
+// outputLimiter limits the number of bytes written to a set of writers,
+// like the stdout and stderr of a container.
+type outputLimiter struct {
+	mu        sync.Mutex
+	remaining int64
+}
+
+// limitOutput returns stdout and stderr wrapped so that at most limit bytes
+// are written to them in total, or the writers themselves if limit isn't
+// positive. nil writers are kept nil.
+func limitOutput(limit int64, stdout, stderr io.Writer) (io.Writer, io.Writer) {
+	if limit <= 0 {
+		return stdout, stderr
+	}
+	limiter := &outputLimiter{remaining: limit}
+	return limiter.wrap(stdout), limiter.wrap(stderr)
+}
+
+func (l *outputLimiter) wrap(w io.Writer) io.Writer {
+	if w == nil {
+		return nil
+	}
+	return &limitedWriter{limiter: l, w: w}
+}
+
+type limitedWriter struct {
+	limiter *outputLimiter
+	w       io.Writer
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	w.limiter.mu.Lock()
+	defer w.limiter.mu.Unlock()
+	truncated := int64(len(p)) > w.limiter.remaining
+	if truncated {
+		p = p[:w.limiter.remaining]
+	}
+	n, err := w.w.Write(p)
+	w.limiter.remaining -= int64(n)
+	if err == nil && truncated {
+		err = ErrOutputTruncated
+	}
+	return n, err
+}
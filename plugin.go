@@ -5,11 +5,13 @@
 package docker
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
+	"time"
 )
 
 // PluginPrivilege represents a privilege for a plugin.
@@ -29,10 +31,22 @@ type InstallPluginOptions struct {
 
 	Auth AuthConfiguration
 
+	// OutputStream receives the progress of the pull, like in
+	// PullImageOptions.
+	OutputStream      io.Writer     `qs:"-"`
+	RawJSONStream     bool          `qs:"-"`
+	InactivityTimeout time.Duration `qs:"-"`
+
+	// JSONMessageHandler, when set, is called with every message of the
+	// JSON progress stream sent by the daemon.
+	JSONMessageHandler func(*JSONMessage) `qs:"-"`
+
 	Context context.Context
 }
 
-// InstallPlugins installs a plugin or returns an error in case of failure.
+// InstallPlugins installs a plugin or returns an error in case of failure,
+// including the errors reported by the daemon in the progress stream, unless
+// RawJSONStream is set.
 //
 // See https://goo.gl/C4t7Tz for more details.
 func (c *Client) InstallPlugins(opts InstallPluginOptions) error {
@@ -40,23 +54,55 @@ func (c *Client) InstallPlugins(opts InstallPluginOptions) error {
 	if err != nil {
 		return err
 	}
+	headers["Content-Type"] = "application/json"
+	data, err := json.Marshal(opts.Plugins)
+	if err != nil {
+		return err
+	}
 
 	path := "/plugins/pull?" + queryString(opts)
-	resp, err := c.do(http.MethodPost, path, doOptions{
-		data:    opts.Plugins,
-		context: opts.Context,
-		headers: headers,
+	return c.stream(http.MethodPost, path, streamOptions{
+		useJSONDecoder:     true,
+		headers:            headers,
+		in:                 bytes.NewReader(data),
+		stdout:             opts.OutputStream,
+		rawJSONStream:      opts.RawJSONStream,
+		inactivityTimeout:  opts.InactivityTimeout,
+		jsonMessageHandler: opts.JSONMessageHandler,
+		context:            opts.Context,
+	})
+}
+
+// ErrPluginPrivilegesDenied is the error returned by
+// InstallPluginWithPrivilegeCheck when the privileges requested by the
+// plugin aren't accepted.
+var ErrPluginPrivilegesDenied = errors.New("plugin privileges denied")
+
+// InstallPluginWithPrivilegeCheck installs a plugin like docker plugin
+// install does: the privileges requested by the plugin are fetched first
+// and given to accept, and the plugin is only pulled, with these
+// privileges granted, when accept returns true. Otherwise, it returns
+// ErrPluginPrivilegesDenied. The plugin is installed with its remote
+// reference as name.
+//
+// accept isn't called for plugins that don't request any privileges, and
+// a nil accept denies all the privileges.
+func (c *Client) InstallPluginWithPrivilegeCheck(remote string, auth AuthConfiguration, accept func([]PluginPrivilege) bool) error {
+	privileges, err := c.GetPluginPrivilegesWithOptions(GetPluginPrivilegesOptions{
+		Remote: remote,
+		Auth:   auth,
 	})
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	// PullPlugin streams back the progress of the pull, we must consume the whole body
-	// otherwise the pull will be canceled on the engine.
-	if _, err := io.ReadAll(resp.Body); err != nil {
-		return err
+	if len(privileges) > 0 && (accept == nil || !accept(privileges)) {
+		return ErrPluginPrivilegesDenied
 	}
-	return nil
+	return c.InstallPlugins(InstallPluginOptions{
+		Remote:  remote,
+		Plugins: privileges,
+		Auth:    auth,
+	})
 }
 
 // PluginSettings stores plugin settings.
@@ -400,20 +446,41 @@ type PushPluginOptions struct {
 	// The Name of the plugin.
 	Name string
 
+	Auth AuthConfiguration
+
+	// OutputStream receives the progress of the push, like in
+	// PushImageOptions.
+	OutputStream      io.Writer
+	RawJSONStream     bool
+	InactivityTimeout time.Duration
+
+	// JSONMessageHandler, when set, is called with every message of the
+	// JSON progress stream sent by the daemon.
+	JSONMessageHandler func(*JSONMessage)
+
 	Context context.Context
 }
 
-// PushPlugin pushes plugin that opts point or returns an error.
+// PushPlugin pushes plugin that opts point or returns an error, including
+// the errors reported by the daemon in the progress stream, unless
+// RawJSONStream is set.
 //
 // See https://goo.gl/C4t7Tz for more details.
 func (c *Client) PushPlugin(opts PushPluginOptions) error {
-	path := "/plugins/" + opts.Name + "/push"
-	resp, err := c.do(http.MethodPost, path, doOptions{context: opts.Context})
+	headers, err := headersWithAuth(opts.Auth)
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
-	return nil
+	path := "/plugins/" + opts.Name + "/push"
+	return c.stream(http.MethodPost, path, streamOptions{
+		useJSONDecoder:     true,
+		headers:            headers,
+		stdout:             opts.OutputStream,
+		rawJSONStream:      opts.RawJSONStream,
+		inactivityTimeout:  opts.InactivityTimeout,
+		jsonMessageHandler: opts.JSONMessageHandler,
+		context:            opts.Context,
+	})
 }
 
 // ConfigurePluginOptions specify parameters to the ConfigurePlugin
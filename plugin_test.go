@@ -5,11 +5,15 @@
 package docker
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -258,6 +262,153 @@ func TestInstallPlugins(t *testing.T) {
 	}
 }
 
+// pluginInstallServer fakes the endpoints used to install plugins, the
+// privileges endpoint returning privileges, and records the paths of the
+// requests and the privileges granted to the pull.
+type pluginInstallServer struct {
+	privileges string
+	paths      []string
+	auths      []string
+	remote     string
+	granted    []PluginPrivilege
+}
+
+func (s *pluginInstallServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.paths = append(s.paths, r.URL.Path)
+	s.auths = append(s.auths, r.Header.Get("X-Registry-Auth"))
+	switch r.URL.Path {
+	case "/plugins/privileges":
+		w.Write([]byte(s.privileges))
+	case "/plugins/pull":
+		s.remote = r.URL.Query().Get("remote")
+		json.NewDecoder(r.Body).Decode(&s.granted)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"Download complete"}`))
+	}
+}
+
+func TestInstallPluginWithPrivilegeCheck(t *testing.T) {
+	t.Parallel()
+	fake := pluginInstallServer{privileges: `[ { "Name": "network", "Description": "", "Value": [ "host" ] }]`}
+	server := httptest.NewServer(&fake)
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	expected := []PluginPrivilege{{Name: "network", Value: []string{"host"}}}
+	var asked []PluginPrivilege
+	err := client.InstallPluginWithPrivilegeCheck("vieux/sshfs", AuthConfiguration{Username: "XY"}, func(privileges []PluginPrivilege) bool {
+		asked = privileges
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(asked, expected) {
+		t.Errorf("InstallPluginWithPrivilegeCheck: wrong privileges to accept. Want %#v. Got %#v.", expected, asked)
+	}
+	expectedPaths := []string{"/plugins/privileges", "/plugins/pull"}
+	if !reflect.DeepEqual(fake.paths, expectedPaths) {
+		t.Fatalf("InstallPluginWithPrivilegeCheck: wrong requests. Want %#v. Got %#v.", expectedPaths, fake.paths)
+	}
+	if fake.remote != "vieux/sshfs" {
+		t.Errorf("InstallPluginWithPrivilegeCheck: wrong remote. Want %q. Got %q.", "vieux/sshfs", fake.remote)
+	}
+	if fake.auths[1] == "" {
+		t.Error("InstallPluginWithPrivilegeCheck: unexpected empty X-Registry-Auth header")
+	}
+	if !reflect.DeepEqual(fake.granted, expected) {
+		t.Errorf("InstallPluginWithPrivilegeCheck: wrong privileges granted. Want %#v. Got %#v.", expected, fake.granted)
+	}
+}
+
+func TestInstallPluginWithPrivilegeCheckDenied(t *testing.T) {
+	t.Parallel()
+	jsonPluginPrivileges := `[ { "Name": "network", "Description": "", "Value": [ "host" ] }]`
+	for _, accept := range []func([]PluginPrivilege) bool{nil, func([]PluginPrivilege) bool { return false }} {
+		fakeRT := &FakeRoundTripper{message: jsonPluginPrivileges, status: http.StatusOK}
+		client := newTestClient(fakeRT)
+		err := client.InstallPluginWithPrivilegeCheck("vieux/sshfs", AuthConfiguration{}, accept)
+		if !errors.Is(err, ErrPluginPrivilegesDenied) {
+			t.Errorf("InstallPluginWithPrivilegeCheck: wrong error. Want %v. Got %v.", ErrPluginPrivilegesDenied, err)
+		}
+		if len(fakeRT.requests) != 1 {
+			t.Errorf("InstallPluginWithPrivilegeCheck: the plugin was pulled after denying its privileges")
+		}
+	}
+}
+
+func TestInstallPluginWithPrivilegeCheckNoPrivileges(t *testing.T) {
+	t.Parallel()
+	fake := pluginInstallServer{privileges: `[]`}
+	server := httptest.NewServer(&fake)
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	err := client.InstallPluginWithPrivilegeCheck("vieux/sshfs", AuthConfiguration{}, func([]PluginPrivilege) bool {
+		t.Error("InstallPluginWithPrivilegeCheck: unexpected call of accept for a plugin without privileges")
+		return false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.paths) != 2 {
+		t.Errorf("InstallPluginWithPrivilegeCheck: wrong number of requests. Want 2. Got %d.", len(fake.paths))
+	}
+}
+
+func TestInstallPluginsProgress(t *testing.T) {
+	t.Parallel()
+	message := `{"status":"Pulling from vieux/sshfs","id":"latest"}
+{"status":"Download complete"}
+`
+	fakeRT := &FakeRoundTripper{message: message, status: http.StatusOK, header: map[string]string{"Content-Type": "application/json"}}
+	client := newTestClient(fakeRT)
+	var out bytes.Buffer
+	var statuses []string
+	err := client.InstallPlugins(InstallPluginOptions{
+		Remote:       "vieux/sshfs",
+		Plugins:      []PluginPrivilege{{Name: "network", Value: []string{"host"}}},
+		OutputStream: &out,
+		JSONMessageHandler: func(msg *JSONMessage) {
+			statuses = append(statuses, msg.Status)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Pulling from vieux/sshfs", "Download complete"}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("InstallPlugins: wrong messages. Want %#v. Got %#v.", expected, statuses)
+	}
+	if !strings.Contains(out.String(), "Download complete") {
+		t.Errorf("InstallPlugins: progress not written to the output stream. Got %q.", out.String())
+	}
+	req := fakeRT.requests[0]
+	if contentType := req.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("InstallPlugins: wrong content type. Want %q. Got %q.", "application/json", contentType)
+	}
+	var privileges []PluginPrivilege
+	if err := json.NewDecoder(req.Body).Decode(&privileges); err != nil {
+		t.Fatal(err)
+	}
+	if len(privileges) != 1 || privileges[0].Name != "network" {
+		t.Errorf("InstallPlugins: wrong privileges sent: %#v", privileges)
+	}
+}
+
+func TestInstallPluginsStreamError(t *testing.T) {
+	t.Parallel()
+	message := `{"status":"Pulling from vieux/sshfs","id":"latest"}
+{"errorDetail":{"message":"unauthorized: authentication required"},"error":"unauthorized: authentication required"}
+`
+	fakeRT := &FakeRoundTripper{message: message, status: http.StatusOK, header: map[string]string{"Content-Type": "application/json"}}
+	client := newTestClient(fakeRT)
+	err := client.InstallPlugins(InstallPluginOptions{Remote: "vieux/sshfs"})
+	if err == nil || !strings.Contains(err.Error(), "authentication required") {
+		t.Errorf("InstallPlugins: wrong error. Want the error of the stream. Got %v.", err)
+	}
+}
+
 func TestInspectPlugin(t *testing.T) {
 	name := "test_plugin"
 	fakeRT := &FakeRoundTripper{message: jsonPluginDetail, status: http.StatusNoContent}
@@ -356,6 +507,36 @@ func TestPushPlugin(t *testing.T) {
 	}
 }
 
+func TestPushPluginStreamError(t *testing.T) {
+	t.Parallel()
+	message := `{"status":"The push refers to repository [docker.io/vieux/sshfs]"}
+{"errorDetail":{"message":"denied: requested access to the resource is denied"},"error":"denied: requested access to the resource is denied"}
+`
+	fakeRT := &FakeRoundTripper{message: message, status: http.StatusOK, header: map[string]string{"Content-Type": "application/json"}}
+	client := newTestClient(fakeRT)
+	var statuses []string
+	err := client.PushPlugin(PushPluginOptions{
+		Name: "vieux/sshfs",
+		Auth: AuthConfiguration{Username: "XY"},
+		JSONMessageHandler: func(msg *JSONMessage) {
+			statuses = append(statuses, msg.Status)
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "access to the resource is denied") {
+		t.Errorf("PushPlugin: wrong error. Want the error of the stream. Got %v.", err)
+	}
+	if len(statuses) != 2 {
+		t.Errorf("PushPlugin: wrong number of messages. Want 2. Got %d.", len(statuses))
+	}
+	req := fakeRT.requests[0]
+	if req.URL.Path != "/plugins/vieux/sshfs/push" {
+		t.Errorf("PushPlugin: wrong path. Want %q. Got %q.", "/plugins/vieux/sshfs/push", req.URL.Path)
+	}
+	if req.Header.Get("X-Registry-Auth") == "" {
+		t.Error("PushPlugin: unexpected empty X-Registry-Auth header")
+	}
+}
+
 func TestConfigurePlugin(t *testing.T) {
 	opts := ConfigurePluginOptions{
 		Name:    "test",
@@ -0,0 +1,146 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPortSpec is the error wrapped by the errors returned by
+// ParsePortSpecs when one of the specs is malformed.
+var ErrInvalidPortSpec = errors.New("invalid port spec")
+
+// ParsePortSpecs parses port specs in the format taken by the -p flag of
+// docker run, returning the ports to set in Config.ExposedPorts and the
+// bindings to set in HostConfig.PortBindings. A spec has the form
+//
+//	[[ip:][hostPort]:]containerPort[/protocol]
+//
+// where the ports may be ranges, like "8080-8081:80-81", and IPv6 addresses
+// are enclosed in brackets, like "[::1]:8080:80". The protocol defaults to
+// tcp. Some examples:
+//
+//	80                     container port 80, published on a random host port
+//	8080:80/udp            container port 80/udp, published on host port 8080
+//	127.0.0.1::80          container port 80, published on a random port of 127.0.0.1
+//	8000-8010:80           container port 80, published on a host port in 8000-8010
+//	8080-8081:80-81        container ports 80 and 81, published on 8080 and 8081
+//
+// All the specs are parsed, and all the malformed ones are reported in the
+// returned error.
+func ParsePortSpecs(specs []string) (map[Port]struct{}, map[Port][]PortBinding, error) {
+	exposed := make(map[Port]struct{})
+	bindings := make(map[Port][]PortBinding)
+	var errs []error
+	for _, spec := range specs {
+		if err := parsePortSpec(spec, exposed, bindings); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	return exposed, bindings, nil
+}
+
+func parsePortSpec(spec string, exposed map[Port]struct{}, bindings map[Port][]PortBinding) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidPortSpec, spec, reason)
+	}
+	rest, proto, ok := strings.Cut(spec, "/")
+	if !ok {
+		proto = "tcp"
+	}
+	switch proto {
+	case "tcp", "udp", "sctp":
+	default:
+		return invalid("invalid protocol")
+	}
+	var ip string
+	if strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]:")
+		if end < 0 {
+			return invalid("invalid IPv6 address")
+		}
+		ip, rest = rest[1:end], rest[end+2:]
+		if !strings.Contains(rest, ":") {
+			return invalid("missing container port")
+		}
+	}
+	parts := strings.Split(rest, ":")
+	var hostPort, containerPort string
+	switch {
+	case len(parts) == 1:
+		containerPort = parts[0]
+	case len(parts) == 2:
+		hostPort, containerPort = parts[0], parts[1]
+	case len(parts) == 3 && ip == "":
+		ip, hostPort, containerPort = parts[0], parts[1], parts[2]
+	default:
+		return invalid("too many colons, IPv6 addresses must be enclosed in brackets")
+	}
+	if ip != "" && net.ParseIP(ip) == nil {
+		return invalid("invalid IP address")
+	}
+	start, end, err := parsePortRange(containerPort)
+	if err != nil {
+		return invalid(err.Error())
+	}
+	var hostStart, hostEnd int
+	if hostPort != "" {
+		if hostStart, hostEnd, err = parsePortRange(hostPort); err != nil {
+			return invalid(err.Error())
+		}
+		if start != end && hostEnd-hostStart != end-start {
+			return invalid("the host and container port ranges have different sizes")
+		}
+	}
+	for i := 0; i <= end-start; i++ {
+		port := Port(strconv.Itoa(start+i) + "/" + proto)
+		binding := PortBinding{HostIP: ip, HostPort: hostPort}
+		if start != end && hostPort != "" {
+			binding.HostPort = strconv.Itoa(hostStart + i)
+		}
+		exposed[port] = struct{}{}
+		bindings[port] = append(bindings[port], binding)
+	}
+	return nil
+}
+
+// FormatPortSpecs returns the port specs equivalent to the given bindings,
+// in the format parsed by ParsePortSpecs: one spec per binding, ordered by
+// container port.
+func FormatPortSpecs(bindings map[Port][]PortBinding) []string {
+	ports := make([]Port, 0, len(bindings))
+	for port := range bindings {
+		ports = append(ports, port)
+	}
+	slices.SortFunc(ports, func(a, b Port) int {
+		numberA, _ := strconv.Atoi(a.Port())
+		numberB, _ := strconv.Atoi(b.Port())
+		if numberA != numberB {
+			return numberA - numberB
+		}
+		return strings.Compare(a.Proto(), b.Proto())
+	})
+	var specs []string
+	for _, port := range ports {
+		containerPort := port.Port() + "/" + port.Proto()
+		for _, binding := range bindings[port] {
+			spec := containerPort
+			switch {
+			case strings.Contains(binding.HostIP, ":"):
+				spec = "[" + binding.HostIP + "]:" + binding.HostPort + ":" + spec
+			case binding.HostIP != "":
+				spec = binding.HostIP + ":" + binding.HostPort + ":" + spec
+			case binding.HostPort != "":
+				spec = binding.HostPort + ":" + spec
+			}
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
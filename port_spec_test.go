@@ -0,0 +1,105 @@
+package docker
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePortSpecs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		spec     string
+		bindings map[Port][]PortBinding
+	}{
+		{"80", map[Port][]PortBinding{"80/tcp": {{}}}},
+		{"8080:80/udp", map[Port][]PortBinding{"80/udp": {{HostPort: "8080"}}}},
+		{"127.0.0.1::80", map[Port][]PortBinding{"80/tcp": {{HostIP: "127.0.0.1"}}}},
+		{"127.0.0.1:8080:80/sctp", map[Port][]PortBinding{"80/sctp": {{HostIP: "127.0.0.1", HostPort: "8080"}}}},
+		{"[::1]:8080:80", map[Port][]PortBinding{"80/tcp": {{HostIP: "::1", HostPort: "8080"}}}},
+		{"[::1]::80", map[Port][]PortBinding{"80/tcp": {{HostIP: "::1"}}}},
+		{"8000-8010:80", map[Port][]PortBinding{"80/tcp": {{HostPort: "8000-8010"}}}},
+		{"8080-8081:80-81", map[Port][]PortBinding{"80/tcp": {{HostPort: "8080"}}, "81/tcp": {{HostPort: "8081"}}}},
+		{"80-81/udp", map[Port][]PortBinding{"80/udp": {{}}, "81/udp": {{}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			exposed, bindings, err := ParsePortSpecs([]string{tt.spec})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(bindings, tt.bindings) {
+				t.Errorf("ParsePortSpecs(%q): wrong bindings. Want %#v. Got %#v.", tt.spec, tt.bindings, bindings)
+			}
+			if len(exposed) != len(tt.bindings) {
+				t.Errorf("ParsePortSpecs(%q): wrong exposed ports: %#v", tt.spec, exposed)
+			}
+			for port := range tt.bindings {
+				if _, ok := exposed[port]; !ok {
+					t.Errorf("ParsePortSpecs(%q): port %s not exposed", tt.spec, port)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePortSpecsSamePort(t *testing.T) {
+	t.Parallel()
+	_, bindings, err := ParsePortSpecs([]string{"127.0.0.1:8080:80", "[::1]:8080:80"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[Port][]PortBinding{"80/tcp": {{HostIP: "127.0.0.1", HostPort: "8080"}, {HostIP: "::1", HostPort: "8080"}}}
+	if !reflect.DeepEqual(bindings, expected) {
+		t.Errorf("ParsePortSpecs: wrong bindings. Want %#v. Got %#v.", expected, bindings)
+	}
+}
+
+func TestParsePortSpecsInvalid(t *testing.T) {
+	t.Parallel()
+	specs := []string{
+		"",
+		"http",
+		"0",
+		"80/icmp",
+		"70000:80",
+		"8080-8082:80-81",
+		"90-80",
+		"::1:8080:80",
+		"[::1:8080:80",
+		"[::1]:80",
+		"localhost:8080:80",
+	}
+	for _, spec := range specs {
+		_, _, err := ParsePortSpecs([]string{spec})
+		if !errors.Is(err, ErrInvalidPortSpec) {
+			t.Errorf("ParsePortSpecs(%q): wrong error. Want %v. Got %v.", spec, ErrInvalidPortSpec, err)
+		}
+	}
+	_, _, err := ParsePortSpecs([]string{"80", "http", "80/icmp"})
+	if err == nil || !strings.Contains(err.Error(), `"http"`) || !strings.Contains(err.Error(), `"80/icmp"`) {
+		t.Errorf("ParsePortSpecs: all the invalid specs should be reported, got %v", err)
+	}
+}
+
+func TestFormatPortSpecs(t *testing.T) {
+	t.Parallel()
+	specs := []string{"9000:9000/udp", "127.0.0.1::443", "[::1]:8080:80", "80", "8000-8010:22", "8080:80"}
+	_, bindings, err := ParsePortSpecs(specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"8000-8010:22/tcp", "[::1]:8080:80/tcp", "80/tcp", "8080:80/tcp", "127.0.0.1::443/tcp", "9000:9000/udp"}
+	got := FormatPortSpecs(bindings)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("FormatPortSpecs: wrong specs. Want %#v. Got %#v.", expected, got)
+	}
+	_, roundTrip, err := ParsePortSpecs(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(roundTrip, bindings) {
+		t.Errorf("FormatPortSpecs: specs don't round trip. Want %#v. Got %#v.", bindings, roundTrip)
+	}
+}
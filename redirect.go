@@ -0,0 +1,76 @@
+package docker
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// DefaultMaxRedirects is the maximum number of redirects followed for a
+// request when RedirectPolicy.MaxRedirects isn't set.
+const DefaultMaxRedirects = 10
+
+// credentialHeaders are the headers holding credentials, which are only
+// forwarded on redirects to trusted hosts.
+var credentialHeaders = []string{"X-Registry-Auth", "X-Registry-Config", "Authorization"}
+
+// RedirectPolicy configures how the client follows the redirects answered by
+// the daemon, or by a proxy in front of it, like a proxy redirecting
+// /images/get to a cache. See SetRedirectPolicy.
+type RedirectPolicy struct {
+	// MaxRedirects is the maximum number of redirects followed for a
+	// request, defaults to DefaultMaxRedirects. A negative value disables
+	// redirects. When a redirect isn't followed, the request fails with an
+	// *Error holding the 3xx status.
+	MaxRedirects int
+
+	// TrustedHosts are the hosts, besides the one of the original
+	// request, that receive its credentials when it's redirected to them:
+	// the X-Registry-Auth, X-Registry-Config and Authorization headers.
+	// These headers are dropped on redirects to other hosts. A host may
+	// include a port, in which case only that port is trusted.
+	TrustedHosts []string
+}
+
+// SetRedirectPolicy makes the HTTPClient follow redirects according to
+// policy. Clients created by the functions of this package follow the
+// default policy, the zero RedirectPolicy. Like SetTimeout, it should not be
+// called concurrently with any other Client methods.
+func (c *Client) SetRedirectPolicy(policy RedirectPolicy) {
+	if c.HTTPClient != nil {
+		c.HTTPClient.CheckRedirect = policy.checkRedirect
+	}
+}
+
+func (policy RedirectPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	maxRedirects := policy.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+	if maxRedirects < 0 || len(via) > maxRedirects {
+		return http.ErrUseLastResponse
+	}
+	if !policy.trusts(req.URL, via[0].URL) {
+		for _, header := range credentialHeaders {
+			req.Header.Del(header)
+		}
+	}
+	return nil
+}
+
+// trusts reports whether the credentials of a request to original may be
+// sent to target.
+func (policy RedirectPolicy) trusts(target, original *url.URL) bool {
+	if target.Host == original.Host {
+		return true
+	}
+	return slices.Contains(policy.TrustedHosts, target.Host) || slices.Contains(policy.TrustedHosts, target.Hostname())
+}
+
+// isUnfollowedRedirect reports whether resp is a redirect the client didn't
+// follow. 304 Not Modified isn't a redirect, the daemon uses it to report
+// requests with no effect, like stopping a stopped container.
+func isUnfollowedRedirect(resp *http.Response) bool {
+	return resp.StatusCode >= http.StatusMultipleChoices && resp.StatusCode < http.StatusBadRequest &&
+		resp.StatusCode != http.StatusNotModified
+}
@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newRedirectServers returns a server answering /target with the
+// X-Registry-Auth header it received, and a client of a daemon redirecting
+// /images/busybox/get to it.
+func newRedirectServers(t *testing.T) (*Client, *httptest.Server) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Registry-Auth")))
+	}))
+	t.Cleanup(target.Close)
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/target", http.StatusFound)
+	}))
+	t.Cleanup(daemon.Close)
+	client, err := NewClient(daemon.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, target
+}
+
+func redirectedAuth(t *testing.T, client *Client) string {
+	resp, err := client.do(http.MethodGet, "/images/busybox/get", doOptions{
+		headers: map[string]string{"X-Registry-Auth": "secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestRedirectDropsCredentialsForOtherHosts(t *testing.T) {
+	t.Parallel()
+	client, _ := newRedirectServers(t)
+	if auth := redirectedAuth(t, client); auth != "" {
+		t.Errorf("redirect: credentials forwarded to another host: %q", auth)
+	}
+}
+
+func TestRedirectForwardsCredentialsToTrustedHosts(t *testing.T) {
+	t.Parallel()
+	client, target := newRedirectServers(t)
+	u, _ := url.Parse(target.URL)
+	client.SetRedirectPolicy(RedirectPolicy{TrustedHosts: []string{u.Host}})
+	if auth := redirectedAuth(t, client); auth != "secret" {
+		t.Errorf("redirect: wrong credentials forwarded to a trusted host. Want %q. Got %q.", "secret", auth)
+	}
+}
+
+func TestRedirectPolicyMaxRedirects(t *testing.T) {
+	t.Parallel()
+	client, target := newRedirectServers(t)
+	client.SetRedirectPolicy(RedirectPolicy{MaxRedirects: -1})
+	_, err := client.do(http.MethodGet, "/images/busybox/get", doOptions{})
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusFound {
+		t.Fatalf("do: wrong error. Want an API error with status %d. Got %#v.", http.StatusFound, err)
+	}
+	if !strings.Contains(e.Message, target.URL+"/target") {
+		t.Errorf("do: the error doesn't tell the location of the redirect: %q", e.Message)
+	}
+}
+
+func TestRedirectPolicyTrusts(t *testing.T) {
+	t.Parallel()
+	original, _ := url.Parse("http://daemon:2375/images/busybox/get")
+	policy := RedirectPolicy{TrustedHosts: []string{"cache.example.com", "mirror.example.com:8443"}}
+	tests := []struct {
+		target  string
+		trusted bool
+	}{
+		{"http://daemon:2375/other", true},
+		{"http://daemon:2376/other", false},
+		{"https://cache.example.com/blob", true},
+		{"https://cache.example.com:8443/blob", true},
+		{"https://mirror.example.com:8443/blob", true},
+		{"https://mirror.example.com/blob", false},
+		{"https://evil.example.com/blob", false},
+	}
+	for _, tt := range tests {
+		target, _ := url.Parse(tt.target)
+		if got := policy.trusts(target, original); got != tt.trusted {
+			t.Errorf("trusts(%q): want %v, got %v", tt.target, tt.trusted, got)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultRegistry is the registry used for image references that don't
+	// specify one.
+	DefaultRegistry = "docker.io"
+
+	// DefaultTag is the tag used by the Docker CLI for image references
+	// that specify neither a tag nor a digest.
+	DefaultTag = "latest"
+
+	legacyDefaultRegistry = "index.docker.io"
+	officialRepoPrefix    = "library/"
+)
+
+// ErrInvalidImageReference is the error returned by ParseImageReference when
+// the given reference is malformed.
+var ErrInvalidImageReference = errors.New("invalid image reference")
+
+var (
+	repositoryComponentRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+	tagRegexp                 = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	digestRegexp              = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-zA-Z0-9=_-]{32,}$`)
+)
+
+// ImageReference is the parsed form of an image reference, like
+// "localhost:5000/samalba/hipache:latest" or "busybox@sha256:...".
+type ImageReference struct {
+	// Registry is the host (and optionally port) of the registry, like
+	// "docker.io" or "localhost:5000".
+	Registry string
+
+	// Repository is the path of the image in the registry, like
+	// "library/busybox" or "samalba/hipache".
+	Repository string
+
+	Tag    string
+	Digest string
+}
+
+// ParseImageReference splits the given reference into its registry,
+// repository, tag and digest, normalizing it the way the Docker daemon does:
+// references without a registry are assumed to live in docker.io, and
+// single-component repositories in docker.io get the "library/" prefix.
+//
+// Some examples:
+//
+//	busybox -> docker.io, library/busybox, "", ""
+//	localhost:5000/samalba/hipache:1.0 -> localhost:5000, samalba/hipache, 1.0, ""
+//	quay.io/coreos/etcd@sha256:4a73... -> quay.io, coreos/etcd, "", sha256:4a73...
+func ParseImageReference(ref string) (ImageReference, error) {
+	var result ImageReference
+	name := ref
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, result.Digest = name[:i], name[i+1:]
+		if !digestRegexp.MatchString(result.Digest) {
+			return ImageReference{}, fmt.Errorf("%w: invalid digest in %q", ErrInvalidImageReference, ref)
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i+1:], "/") {
+		name, result.Tag = name[:i], name[i+1:]
+		if !tagRegexp.MatchString(result.Tag) {
+			return ImageReference{}, fmt.Errorf("%w: invalid tag in %q", ErrInvalidImageReference, ref)
+		}
+	}
+	result.Registry = DefaultRegistry
+	if i := strings.Index(name, "/"); i >= 0 && isRegistryHost(name[:i]) {
+		result.Registry, name = name[:i], name[i+1:]
+		if result.Registry == legacyDefaultRegistry {
+			result.Registry = DefaultRegistry
+		}
+	}
+	if result.Registry == DefaultRegistry && !strings.Contains(name, "/") {
+		name = officialRepoPrefix + name
+	}
+	for _, component := range strings.Split(name, "/") {
+		if !repositoryComponentRegexp.MatchString(component) {
+			return ImageReference{}, fmt.Errorf("%w: invalid repository name in %q", ErrInvalidImageReference, ref)
+		}
+	}
+	result.Repository = name
+	return result, nil
+}
+
+// isRegistryHost reports whether the first component of a reference names a
+// registry rather than a repository namespace, using the same heuristic as
+// the Docker CLI.
+func isRegistryHost(component string) bool {
+	return component == "localhost" || strings.ContainsAny(component, ".:") ||
+		strings.ToLower(component) != component
+}
+
+// Name returns the fully qualified name of the repository, without tag or
+// digest, like "docker.io/library/busybox".
+func (r ImageReference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// FamiliarName returns the shortest form of the repository name, as displayed
+// by the Docker CLI: "docker.io/library/busybox" becomes "busybox", and
+// "docker.io/samalba/hipache" becomes "samalba/hipache".
+func (r ImageReference) FamiliarName() string {
+	if r.Registry != DefaultRegistry {
+		return r.Name()
+	}
+	if name := strings.TrimPrefix(r.Repository, officialRepoPrefix); !strings.Contains(name, "/") {
+		return name
+	}
+	return r.Repository
+}
+
+// TagOrDigest returns the value expected in the tag parameter of the pull
+// API: the digest when there's one, the tag otherwise.
+func (r ImageReference) TagOrDigest() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// String returns the fully qualified reference, including tag and digest when
+// they are set.
+func (r ImageReference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// NormalizeImageReference returns the fully qualified form of the given
+// reference, adding the default registry and the "latest" tag when they're
+// missing. For example, "busybox" becomes "docker.io/library/busybox:latest".
+func NormalizeImageReference(ref string) (string, error) {
+	parsed, err := ParseImageReference(ref)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Tag == "" && parsed.Digest == "" {
+		parsed.Tag = DefaultTag
+	}
+	return parsed.String(), nil
+}
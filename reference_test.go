@@ -0,0 +1,116 @@
+package docker
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseImageReference(t *testing.T) {
+	t.Parallel()
+	const digest = "sha256:4a731fb46adc5cefe3ae374a8b6020fc1b6ad667a279647766e9a3cd89f6fa92"
+	tests := []struct {
+		input    string
+		expected ImageReference
+		familiar string
+		str      string
+	}{
+		{
+			"busybox",
+			ImageReference{Registry: "docker.io", Repository: "library/busybox"},
+			"busybox",
+			"docker.io/library/busybox",
+		},
+		{
+			"tsuru/python:2.7",
+			ImageReference{Registry: "docker.io", Repository: "tsuru/python", Tag: "2.7"},
+			"tsuru/python",
+			"docker.io/tsuru/python:2.7",
+		},
+		{
+			"index.docker.io/library/busybox:latest",
+			ImageReference{Registry: "docker.io", Repository: "library/busybox", Tag: "latest"},
+			"busybox",
+			"docker.io/library/busybox:latest",
+		},
+		{
+			"localhost.localdomain:5000/samalba/hipache:latest",
+			ImageReference{Registry: "localhost.localdomain:5000", Repository: "samalba/hipache", Tag: "latest"},
+			"localhost.localdomain:5000/samalba/hipache",
+			"localhost.localdomain:5000/samalba/hipache:latest",
+		},
+		{
+			"localhost/hipache",
+			ImageReference{Registry: "localhost", Repository: "hipache"},
+			"localhost/hipache",
+			"localhost/hipache",
+		},
+		{
+			"busybox:latest@" + digest,
+			ImageReference{Registry: "docker.io", Repository: "library/busybox", Tag: "latest", Digest: digest},
+			"busybox",
+			"docker.io/library/busybox:latest@" + digest,
+		},
+		{
+			"quay.io/coreos/etcd@" + digest,
+			ImageReference{Registry: "quay.io", Repository: "coreos/etcd", Digest: digest},
+			"quay.io/coreos/etcd",
+			"quay.io/coreos/etcd@" + digest,
+		},
+	}
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.input, func(t *testing.T) {
+			t.Parallel()
+			ref, err := ParseImageReference(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ref != test.expected {
+				t.Errorf("ParseImageReference(%q): wrong result. Want %#v. Got %#v", test.input, test.expected, ref)
+			}
+			if name := ref.FamiliarName(); name != test.familiar {
+				t.Errorf("FamiliarName(): wrong name. Want %q. Got %q", test.familiar, name)
+			}
+			if str := ref.String(); str != test.str {
+				t.Errorf("String(): wrong reference. Want %q. Got %q", test.str, str)
+			}
+		})
+	}
+}
+
+func TestParseImageReferenceInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []string{
+		"",
+		"Busybox",
+		"busybox:",
+		"busybox:-latest",
+		"busybox@sha256:abc",
+		"tsuru//python",
+	}
+	for _, input := range tests {
+		_, err := ParseImageReference(input)
+		if !errors.Is(err, ErrInvalidImageReference) {
+			t.Errorf("ParseImageReference(%q): wrong error. Want %#v. Got %#v", input, ErrInvalidImageReference, err)
+		}
+	}
+}
+
+func TestNormalizeImageReference(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"busybox":                        "docker.io/library/busybox:latest",
+		"tsuru/python:2.7":               "docker.io/tsuru/python:2.7",
+		"localhost:5000/samalba/hipache": "localhost:5000/samalba/hipache:latest",
+		"busybox@sha256:4a731fb46adc5cefe3ae374a8b6020fc1b6ad667a279647766e9a3cd89f6fa92": "docker.io/library/busybox@sha256:4a731fb46adc5cefe3ae374a8b6020fc1b6ad667a279647766e9a3cd89f6fa92",
+	}
+	for input, expected := range tests {
+		got, err := NormalizeImageReference(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != expected {
+			t.Errorf("NormalizeImageReference(%q): wrong result. Want %q. Got %q", input, expected, got)
+		}
+	}
+}
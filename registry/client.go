@@ -0,0 +1,290 @@
+// Package registry is a lightweight client for the Docker Registry HTTP API
+// V2 (also implemented by OCI distribution registries), that lists the tags
+// of repositories and fetches image manifests and configs directly from a
+// registry, without going through the Docker daemon.
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// dockerHubHost is the host serving the registry API for docker.io.
+const dockerHubHost = "registry-1.docker.io"
+
+// ErrUnsupportedAuth is the error returned when the registry requests an
+// authentication scheme other than Basic and Bearer.
+var ErrUnsupportedAuth = errors.New("unsupported registry authentication scheme")
+
+// ErrForeignURL is the error returned when the registry points to a URL on
+// another host, like the next page of a list of tags, which isn't followed
+// as the request would carry the credentials of the registry.
+var ErrForeignURL = errors.New("registry URL on another host")
+
+// Error is the error returned when the registry responds with an error
+// status. Code is the first error code reported by the registry, like
+// "MANIFEST_UNKNOWN" or "NAME_UNKNOWN", when there's one.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("registry returned status code %d (%s): %s", e.Status, e.Code, e.Message)
+	}
+	return fmt.Sprintf("registry returned status code %d: %s", e.Status, e.Message)
+}
+
+// Client is a client for the registry API. Its zero value is not usable, use
+// NewClient instead. A Client is safe for concurrent use, and caches the
+// tokens it obtains from the registries' authorization services.
+type Client struct {
+	// HTTPClient is used for all requests, defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// PlainHTTP makes the client talk to registries over HTTP instead of
+	// HTTPS, for local, insecure registries.
+	PlainHTTP bool
+
+	auth docker.AuthConfiguration
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewClient returns a client authenticating with the given credentials. The
+// credentials can be empty, for anonymous access.
+func NewClient(auth docker.AuthConfiguration) *Client {
+	return &Client{auth: auth, tokens: make(map[string]string)}
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) baseURL(ref docker.ImageReference) string {
+	scheme := "https"
+	if c.PlainHTTP {
+		scheme = "http"
+	}
+	host := ref.Registry
+	if host == docker.DefaultRegistry {
+		host = dockerHubHost
+	}
+	return scheme + "://" + host + "/v2/" + ref.Repository
+}
+
+// get sends a GET request for the given path in the repository of ref,
+// answering the authentication challenge of the registry if needed. The
+// caller must close the body of the returned response, whose status is
+// always 2xx.
+func (c *Client) get(ctx context.Context, ref docker.ImageReference, path string, accept []string) (*http.Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	scope := "repository:" + ref.Repository + ":pull"
+	urlStr := c.baseURL(ref) + path
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		// the credentials must not be sent to other hosts
+		target, err := url.Parse(path)
+		if err != nil {
+			return nil, err
+		}
+		base, err := url.Parse(c.baseURL(ref))
+		if err != nil {
+			return nil, err
+		}
+		if target.Scheme != base.Scheme || target.Host != base.Host {
+			return nil, fmt.Errorf("%w: %q", ErrForeignURL, path)
+		}
+		urlStr = path
+	}
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, mediaType := range accept {
+			req.Header.Add("Accept", mediaType)
+		}
+		return req, nil
+	}
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+	c.authorize(req, ref.Registry, scope)
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := c.login(ctx, ref.Registry, scope, challenge); err != nil {
+			return nil, err
+		}
+		if req, err = newRequest(); err != nil {
+			return nil, err
+		}
+		c.authorize(req, ref.Registry, scope)
+		if resp, err = c.httpClient().Do(req); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newError(resp)
+	}
+	return resp, nil
+}
+
+func newError(resp *http.Response) error {
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &Error{Status: resp.StatusCode, Message: fmt.Sprintf("cannot read body, err: %v", err)}
+	}
+	var body struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(data, &body) == nil && len(body.Errors) > 0 {
+		return &Error{Status: resp.StatusCode, Code: body.Errors[0].Code, Message: body.Errors[0].Message}
+	}
+	return &Error{Status: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+}
+
+// authorize sets the Authorization header of req, using the cached token for
+// the scope or, for registries using basic authentication, the credentials.
+func (c *Client) authorize(req *http.Request, registry, scope string) {
+	c.mu.Lock()
+	token, ok := c.tokens[registry+" "+scope]
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+	if token == "" {
+		req.SetBasicAuth(c.auth.Username, c.auth.Password)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+}
+
+// login answers the given WWW-Authenticate challenge, caching the token for
+// later requests in the same scope. An empty token in the cache means basic
+// authentication.
+func (c *Client) login(ctx context.Context, registry, scope, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	var token string
+	switch scheme {
+	case "basic":
+		if c.auth.Username == "" {
+			return &Error{Status: http.StatusUnauthorized, Message: "registry requires credentials"}
+		}
+	case "bearer":
+		if c.auth.RegistryToken != "" {
+			token = c.auth.RegistryToken
+			break
+		}
+		var err error
+		if token, err = c.fetchToken(ctx, params, scope); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAuth, challenge)
+	}
+	c.mu.Lock()
+	c.tokens[registry+" "+scope] = token
+	c.mu.Unlock()
+	return nil
+}
+
+// fetchToken obtains a token from the authorization service described by
+// the parameters of a Bearer challenge.
+func (c *Client) fetchToken(ctx context.Context, params map[string]string, scope string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("%w: invalid realm %q", ErrUnsupportedAuth, params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if s := params["scope"]; s != "" {
+		scope = s
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case c.auth.IdentityToken != "":
+		req.SetBasicAuth("<token>", c.auth.IdentityToken)
+	case c.auth.Username != "":
+		req.SetBasicAuth(c.auth.Username, c.auth.Password)
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", newError(resp)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token == "" {
+		body.Token = body.AccessToken
+	}
+	return body.Token, nil
+}
+
+// parseChallenge parses a WWW-Authenticate header like
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`,
+// returning the lowercased scheme and its parameters.
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := make(map[string]string)
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimLeft(rest, ", ") {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key], rest = value[1:end+1], value[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(value, ",")
+			params[key] = strings.TrimSpace(value)
+		}
+	}
+	return strings.ToLower(scheme), params
+}
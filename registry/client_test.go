@@ -0,0 +1,207 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	testConfig   = `{"architecture":"amd64","os":"linux","config":{"Env":["PATH=/bin"],"Cmd":["sh"]},"rootfs":{"type":"layers","diff_ids":["sha256:aaa"]}}`
+	testToken    = "s3cr3t-token"
+	testUsername = "gopher"
+	testPassword = "gopher123"
+)
+
+var testManifest = `{"schemaVersion":2,"mediaType":"` + MediaTypeDockerManifest + `","config":{"mediaType":"application/vnd.docker.container.image.v1+json","digest":"` + digestOf([]byte(testConfig)) + `","size":10},"layers":[{"mediaType":"application/vnd.docker.image.rootfs.diff.tar.gzip","digest":"sha256:bbb","size":20}]}`
+
+// newTestRegistry starts a registry serving the "library/app" repository,
+// protected by token authentication.
+func newTestRegistry(t *testing.T) (*httptest.Server, *int) {
+	t.Helper()
+	var tokenRequests int
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		user, pass, _ := r.BasicAuth()
+		if user != testUsername || pass != testPassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("scope") != "repository:team/app:pull" || r.URL.Query().Get("service") != "test-registry" {
+			t.Errorf("wrong token request: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"token":"` + testToken + `"}`))
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test-registry",scope="repository:team/app:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/team/app/tags/list":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/team/app/tags/list?n=2&last=1.1>; rel="next"`)
+				w.Write([]byte(`{"name":"team/app","tags":["1.0","1.1"]}`))
+				return
+			}
+			w.Write([]byte(`{"name":"team/app","tags":["latest"]}`))
+		case "/v2/team/app/manifests/1.0", "/v2/team/app/manifests/" + digestOf([]byte(testManifest)):
+			if !strings.Contains(strings.Join(r.Header.Values("Accept"), ","), MediaTypeOCIManifest) {
+				t.Errorf("manifest request without the OCI media type: %v", r.Header.Values("Accept"))
+			}
+			w.Header().Set("Content-Type", MediaTypeDockerManifest)
+			w.Write([]byte(testManifest))
+		case "/v2/team/app/blobs/" + digestOf([]byte(testConfig)):
+			w.Write([]byte(testConfig))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`))
+		}
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &tokenRequests
+}
+
+func newTestClient(server *httptest.Server) (*Client, string) {
+	client := NewClient(docker.AuthConfiguration{Username: testUsername, Password: testPassword})
+	client.PlainHTTP = true
+	return client, strings.TrimPrefix(server.URL, "http://") + "/team/app"
+}
+
+func TestListTags(t *testing.T) {
+	t.Parallel()
+	server, tokenRequests := newTestRegistry(t)
+	client, repository := newTestClient(server)
+	tags, err := client.ListTags(context.Background(), repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"1.0", "1.1", "latest"}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("ListTags: wrong tags. Want %v. Got %v.", expected, tags)
+	}
+	if *tokenRequests != 1 {
+		t.Errorf("ListTags: token should be cached, got %d token requests.", *tokenRequests)
+	}
+}
+
+func TestListTagsForeignLink(t *testing.T) {
+	t.Parallel()
+	var foreignRequests int
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		foreignRequests++
+		w.Write([]byte(`{"tags":[]}`))
+	}))
+	defer foreign.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test-registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Link", `<`+foreign.URL+`/v2/team/app/tags/list?last=1.0>; rel="next"`)
+		w.Write([]byte(`{"name":"team/app","tags":["1.0"]}`))
+	}))
+	defer server.Close()
+	client, repository := newTestClient(server)
+	_, err := client.ListTags(context.Background(), repository)
+	if !errors.Is(err, ErrForeignURL) {
+		t.Errorf("ListTags: wrong error. Want %v. Got %v.", ErrForeignURL, err)
+	}
+	if foreignRequests != 0 {
+		t.Errorf("ListTags: %d requests sent to another host", foreignRequests)
+	}
+}
+
+func TestManifestAndConfig(t *testing.T) {
+	t.Parallel()
+	server, _ := newTestRegistry(t)
+	client, repository := newTestClient(server)
+	manifest, err := client.Manifest(context.Background(), repository+":1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Digest != digestOf([]byte(testManifest)) || manifest.IsList() {
+		t.Errorf("Manifest: wrong manifest: %#v.", manifest)
+	}
+	if len(manifest.Layers) != 1 || manifest.Layers[0].Digest != "sha256:bbb" {
+		t.Errorf("Manifest: wrong layers: %#v.", manifest.Layers)
+	}
+	byDigest, err := client.Manifest(context.Background(), repository+"@"+manifest.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(byDigest, manifest) {
+		t.Errorf("Manifest: fetching by digest returned a different manifest: %#v.", byDigest)
+	}
+	config, err := client.Config(context.Background(), repository, manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.OS != "linux" || !reflect.DeepEqual(config.Config.Cmd, []string{"sh"}) || config.RootFS.DiffIDs[0] != "sha256:aaa" {
+		t.Errorf("Config: wrong config: %#v.", config)
+	}
+}
+
+func TestManifestNotFound(t *testing.T) {
+	t.Parallel()
+	server, _ := newTestRegistry(t)
+	client, repository := newTestClient(server)
+	_, err := client.Manifest(context.Background(), repository+":2.0")
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusNotFound || e.Code != "MANIFEST_UNKNOWN" {
+		t.Errorf("Manifest: wrong error: %#v.", err)
+	}
+}
+
+func TestManifestUnauthorized(t *testing.T) {
+	t.Parallel()
+	server, _ := newTestRegistry(t)
+	client, repository := newTestClient(server)
+	client.auth.Password = "wrong"
+	_, err := client.Manifest(context.Background(), repository+":1.0")
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusUnauthorized {
+		t.Errorf("Manifest: wrong error: %#v.", err)
+	}
+}
+
+func TestConfigManifestList(t *testing.T) {
+	t.Parallel()
+	list := &Manifest{MediaType: MediaTypeOCIIndex, Manifests: []Descriptor{
+		{Digest: "sha256:arm", Platform: &Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+		{Digest: "sha256:amd", Platform: &Platform{OS: "linux", Architecture: "amd64"}},
+	}}
+	if _, err := NewClient(docker.AuthConfiguration{}).Config(context.Background(), "busybox", list); !errors.Is(err, ErrManifestList) {
+		t.Errorf("Config: wrong error. Want %#v. Got %#v.", ErrManifestList, err)
+	}
+	if d, ok := list.Platform("linux", "arm64", ""); !ok || d.Digest != "sha256:arm" {
+		t.Errorf("Platform: wrong descriptor: %#v.", d)
+	}
+	if _, ok := list.Platform("windows", "amd64", ""); ok {
+		t.Error("Platform: unexpected descriptor for windows/amd64.")
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	t.Parallel()
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/busybox:pull"`)
+	expected := map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/busybox:pull",
+	}
+	if scheme != "bearer" || !reflect.DeepEqual(params, expected) {
+		t.Errorf("parseChallenge: wrong result: %q %#v.", scheme, params)
+	}
+}
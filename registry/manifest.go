@@ -0,0 +1,232 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// Media types of the manifests supported by the client.
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+// maxManifestSize is the maximum size of a manifest or config accepted by
+// the client.
+const maxManifestSize = 4 << 20
+
+// ErrManifestList is the error returned by Config when given a manifest list
+// (or OCI index) instead of the manifest of a single image. Use
+// Manifest.Platform to pick the manifest of one of the platforms.
+var ErrManifestList = errors.New("manifest is a manifest list")
+
+var manifestMediaTypes = []string{
+	MediaTypeDockerManifest,
+	MediaTypeDockerManifestList,
+	MediaTypeOCIManifest,
+	MediaTypeOCIIndex,
+}
+
+// Descriptor references content in the registry: a layer, a config or, in
+// manifest lists, the manifest of one of the platforms.
+type Descriptor struct {
+	MediaType string    `json:"mediaType"`
+	Digest    string    `json:"digest"`
+	Size      int64     `json:"size"`
+	Platform  *Platform `json:"platform,omitempty"`
+}
+
+// Platform is the platform of an image in a manifest list.
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Manifest is an image manifest, or a manifest list when Manifests is set.
+type Manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType,omitempty"`
+	Config        Descriptor   `json:"config"`
+	Layers        []Descriptor `json:"layers,omitempty"`
+	Manifests     []Descriptor `json:"manifests,omitempty"`
+
+	// Digest is the digest of the manifest, which can be used to pull the
+	// image by digest. Raw holds the manifest as returned by the registry.
+	Digest string `json:"-"`
+	Raw    []byte `json:"-"`
+}
+
+// IsList reports whether the manifest is a manifest list (or OCI index).
+func (m *Manifest) IsList() bool {
+	return m.MediaType == MediaTypeDockerManifestList || m.MediaType == MediaTypeOCIIndex ||
+		(m.MediaType == "" && len(m.Manifests) > 0)
+}
+
+// Platform returns the descriptor of the manifest for the given platform in
+// a manifest list. An empty variant matches any variant.
+func (m *Manifest) Platform(os, arch, variant string) (Descriptor, bool) {
+	for _, d := range m.Manifests {
+		if d.Platform != nil && d.Platform.OS == os && d.Platform.Architecture == arch &&
+			(variant == "" || d.Platform.Variant == variant) {
+			return d, true
+		}
+	}
+	return Descriptor{}, false
+}
+
+// ImageConfig is the configuration of an image, as stored in the registry.
+type ImageConfig struct {
+	Architecture string        `json:"architecture"`
+	OS           string        `json:"os"`
+	Variant      string        `json:"variant,omitempty"`
+	Created      *time.Time    `json:"created,omitempty"`
+	Author       string        `json:"author,omitempty"`
+	Config       docker.Config `json:"config"`
+	RootFS       struct {
+		Type    string   `json:"type"`
+		DiffIDs []string `json:"diff_ids"`
+	} `json:"rootfs"`
+	History []struct {
+		Created    *time.Time `json:"created,omitempty"`
+		CreatedBy  string     `json:"created_by,omitempty"`
+		Comment    string     `json:"comment,omitempty"`
+		EmptyLayer bool       `json:"empty_layer,omitempty"`
+	} `json:"history,omitempty"`
+}
+
+// ListTags returns the tags of the repository of the given reference, like
+// "busybox" or "quay.io/coreos/etcd", following the pagination of the
+// registry.
+func (c *Client) ListTags(ctx context.Context, repository string) ([]string, error) {
+	ref, err := docker.ParseImageReference(repository)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for path := "/tags/list"; path != ""; {
+		resp, err := c.get(ctx, ref, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+		path, err = nextPage(resp, c.baseURL(ref))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// nextPage returns the URL of the next page of results, taken from the Link
+// header of resp and resolved against the base URL of the repository, or an
+// empty string on the last page.
+func nextPage(resp *http.Response, baseURL string) (string, error) {
+	link := resp.Header.Get("Link")
+	if link == "" {
+		return "", nil
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start < 0 || end < start || !strings.Contains(link[end:], `rel="next"`) {
+		return "", nil
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	next, err := base.Parse(link[start+1 : end])
+	if err != nil {
+		return "", err
+	}
+	return next.String(), nil
+}
+
+// Manifest fetches the manifest of the given reference, like "busybox:1.36"
+// or "quay.io/coreos/etcd@sha256:...". References without a tag or digest
+// default to the "latest" tag.
+func (c *Client) Manifest(ctx context.Context, ref string) (*Manifest, error) {
+	parsed, err := docker.ParseImageReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	reference := parsed.TagOrDigest()
+	if reference == "" {
+		reference = docker.DefaultTag
+	}
+	resp, err := c.get(ctx, parsed, "/manifests/"+reference, manifestMediaTypes)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, err
+	}
+	manifest := Manifest{Raw: data, Digest: digestOf(data)}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.MediaType == "" {
+		manifest.MediaType, _, _ = strings.Cut(resp.Header.Get("Content-Type"), ";")
+	}
+	if parsed.Digest != "" && parsed.Digest != manifest.Digest {
+		return nil, fmt.Errorf("manifest digest mismatch: want %s, got %s", parsed.Digest, manifest.Digest)
+	}
+	return &manifest, nil
+}
+
+// Config fetches the configuration of the image described by the given
+// manifest, which must belong to the repository of ref. It returns
+// ErrManifestList for manifest lists.
+func (c *Client) Config(ctx context.Context, ref string, manifest *Manifest) (*ImageConfig, error) {
+	if manifest.IsList() {
+		return nil, ErrManifestList
+	}
+	parsed, err := docker.ParseImageReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.get(ctx, parsed, "/blobs/"+manifest.Config.Digest, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, err
+	}
+	if digest := digestOf(data); digest != manifest.Config.Digest {
+		return nil, fmt.Errorf("config digest mismatch: want %s, got %s", manifest.Config.Digest, digest)
+	}
+	var config ImageConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,244 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// RunSpec is a high-level description of a container, mirroring the flags
+// of docker run. Its CreateContainerOptions method translates it into the
+// options of CreateContainer.
+type RunSpec struct {
+	Name  string
+	Image string
+
+	// Entrypoint, when set, replaces the entrypoint of the image, and
+	// Command the arguments given to it (the CMD of the image).
+	Entrypoint []string
+	Command    []string
+
+	// Env holds the environment variables, in the KEY=value form.
+	Env        []string
+	Labels     map[string]string
+	WorkingDir string
+	User       string
+	Hostname   string
+
+	// Ports are the ports to publish, in the format of the -p flag, like
+	// "8080:80" or "127.0.0.1:53:53/udp". See ParsePortSpecs.
+	Ports []string
+
+	// Volumes are the volumes to mount, in the format of the -v flag:
+	// "name:/path" for a named volume, "/host/path:/path" for a bind
+	// mount, or "/path" for an anonymous volume. Mount options, like
+	// "ro", may follow, as in "/host/path:/path:ro".
+	Volumes []string
+
+	// Tmpfs are the tmpfs filesystems to mount, in the format of the
+	// --tmpfs flag, like "/run" or "/tmp:size=64m".
+	Tmpfs []string
+
+	// Restart is the restart policy, in the format of the --restart flag:
+	// "no", "always", "unless-stopped" or "on-failure[:max-retries]".
+	Restart string
+
+	// Network is the network to connect the container to, either a network
+	// name or ID, or one of the "bridge", "host", "none" and
+	// "container:<name|id>" modes. NetworkAliases are the aliases of the
+	// container in that network, which must be user-defined.
+	Network        string
+	NetworkAliases []string
+
+	// Interactive keeps the standard input open, and TTY allocates a
+	// pseudo-terminal.
+	Interactive bool
+	TTY         bool
+
+	// AutoRemove makes the daemon remove the container once it exits.
+	AutoRemove bool
+	Privileged bool
+	ReadOnly   bool
+}
+
+// CreateContainerOptions translates the spec into the options of
+// CreateContainer, checking that its settings are well-formed and
+// compatible with each other: for instance, AutoRemove can't be combined
+// with a restart policy, and ports can't be published on the host network.
+// All the problems found are reported in the returned error, which wraps
+// ErrInvalidContainerOptions.
+func (spec RunSpec) CreateContainerOptions() (CreateContainerOptions, error) {
+	var errs []error
+	if spec.Image == "" {
+		errs = append(errs, errors.New("missing image"))
+	}
+	config := &Config{
+		Image:        spec.Image,
+		Entrypoint:   spec.Entrypoint,
+		Cmd:          spec.Command,
+		Labels:       spec.Labels,
+		WorkingDir:   spec.WorkingDir,
+		User:         spec.User,
+		Hostname:     spec.Hostname,
+		Tty:          spec.TTY,
+		OpenStdin:    spec.Interactive,
+		StdinOnce:    spec.Interactive,
+		AttachStdin:  spec.Interactive,
+		AttachStdout: true,
+		AttachStderr: true,
+	}
+	hostConfig := &HostConfig{
+		AutoRemove:     spec.AutoRemove,
+		Privileged:     spec.Privileged,
+		ReadonlyRootfs: spec.ReadOnly,
+	}
+	for _, env := range spec.Env {
+		if key, _, _ := strings.Cut(env, "="); key == "" {
+			errs = append(errs, fmt.Errorf("invalid environment variable %q", env))
+			continue
+		}
+		config.Env = append(config.Env, env)
+	}
+
+	if len(spec.Ports) > 0 {
+		exposed, bindings, err := ParsePortSpecs(spec.Ports)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		config.ExposedPorts = exposed
+		hostConfig.PortBindings = bindings
+	}
+
+	targets := make(map[string]bool)
+	addTarget := func(target string) {
+		if targets[target] {
+			errs = append(errs, fmt.Errorf("duplicate mount point %q", target))
+		}
+		targets[target] = true
+	}
+	for _, volume := range spec.Volumes {
+		source, target, err := parseVolumeSpec(volume)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		addTarget(target)
+		if source == "" {
+			if config.Volumes == nil {
+				config.Volumes = make(map[string]struct{})
+			}
+			config.Volumes[target] = struct{}{}
+			continue
+		}
+		hostConfig.Binds = append(hostConfig.Binds, volume)
+	}
+	for _, tmpfs := range spec.Tmpfs {
+		target, options, _ := strings.Cut(tmpfs, ":")
+		if !path.IsAbs(target) {
+			errs = append(errs, fmt.Errorf("invalid tmpfs %q: the mount point must be an absolute path", tmpfs))
+			continue
+		}
+		addTarget(target)
+		if hostConfig.Tmpfs == nil {
+			hostConfig.Tmpfs = make(map[string]string)
+		}
+		hostConfig.Tmpfs[target] = options
+	}
+
+	if spec.Restart != "" {
+		policy, err := parseRestartPolicy(spec.Restart)
+		if err != nil {
+			errs = append(errs, err)
+		} else if policy.Name != "no" && spec.AutoRemove {
+			errs = append(errs, fmt.Errorf("restart policy %q can't be used with AutoRemove", spec.Restart))
+		}
+		hostConfig.RestartPolicy = policy
+	}
+
+	var networkingConfig *NetworkingConfig
+	network := spec.Network
+	isContainerNetwork := strings.HasPrefix(network, "container:")
+	switch {
+	case network == "host" || network == "none" || isContainerNetwork:
+		if len(spec.Ports) > 0 {
+			errs = append(errs, fmt.Errorf("ports can't be published with network %q", network))
+		}
+		if network != "none" && spec.Hostname != "" {
+			errs = append(errs, fmt.Errorf("hostname can't be set with network %q", network))
+		}
+	case network != "" && network != "bridge" && network != "default":
+		if len(spec.NetworkAliases) > 0 {
+			networkingConfig = &NetworkingConfig{EndpointsConfig: map[string]*EndpointConfig{
+				network: {Aliases: spec.NetworkAliases},
+			}}
+		}
+	}
+	if len(spec.NetworkAliases) > 0 && networkingConfig == nil {
+		errs = append(errs, errors.New("network aliases can only be used with user-defined networks"))
+	}
+	hostConfig.NetworkMode = network
+
+	opts := CreateContainerOptions{
+		Name:             spec.Name,
+		Config:           config,
+		HostConfig:       hostConfig,
+		NetworkingConfig: networkingConfig,
+	}
+	if len(errs) > 0 {
+		return opts, fmt.Errorf("%w: %w", ErrInvalidContainerOptions, errors.Join(errs...))
+	}
+	return opts, nil
+}
+
+// parseVolumeSpec parses a volume in the format of the -v flag of docker
+// run, returning its source, empty for anonymous volumes, and its mount
+// point.
+func parseVolumeSpec(volume string) (source, target string, err error) {
+	parts := strings.Split(volume, ":")
+	var mode string
+	switch len(parts) {
+	case 1:
+		target = parts[0]
+	case 2:
+		source, target = parts[0], parts[1]
+	case 3:
+		source, target, mode = parts[0], parts[1], parts[2]
+	default:
+		return "", "", fmt.Errorf("invalid volume %q", volume)
+	}
+	if !path.IsAbs(target) {
+		return "", "", fmt.Errorf("invalid volume %q: the mount point must be an absolute path", volume)
+	}
+	if len(parts) > 1 && source == "" {
+		return "", "", fmt.Errorf("invalid volume %q: missing source", volume)
+	}
+	for _, option := range strings.Split(mode, ",") {
+		switch option {
+		case "", "ro", "rw", "z", "Z", "nocopy", "consistent", "cached", "delegated",
+			"shared", "rshared", "slave", "rslave", "private", "rprivate":
+		default:
+			return "", "", fmt.Errorf("invalid volume %q: unknown option %q", volume, option)
+		}
+	}
+	return source, target, nil
+}
+
+// parseRestartPolicy parses a restart policy in the format of the --restart
+// flag of docker run.
+func parseRestartPolicy(restart string) (RestartPolicy, error) {
+	name, rawRetries, hasRetries := strings.Cut(restart, ":")
+	policy := RestartPolicy{Name: name}
+	if hasRetries {
+		if name != "on-failure" {
+			return policy, fmt.Errorf("maximum retry count can't be used with restart policy %q", name)
+		}
+		retries, err := strconv.Atoi(rawRetries)
+		if err != nil || retries < 0 {
+			return policy, fmt.Errorf("invalid maximum retry count in restart policy %q", restart)
+		}
+		policy.MaximumRetryCount = retries
+	}
+	return policy, validateRestartPolicy(policy)
+}
@@ -0,0 +1,111 @@
+package docker
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunSpecCreateContainerOptions(t *testing.T) {
+	t.Parallel()
+	spec := RunSpec{
+		Name:           "web",
+		Image:          "nginx:1.25",
+		Command:        []string{"nginx", "-g", "daemon off;"},
+		Env:            []string{"MODE=production", "DEBUG="},
+		Labels:         map[string]string{"app": "web"},
+		Ports:          []string{"8080:80", "127.0.0.1::443"},
+		Volumes:        []string{"web-data:/data", "/srv/conf:/etc/nginx/conf.d:ro", "/cache"},
+		Tmpfs:          []string{"/run", "/tmp:size=64m"},
+		Restart:        "on-failure:3",
+		Network:        "frontend",
+		NetworkAliases: []string{"www"},
+		Interactive:    true,
+		TTY:            true,
+	}
+	opts, err := spec.CreateContainerOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := CreateContainerOptions{
+		Name: "web",
+		Config: &Config{
+			Image:        "nginx:1.25",
+			Cmd:          []string{"nginx", "-g", "daemon off;"},
+			Env:          []string{"MODE=production", "DEBUG="},
+			Labels:       map[string]string{"app": "web"},
+			ExposedPorts: map[Port]struct{}{"80/tcp": {}, "443/tcp": {}},
+			Volumes:      map[string]struct{}{"/cache": {}},
+			Tty:          true,
+			OpenStdin:    true,
+			StdinOnce:    true,
+			AttachStdin:  true,
+			AttachStdout: true,
+			AttachStderr: true,
+		},
+		HostConfig: &HostConfig{
+			PortBindings: map[Port][]PortBinding{
+				"80/tcp":  {{HostPort: "8080"}},
+				"443/tcp": {{HostIP: "127.0.0.1"}},
+			},
+			Binds:         []string{"web-data:/data", "/srv/conf:/etc/nginx/conf.d:ro"},
+			Tmpfs:         map[string]string{"/run": "", "/tmp": "size=64m"},
+			RestartPolicy: RestartOnFailure(3),
+			NetworkMode:   "frontend",
+		},
+		NetworkingConfig: &NetworkingConfig{EndpointsConfig: map[string]*EndpointConfig{
+			"frontend": {Aliases: []string{"www"}},
+		}},
+	}
+	if !reflect.DeepEqual(opts, expected) {
+		t.Errorf("CreateContainerOptions: wrong options.\nWant %#v.\nGot  %#v.", expected, opts)
+	}
+	if err := opts.Validate(); err != nil {
+		t.Errorf("CreateContainerOptions: returned options aren't valid: %v", err)
+	}
+}
+
+func TestRunSpecCreateContainerOptionsInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		spec RunSpec
+		want string
+	}{
+		{"missing image", RunSpec{}, "missing image"},
+		{"invalid env", RunSpec{Image: "busybox", Env: []string{"=value"}}, `invalid environment variable "=value"`},
+		{"invalid port", RunSpec{Image: "busybox", Ports: []string{"http"}}, `invalid port spec "http"`},
+		{"relative volume", RunSpec{Image: "busybox", Volumes: []string{"data:data"}}, "must be an absolute path"},
+		{"volume option", RunSpec{Image: "busybox", Volumes: []string{"/a:/b:rx"}}, `unknown option "rx"`},
+		{"duplicate mount", RunSpec{Image: "busybox", Volumes: []string{"/data"}, Tmpfs: []string{"/data"}}, `duplicate mount point "/data"`},
+		{"restart policy", RunSpec{Image: "busybox", Restart: "sometimes"}, `invalid restart policy "sometimes"`},
+		{"restart retries", RunSpec{Image: "busybox", Restart: "always:3"}, "maximum retry count"},
+		{"restart and remove", RunSpec{Image: "busybox", Restart: "always", AutoRemove: true}, "can't be used with AutoRemove"},
+		{"host network ports", RunSpec{Image: "busybox", Network: "host", Ports: []string{"80"}}, `ports can't be published with network "host"`},
+		{"container network hostname", RunSpec{Image: "busybox", Network: "container:db", Hostname: "web"}, "hostname can't be set"},
+		{"bridge aliases", RunSpec{Image: "busybox", NetworkAliases: []string{"www"}}, "user-defined networks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.spec.CreateContainerOptions()
+			if !errors.Is(err, ErrInvalidContainerOptions) {
+				t.Fatalf("CreateContainerOptions: wrong error. Want %v. Got %v.", ErrInvalidContainerOptions, err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CreateContainerOptions: wrong error. Want it to contain %q. Got %q.", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRunSpecCreateContainerOptionsNoRestart(t *testing.T) {
+	t.Parallel()
+	opts, err := RunSpec{Image: "busybox", Restart: "no", AutoRemove: true, Network: "none"}.CreateContainerOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.HostConfig.RestartPolicy != NeverRestart() || !opts.HostConfig.AutoRemove || opts.HostConfig.NetworkMode != "none" {
+		t.Errorf("CreateContainerOptions: wrong host config: %#v", opts.HostConfig)
+	}
+}
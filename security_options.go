@@ -0,0 +1,98 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Names of the security options reported by the daemon in
+// DockerInfo.SecurityOptions.
+const (
+	SecurityOptionAppArmor = "apparmor"
+	SecurityOptionSeccomp  = "seccomp"
+	SecurityOptionSELinux  = "selinux"
+	SecurityOptionUserns   = "userns"
+	SecurityOptionRootless = "rootless"
+	SecurityOptionCgroupns = "cgroupns"
+)
+
+// ErrInvalidSecurityOption is the error wrapped by the errors returned by
+// ParseSecurityOptions when an option is malformed.
+var ErrInvalidSecurityOption = errors.New("invalid security option")
+
+// SecurityOption is a security feature enabled in the daemon, parsed from
+// DockerInfo.SecurityOptions. For instance, "name=seccomp,profile=default"
+// is parsed into the seccomp option, with "default" as its "profile".
+type SecurityOption struct {
+	Name    string
+	Options map[string]string
+}
+
+// ParseSecurityOptions parses security options in the format of
+// DockerInfo.SecurityOptions. Options reported by daemons older than API
+// 1.30 are just names, like "seccomp", and are parsed as such.
+func ParseSecurityOptions(options []string) ([]SecurityOption, error) {
+	parsed := make([]SecurityOption, 0, len(options))
+	for _, option := range options {
+		securityOption, err := parseSecurityOption(option)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, securityOption)
+	}
+	return parsed, nil
+}
+
+func parseSecurityOption(option string) (SecurityOption, error) {
+	if !strings.Contains(option, "=") {
+		return SecurityOption{Name: option}, nil
+	}
+	var securityOption SecurityOption
+	for _, field := range strings.Split(option, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key == "" {
+			return SecurityOption{}, fmt.Errorf("%w %q", ErrInvalidSecurityOption, option)
+		}
+		if key == "name" {
+			securityOption.Name = value
+			continue
+		}
+		if securityOption.Options == nil {
+			securityOption.Options = make(map[string]string)
+		}
+		securityOption.Options[key] = value
+	}
+	if securityOption.Name == "" {
+		return SecurityOption{}, fmt.Errorf("%w %q: missing name", ErrInvalidSecurityOption, option)
+	}
+	return securityOption, nil
+}
+
+// SecurityOption returns the security option of the daemon with the given
+// name, like SecurityOptionSeccomp, and whether it's enabled. Malformed
+// options are ignored.
+func (info *DockerInfo) SecurityOption(name string) (SecurityOption, bool) {
+	for _, option := range info.SecurityOptions {
+		securityOption, err := parseSecurityOption(option)
+		if err == nil && securityOption.Name == name {
+			return securityOption, true
+		}
+	}
+	return SecurityOption{}, false
+}
+
+// IsRootless reports whether the daemon runs as an unprivileged user, in
+// which case privileged containers don't get more privileges than the user
+// running the daemon.
+func (info *DockerInfo) IsRootless() bool {
+	_, ok := info.SecurityOption(SecurityOptionRootless)
+	return ok
+}
+
+// IsUsernsRemapped reports whether the daemon remaps the users of the
+// containers to a user namespace, see the --userns-remap flag of dockerd.
+func (info *DockerInfo) IsUsernsRemapped() bool {
+	_, ok := info.SecurityOption(SecurityOptionUserns)
+	return ok
+}
@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseSecurityOptions(t *testing.T) {
+	t.Parallel()
+	options := []string{
+		"name=apparmor",
+		"name=seccomp,profile=builtin",
+		"name=selinux",
+		"name=rootless",
+		"name=cgroupns",
+		"seccomp",
+	}
+	expected := []SecurityOption{
+		{Name: SecurityOptionAppArmor},
+		{Name: SecurityOptionSeccomp, Options: map[string]string{"profile": "builtin"}},
+		{Name: SecurityOptionSELinux},
+		{Name: SecurityOptionRootless},
+		{Name: SecurityOptionCgroupns},
+		{Name: SecurityOptionSeccomp},
+	}
+	got, err := ParseSecurityOptions(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseSecurityOptions: wrong options. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestParseSecurityOptionsInvalid(t *testing.T) {
+	t.Parallel()
+	for _, option := range []string{"name=seccomp,profile", "profile=default", "name=seccomp,=x"} {
+		if _, err := ParseSecurityOptions([]string{option}); !errors.Is(err, ErrInvalidSecurityOption) {
+			t.Errorf("ParseSecurityOptions(%q): wrong error. Want %v. Got %v.", option, ErrInvalidSecurityOption, err)
+		}
+	}
+}
+
+func TestDockerInfoSecurityOption(t *testing.T) {
+	t.Parallel()
+	info := DockerInfo{SecurityOptions: []string{"name=seccomp,profile=default", "bogus=", "name=rootless"}}
+	seccomp, ok := info.SecurityOption(SecurityOptionSeccomp)
+	if !ok || seccomp.Options["profile"] != "default" {
+		t.Errorf("SecurityOption: wrong seccomp option: %#v, %v", seccomp, ok)
+	}
+	if _, ok := info.SecurityOption(SecurityOptionSELinux); ok {
+		t.Error("SecurityOption: unexpected selinux option")
+	}
+	if !info.IsRootless() {
+		t.Error("IsRootless: want true, got false")
+	}
+	if info.IsUsernsRemapped() {
+		t.Error("IsUsernsRemapped: want false, got true")
+	}
+	info = DockerInfo{SecurityOptions: []string{"name=apparmor", "name=userns"}}
+	if info.IsRootless() {
+		t.Error("IsRootless: want false, got true")
+	}
+	if !info.IsUsernsRemapped() {
+		t.Error("IsUsernsRemapped: want true, got false")
+	}
+}
@@ -4,6 +4,12 @@
 
 package docker
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // Signal represents a signal that can be send to the container on
 // KillContainer call.
 type Signal int
@@ -47,3 +53,57 @@ const (
 	SIGXCPU   = Signal(0x18)
 	SIGXFSZ   = Signal(0x19)
 )
+
+var signalsByName = map[string]Signal{
+	"ABRT":   SIGABRT,
+	"ALRM":   SIGALRM,
+	"BUS":    SIGBUS,
+	"CHLD":   SIGCHLD,
+	"CLD":    SIGCLD,
+	"CONT":   SIGCONT,
+	"FPE":    SIGFPE,
+	"HUP":    SIGHUP,
+	"ILL":    SIGILL,
+	"INT":    SIGINT,
+	"IO":     SIGIO,
+	"IOT":    SIGIOT,
+	"KILL":   SIGKILL,
+	"PIPE":   SIGPIPE,
+	"POLL":   SIGPOLL,
+	"PROF":   SIGPROF,
+	"PWR":    SIGPWR,
+	"QUIT":   SIGQUIT,
+	"SEGV":   SIGSEGV,
+	"STKFLT": SIGSTKFLT,
+	"STOP":   SIGSTOP,
+	"SYS":    SIGSYS,
+	"TERM":   SIGTERM,
+	"TRAP":   SIGTRAP,
+	"TSTP":   SIGTSTP,
+	"TTIN":   SIGTTIN,
+	"TTOU":   SIGTTOU,
+	"UNUSED": SIGUNUSED,
+	"URG":    SIGURG,
+	"USR1":   SIGUSR1,
+	"USR2":   SIGUSR2,
+	"VTALRM": SIGVTALRM,
+	"WINCH":  SIGWINCH,
+	"XCPU":   SIGXCPU,
+	"XFSZ":   SIGXFSZ,
+}
+
+// ParseSignal parses the given signal, which may be either numeric ("1") or
+// symbolic, with or without the SIG prefix ("SIGHUP", "hup").
+func ParseSignal(s string) (Signal, error) {
+	if n, err := strconv.ParseUint(s, 10, 8); err == nil {
+		if n == 0 {
+			return 0, fmt.Errorf("invalid signal: %s", s)
+		}
+		return Signal(n), nil
+	}
+	name := strings.TrimPrefix(strings.ToUpper(s), "SIG")
+	if signal, ok := signalsByName[name]; ok {
+		return signal, nil
+	}
+	return 0, fmt.Errorf("invalid signal: %s", s)
+}
@@ -0,0 +1,35 @@
+package docker
+
+import "testing"
+
+func TestParseSignal(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected Signal
+	}{
+		{"SIGHUP", SIGHUP},
+		{"HUP", SIGHUP},
+		{"sigusr1", SIGUSR1},
+		{"term", SIGTERM},
+		{"9", SIGKILL},
+	}
+	for _, test := range tests {
+		got, err := ParseSignal(test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.expected {
+			t.Errorf("ParseSignal(%q): wrong signal. Want %d. Got %d.", test.input, test.expected, got)
+		}
+	}
+}
+
+func TestParseSignalInvalid(t *testing.T) {
+	t.Parallel()
+	for _, input := range []string{"", "0", "SIGFOO", "-1", "300"} {
+		if _, err := ParseSignal(input); err == nil {
+			t.Errorf("ParseSignal(%q): expected non-nil error, got <nil>", input)
+		}
+	}
+}
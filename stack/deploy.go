@@ -0,0 +1,405 @@
+package stack
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/swarm"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	// LabelNamespace is the label set on every resource of a stack, holding
+	// the namespace of the stack. It's the same label used by the Docker CLI,
+	// so stacks deployed by this package can be managed with "docker stack".
+	LabelNamespace = "com.docker.stack.namespace"
+
+	// LabelSpecHash is the label holding the hash of the spec a service was
+	// deployed from, used to skip updates of unchanged services.
+	LabelSpecHash = "com.github.fsouza.go-dockerclient.stack.spec-hash"
+
+	defaultNetwork = "default"
+)
+
+// ErrExternalNotFound is the error returned by Deploy when an external
+// network or secret referenced by the spec doesn't exist.
+var ErrExternalNotFound = errors.New("external resource not found")
+
+// DeployOptions specify parameters to the Deploy function.
+type DeployOptions struct {
+	// Namespace is the name of the stack. Resources of the stack are named
+	// after it, like "<namespace>_<service>".
+	Namespace string
+	Spec      Spec
+
+	// Prune removes services of the stack that are no longer in the spec.
+	Prune bool
+
+	// Auth is used by the daemon to pull the images of the services.
+	Auth docker.AuthConfiguration
+
+	Context context.Context
+}
+
+// DeployResult lists the services touched by Deploy, by their full name.
+type DeployResult struct {
+	Created   []string
+	Updated   []string
+	Unchanged []string
+	Removed   []string
+}
+
+// Deploy creates or updates the resources described by the spec in the swarm
+// the client is connected to: missing networks and secrets are created, and
+// services are created, or updated when their spec changed since the last
+// deploy.
+func Deploy(client *docker.Client, opts DeployOptions) (*DeployResult, error) {
+	if opts.Namespace == "" {
+		return nil, errors.New("stack: namespace is required")
+	}
+	d := deployer{client: client, opts: opts, ctx: opts.Context}
+	if d.ctx == nil {
+		d.ctx = context.Background()
+	}
+	networks, err := d.deployNetworks()
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := d.deploySecrets()
+	if err != nil {
+		return nil, err
+	}
+	return d.deployServices(networks, secrets)
+}
+
+type deployer struct {
+	client *docker.Client
+	opts   DeployOptions
+	ctx    context.Context
+}
+
+func (d *deployer) scopedName(name string) string {
+	return d.opts.Namespace + "_" + name
+}
+
+func (d *deployer) labels(labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[LabelNamespace] = d.opts.Namespace
+	return result
+}
+
+// deployNetworks creates the missing networks of the stack, returning the
+// name of the network of each key in the spec.
+func (d *deployer) deployNetworks() (map[string]string, error) {
+	specs := make(map[string]Network, len(d.opts.Spec.Networks)+1)
+	for key, network := range d.opts.Spec.Networks {
+		specs[key] = network
+	}
+	for _, service := range d.opts.Spec.Services {
+		if len(service.Networks) == 0 {
+			if _, ok := specs[defaultNetwork]; !ok {
+				specs[defaultNetwork] = Network{}
+			}
+		}
+		for _, key := range service.Networks {
+			if _, ok := specs[key]; !ok {
+				return nil, fmt.Errorf("stack: service refers to undefined network %q", key)
+			}
+		}
+	}
+	names := make(map[string]string, len(specs))
+	for key, spec := range specs {
+		name := spec.Name
+		if name == "" {
+			name = key
+			if !spec.External {
+				name = d.scopedName(key)
+			}
+		}
+		names[key] = name
+		existing, err := d.client.FilteredListNetworksWithContext(docker.NetworkFilterOpts{"name": {name: true}}, d.ctx)
+		if err != nil {
+			return nil, err
+		}
+		if hasNetwork(existing, name) {
+			continue
+		}
+		if spec.External {
+			return nil, fmt.Errorf("%w: network %q", ErrExternalNotFound, name)
+		}
+		driver := spec.Driver
+		if driver == "" {
+			driver = "overlay"
+		}
+		_, err = d.client.CreateNetwork(docker.CreateNetworkOptions{
+			Name:       name,
+			Driver:     driver,
+			Scope:      "swarm",
+			Labels:     d.labels(spec.Labels),
+			Attachable: spec.Attachable,
+			Internal:   spec.Internal,
+			Context:    d.ctx,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+func hasNetwork(networks []docker.Network, name string) bool {
+	for _, network := range networks {
+		if network.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// deploySecrets creates the missing secrets of the stack, returning the
+// secret of each key in the spec. Secrets are immutable in Swarm, so
+// existing secrets are left untouched.
+func (d *deployer) deploySecrets() (map[string]swarm.Secret, error) {
+	result := make(map[string]swarm.Secret, len(d.opts.Spec.Secrets))
+	for key, spec := range d.opts.Spec.Secrets {
+		name := spec.Name
+		if name == "" {
+			name = key
+			if !spec.External {
+				name = d.scopedName(key)
+			}
+		}
+		existing, err := d.client.ListSecrets(docker.ListSecretsOptions{
+			Filters: map[string][]string{"name": {name}},
+			Context: d.ctx,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if secret, ok := findSecret(existing, name); ok {
+			result[key] = secret
+			continue
+		}
+		if spec.External {
+			return nil, fmt.Errorf("%w: secret %q", ErrExternalNotFound, name)
+		}
+		data := spec.Data
+		if spec.File != "" {
+			if data, err = os.ReadFile(spec.File); err != nil {
+				return nil, err
+			}
+		}
+		secretSpec := swarm.SecretSpec{
+			Annotations: swarm.Annotations{Name: name, Labels: d.labels(spec.Labels)},
+			Data:        data,
+		}
+		secret, err := d.client.CreateSecret(docker.CreateSecretOptions{SecretSpec: secretSpec, Context: d.ctx})
+		if err != nil {
+			return nil, err
+		}
+		secret.Spec = secretSpec
+		result[key] = *secret
+	}
+	return result, nil
+}
+
+func findSecret(secrets []swarm.Secret, name string) (swarm.Secret, bool) {
+	for _, secret := range secrets {
+		if secret.Spec.Name == name {
+			return secret, true
+		}
+	}
+	return swarm.Secret{}, false
+}
+
+func (d *deployer) deployServices(networks map[string]string, secrets map[string]swarm.Secret) (*DeployResult, error) {
+	existing, err := d.client.ListServices(docker.ListServicesOptions{
+		Filters: map[string][]string{"label": {LabelNamespace + "=" + d.opts.Namespace}},
+		Context: d.ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]swarm.Service, len(existing))
+	for _, service := range existing {
+		byName[service.Spec.Name] = service
+	}
+	keys := make([]string, 0, len(d.opts.Spec.Services))
+	for key := range d.opts.Spec.Services {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var result DeployResult
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		spec, err := d.serviceSpec(key, d.opts.Spec.Services[key], networks, secrets)
+		if err != nil {
+			return nil, err
+		}
+		wanted[spec.Name] = true
+		current, ok := byName[spec.Name]
+		switch {
+		case !ok:
+			_, err = d.client.CreateService(docker.CreateServiceOptions{
+				Auth:        d.opts.Auth,
+				ServiceSpec: spec,
+				Context:     d.ctx,
+			})
+			result.Created = append(result.Created, spec.Name)
+		case current.Spec.Labels[LabelSpecHash] == spec.Labels[LabelSpecHash]:
+			result.Unchanged = append(result.Unchanged, spec.Name)
+		default:
+			err = d.client.UpdateService(current.ID, docker.UpdateServiceOptions{
+				Auth:        d.opts.Auth,
+				ServiceSpec: spec,
+				Version:     current.Version.Index,
+				Context:     d.ctx,
+			})
+			result.Updated = append(result.Updated, spec.Name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if d.opts.Prune {
+		for _, service := range existing {
+			if wanted[service.Spec.Name] {
+				continue
+			}
+			if err := d.client.RemoveService(docker.RemoveServiceOptions{ID: service.ID, Context: d.ctx}); err != nil {
+				return nil, err
+			}
+			result.Removed = append(result.Removed, service.Spec.Name)
+		}
+	}
+	return &result, nil
+}
+
+// serviceSpec converts a service of the stack into the spec sent to the
+// daemon, labeled with the hash of the result.
+func (d *deployer) serviceSpec(key string, service Service, networks map[string]string, secrets map[string]swarm.Secret) (swarm.ServiceSpec, error) {
+	name := d.scopedName(key)
+	containerSpec := &swarm.ContainerSpec{
+		Image:   service.Image,
+		Labels:  d.labels(service.Labels),
+		Command: service.Entrypoint,
+		Args:    service.Command,
+		Env:     docker.EnvFromMap(service.Environment),
+		Dir:     service.WorkingDir,
+		User:    service.User,
+	}
+	for _, volume := range service.Volumes {
+		m, err := d.mount(volume)
+		if err != nil {
+			return swarm.ServiceSpec{}, err
+		}
+		containerSpec.Mounts = append(containerSpec.Mounts, m)
+	}
+	for _, secretKey := range service.Secrets {
+		secret, ok := secrets[secretKey]
+		if !ok {
+			return swarm.ServiceSpec{}, fmt.Errorf("stack: service %q refers to undefined secret %q", name, secretKey)
+		}
+		containerSpec.Secrets = append(containerSpec.Secrets, &swarm.SecretReference{
+			SecretID:   secret.ID,
+			SecretName: secret.Spec.Name,
+			File:       &swarm.SecretReferenceFileTarget{Name: secretKey, UID: "0", GID: "0", Mode: 0o444},
+		})
+	}
+	serviceNetworks := service.Networks
+	if len(serviceNetworks) == 0 {
+		serviceNetworks = []string{defaultNetwork}
+	}
+	var attachments []swarm.NetworkAttachmentConfig
+	for _, networkKey := range serviceNetworks {
+		// the other services of the stack reach it by its name
+		attachments = append(attachments, swarm.NetworkAttachmentConfig{Target: networks[networkKey], Aliases: []string{key}})
+	}
+	spec := swarm.ServiceSpec{
+		Annotations: swarm.Annotations{Name: name, Labels: d.labels(service.Deploy.Labels)},
+		TaskTemplate: swarm.TaskSpec{
+			ContainerSpec: containerSpec,
+			Networks:      attachments,
+		},
+	}
+	switch service.Deploy.Mode {
+	case "", "replicated":
+		spec.Mode.Replicated = &swarm.ReplicatedService{Replicas: service.Deploy.Replicas}
+	case "global":
+		spec.Mode.Global = &swarm.GlobalService{}
+	default:
+		return swarm.ServiceSpec{}, fmt.Errorf("stack: invalid mode %q for service %q", service.Deploy.Mode, name)
+	}
+	if len(service.Ports) > 0 {
+		spec.EndpointSpec = &swarm.EndpointSpec{}
+		for _, port := range service.Ports {
+			protocol, mode := port.Protocol, port.Mode
+			if protocol == "" {
+				protocol = "tcp"
+			}
+			if mode == "" {
+				mode = "ingress"
+			}
+			spec.EndpointSpec.Ports = append(spec.EndpointSpec.Ports, swarm.PortConfig{
+				Protocol:      swarm.PortConfigProtocol(protocol),
+				TargetPort:    port.Target,
+				PublishedPort: port.Published,
+				PublishMode:   swarm.PortConfigPublishMode(mode),
+			})
+		}
+	}
+	hash, err := specHash(spec)
+	if err != nil {
+		return swarm.ServiceSpec{}, err
+	}
+	spec.Labels[LabelSpecHash] = hash
+	return spec, nil
+}
+
+func (d *deployer) mount(volume ServiceVolume) (mount.Mount, error) {
+	m := mount.Mount{Type: mount.TypeVolume, Source: volume.Source, Target: volume.Target, ReadOnly: volume.ReadOnly}
+	if volume.Source == "" {
+		return m, nil
+	}
+	if filepath.IsAbs(volume.Source) {
+		m.Type = mount.TypeBind
+		return m, nil
+	}
+	spec, ok := d.opts.Spec.Volumes[volume.Source]
+	if !ok {
+		return mount.Mount{}, fmt.Errorf("stack: undefined volume %q", volume.Source)
+	}
+	switch {
+	case spec.Name != "":
+		m.Source = spec.Name
+	case !spec.External:
+		m.Source = d.scopedName(volume.Source)
+	}
+	if !spec.External {
+		m.VolumeOptions = &mount.VolumeOptions{Labels: d.labels(spec.Labels)}
+		if spec.Driver != "" {
+			m.VolumeOptions.DriverConfig = &mount.Driver{Name: spec.Driver, Options: spec.DriverOpts}
+		}
+	}
+	return m, nil
+}
+
+func specHash(spec swarm.ServiceSpec) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
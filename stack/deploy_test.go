@@ -0,0 +1,233 @@
+package stack
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// fakeSwarm is a minimal in-memory implementation of the network, secret and
+// service endpoints used by Deploy.
+type fakeSwarm struct {
+	mu       sync.Mutex
+	networks []docker.CreateNetworkOptions
+	secrets  []swarm.Secret
+	services []swarm.Service
+	updates  int
+}
+
+func (f *fakeSwarm) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := r.URL.Path
+	if i := strings.Index(path[1:], "/"); strings.HasPrefix(path, "/v") && i > 0 {
+		path = path[i+1:]
+	}
+	switch {
+	case r.Method == http.MethodGet && path == "/networks":
+		var networks []docker.Network
+		for _, n := range f.networks {
+			networks = append(networks, docker.Network{Name: n.Name, ID: n.Name, Driver: n.Driver, Labels: n.Labels})
+		}
+		json.NewEncoder(w).Encode(networks)
+	case r.Method == http.MethodPost && path == "/networks/create":
+		var opts docker.CreateNetworkOptions
+		json.NewDecoder(r.Body).Decode(&opts)
+		f.networks = append(f.networks, opts)
+		json.NewEncoder(w).Encode(map[string]string{"Id": opts.Name})
+	case r.Method == http.MethodGet && path == "/secrets":
+		json.NewEncoder(w).Encode(f.secrets)
+	case r.Method == http.MethodPost && path == "/secrets/create":
+		var spec swarm.SecretSpec
+		json.NewDecoder(r.Body).Decode(&spec)
+		secret := swarm.Secret{ID: "secret-" + strconv.Itoa(len(f.secrets)), Spec: spec}
+		f.secrets = append(f.secrets, secret)
+		json.NewEncoder(w).Encode(map[string]string{"ID": secret.ID})
+	case r.Method == http.MethodGet && path == "/services":
+		json.NewEncoder(w).Encode(f.services)
+	case r.Method == http.MethodPost && path == "/services/create":
+		var spec swarm.ServiceSpec
+		json.NewDecoder(r.Body).Decode(&spec)
+		service := swarm.Service{ID: "service-" + spec.Name, Spec: spec}
+		service.Version.Index = 1
+		f.services = append(f.services, service)
+		json.NewEncoder(w).Encode(map[string]string{"ID": service.ID})
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/update"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, "/services/"), "/update")
+		for i := range f.services {
+			if f.services[i].ID == id {
+				if strconv.FormatUint(f.services[i].Version.Index, 10) != r.URL.Query().Get("version") {
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+				json.NewDecoder(r.Body).Decode(&f.services[i].Spec)
+				f.services[i].Version.Index++
+				f.updates++
+			}
+		}
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/services/"):
+		id := strings.TrimPrefix(path, "/services/")
+		for i := range f.services {
+			if f.services[i].ID == id {
+				f.services = append(f.services[:i], f.services[i+1:]...)
+				break
+			}
+		}
+	default:
+		http.Error(w, "unexpected request: "+r.Method+" "+path, http.StatusNotFound)
+	}
+}
+
+func newFakeSwarm(t *testing.T) (*fakeSwarm, *docker.Client) {
+	fake := &fakeSwarm{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client, err := docker.NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SkipServerVersionCheck = true
+	return fake, client
+}
+
+func testSpec() Spec {
+	replicas := uint64(2)
+	return Spec{
+		Services: map[string]Service{
+			"web": {
+				Image:       "nginx:1.27",
+				Environment: map[string]string{"MODE": "production"},
+				Ports:       []Port{{Target: 80, Published: 8080}},
+				Networks:    []string{"front"},
+				Secrets:     []string{"token"},
+				Volumes:     []ServiceVolume{{Source: "static", Target: "/usr/share/nginx/html", ReadOnly: true}},
+				Deploy:      DeployConfig{Replicas: &replicas},
+			},
+			"worker": {Image: "busybox", Command: []string{"sleep", "infinity"}},
+		},
+		Networks: map[string]Network{"front": {Attachable: true}},
+		Volumes:  map[string]Volume{"static": {Driver: "local"}},
+		Secrets:  map[string]Secret{"token": {Data: []byte("s3cr3t")}},
+	}
+}
+
+func TestDeploy(t *testing.T) {
+	t.Parallel()
+	fake, client := newFakeSwarm(t)
+	result, err := Deploy(client, DeployOptions{Namespace: "app", Spec: testSpec()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &DeployResult{Created: []string{"app_web", "app_worker"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Deploy: wrong result. Want %#v. Got %#v.", expected, result)
+	}
+	var networks []string
+	for _, n := range fake.networks {
+		networks = append(networks, n.Name)
+		if n.Driver != "overlay" || n.Labels[LabelNamespace] != "app" {
+			t.Errorf("Deploy: wrong network %#v.", n)
+		}
+	}
+	if len(networks) != 2 {
+		t.Errorf("Deploy: wrong networks created: %v.", networks)
+	}
+	if len(fake.secrets) != 1 || fake.secrets[0].Spec.Name != "app_token" || string(fake.secrets[0].Spec.Data) != "s3cr3t" {
+		t.Errorf("Deploy: wrong secrets created: %#v.", fake.secrets)
+	}
+	web := fake.services[0].Spec
+	if web.Name != "app_web" {
+		t.Fatalf("Deploy: wrong service created: %q.", web.Name)
+	}
+	container := web.TaskTemplate.ContainerSpec
+	if container.Image != "nginx:1.27" || !reflect.DeepEqual(container.Env, []string{"MODE=production"}) {
+		t.Errorf("Deploy: wrong container spec: %#v.", container)
+	}
+	if len(container.Secrets) != 1 || container.Secrets[0].SecretID != "secret-0" || container.Secrets[0].File.Name != "token" {
+		t.Errorf("Deploy: wrong secret references: %#v.", container.Secrets)
+	}
+	if len(container.Mounts) != 1 || container.Mounts[0].Source != "app_static" || container.Mounts[0].VolumeOptions.DriverConfig.Name != "local" {
+		t.Errorf("Deploy: wrong mounts: %#v.", container.Mounts)
+	}
+	if *web.Mode.Replicated.Replicas != 2 {
+		t.Errorf("Deploy: wrong replicas: %d.", *web.Mode.Replicated.Replicas)
+	}
+	if len(web.TaskTemplate.Networks) != 1 || web.TaskTemplate.Networks[0].Target != "app_front" {
+		t.Errorf("Deploy: wrong networks: %#v.", web.TaskTemplate.Networks)
+	}
+	if aliases := web.TaskTemplate.Networks[0].Aliases; len(aliases) != 1 || aliases[0] != "web" {
+		t.Errorf("Deploy: wrong network aliases. Want [web]. Got %v.", aliases)
+	}
+	if worker := fake.services[1].Spec; worker.TaskTemplate.Networks[0].Target != "app_default" {
+		t.Errorf("Deploy: worker should be attached to the default network: %#v.", worker.TaskTemplate.Networks)
+	}
+}
+
+func TestDeployUpdatesChangedServices(t *testing.T) {
+	t.Parallel()
+	fake, client := newFakeSwarm(t)
+	spec := testSpec()
+	if _, err := Deploy(client, DeployOptions{Namespace: "app", Spec: spec}); err != nil {
+		t.Fatal(err)
+	}
+	web := spec.Services["web"]
+	web.Image = "nginx:1.28"
+	spec.Services["web"] = web
+	result, err := Deploy(client, DeployOptions{Namespace: "app", Spec: spec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &DeployResult{Updated: []string{"app_web"}, Unchanged: []string{"app_worker"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Deploy: wrong result. Want %#v. Got %#v.", expected, result)
+	}
+	if fake.updates != 1 || fake.services[0].Spec.TaskTemplate.ContainerSpec.Image != "nginx:1.28" {
+		t.Errorf("Deploy: service not updated: %#v.", fake.services[0].Spec.TaskTemplate.ContainerSpec)
+	}
+	if len(fake.networks) != 2 || len(fake.secrets) != 1 {
+		t.Errorf("Deploy: existing networks or secrets recreated: %d networks, %d secrets.", len(fake.networks), len(fake.secrets))
+	}
+}
+
+func TestDeployPrune(t *testing.T) {
+	t.Parallel()
+	fake, client := newFakeSwarm(t)
+	spec := testSpec()
+	if _, err := Deploy(client, DeployOptions{Namespace: "app", Spec: spec}); err != nil {
+		t.Fatal(err)
+	}
+	delete(spec.Services, "worker")
+	result, err := Deploy(client, DeployOptions{Namespace: "app", Spec: spec, Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &DeployResult{Unchanged: []string{"app_web"}, Removed: []string{"app_worker"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Deploy: wrong result. Want %#v. Got %#v.", expected, result)
+	}
+	if len(fake.services) != 1 {
+		t.Errorf("Deploy: wrong number of services after prune: %d.", len(fake.services))
+	}
+}
+
+func TestDeployExternalNotFound(t *testing.T) {
+	t.Parallel()
+	_, client := newFakeSwarm(t)
+	spec := Spec{
+		Services: map[string]Service{"web": {Image: "nginx", Networks: []string{"proxy"}}},
+		Networks: map[string]Network{"proxy": {External: true}},
+	}
+	_, err := Deploy(client, DeployOptions{Namespace: "app", Spec: spec})
+	if !errors.Is(err, ErrExternalNotFound) {
+		t.Errorf("Deploy: wrong error. Want %#v. Got %#v.", ErrExternalNotFound, err)
+	}
+}
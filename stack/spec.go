@@ -0,0 +1,103 @@
+// Package stack deploys multi-service applications described by a
+// Compose-like specification to a Swarm cluster, providing the semantics of
+// "docker stack deploy" on top of go-dockerclient.
+package stack
+
+// Spec is the parsed form of a docker-compose/stack file. The json and yaml
+// tags follow the Compose file format, so a Spec can be decoded directly from
+// a (simple) stack file.
+type Spec struct {
+	Services map[string]Service `json:"services,omitempty" yaml:"services,omitempty"`
+	Networks map[string]Network `json:"networks,omitempty" yaml:"networks,omitempty"`
+	Volumes  map[string]Volume  `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	Secrets  map[string]Secret  `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+}
+
+// Service describes a service of the stack.
+type Service struct {
+	Image       string            `json:"image" yaml:"image"`
+	Command     []string          `json:"command,omitempty" yaml:"command,omitempty"`
+	Entrypoint  []string          `json:"entrypoint,omitempty" yaml:"entrypoint,omitempty"`
+	Environment map[string]string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	WorkingDir  string            `json:"working_dir,omitempty" yaml:"working_dir,omitempty"`
+	User        string            `json:"user,omitempty" yaml:"user,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Ports       []Port            `json:"ports,omitempty" yaml:"ports,omitempty"`
+	Volumes     []ServiceVolume   `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	Secrets     []string          `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+
+	// Networks lists the networks of the stack the service is attached to.
+	// Services without networks are attached to the "default" network,
+	// which is created when needed.
+	Networks []string `json:"networks,omitempty" yaml:"networks,omitempty"`
+
+	Deploy DeployConfig `json:"deploy,omitempty" yaml:"deploy,omitempty"`
+}
+
+// DeployConfig holds the deployment settings of a service.
+type DeployConfig struct {
+	// Mode is either "replicated" (the default) or "global".
+	Mode     string  `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Replicas *uint64 `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+
+	// Labels are set on the service, while the labels in Service are set
+	// on its containers.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// Port is a port published by a service.
+type Port struct {
+	Target    uint32 `json:"target" yaml:"target"`
+	Published uint32 `json:"published,omitempty" yaml:"published,omitempty"`
+
+	// Protocol is "tcp" (the default), "udp" or "sctp".
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+
+	// Mode is "ingress" (the default) or "host".
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty"`
+}
+
+// ServiceVolume is a volume mounted in the containers of a service. Source
+// may name a volume of the stack, an absolute host path (bind mount) or be
+// empty, for an anonymous volume.
+type ServiceVolume struct {
+	Source   string `json:"source,omitempty" yaml:"source,omitempty"`
+	Target   string `json:"target" yaml:"target"`
+	ReadOnly bool   `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+}
+
+// Network is a network of the stack.
+type Network struct {
+	// Driver defaults to "overlay".
+	Driver     string            `json:"driver,omitempty" yaml:"driver,omitempty"`
+	Attachable bool              `json:"attachable,omitempty" yaml:"attachable,omitempty"`
+	Internal   bool              `json:"internal,omitempty" yaml:"internal,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// External networks must exist, and aren't managed by the stack.
+	External bool `json:"external,omitempty" yaml:"external,omitempty"`
+
+	// Name overrides the name of the network, which defaults to the key
+	// of the network in the spec prefixed by the namespace.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// Volume is a named volume of the stack. Swarm volumes are created on demand
+// by the nodes running the tasks that mount them.
+type Volume struct {
+	Driver     string            `json:"driver,omitempty" yaml:"driver,omitempty"`
+	DriverOpts map[string]string `json:"driver_opts,omitempty" yaml:"driver_opts,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	External   bool              `json:"external,omitempty" yaml:"external,omitempty"`
+	Name       string            `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// Secret is a secret of the stack, whose content is taken either from File
+// or from Data.
+type Secret struct {
+	File     string            `json:"file,omitempty" yaml:"file,omitempty"`
+	Data     []byte            `json:"-" yaml:"-"`
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	External bool              `json:"external,omitempty" yaml:"external,omitempty"`
+	Name     string            `json:"name,omitempty" yaml:"name,omitempty"`
+}
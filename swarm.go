@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/docker/docker/api/types/swarm"
 )
@@ -103,9 +104,10 @@ func (c *Client) LeaveSwarm(opts LeaveSwarmOptions) error {
 		if errors.As(err, &e) && (e.Status == http.StatusNotAcceptable || e.Status == http.StatusServiceUnavailable) {
 			return ErrNodeNotInSwarm
 		}
+		return err
 	}
 	resp.Body.Close()
-	return err
+	return nil
 }
 
 // UpdateSwarmOptions specify parameters to the UpdateSwarm function.
@@ -136,9 +138,10 @@ func (c *Client) UpdateSwarm(opts UpdateSwarmOptions) error {
 		if errors.As(err, &e) && (e.Status == http.StatusNotAcceptable || e.Status == http.StatusServiceUnavailable) {
 			return ErrNodeNotInSwarm
 		}
+		return err
 	}
 	resp.Body.Close()
-	return err
+	return nil
 }
 
 // InspectSwarm inspects a Swarm.
@@ -159,3 +162,99 @@ func (c *Client) InspectSwarm(ctx context.Context) (swarm.Swarm, error) {
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	return response, err
 }
+
+// SwarmJoinTokens returns the tokens used by workers and managers to join the
+// swarm. It must be called on a manager node.
+func (c *Client) SwarmJoinTokens(ctx context.Context) (swarm.JoinTokens, error) {
+	sw, err := c.InspectSwarm(ctx)
+	if err != nil {
+		return swarm.JoinTokens{}, err
+	}
+	return sw.JoinTokens, nil
+}
+
+// RotateJoinTokens replaces the worker and/or manager join tokens of the
+// swarm, keeping its current spec, and returns the resulting tokens. Nodes
+// that already joined the swarm aren't affected.
+func (c *Client) RotateJoinTokens(ctx context.Context, worker, manager bool) (swarm.JoinTokens, error) {
+	sw, err := c.InspectSwarm(ctx)
+	if err != nil {
+		return swarm.JoinTokens{}, err
+	}
+	err = c.UpdateSwarm(UpdateSwarmOptions{
+		Version:            int(sw.Version.Index),
+		RotateWorkerToken:  worker,
+		RotateManagerToken: manager,
+		Swarm:              sw.Spec,
+		Context:            ctx,
+	})
+	if err != nil {
+		return swarm.JoinTokens{}, err
+	}
+	return c.SwarmJoinTokens(ctx)
+}
+
+// SwarmTLSInfo returns the TLS information of the swarm: the root CA
+// certificate trusted by its nodes and the issuer of their certificates.
+func (c *Client) SwarmTLSInfo(ctx context.Context) (swarm.TLSInfo, error) {
+	sw, err := c.InspectSwarm(ctx)
+	if err != nil {
+		return swarm.TLSInfo{}, err
+	}
+	return sw.TLSInfo, nil
+}
+
+// RotateSwarmCAOptions specify parameters to the RotateSwarmCA function.
+type RotateSwarmCAOptions struct {
+	// SigningCACert and SigningCAKey are the PEM encoded certificate and
+	// key of the new root CA. When they're empty, the swarm generates a new
+	// root CA, unless it uses external CAs.
+	SigningCACert string
+	SigningCAKey  string
+
+	// ExternalCAs, when not nil, replaces the external CAs the managers
+	// send certificate signing requests to. Their CACert must be the
+	// SigningCACert, if given.
+	ExternalCAs []*swarm.ExternalCA
+
+	// NodeCertExpiry, when positive, replaces the validity period of the
+	// certificates issued to the nodes.
+	NodeCertExpiry time.Duration
+
+	Context context.Context
+}
+
+// RotateSwarmCA replaces the root CA of the swarm, keeping the rest of its
+// spec, and returns the swarm once the rotation is started.
+//
+// The rotation is carried out in the background, as the nodes get new
+// certificates: RootRotationInProgress is true in the returned ClusterInfo
+// until it's done, and TLSInfo only reports the new root CA afterwards.
+func (c *Client) RotateSwarmCA(opts RotateSwarmCAOptions) (swarm.Swarm, error) {
+	if opts.SigningCAKey != "" && opts.SigningCACert == "" {
+		return swarm.Swarm{}, errors.New("swarm CA rotation: signing key given without a certificate")
+	}
+	sw, err := c.InspectSwarm(opts.Context)
+	if err != nil {
+		return swarm.Swarm{}, err
+	}
+	caConfig := &sw.Spec.CAConfig
+	caConfig.SigningCACert = opts.SigningCACert
+	caConfig.SigningCAKey = opts.SigningCAKey
+	caConfig.ForceRotate++
+	if opts.ExternalCAs != nil {
+		caConfig.ExternalCAs = opts.ExternalCAs
+	}
+	if opts.NodeCertExpiry > 0 {
+		caConfig.NodeCertExpiry = opts.NodeCertExpiry
+	}
+	err = c.UpdateSwarm(UpdateSwarmOptions{
+		Version: int(sw.Version.Index),
+		Swarm:   sw.Spec,
+		Context: opts.Context,
+	})
+	if err != nil {
+		return swarm.Swarm{}, err
+	}
+	return c.InspectSwarm(opts.Context)
+}
@@ -0,0 +1,83 @@
+package docker
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// ErrNoSwarmLeader is the error returned by SwarmLeader when no manager is
+// currently the leader of the swarm, which happens when the swarm lost its
+// quorum or is in the middle of an election.
+var ErrNoSwarmLeader = errors.New("swarm has no leader")
+
+// SwarmManager summarizes the status of a manager node of the swarm.
+type SwarmManager struct {
+	NodeID       string
+	Hostname     string
+	Addr         string
+	Leader       bool
+	Reachability swarm.Reachability
+	Availability swarm.NodeAvailability
+}
+
+// Reachable reports whether the manager is reachable by the other managers.
+func (m SwarmManager) Reachable() bool {
+	return m.Reachability == swarm.ReachabilityReachable
+}
+
+// SwarmManagers returns the status of the manager nodes of the swarm, sorted
+// by hostname.
+func (c *Client) SwarmManagers() ([]SwarmManager, error) {
+	nodes, err := c.ListNodes(ListNodesOptions{
+		Filters: NodeFilters{Role: swarm.NodeRoleManager}.Filters(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	managers := make([]SwarmManager, 0, len(nodes))
+	for _, node := range nodes {
+		if node.ManagerStatus == nil {
+			continue
+		}
+		managers = append(managers, SwarmManager{
+			NodeID:       node.ID,
+			Hostname:     node.Description.Hostname,
+			Addr:         node.ManagerStatus.Addr,
+			Leader:       node.ManagerStatus.Leader,
+			Reachability: node.ManagerStatus.Reachability,
+			Availability: node.Spec.Availability,
+		})
+	}
+	sort.Slice(managers, func(i, j int) bool {
+		return managers[i].Hostname < managers[j].Hostname
+	})
+	return managers, nil
+}
+
+// SwarmLeader returns the manager currently leading the swarm.
+func (c *Client) SwarmLeader() (*SwarmManager, error) {
+	managers, err := c.SwarmManagers()
+	if err != nil {
+		return nil, err
+	}
+	for i := range managers {
+		if managers[i].Leader {
+			return &managers[i], nil
+		}
+	}
+	return nil, ErrNoSwarmLeader
+}
+
+// SwarmHasQuorum reports whether a majority of the given managers is
+// reachable, which is required for the swarm to accept changes.
+func SwarmHasQuorum(managers []SwarmManager) bool {
+	var reachable int
+	for _, m := range managers {
+		if m.Reachable() {
+			reachable++
+		}
+	}
+	return reachable > len(managers)/2
+}
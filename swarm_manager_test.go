@@ -0,0 +1,76 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+func newManagersTestClient(nodes []swarm.Node) (Client, *FakeRoundTripper) {
+	data, _ := json.Marshal(nodes)
+	fakeRT := &FakeRoundTripper{message: string(data), status: http.StatusOK}
+	return newTestClient(fakeRT), fakeRT
+}
+
+func managerNode(id, hostname string, leader bool, reachability swarm.Reachability) swarm.Node {
+	return swarm.Node{
+		ID:            id,
+		Description:   swarm.NodeDescription{Hostname: hostname},
+		ManagerStatus: &swarm.ManagerStatus{Leader: leader, Reachability: reachability, Addr: hostname + ":2377"},
+	}
+}
+
+func TestSwarmManagers(t *testing.T) {
+	t.Parallel()
+	client, fakeRT := newManagersTestClient([]swarm.Node{
+		managerNode("n2", "node-2", false, swarm.ReachabilityUnreachable),
+		managerNode("n1", "node-1", true, swarm.ReachabilityReachable),
+	})
+	managers, err := client.SwarmManagers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []SwarmManager{
+		{NodeID: "n1", Hostname: "node-1", Addr: "node-1:2377", Leader: true, Reachability: swarm.ReachabilityReachable},
+		{NodeID: "n2", Hostname: "node-2", Addr: "node-2:2377", Reachability: swarm.ReachabilityUnreachable},
+	}
+	if !reflect.DeepEqual(managers, expected) {
+		t.Errorf("SwarmManagers: wrong result.\nWant %#v.\nGot  %#v.", expected, managers)
+	}
+	if got := fakeRT.requests[0].URL.Query().Get("filters"); got != `{"role":["manager"]}` {
+		t.Errorf("SwarmManagers: wrong filters: %s.", got)
+	}
+	if SwarmHasQuorum(managers) {
+		t.Error("SwarmHasQuorum: expected no quorum with one of two managers reachable")
+	}
+	if !SwarmHasQuorum(managers[:1]) {
+		t.Error("SwarmHasQuorum: expected quorum with the only manager reachable")
+	}
+}
+
+func TestSwarmLeader(t *testing.T) {
+	t.Parallel()
+	client, _ := newManagersTestClient([]swarm.Node{
+		managerNode("n1", "node-1", false, swarm.ReachabilityReachable),
+		managerNode("n2", "node-2", true, swarm.ReachabilityReachable),
+	})
+	leader, err := client.SwarmLeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leader.NodeID != "n2" {
+		t.Errorf("SwarmLeader: wrong leader. Want %q. Got %q.", "n2", leader.NodeID)
+	}
+}
+
+func TestSwarmLeaderNoLeader(t *testing.T) {
+	t.Parallel()
+	client, _ := newManagersTestClient([]swarm.Node{managerNode("n1", "node-1", false, swarm.ReachabilityUnreachable)})
+	if _, err := client.SwarmLeader(); !errors.Is(err, ErrNoSwarmLeader) {
+		t.Errorf("SwarmLeader: wrong error. Want %#v. Got %#v.", ErrNoSwarmLeader, err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/docker/docker/api/types/swarm"
 )
@@ -32,10 +33,53 @@ func (err *NoSuchNode) Error() string {
 //
 // See http://goo.gl/3K4GwU for more details.
 type ListNodesOptions struct {
+	// Filters can be built with NodeFilters.
 	Filters map[string][]string
+
+	// Availability, when set, restricts the result to nodes with the given
+	// availability. The API doesn't support this filter, so it's applied by
+	// the client.
+	Availability swarm.NodeAvailability `qs:"-"`
+
 	Context context.Context
 }
 
+// NodeFilters describes the filters supported by ListNodes. Empty fields are
+// ignored.
+type NodeFilters struct {
+	ID   string
+	Name string
+	Role swarm.NodeRole
+
+	// Membership is either "accepted" or "pending".
+	Membership string
+
+	// Labels filters by the labels of the node, set via the API, while
+	// EngineLabels filters by the labels of the Docker daemon.
+	Labels       LabelFilter
+	EngineLabels LabelFilter
+}
+
+// Filters returns the filters in the format expected by
+// ListNodesOptions.Filters.
+func (f NodeFilters) Filters() map[string][]string {
+	filters := make(map[string][]string)
+	for key, value := range map[string]string{
+		"id":         f.ID,
+		"name":       f.Name,
+		"role":       string(f.Role),
+		"membership": f.Membership,
+	} {
+		if value != "" {
+			filters[key] = []string{value}
+		}
+	}
+	if len(f.Labels) > 0 {
+		filters["node.label"] = f.Labels.Values()
+	}
+	return f.EngineLabels.AddTo(filters)
+}
+
 // ListNodes returns a slice of nodes matching the given criteria.
 //
 // See http://goo.gl/3K4GwU for more details.
@@ -50,6 +94,15 @@ func (c *Client) ListNodes(opts ListNodesOptions) ([]swarm.Node, error) {
 	if err := json.NewDecoder(resp.Body).Decode(&nodes); err != nil {
 		return nil, err
 	}
+	if opts.Availability != "" {
+		filtered := nodes[:0]
+		for _, node := range nodes {
+			if node.Spec.Availability == opts.Availability {
+				filtered = append(filtered, node)
+			}
+		}
+		nodes = filtered
+	}
 	return nodes, nil
 }
 
@@ -132,3 +185,56 @@ func (c *Client) RemoveNode(opts RemoveNodeOptions) error {
 	resp.Body.Close()
 	return nil
 }
+
+// maxNodeUpdateRetries is the number of times AddNodeLabels and
+// RemoveNodeLabels retry when the node is modified concurrently.
+const maxNodeUpdateRetries = 3
+
+// AddNodeLabels adds the given labels to a node, replacing existing labels
+// with the same keys.
+func (c *Client) AddNodeLabels(id string, labels map[string]string) error {
+	return c.updateNodeLabels(id, func(current map[string]string) {
+		for key, value := range labels {
+			current[key] = value
+		}
+	})
+}
+
+// RemoveNodeLabels removes the labels with the given keys from a node. Keys
+// that aren't set in the node are ignored.
+func (c *Client) RemoveNodeLabels(id string, keys ...string) error {
+	return c.updateNodeLabels(id, func(current map[string]string) {
+		for _, key := range keys {
+			delete(current, key)
+		}
+	})
+}
+
+// updateNodeLabels applies change to the labels of a node, retrying when
+// the node version changes between the inspection and the update.
+func (c *Client) updateNodeLabels(id string, change func(map[string]string)) error {
+	for retries := 0; ; retries++ {
+		node, err := c.InspectNode(id)
+		if err != nil {
+			return err
+		}
+		spec := node.Spec
+		labels := make(map[string]string, len(spec.Labels))
+		for key, value := range spec.Labels {
+			labels[key] = value
+		}
+		change(labels)
+		spec.Labels = labels
+		err = c.UpdateNode(node.ID, UpdateNodeOptions{NodeSpec: spec, Version: node.Version.Index})
+		if err == nil || retries >= maxNodeUpdateRetries || !isUpdateOutOfSequence(err) {
+			return err
+		}
+	}
+}
+
+// isUpdateOutOfSequence reports whether err is the error returned by the
+// swarm manager when an object is updated with an outdated version.
+func isUpdateOutOfSequence(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && strings.Contains(e.Message, "update out of sequence")
+}
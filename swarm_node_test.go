@@ -7,6 +7,7 @@ package docker
 import (
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"testing"
@@ -248,3 +249,81 @@ func TestRemoveNodeNotFound(t *testing.T) {
 	err := client.RemoveNode(RemoveNodeOptions{ID: "notfound"})
 	expectNoSuchNode(t, "notfound", err)
 }
+
+func TestNodeFilters(t *testing.T) {
+	t.Parallel()
+	filters := NodeFilters{
+		Name:         "node-1",
+		Role:         swarm.NodeRoleManager,
+		Labels:       LabelFilter{"zone": "a"},
+		EngineLabels: LabelFilter{"ssd": ""},
+	}.Filters()
+	expected := map[string][]string{
+		"name":       {"node-1"},
+		"role":       {"manager"},
+		"node.label": {"zone=a"},
+		"label":      {"ssd"},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("NodeFilters: wrong filters. Want %#v. Got %#v.", expected, filters)
+	}
+}
+
+func TestListNodesAvailability(t *testing.T) {
+	t.Parallel()
+	nodes := []swarm.Node{
+		{ID: "n1", Spec: swarm.NodeSpec{Availability: swarm.NodeAvailabilityActive}},
+		{ID: "n2", Spec: swarm.NodeSpec{Availability: swarm.NodeAvailabilityDrain}},
+	}
+	data, _ := json.Marshal(nodes)
+	client := newTestClient(&FakeRoundTripper{message: string(data), status: http.StatusOK})
+	got, err := client.ListNodes(ListNodesOptions{Availability: swarm.NodeAvailabilityDrain})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "n2" {
+		t.Errorf("ListNodes: wrong nodes with availability filter: %#v.", got)
+	}
+}
+
+func TestNodeLabels(t *testing.T) {
+	t.Parallel()
+	var updates int
+	node := swarm.Node{ID: "n1", Spec: swarm.NodeSpec{Annotations: swarm.Annotations{Labels: map[string]string{"zone": "a", "old": "x"}}}}
+	node.Version.Index = 3
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(node)
+			return
+		}
+		updates++
+		if updates == 1 {
+			// simulates a concurrent update of the node
+			node.Version.Index++
+			http.Error(w, "rpc error: update out of sequence", http.StatusInternalServerError)
+			return
+		}
+		if got := r.URL.Query().Get("version"); got != "4" {
+			t.Errorf("UpdateNode: wrong version. Want %q. Got %q.", "4", got)
+		}
+		var spec swarm.NodeSpec
+		json.NewDecoder(r.Body).Decode(&spec)
+		node.Spec = spec
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	if err := client.AddNodeLabels("n1", map[string]string{"zone": "b", "ssd": "true"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RemoveNodeLabels("n1", "old", "missing"); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"zone": "b", "ssd": "true"}
+	if !reflect.DeepEqual(node.Spec.Labels, expected) {
+		t.Errorf("Node labels: want %#v. Got %#v.", expected, node.Spec.Labels)
+	}
+	if updates != 3 {
+		t.Errorf("Node labels: wrong number of updates. Want 3. Got %d.", updates)
+	}
+}
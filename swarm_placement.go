@@ -0,0 +1,164 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// ErrInvalidPlacement is the error wrapped by the errors returned when a
+// placement constraint or preference isn't accepted by the swarm scheduler.
+var ErrInvalidPlacement = errors.New("invalid placement")
+
+// The patterns used by the swarm scheduler to validate constraints.
+var (
+	placementKeyPattern   = regexp.MustCompile(`^(?i)[a-z_][a-z0-9\-_.]+$`)
+	placementValuePattern = regexp.MustCompile(`^(?i)[a-z0-9:\-_\s\.\*\(\)\?\+\[\]\\\^\$\|\/]+$`)
+)
+
+// placementAttributes are the node attributes a constraint may refer to,
+// besides node and engine labels.
+var placementAttributes = map[string]bool{
+	"node.id":            true,
+	"node.hostname":      true,
+	"node.role":          true,
+	"node.platform.os":   true,
+	"node.platform.arch": true,
+}
+
+// Placement builds the placement of the tasks of a swarm service,
+// validating constraints and preferences as they're added, instead of
+// letting a typo result in tasks that are never scheduled, or scheduled
+// anywhere. The first invalid constraint or preference is reported by Build
+// and Apply.
+//
+// For instance:
+//
+//	err := docker.NewPlacement().
+//		Equal("node.labels.region", "us-east").
+//		NotEqual("node.role", "manager").
+//		Spread("node.labels.zone").
+//		Apply(&spec)
+type Placement struct {
+	constraints []string
+	preferences []swarm.PlacementPreference
+	err         error
+}
+
+// NewPlacement returns an empty Placement.
+func NewPlacement() *Placement {
+	return &Placement{}
+}
+
+// Constraint adds a constraint expression, such as
+// "node.labels.region == us-east".
+func (p *Placement) Constraint(expr string) *Placement {
+	if err := ValidatePlacementConstraint(expr); err != nil {
+		p.fail(err)
+		return p
+	}
+	p.constraints = append(p.constraints, strings.TrimSpace(expr))
+	return p
+}
+
+// Equal adds a constraint requiring the given node attribute or label to be
+// equal to value.
+func (p *Placement) Equal(key, value string) *Placement {
+	return p.Constraint(key + "==" + value)
+}
+
+// NotEqual adds a constraint requiring the given node attribute or label to
+// differ from value.
+func (p *Placement) NotEqual(key, value string) *Placement {
+	return p.Constraint(key + "!=" + value)
+}
+
+// Spread adds a preference to spread the tasks evenly over the values of
+// the given label, such as "node.labels.zone".
+func (p *Placement) Spread(descriptor string) *Placement {
+	if !isPlacementLabel(descriptor) {
+		p.fail(fmt.Errorf("%w: spread descriptor %q must be a node or engine label", ErrInvalidPlacement, descriptor))
+		return p
+	}
+	p.preferences = append(p.preferences, swarm.PlacementPreference{
+		Spread: &swarm.SpreadOver{SpreadDescriptor: descriptor},
+	})
+	return p
+}
+
+func (p *Placement) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// Build returns the placement, or the first error found in its constraints
+// and preferences.
+func (p *Placement) Build() (*swarm.Placement, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &swarm.Placement{
+		Constraints: append([]string(nil), p.constraints...),
+		Preferences: append([]swarm.PlacementPreference(nil), p.preferences...),
+	}, nil
+}
+
+// Apply adds the constraints and preferences of the placement to the task
+// template of spec, keeping the ones it already has. spec is left unchanged
+// when the placement is invalid.
+func (p *Placement) Apply(spec *swarm.ServiceSpec) error {
+	placement, err := p.Build()
+	if err != nil {
+		return err
+	}
+	if spec.TaskTemplate.Placement == nil {
+		spec.TaskTemplate.Placement = &swarm.Placement{}
+	}
+	current := spec.TaskTemplate.Placement
+	current.Constraints = append(current.Constraints, placement.Constraints...)
+	current.Preferences = append(current.Preferences, placement.Preferences...)
+	return nil
+}
+
+// ValidatePlacementConstraint checks that expr is a constraint accepted by
+// the swarm scheduler: a known node attribute, or a node or engine label,
+// compared to a value with == or !=.
+func ValidatePlacementConstraint(expr string) error {
+	var key, value string
+	var found bool
+	for _, op := range []string{"==", "!="} {
+		if key, value, found = strings.Cut(expr, op); found {
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: constraint %q must use == or !=", ErrInvalidPlacement, expr)
+	}
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if !placementKeyPattern.MatchString(key) {
+		return fmt.Errorf("%w: constraint %q has an invalid key", ErrInvalidPlacement, expr)
+	}
+	if !placementAttributes[strings.ToLower(key)] && !isPlacementLabel(key) {
+		return fmt.Errorf("%w: constraint %q refers to unknown attribute %q", ErrInvalidPlacement, expr, key)
+	}
+	if !placementValuePattern.MatchString(value) {
+		return fmt.Errorf("%w: constraint %q has an invalid value", ErrInvalidPlacement, expr)
+	}
+	if strings.EqualFold(key, "node.role") && value != "manager" && value != "worker" {
+		return fmt.Errorf("%w: constraint %q: node.role must be manager or worker", ErrInvalidPlacement, expr)
+	}
+	return nil
+}
+
+func isPlacementLabel(key string) bool {
+	for _, prefix := range []string{"node.labels.", "engine.labels."} {
+		if len(key) > len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) {
+			return placementKeyPattern.MatchString(key)
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+func TestPlacementApply(t *testing.T) {
+	t.Parallel()
+	spec := swarm.ServiceSpec{
+		TaskTemplate: swarm.TaskSpec{Placement: &swarm.Placement{Constraints: []string{"node.platform.os==linux"}}},
+	}
+	err := NewPlacement().
+		Constraint(" node.labels.region == us-east ").
+		NotEqual("node.role", "manager").
+		Spread("node.labels.zone").
+		Apply(&spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &swarm.Placement{
+		Constraints: []string{"node.platform.os==linux", "node.labels.region == us-east", "node.role!=manager"},
+		Preferences: []swarm.PlacementPreference{{Spread: &swarm.SpreadOver{SpreadDescriptor: "node.labels.zone"}}},
+	}
+	if !reflect.DeepEqual(spec.TaskTemplate.Placement, expected) {
+		t.Errorf("Apply: wrong placement. Want %#v. Got %#v.", expected, spec.TaskTemplate.Placement)
+	}
+}
+
+func TestPlacementInvalid(t *testing.T) {
+	t.Parallel()
+	tests := map[string]*Placement{
+		"missing operator":  NewPlacement().Constraint("node.labels.region=us-east"),
+		"unknown attribute": NewPlacement().Equal("node.label.region", "us-east"),
+		"empty label":       NewPlacement().Equal("node.labels.", "us-east"),
+		"empty value":       NewPlacement().Equal("node.hostname", " "),
+		"invalid role":      NewPlacement().Equal("node.role", "master"),
+		"invalid spread":    NewPlacement().Equal("node.id", "abc").Spread("node.hostname"),
+	}
+	for name, placement := range tests {
+		spec := swarm.ServiceSpec{}
+		if err := placement.Apply(&spec); !errors.Is(err, ErrInvalidPlacement) {
+			t.Errorf("%s: wrong error. Want %#v. Got %#v.", name, ErrInvalidPlacement, err)
+		}
+		if spec.TaskTemplate.Placement != nil {
+			t.Errorf("%s: spec shouldn't be changed: %#v.", name, spec.TaskTemplate.Placement)
+		}
+	}
+}
@@ -28,11 +28,23 @@ func (err *NoSuchService) Error() string {
 	return "No such service: " + err.ID
 }
 
+// Sources of the registry authentication used by UpdateService when no
+// authentication is given.
+const (
+	RegistryAuthFromSpec         = "spec"
+	RegistryAuthFromPreviousSpec = "previous-spec"
+)
+
 // CreateServiceOptions specify parameters to the CreateService function.
 //
+// Auth is sent to the daemon so the nodes can pull the image of the service
+// from a private registry. When it's empty, the configuration of the registry
+// hosting the image is taken from AuthConfigs, if any.
+//
 // See https://goo.gl/KrVjHz for more details.
 type CreateServiceOptions struct {
-	Auth AuthConfiguration `qs:"-"`
+	Auth        AuthConfiguration  `qs:"-"`
+	AuthConfigs AuthConfigurations `qs:"-"`
 	swarm.ServiceSpec
 	Context context.Context
 }
@@ -42,7 +54,7 @@ type CreateServiceOptions struct {
 //
 // See https://goo.gl/KrVjHz for more details.
 func (c *Client) CreateService(opts CreateServiceOptions) (*swarm.Service, error) {
-	headers, err := headersWithAuth(opts.Auth)
+	headers, err := headersWithAuth(serviceAuth(opts.Auth, opts.AuthConfigs, opts.ServiceSpec))
 	if err != nil {
 		return nil, err
 	}
@@ -91,20 +103,27 @@ func (c *Client) RemoveService(opts RemoveServiceOptions) error {
 
 // UpdateServiceOptions specify parameters to the UpdateService function.
 //
+// Auth and AuthConfigs work like in CreateServiceOptions. When both are
+// empty, RegistryAuthFrom tells the daemon which authentication to keep:
+// the one of the current spec (RegistryAuthFromSpec, the default) or of the
+// previous one (RegistryAuthFromPreviousSpec).
+//
 // See https://goo.gl/wu3MmS for more details.
 type UpdateServiceOptions struct {
-	Auth              AuthConfiguration `qs:"-"`
+	Auth              AuthConfiguration  `qs:"-"`
+	AuthConfigs       AuthConfigurations `qs:"-"`
 	swarm.ServiceSpec `qs:"-"`
 	Context           context.Context
 	Version           uint64
 	Rollback          string
+	RegistryAuthFrom  string `qs:"registryAuthFrom"`
 }
 
 // UpdateService updates the service at ID with the options
 //
 // See https://goo.gl/wu3MmS for more details.
 func (c *Client) UpdateService(id string, opts UpdateServiceOptions) error {
-	headers, err := headersWithAuth(opts.Auth)
+	headers, err := headersWithAuth(serviceAuth(opts.Auth, opts.AuthConfigs, opts.ServiceSpec))
 	if err != nil {
 		return err
 	}
@@ -125,16 +144,47 @@ func (c *Client) UpdateService(id string, opts UpdateServiceOptions) error {
 	return nil
 }
 
+// serviceAuth returns the authentication to send with the spec of a service:
+// auth if it's set, otherwise the one of the registry of its image in
+// configs.
+func serviceAuth(auth AuthConfiguration, configs AuthConfigurations, spec swarm.ServiceSpec) AuthConfiguration {
+	if !auth.isEmpty() || spec.TaskTemplate.ContainerSpec == nil {
+		return auth
+	}
+	return configs.forImage(spec.TaskTemplate.ContainerSpec.Image)
+}
+
 // InspectService returns information about a service by its ID.
 //
 // See https://goo.gl/dHmr75 for more details.
 func (c *Client) InspectService(id string) (*swarm.Service, error) {
-	path := "/services/" + id
-	resp, err := c.do(http.MethodGet, path, doOptions{})
+	return c.InspectServiceWithOptions(InspectServiceOptions{ID: id})
+}
+
+// InspectServiceOptions specify parameters to the InspectServiceWithOptions
+// function.
+//
+// See https://goo.gl/dHmr75 for more details.
+type InspectServiceOptions struct {
+	ID string `qs:"-"`
+
+	// InsertDefaults fills in default values for fields that weren't set
+	// when the service was created.
+	InsertDefaults bool `qs:"insertDefaults"`
+
+	Context context.Context
+}
+
+// InspectServiceWithOptions returns information about a service by its ID.
+//
+// See https://goo.gl/dHmr75 for more details.
+func (c *Client) InspectServiceWithOptions(opts InspectServiceOptions) (*swarm.Service, error) {
+	path := "/services/" + opts.ID + "?" + queryString(opts)
+	resp, err := c.do(http.MethodGet, path, doOptions{context: opts.Context})
 	if err != nil {
 		var e *Error
 		if errors.As(err, &e) && e.Status == http.StatusNotFound {
-			return nil, &NoSuchService{ID: id}
+			return nil, &NoSuchService{ID: opts.ID}
 		}
 		return nil, err
 	}
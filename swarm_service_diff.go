@@ -0,0 +1,197 @@
+package docker
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// DiffServiceSpec compares the spec of a deployed service, as returned by
+// InspectService, with a desired spec, and returns the paths of the fields
+// that differ, like "TaskTemplate.ContainerSpec.Image" or
+// "Mode.Replicated.Replicas", sorted. Slices and maps are reported as a
+// whole. An empty result means that updating the service wouldn't change it.
+//
+// Before comparing, the specs are normalized for the defaults filled in by
+// the daemon and the CLI, so that a desired spec leaving them unset matches
+// the deployed one:
+//
+//   - a service without mode is replicated with one replica;
+//   - the runtime defaults to "container", and the isolation of the
+//     container to "default";
+//   - the endpoint mode defaults to "vip", and published ports to the "tcp"
+//     protocol and the "ingress" publish mode;
+//   - the image isn't compared to the digest the daemon pinned it to, unless
+//     the desired image has one, and "nginx" matches
+//     "docker.io/library/nginx:latest";
+//   - unset update and rollback configs, placement platforms, and force
+//     update counter keep their deployed values;
+//   - networks in the deprecated ServiceSpec.Networks field are compared
+//     with TaskTemplate.Networks;
+//   - nil and empty slices, maps and pointers to structs are equal, except
+//     for structs without fields, like GlobalService, whose presence matters.
+//
+// Neither spec is modified.
+func DiffServiceSpec(current, desired swarm.ServiceSpec) []string {
+	normalizeServiceSpec(&current)
+	normalizeServiceSpec(&desired)
+	keepServiceDefaults(&desired, &current)
+	var changed []string
+	diffValues("", reflect.ValueOf(current), reflect.ValueOf(desired), &changed)
+	sort.Strings(changed)
+	return changed
+}
+
+// ServiceNeedsUpdate reports whether a service deployed with the current
+// spec must be updated to match the desired spec, as defined by
+// DiffServiceSpec. Reconcilers can use it to avoid redundant updates, which
+// restart the tasks of the service.
+func ServiceNeedsUpdate(current, desired swarm.ServiceSpec) bool {
+	return len(DiffServiceSpec(current, desired)) > 0
+}
+
+// normalizeServiceSpec fills the defaults of the daemon in spec. The values
+// spec points to are copied before being modified, as they're shared with
+// the caller of DiffServiceSpec.
+func normalizeServiceSpec(spec *swarm.ServiceSpec) {
+	mode := &spec.Mode
+	if mode.Replicated == nil && mode.Global == nil && mode.ReplicatedJob == nil && mode.GlobalJob == nil {
+		mode.Replicated = &swarm.ReplicatedService{}
+	}
+	if mode.Replicated != nil && mode.Replicated.Replicas == nil {
+		replicas := uint64(1)
+		mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+	}
+	var endpointSpec swarm.EndpointSpec
+	if spec.EndpointSpec != nil {
+		endpointSpec = *spec.EndpointSpec
+	}
+	if endpointSpec.Mode == "" {
+		endpointSpec.Mode = swarm.ResolutionModeVIP
+	}
+	endpointSpec.Ports = append([]swarm.PortConfig(nil), endpointSpec.Ports...)
+	for i := range endpointSpec.Ports {
+		port := &endpointSpec.Ports[i]
+		if port.Protocol == "" {
+			port.Protocol = swarm.PortConfigProtocolTCP
+		}
+		if port.PublishMode == "" {
+			port.PublishMode = swarm.PortConfigPublishModeIngress
+		}
+	}
+	spec.EndpointSpec = &endpointSpec
+	if len(spec.TaskTemplate.Networks) == 0 {
+		spec.TaskTemplate.Networks = spec.Networks
+	}
+	spec.Networks = nil
+	if spec.TaskTemplate.Runtime == "" {
+		spec.TaskTemplate.Runtime = swarm.RuntimeContainer
+	}
+	if spec.TaskTemplate.ContainerSpec != nil {
+		containerSpec := *spec.TaskTemplate.ContainerSpec
+		if image, err := NormalizeImageReference(containerSpec.Image); err == nil {
+			containerSpec.Image = image
+		}
+		if containerSpec.Isolation == "" {
+			containerSpec.Isolation = container.IsolationDefault
+		}
+		spec.TaskTemplate.ContainerSpec = &containerSpec
+	}
+}
+
+// keepServiceDefaults copies to desired the values of current that the
+// daemon or the CLI chose because desired leaves them unset. Both specs must
+// have been normalized.
+func keepServiceDefaults(desired, current *swarm.ServiceSpec) {
+	if desired.UpdateConfig == nil {
+		desired.UpdateConfig = current.UpdateConfig
+	}
+	if desired.RollbackConfig == nil {
+		desired.RollbackConfig = current.RollbackConfig
+	}
+	if desired.TaskTemplate.ForceUpdate == 0 {
+		desired.TaskTemplate.ForceUpdate = current.TaskTemplate.ForceUpdate
+	}
+	if current.TaskTemplate.Placement != nil && len(current.TaskTemplate.Placement.Platforms) > 0 {
+		var placement swarm.Placement
+		if desired.TaskTemplate.Placement != nil {
+			placement = *desired.TaskTemplate.Placement
+		}
+		if len(placement.Platforms) == 0 {
+			placement.Platforms = current.TaskTemplate.Placement.Platforms
+		}
+		desired.TaskTemplate.Placement = &placement
+	}
+	desiredContainer, currentContainer := desired.TaskTemplate.ContainerSpec, current.TaskTemplate.ContainerSpec
+	if desiredContainer != nil && currentContainer != nil && !strings.Contains(desiredContainer.Image, "@") {
+		if i := strings.Index(currentContainer.Image, "@"); i >= 0 {
+			currentContainer.Image = currentContainer.Image[:i]
+		}
+	}
+}
+
+// diffValues appends to changed the paths of the fields that differ between
+// a and b, which have the same type.
+func diffValues(path string, a, b reflect.Value, changed *[]string) {
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() && b.IsNil() {
+			return
+		}
+		if a.Type().Elem().Kind() != reflect.Struct {
+			if a.IsNil() || b.IsNil() || !reflect.DeepEqual(a.Elem().Interface(), b.Elem().Interface()) {
+				*changed = append(*changed, path)
+			}
+			return
+		}
+		if a.IsNil() != b.IsNil() && !hasExportedFields(a.Type().Elem()) {
+			// marker structs, like GlobalService, only matter by their presence
+			*changed = append(*changed, path)
+			return
+		}
+		diffValues(path, derefOrZero(a), derefOrZero(b), changed)
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := path
+			if !field.Anonymous {
+				fieldPath = strings.TrimPrefix(path+"."+field.Name, ".")
+			}
+			diffValues(fieldPath, a.Field(i), b.Field(i), changed)
+		}
+	case reflect.Slice, reflect.Map:
+		if a.Len() == 0 && b.Len() == 0 {
+			return
+		}
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changed = append(*changed, path)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changed = append(*changed, path)
+		}
+	}
+}
+
+func derefOrZero(v reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return reflect.Zero(v.Type().Elem())
+	}
+	return v.Elem()
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// deployedServiceSpec returns the spec of a service as returned by the daemon
+// after creating it from minimalServiceSpec.
+func deployedServiceSpec() swarm.ServiceSpec {
+	replicas := uint64(1)
+	return swarm.ServiceSpec{
+		Annotations: swarm.Annotations{Name: "web", Labels: map[string]string{}},
+		TaskTemplate: swarm.TaskSpec{
+			ContainerSpec: &swarm.ContainerSpec{
+				Image:     "nginx:latest@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31",
+				Env:       []string{"PORT=80"},
+				Isolation: "default",
+			},
+			Placement:   &swarm.Placement{Platforms: []swarm.Platform{{Architecture: "amd64", OS: "linux"}}},
+			ForceUpdate: 2,
+			Runtime:     swarm.RuntimeContainer,
+		},
+		Mode: swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+		UpdateConfig: &swarm.UpdateConfig{
+			Parallelism:   1,
+			FailureAction: swarm.UpdateFailureActionPause,
+			Monitor:       5 * time.Second,
+			Order:         swarm.UpdateOrderStopFirst,
+		},
+		EndpointSpec: &swarm.EndpointSpec{
+			Mode:  swarm.ResolutionModeVIP,
+			Ports: []swarm.PortConfig{{Protocol: swarm.PortConfigProtocolTCP, TargetPort: 80, PublishedPort: 8080, PublishMode: swarm.PortConfigPublishModeIngress}},
+		},
+	}
+}
+
+func minimalServiceSpec() swarm.ServiceSpec {
+	return swarm.ServiceSpec{
+		Annotations: swarm.Annotations{Name: "web"},
+		TaskTemplate: swarm.TaskSpec{
+			ContainerSpec: &swarm.ContainerSpec{Image: "docker.io/library/nginx", Env: []string{"PORT=80"}},
+		},
+		EndpointSpec: &swarm.EndpointSpec{
+			Ports: []swarm.PortConfig{{TargetPort: 80, PublishedPort: 8080}},
+		},
+	}
+}
+
+func TestDiffServiceSpec(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		modify   func(*swarm.ServiceSpec)
+		expected []string
+	}{
+		{"defaults", func(*swarm.ServiceSpec) {}, nil},
+		{
+			"image",
+			func(spec *swarm.ServiceSpec) { spec.TaskTemplate.ContainerSpec.Image = "nginx:1.27" },
+			[]string{"TaskTemplate.ContainerSpec.Image"},
+		},
+		{
+			"pinned digest",
+			func(spec *swarm.ServiceSpec) {
+				spec.TaskTemplate.ContainerSpec.Image = "nginx@sha256:a2b1d0a5e0c9f3e8b7a6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4"
+			},
+			[]string{"TaskTemplate.ContainerSpec.Image"},
+		},
+		{
+			"replicas and env",
+			func(spec *swarm.ServiceSpec) {
+				replicas := uint64(3)
+				spec.Mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+				spec.TaskTemplate.ContainerSpec.Env = []string{"PORT=8080"}
+			},
+			[]string{"Mode.Replicated.Replicas", "TaskTemplate.ContainerSpec.Env"},
+		},
+		{
+			"removed port",
+			func(spec *swarm.ServiceSpec) { spec.EndpointSpec = nil },
+			[]string{"EndpointSpec.Ports"},
+		},
+		{
+			"labels",
+			func(spec *swarm.ServiceSpec) { spec.Labels = map[string]string{"app": "web"} },
+			[]string{"Labels"},
+		},
+		{
+			"update config",
+			func(spec *swarm.ServiceSpec) { spec.UpdateConfig = &swarm.UpdateConfig{Parallelism: 2} },
+			[]string{"UpdateConfig.FailureAction", "UpdateConfig.Monitor", "UpdateConfig.Order", "UpdateConfig.Parallelism"},
+		},
+		{
+			"global mode",
+			func(spec *swarm.ServiceSpec) { spec.Mode.Global = &swarm.GlobalService{} },
+			[]string{"Mode.Global", "Mode.Replicated.Replicas"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			desired := minimalServiceSpec()
+			tt.modify(&desired)
+			changed := DiffServiceSpec(deployedServiceSpec(), desired)
+			if !reflect.DeepEqual(changed, tt.expected) {
+				t.Errorf("DiffServiceSpec: wrong changes. Want %#v. Got %#v.", tt.expected, changed)
+			}
+			if needsUpdate := ServiceNeedsUpdate(deployedServiceSpec(), desired); needsUpdate != (len(tt.expected) > 0) {
+				t.Errorf("ServiceNeedsUpdate: wrong result. Want %v. Got %v.", len(tt.expected) > 0, needsUpdate)
+			}
+		})
+	}
+}
+
+func TestDiffServiceSpecDoesNotModifySpecs(t *testing.T) {
+	t.Parallel()
+	current, desired := deployedServiceSpec(), minimalServiceSpec()
+	DiffServiceSpec(current, desired)
+	if !reflect.DeepEqual(current, deployedServiceSpec()) {
+		t.Errorf("DiffServiceSpec: current spec modified: %#v", current)
+	}
+	if !reflect.DeepEqual(desired, minimalServiceSpec()) {
+		t.Errorf("DiffServiceSpec: desired spec modified: %#v", desired)
+	}
+}
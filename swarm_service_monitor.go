@@ -0,0 +1,156 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// serviceUpdateStartTimeout is the default time MonitorServiceUpdate waits
+// for the rollout of an update to start.
+const serviceUpdateStartTimeout = 5 * time.Second
+
+var (
+	// ErrServiceUpdatePaused is the error returned by MonitorServiceUpdate
+	// when the update (or its rollback) is paused by the manager, usually
+	// because too many tasks failed.
+	ErrServiceUpdatePaused = errors.New("service update paused")
+
+	// ErrServiceUpdateRolledBack is the error returned by
+	// MonitorServiceUpdate when the update is rolled back.
+	ErrServiceUpdateRolledBack = errors.New("service update rolled back")
+)
+
+// ServiceUpdateEvent is a change observed by MonitorServiceUpdate: either a
+// change of the update status of the service, or a state transition of one
+// of its tasks.
+type ServiceUpdateEvent struct {
+	// UpdateStatus is set when the update status of the service changed.
+	UpdateStatus *swarm.UpdateStatus
+
+	// Task is set when a task changed state. PreviousState is empty for
+	// tasks that weren't seen before.
+	Task          *swarm.Task
+	PreviousState swarm.TaskState
+}
+
+// MonitorServiceUpdateOptions specify parameters to the MonitorServiceUpdate
+// function.
+type MonitorServiceUpdateOptions struct {
+	ID string
+
+	// Progress, when set, is called with every observed change.
+	Progress func(ServiceUpdateEvent)
+
+	// Interval is the polling interval, defaults to 500ms.
+	Interval time.Duration
+
+	// StartTimeout is how long to wait for the rollout of the update to
+	// start, defaults to 5s. Updates that don't change the tasks, like
+	// changes to the labels of the service, aren't rolled out, so the
+	// service is considered up to date when no rollout starts in time.
+	StartTimeout time.Duration
+
+	// PreviousUpdateStatus is the update status of the service before the
+	// update, as returned by InspectService before calling UpdateService.
+	// The status of the previous update, started at the same time, is
+	// ignored, so that the update isn't reported as completed before its
+	// rollout starts. When it's nil, every status is considered to belong
+	// to the update being monitored.
+	PreviousUpdateStatus *swarm.UpdateStatus
+
+	Context context.Context
+}
+
+// MonitorServiceUpdate follows the update of a service, started by
+// UpdateService, until it finishes, like "docker service update
+// --detach=false" does. It returns nil once the update is completed,
+// ErrServiceUpdatePaused if the update is paused and
+// ErrServiceUpdateRolledBack if it's rolled back. Services that were never
+// updated are considered up to date.
+//
+// The update status left by a previous update is ignored when it's given in
+// PreviousUpdateStatus, so that the update isn't reported as completed
+// before its rollout starts.
+func (c *Client) MonitorServiceUpdate(opts MonitorServiceUpdateOptions) error {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = serviceTasksPollInterval
+	}
+	startTimeout := opts.StartTimeout
+	if startTimeout <= 0 {
+		startTimeout = serviceUpdateStartTimeout
+	}
+	progress := opts.Progress
+	if progress == nil {
+		progress = func(ServiceUpdateEvent) {}
+	}
+	var lastUpdateState swarm.UpdateState
+	startDeadline := time.Now().Add(startTimeout)
+	taskStates := make(map[string]swarm.TaskState)
+	for {
+		service, err := c.InspectServiceWithOptions(InspectServiceOptions{ID: opts.ID, Context: ctx})
+		if err != nil {
+			return err
+		}
+		tasks, err := c.ListTasks(ListTasksOptions{
+			Filters: map[string][]string{"service": {service.ID}},
+			Context: ctx,
+		})
+		if err != nil {
+			return err
+		}
+		for i := range tasks {
+			task := &tasks[i]
+			previous, seen := taskStates[task.ID]
+			if seen && previous == task.Status.State {
+				continue
+			}
+			taskStates[task.ID] = task.Status.State
+			progress(ServiceUpdateEvent{Task: task, PreviousState: previous})
+		}
+		status := service.UpdateStatus
+		if isPreviousUpdate(status, opts.PreviousUpdateStatus) {
+			status = nil
+		}
+		if status == nil {
+			if !service.UpdatedAt.After(service.CreatedAt) || time.Now().After(startDeadline) {
+				return nil
+			}
+		} else {
+			if status.State != lastUpdateState {
+				lastUpdateState = status.State
+				progress(ServiceUpdateEvent{UpdateStatus: status})
+			}
+			switch status.State {
+			case swarm.UpdateStateCompleted:
+				return nil
+			case swarm.UpdateStatePaused, swarm.UpdateStateRollbackPaused:
+				return fmt.Errorf("%w: %s", ErrServiceUpdatePaused, status.Message)
+			case swarm.UpdateStateRollbackCompleted:
+				return fmt.Errorf("%w: %s", ErrServiceUpdateRolledBack, status.Message)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// isPreviousUpdate reports whether status is the status of the update
+// described by previous, comparing their start times.
+func isPreviousUpdate(status, previous *swarm.UpdateStatus) bool {
+	if status == nil || previous == nil || status.StartedAt == nil || previous.StartedAt == nil {
+		return false
+	}
+	return status.StartedAt.Equal(*previous.StartedAt)
+}
@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+func newMonitorTestClient(t *testing.T, states []swarm.UpdateState, taskStates []swarm.TaskState) *Client {
+	services := make([]swarm.Service, len(states))
+	for i, state := range states {
+		services[i].ID = "svc"
+		if state != "" {
+			services[i].UpdateStatus = &swarm.UpdateStatus{State: state, Message: "update " + string(state)}
+		}
+	}
+	return newMonitorServicesTestClient(t, services, taskStates)
+}
+
+func newMonitorServicesTestClient(t *testing.T, services []swarm.Service, taskStates []swarm.TaskState) *Client {
+	var serviceCalls, taskCalls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/services/svc", func(w http.ResponseWriter, _ *http.Request) {
+		i := int(atomic.AddInt32(&serviceCalls, 1)) - 1
+		if i >= len(services) {
+			i = len(services) - 1
+		}
+		json.NewEncoder(w).Encode(services[i])
+	})
+	mux.HandleFunc("/tasks", func(w http.ResponseWriter, _ *http.Request) {
+		i := int(atomic.AddInt32(&taskCalls, 1)) - 1
+		if i >= len(taskStates) {
+			i = len(taskStates) - 1
+		}
+		json.NewEncoder(w).Encode([]swarm.Task{{ID: "t1", Status: swarm.TaskStatus{State: taskStates[i]}}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	return client
+}
+
+func TestMonitorServiceUpdate(t *testing.T) {
+	t.Parallel()
+	client := newMonitorTestClient(t,
+		[]swarm.UpdateState{swarm.UpdateStateUpdating, swarm.UpdateStateUpdating, swarm.UpdateStateCompleted},
+		[]swarm.TaskState{swarm.TaskStatePreparing, swarm.TaskStateStarting, swarm.TaskStateRunning},
+	)
+	var events []string
+	err := client.MonitorServiceUpdate(MonitorServiceUpdateOptions{
+		ID:       "svc",
+		Interval: time.Millisecond,
+		Progress: func(event ServiceUpdateEvent) {
+			if event.UpdateStatus != nil {
+				events = append(events, "update "+string(event.UpdateStatus.State))
+			} else {
+				events = append(events, "task "+string(event.PreviousState)+"->"+string(event.Task.Status.State))
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"task ->preparing",
+		"update updating",
+		"task preparing->starting",
+		"task starting->running",
+		"update completed",
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("MonitorServiceUpdate: wrong events.\nWant %q.\nGot  %q.", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("MonitorServiceUpdate: wrong event %d. Want %q. Got %q.", i, expected[i], events[i])
+		}
+	}
+}
+
+func TestMonitorServiceUpdateFailures(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		states   []swarm.UpdateState
+		expected error
+	}{
+		{[]swarm.UpdateState{swarm.UpdateStateUpdating, swarm.UpdateStatePaused}, ErrServiceUpdatePaused},
+		{[]swarm.UpdateState{swarm.UpdateStateRollbackStarted, swarm.UpdateStateRollbackCompleted}, ErrServiceUpdateRolledBack},
+		{[]swarm.UpdateState{swarm.UpdateStateRollbackStarted, swarm.UpdateStateRollbackPaused}, ErrServiceUpdatePaused},
+		{[]swarm.UpdateState{""}, nil},
+	}
+	for _, test := range tests {
+		client := newMonitorTestClient(t, test.states, []swarm.TaskState{swarm.TaskStateFailed})
+		err := client.MonitorServiceUpdate(MonitorServiceUpdateOptions{ID: "svc", Interval: time.Millisecond})
+		if !errors.Is(err, test.expected) {
+			t.Errorf("MonitorServiceUpdate(%v): wrong error. Want %v. Got %v.", test.states, test.expected, err)
+		}
+	}
+}
+
+func TestMonitorServiceUpdateStaleStatus(t *testing.T) {
+	t.Parallel()
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	previous, current := created.Add(time.Minute), created.Add(time.Hour)
+	service := func(state swarm.UpdateState, startedAt time.Time) swarm.Service {
+		service := swarm.Service{ID: "svc"}
+		// the manager bumps UpdatedAt when recording the update status
+		service.CreatedAt, service.UpdatedAt = created, current.Add(time.Second)
+		service.UpdateStatus = &swarm.UpdateStatus{State: state, StartedAt: &startedAt}
+		return service
+	}
+	client := newMonitorServicesTestClient(t, []swarm.Service{
+		service(swarm.UpdateStateCompleted, previous),
+		service(swarm.UpdateStateCompleted, previous),
+		service(swarm.UpdateStateUpdating, current),
+		service(swarm.UpdateStatePaused, current),
+	}, []swarm.TaskState{swarm.TaskStateRunning})
+	var states []swarm.UpdateState
+	err := client.MonitorServiceUpdate(MonitorServiceUpdateOptions{
+		ID:                   "svc",
+		Interval:             time.Millisecond,
+		StartTimeout:         time.Minute,
+		PreviousUpdateStatus: &swarm.UpdateStatus{State: swarm.UpdateStateCompleted, StartedAt: &previous},
+		Progress: func(event ServiceUpdateEvent) {
+			if event.UpdateStatus != nil {
+				states = append(states, event.UpdateStatus.State)
+			}
+		},
+	})
+	if !errors.Is(err, ErrServiceUpdatePaused) {
+		t.Errorf("MonitorServiceUpdate: wrong error. Want %v. Got %v.", ErrServiceUpdatePaused, err)
+	}
+	expected := []swarm.UpdateState{swarm.UpdateStateUpdating, swarm.UpdateStatePaused}
+	if !reflect.DeepEqual(states, expected) {
+		t.Errorf("MonitorServiceUpdate: wrong update states. Want %q. Got %q.", expected, states)
+	}
+}
+
+func TestMonitorServiceUpdateNotRolledOut(t *testing.T) {
+	t.Parallel()
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	service := swarm.Service{ID: "svc"}
+	service.CreatedAt, service.UpdatedAt = created, created.Add(time.Hour)
+	client := newMonitorServicesTestClient(t, []swarm.Service{service}, []swarm.TaskState{swarm.TaskStateRunning})
+	start := time.Now()
+	err := client.MonitorServiceUpdate(MonitorServiceUpdateOptions{
+		ID:           "svc",
+		Interval:     time.Millisecond,
+		StartTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("MonitorServiceUpdate: returned after %s, before the rollout could start", elapsed)
+	}
+}
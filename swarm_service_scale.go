@@ -0,0 +1,124 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// ErrServiceNotReplicated is the error returned by ScaleService when the
+// service runs in global mode, and thus can't be scaled.
+var ErrServiceNotReplicated = errors.New("service is not in replicated mode")
+
+// serviceTasksPollInterval is the interval between two listings of the tasks
+// of a service when waiting for it to converge.
+const serviceTasksPollInterval = 500 * time.Millisecond
+
+// DefaultScaleTimeout is how long ScaleService waits for the tasks of a
+// service to converge when no timeout is given.
+const DefaultScaleTimeout = time.Minute
+
+// ServiceNotConverged is the error returned when the tasks of a service don't
+// reach the desired state within the timeout. Failed lists the tasks that
+// failed or were rejected while waiting.
+type ServiceNotConverged struct {
+	ID      string
+	Desired uint64
+	Running uint64
+	Failed  []swarm.Task
+}
+
+func (err *ServiceNotConverged) Error() string {
+	msg := fmt.Sprintf("service %s did not converge: %d of %d tasks running", err.ID, err.Running, err.Desired)
+	if len(err.Failed) > 0 {
+		failures := make([]string, len(err.Failed))
+		for i, task := range err.Failed {
+			failures[i] = fmt.Sprintf("task %s %s: %s", task.ID, task.Status.State, task.Status.Err)
+		}
+		msg += " (" + strings.Join(failures, "; ") + ")"
+	}
+	return msg
+}
+
+// ScaleService sets the number of replicas of a service. When wait is true,
+// it blocks until the desired number of tasks is running, returning a
+// *ServiceNotConverged error if that doesn't happen within timeout. A zero
+// timeout means DefaultScaleTimeout.
+func (c *Client) ScaleService(id string, replicas uint64, wait bool, timeout time.Duration) error {
+	service, err := c.InspectService(id)
+	if err != nil {
+		return err
+	}
+	if service.Spec.Mode.Replicated == nil {
+		return ErrServiceNotReplicated
+	}
+	var known map[string]bool
+	if wait {
+		tasks, err := c.ListTasks(ListTasksOptions{Filters: map[string][]string{"service": {service.ID}}})
+		if err != nil {
+			return err
+		}
+		known = make(map[string]bool, len(tasks))
+		for _, task := range tasks {
+			known[task.ID] = true
+		}
+	}
+	spec := service.Spec
+	spec.Mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+	err = c.UpdateService(service.ID, UpdateServiceOptions{ServiceSpec: spec, Version: service.Version.Index})
+	if err != nil || !wait {
+		return err
+	}
+	if timeout <= 0 {
+		timeout = DefaultScaleTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return c.waitServiceTasks(ctx, service.ID, replicas, known)
+}
+
+// waitServiceTasks polls the tasks of a service until exactly desired tasks
+// are running. Only tasks that are meant to keep running are counted, so the
+// ones being shut down by a scale down don't count toward the target. Tasks in
+// known are ignored when looking for failures, as they predate the change
+// being waited for.
+func (c *Client) waitServiceTasks(ctx context.Context, id string, desired uint64, known map[string]bool) error {
+	var running uint64
+	var failed []swarm.Task
+	for {
+		tasks, err := c.ListTasks(ListTasksOptions{
+			Filters: map[string][]string{"service": {id}},
+			Context: ctx,
+		})
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if err == nil {
+			running, failed = 0, nil
+			for _, task := range tasks {
+				switch task.Status.State {
+				case swarm.TaskStateRunning:
+					if task.DesiredState == swarm.TaskStateRunning {
+						running++
+					}
+				case swarm.TaskStateFailed, swarm.TaskStateRejected:
+					if !known[task.ID] {
+						failed = append(failed, task)
+					}
+				}
+			}
+			if running == desired {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return &ServiceNotConverged{ID: id, Desired: desired, Running: running, Failed: failed}
+		case <-time.After(serviceTasksPollInterval):
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+func newScaleTestServer(t *testing.T, mode swarm.ServiceMode, tasksFunc func(call int32) []swarm.Task) (*Client, *swarm.ServiceSpec) {
+	var updated swarm.ServiceSpec
+	var calls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/services/svc", func(w http.ResponseWriter, _ *http.Request) {
+		service := swarm.Service{ID: "svc", Spec: swarm.ServiceSpec{Mode: mode}}
+		service.Version.Index = 7
+		json.NewEncoder(w).Encode(service)
+	})
+	mux.HandleFunc("/services/svc/update", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("version"); got != "7" {
+			t.Errorf("ScaleService: wrong version. Want %q. Got %q.", "7", got)
+		}
+		json.NewDecoder(r.Body).Decode(&updated)
+	})
+	mux.HandleFunc("/tasks", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(tasksFunc(atomic.AddInt32(&calls, 1)))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	return client, &updated
+}
+
+func newTestTask(id string, state swarm.TaskState) swarm.Task {
+	return swarm.Task{ID: id, ServiceID: "svc", DesiredState: swarm.TaskStateRunning, Status: swarm.TaskStatus{State: state, Err: "boom"}}
+}
+
+func TestScaleService(t *testing.T) {
+	t.Parallel()
+	client, updated := newScaleTestServer(t, swarm.ServiceMode{Replicated: &swarm.ReplicatedService{}}, func(call int32) []swarm.Task {
+		if call < 3 {
+			return []swarm.Task{newTestTask("t1", swarm.TaskStateRunning)}
+		}
+		return []swarm.Task{newTestTask("t1", swarm.TaskStateRunning), newTestTask("t2", swarm.TaskStateRunning), newTestTask("t3", swarm.TaskStateRunning)}
+	})
+	if err := client.ScaleService("svc", 3, true, 0); err != nil {
+		t.Fatal(err)
+	}
+	if replicas := updated.Mode.Replicated.Replicas; replicas == nil || *replicas != 3 {
+		t.Errorf("ScaleService: wrong replicas in update: %v.", replicas)
+	}
+}
+
+func TestScaleServiceDown(t *testing.T) {
+	t.Parallel()
+	client, _ := newScaleTestServer(t, swarm.ServiceMode{Replicated: &swarm.ReplicatedService{}}, func(int32) []swarm.Task {
+		stopping := newTestTask("t2", swarm.TaskStateRunning)
+		stopping.DesiredState = swarm.TaskStateShutdown
+		return []swarm.Task{newTestTask("t1", swarm.TaskStateRunning), stopping}
+	})
+	if err := client.ScaleService("svc", 1, true, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScaleServiceNotConverged(t *testing.T) {
+	t.Parallel()
+	client, _ := newScaleTestServer(t, swarm.ServiceMode{Replicated: &swarm.ReplicatedService{}}, func(call int32) []swarm.Task {
+		if call == 1 {
+			return []swarm.Task{newTestTask("old", swarm.TaskStateFailed)}
+		}
+		return []swarm.Task{newTestTask("old", swarm.TaskStateFailed), newTestTask("t1", swarm.TaskStateRunning), newTestTask("t2", swarm.TaskStateRejected)}
+	})
+	err := client.ScaleService("svc", 2, true, 100*time.Millisecond)
+	var notConverged *ServiceNotConverged
+	if !errors.As(err, &notConverged) {
+		t.Fatalf("ScaleService: wrong error. Want ServiceNotConverged. Got %#v.", err)
+	}
+	if notConverged.Running != 1 || notConverged.Desired != 2 {
+		t.Errorf("ScaleService: wrong task counts: %d of %d.", notConverged.Running, notConverged.Desired)
+	}
+	if len(notConverged.Failed) != 1 || notConverged.Failed[0].ID != "t2" {
+		t.Errorf("ScaleService: wrong failed tasks: %#v.", notConverged.Failed)
+	}
+}
+
+func TestScaleServiceGlobal(t *testing.T) {
+	t.Parallel()
+	client, _ := newScaleTestServer(t, swarm.ServiceMode{Global: &swarm.GlobalService{}}, nil)
+	if err := client.ScaleService("svc", 2, false, 0); !errors.Is(err, ErrServiceNotReplicated) {
+		t.Errorf("ScaleService: wrong error. Want %#v. Got %#v.", ErrServiceNotReplicated, err)
+	}
+}
@@ -114,6 +114,52 @@ func TestCreateServiceWithAuthentication(t *testing.T) {
 	}
 }
 
+func TestCreateServiceWithAuthConfigs(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"ID":"4fa6e0f0c6786287e131c3852c58a2e01cc697a68231826813597e4994f1d6e2"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	quayAuth := AuthConfiguration{Username: "gopher", Password: "quay-pass", ServerAddress: "quay.io"}
+	opts := CreateServiceOptions{
+		AuthConfigs: AuthConfigurations{Configs: map[string]AuthConfiguration{
+			"https://index.docker.io/v1/": {Username: "gopher", Password: "hub-pass"},
+			"https://quay.io":             quayAuth,
+		}},
+	}
+	opts.TaskTemplate.ContainerSpec = &swarm.ContainerSpec{Image: "quay.io/coreos/etcd:v3.5.0"}
+	if _, err := client.CreateService(opts); err != nil {
+		t.Fatal(err)
+	}
+	auth, err := base64.URLEncoding.DecodeString(fakeRT.requests[0].Header.Get("X-Registry-Auth"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotAuth AuthConfiguration
+	if err := json.Unmarshal(auth, &gotAuth); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotAuth, quayAuth) {
+		t.Errorf("CreateService: wrong auth configuration. Want %#v. Got %#v.", quayAuth, gotAuth)
+	}
+}
+
+func TestCreateServiceWithAuthConfigsNoMatch(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"ID":"4fa6e0f0c6786287e131c3852c58a2e01cc697a68231826813597e4994f1d6e2"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	opts := CreateServiceOptions{
+		AuthConfigs: AuthConfigurations{Configs: map[string]AuthConfiguration{
+			"quay.io": {Username: "gopher", Password: "quay-pass"},
+		}},
+	}
+	opts.TaskTemplate.ContainerSpec = &swarm.ContainerSpec{Image: "nginx:latest"}
+	if _, err := client.CreateService(opts); err != nil {
+		t.Fatal(err)
+	}
+	if authHeader, ok := fakeRT.requests[0].Header["X-Registry-Auth"]; ok {
+		t.Errorf("CreateService: unexpected X-Registry-Auth header: %v", authHeader)
+	}
+}
+
 func TestRemoveService(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
@@ -200,6 +246,22 @@ func TestUpdateServiceRollback(t *testing.T) {
 	}
 }
 
+func TestUpdateServiceRegistryAuthFrom(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	id := "4fa6e0f0c6786287e131c3852c58a2e01cc697a68231826813597e4994f1d6e2"
+	update := UpdateServiceOptions{Version: 23, RegistryAuthFrom: RegistryAuthFromPreviousSpec}
+	if err := client.UpdateService(id, update); err != nil {
+		t.Fatal(err)
+	}
+	query := fakeRT.requests[0].URL.Query()
+	expected := url.Values{"version": {"23"}, "registryAuthFrom": {"previous-spec"}}
+	if !reflect.DeepEqual(query, expected) {
+		t.Errorf("UpdateService: Wrong querystring in request. Want %v. Got %v.", expected, query)
+	}
+}
+
 func TestUpdateServiceWithAuthentication(t *testing.T) {
 	t.Parallel()
 	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
@@ -615,3 +677,20 @@ func TestGetServiceLogsNoContainer(t *testing.T) {
 		t.Errorf("AttachToContainer: wrong error. Want %#v. Got %#v.", expected, err)
 	}
 }
+
+func TestInspectServiceWithOptions(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: `{"ID":"ak7w3gjqoa3kuz8xcpnyy0pvl"}`, status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	service, err := client.InspectServiceWithOptions(InspectServiceOptions{ID: "ak7w3gjqoa3kuz8xcpnyy0pvl", InsertDefaults: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.ID != "ak7w3gjqoa3kuz8xcpnyy0pvl" {
+		t.Errorf("InspectServiceWithOptions: wrong ID. Got %q.", service.ID)
+	}
+	req := fakeRT.requests[0]
+	if got := req.URL.Query().Get("insertDefaults"); got != "1" {
+		t.Errorf("InspectServiceWithOptions: wrong insertDefaults. Want %q. Got %q.", "1", got)
+	}
+}
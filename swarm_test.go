@@ -6,11 +6,14 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types/swarm"
 )
@@ -210,3 +213,124 @@ func TestInspectSwarmNotInSwarm(t *testing.T) {
 		t.Errorf("InspectSwarm: Wrong error type. Want %#v. Got %#v", ErrNodeNotInSwarm, err)
 	}
 }
+
+func TestUpdateSwarmError(t *testing.T) {
+	t.Parallel()
+	client := newTestClient(&FakeRoundTripper{message: "bad spec", status: http.StatusBadRequest})
+	err := client.UpdateSwarm(UpdateSwarmOptions{})
+	var e *Error
+	if !errors.As(err, &e) || e.Status != http.StatusBadRequest {
+		t.Errorf("UpdateSwarm: wrong error. Got %#v.", err)
+	}
+}
+
+func TestRotateJoinTokens(t *testing.T) {
+	t.Parallel()
+	var rotated bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			query := r.URL.Query()
+			if query.Get("version") != "5" || query.Get("rotateWorkerToken") != "true" || query.Get("rotateManagerToken") != "false" {
+				t.Errorf("RotateJoinTokens: wrong query string: %s.", r.URL.RawQuery)
+			}
+			var spec swarm.Spec
+			json.NewDecoder(r.Body).Decode(&spec)
+			if spec.Name != "default" {
+				t.Errorf("RotateJoinTokens: spec not preserved: %#v.", spec)
+			}
+			rotated = true
+			return
+		}
+		sw := swarm.Swarm{JoinTokens: swarm.JoinTokens{Worker: "SWMTKN-worker", Manager: "SWMTKN-manager"}}
+		sw.Version.Index = 5
+		sw.Spec.Name = "default"
+		if rotated {
+			sw.JoinTokens.Worker = "SWMTKN-worker-2"
+		}
+		json.NewEncoder(w).Encode(sw)
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.SkipServerVersionCheck = true
+	tokens, err := client.SwarmJoinTokens(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.Worker != "SWMTKN-worker" || tokens.Manager != "SWMTKN-manager" {
+		t.Errorf("SwarmJoinTokens: wrong tokens: %#v.", tokens)
+	}
+	tokens, err = client.RotateJoinTokens(context.TODO(), true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.Worker != "SWMTKN-worker-2" || tokens.Manager != "SWMTKN-manager" {
+		t.Errorf("RotateJoinTokens: wrong tokens: %#v.", tokens)
+	}
+}
+
+func TestRotateSwarmCA(t *testing.T) {
+	t.Parallel()
+	var rotated bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if got := r.URL.Query().Get("version"); got != "7" {
+				t.Errorf("RotateSwarmCA: wrong version: %s.", got)
+			}
+			var spec swarm.Spec
+			json.NewDecoder(r.Body).Decode(&spec)
+			expected := swarm.CAConfig{
+				NodeCertExpiry: 24 * time.Hour,
+				ExternalCAs:    []*swarm.ExternalCA{{Protocol: swarm.ExternalCAProtocolCFSSL, URL: "https://ca.example.com", CACert: "new-cert"}},
+				SigningCACert:  "new-cert",
+				SigningCAKey:   "new-key",
+				ForceRotate:    3,
+			}
+			if spec.Name != "default" || !reflect.DeepEqual(spec.CAConfig, expected) {
+				t.Errorf("RotateSwarmCA: wrong spec: %#v.", spec)
+			}
+			rotated = true
+			return
+		}
+		sw := swarm.Swarm{}
+		sw.Version.Index = 7
+		sw.Spec.Name = "default"
+		sw.Spec.CAConfig.NodeCertExpiry = 90 * 24 * time.Hour
+		sw.Spec.CAConfig.ForceRotate = 2
+		sw.TLSInfo.TrustRoot = "old-cert"
+		sw.RootRotationInProgress = rotated
+		json.NewEncoder(w).Encode(sw)
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	tlsInfo, err := client.SwarmTLSInfo(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsInfo.TrustRoot != "old-cert" {
+		t.Errorf("SwarmTLSInfo: wrong TLS info: %#v.", tlsInfo)
+	}
+	sw, err := client.RotateSwarmCA(RotateSwarmCAOptions{
+		SigningCACert:  "new-cert",
+		SigningCAKey:   "new-key",
+		ExternalCAs:    []*swarm.ExternalCA{{Protocol: swarm.ExternalCAProtocolCFSSL, URL: "https://ca.example.com", CACert: "new-cert"}},
+		NodeCertExpiry: 24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sw.RootRotationInProgress {
+		t.Error("RotateSwarmCA: rotation should be in progress")
+	}
+}
+
+func TestRotateSwarmCAKeyWithoutCert(t *testing.T) {
+	t.Parallel()
+	fakeRT := &FakeRoundTripper{message: "", status: http.StatusOK}
+	client := newTestClient(fakeRT)
+	if _, err := client.RotateSwarmCA(RotateSwarmCAOptions{SigningCAKey: "key"}); err == nil {
+		t.Fatal("RotateSwarmCA: unexpected <nil> error")
+	}
+	if len(fakeRT.requests) != 0 {
+		t.Errorf("RotateSwarmCA: unexpected requests: %d", len(fakeRT.requests))
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// dockerfileStage is a build stage of a Dockerfile, with the instructions
+// the fake server understands.
+type dockerfileStage struct {
+	name         string
+	from         string
+	labels       map[string]string
+	exposedPorts map[docker.Port]struct{}
+}
+
+// parseDockerfile minimally parses a Dockerfile, handling the FROM, ARG,
+// LABEL and EXPOSE instructions, and returns its stages. Variables are
+// expanded with buildArgs and the defaults declared with ARG.
+func parseDockerfile(content string, buildArgs map[string]string) ([]*dockerfileStage, error) {
+	var stages []*dockerfileStage
+	args := make(map[string]string)
+	expand := func(s string) string {
+		return os.Expand(s, func(name string) string {
+			if value, ok := buildArgs[name]; ok {
+				return value
+			}
+			return args[name]
+		})
+	}
+	for _, line := range dockerfileInstructions(content) {
+		instruction, rest := line, ""
+		if i := strings.IndexFunc(line, unicode.IsSpace); i >= 0 {
+			instruction, rest = line[:i], strings.TrimSpace(line[i:])
+		}
+		instruction = strings.ToUpper(instruction)
+		if instruction != "FROM" && instruction != "ARG" && len(stages) == 0 {
+			return nil, fmt.Errorf("%s instruction before FROM", instruction)
+		}
+		switch instruction {
+		case "ARG":
+			name, value, _ := strings.Cut(rest, "=")
+			args[name] = expand(strings.Trim(value, `"`))
+		case "FROM":
+			words := strings.Fields(expand(rest))
+			if len(words) == 0 {
+				return nil, fmt.Errorf("FROM requires an image")
+			}
+			stage := &dockerfileStage{
+				from:         words[0],
+				labels:       make(map[string]string),
+				exposedPorts: make(map[docker.Port]struct{}),
+			}
+			if len(words) == 3 && strings.EqualFold(words[1], "as") {
+				stage.name = words[2]
+			}
+			for _, previous := range stages {
+				if previous.name != "" && previous.name == stage.from {
+					for k, v := range previous.labels {
+						stage.labels[k] = v
+					}
+					for port := range previous.exposedPorts {
+						stage.exposedPorts[port] = struct{}{}
+					}
+				}
+			}
+			stages = append(stages, stage)
+		case "LABEL":
+			stage := stages[len(stages)-1]
+			words := splitDockerfileWords(rest)
+			if len(words) > 0 && !strings.Contains(words[0], "=") {
+				// legacy form: LABEL key value
+				stage.labels[words[0]] = expand(strings.Join(words[1:], " "))
+				continue
+			}
+			for _, word := range words {
+				key, value, ok := strings.Cut(word, "=")
+				if !ok {
+					return nil, fmt.Errorf("invalid LABEL %q", rest)
+				}
+				stage.labels[expand(key)] = expand(value)
+			}
+		case "EXPOSE":
+			stage := stages[len(stages)-1]
+			for _, port := range strings.Fields(expand(rest)) {
+				if !strings.Contains(port, "/") {
+					port += "/tcp"
+				}
+				stage.exposedPorts[docker.Port(port)] = struct{}{}
+			}
+		}
+	}
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("no FROM instruction in Dockerfile")
+	}
+	return stages, nil
+}
+
+// dockerfileInstructions returns the instructions of a Dockerfile, joining
+// continuation lines and skipping comments and blank lines.
+func dockerfileInstructions(content string) []string {
+	var instructions []string
+	var current strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if continued, ok := strings.CutSuffix(line, `\`); ok {
+			current.WriteString(continued)
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(line)
+		instructions = append(instructions, current.String())
+		current.Reset()
+	}
+	if current.Len() > 0 {
+		instructions = append(instructions, current.String())
+	}
+	return instructions
+}
+
+// splitDockerfileWords splits s on blanks, honoring double quotes, which
+// are removed, and backslash escapes.
+func splitDockerfileWords(s string) []string {
+	var words []string
+	var word strings.Builder
+	inQuotes, inWord := false, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			word.WriteByte(s[i])
+			inWord = true
+		case c == '"':
+			inQuotes = !inQuotes
+			inWord = true
+		case (c == ' ' || c == '\t') && !inQuotes:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
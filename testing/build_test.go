@@ -0,0 +1,112 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"archive/tar"
+	"bytes"
+	"reflect"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestParseDockerfile(t *testing.T) {
+	t.Parallel()
+	dockerfile := `# syntax=docker/dockerfile:1
+ARG BASE=alpine:3.19
+FROM $BASE AS builder
+LABEL stage=build
+EXPOSE 8080
+
+FROM builder
+ARG VERSION=dev
+LABEL org.opencontainers.image.version=$VERSION \
+      description="a test image" \
+      maintainer=me
+LABEL legacy some value
+EXPOSE 53/udp 9090
+RUN echo ignored
+`
+	stages, err := parseDockerfile(dockerfile, map[string]string{"VERSION": "1.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stages) != 2 {
+		t.Fatalf("parseDockerfile: wrong number of stages: %d.", len(stages))
+	}
+	if stages[0].name != "builder" || stages[0].from != "alpine:3.19" || stages[1].from != "builder" {
+		t.Errorf("parseDockerfile: wrong stages: %#v, %#v.", stages[0], stages[1])
+	}
+	expectedLabels := map[string]string{
+		"stage":                            "build",
+		"org.opencontainers.image.version": "1.2.3",
+		"description":                      "a test image",
+		"maintainer":                       "me",
+		"legacy":                           "some value",
+	}
+	if !reflect.DeepEqual(stages[1].labels, expectedLabels) {
+		t.Errorf("parseDockerfile: wrong labels. Want %#v. Got %#v.", expectedLabels, stages[1].labels)
+	}
+	expectedPorts := map[docker.Port]struct{}{"8080/tcp": {}, "53/udp": {}, "9090/tcp": {}}
+	if !reflect.DeepEqual(stages[1].exposedPorts, expectedPorts) {
+		t.Errorf("parseDockerfile: wrong ports. Want %#v. Got %#v.", expectedPorts, stages[1].exposedPorts)
+	}
+}
+
+func TestParseDockerfileInvalid(t *testing.T) {
+	t.Parallel()
+	for _, dockerfile := range []string{"", "RUN true\nFROM alpine", "FROM alpine\nLABEL a=b c"} {
+		if _, err := parseDockerfile(dockerfile, nil); err == nil {
+			t.Errorf("parseDockerfile: expected an error for %q", dockerfile)
+		}
+	}
+}
+
+func TestBuildImageRecordsOptions(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var input bytes.Buffer
+	tw := tar.NewWriter(&input)
+	dockerfile := []byte("FROM alpine AS base\nLABEL stage=base\nFROM base AS final\nARG PORT=80\nEXPOSE $PORT\nLABEL team=core\n")
+	tw.WriteHeader(&tar.Header{Name: "build/Dockerfile.test", Size: int64(len(dockerfile)), Mode: 0o644})
+	tw.Write(dockerfile)
+	tw.Close()
+	var output bytes.Buffer
+	err = client.BuildImage(docker.BuildImageOptions{
+		Name:         "app:latest",
+		Dockerfile:   "build/Dockerfile.test",
+		BuildArgs:    []docker.BuildArg{{Name: "PORT", Value: "8080"}},
+		Labels:       map[string]string{"team": "platform"},
+		InputStream:  &input,
+		OutputStream: &output,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	image, err := client.InspectImage("app:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedLabels := map[string]string{"stage": "base", "team": "platform"}
+	if !reflect.DeepEqual(image.Config.Labels, expectedLabels) {
+		t.Errorf("BuildImage: wrong labels. Want %#v. Got %#v.", expectedLabels, image.Config.Labels)
+	}
+	if _, ok := image.Config.ExposedPorts["8080/tcp"]; !ok || len(image.Config.ExposedPorts) != 1 {
+		t.Errorf("BuildImage: wrong exposed ports: %#v.", image.Config.ExposedPorts)
+	}
+	args, ok := server.ImageBuildArgs("app:latest")
+	if !ok || !reflect.DeepEqual(args, map[string]string{"PORT": "8080"}) {
+		t.Errorf("ImageBuildArgs: wrong args: %#v.", args)
+	}
+}
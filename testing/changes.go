@@ -0,0 +1,51 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"encoding/json"
+	"net/http"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/gorilla/mux"
+)
+
+// SetContainerChanges sets the changes to the filesystem of the given
+// container, by ID or name, returned by its changes endpoint. The endpoint
+// also reports the last file uploaded to the container as added, and sorts
+// the changes by path.
+func (s *DockerServer) SetContainerChanges(id string, changes ...docker.Change) error {
+	s.cMut.Lock()
+	defer s.cMut.Unlock()
+	container, err := s.findContainerWithLock(id, false)
+	if err != nil {
+		return err
+	}
+	if s.changes == nil {
+		s.changes = make(map[string][]docker.Change)
+	}
+	s.changes[container.ID] = append([]docker.Change(nil), changes...)
+	return nil
+}
+
+func (s *DockerServer) containerChanges(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	s.cMut.RLock()
+	container, err := s.findContainerWithLock(id, false)
+	if err != nil {
+		s.cMut.RUnlock()
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	changes := append([]docker.Change{}, s.changes[container.ID]...)
+	if path, ok := s.uploadedFiles[container.ID]; ok {
+		changes = append(changes, docker.Change{Path: path, Kind: docker.ChangeAdd})
+	}
+	s.cMut.RUnlock()
+	docker.SortChanges(changes)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(changes)
+}
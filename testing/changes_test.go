@@ -0,0 +1,63 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"archive/tar"
+	"bytes"
+	"reflect"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestContainerChanges(t *testing.T) {
+	t.Parallel()
+	server, client, container := newLogsTestServer(t)
+	changes, err := client.ContainerChanges(container.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("ContainerChanges: want no changes. Got %#v.", changes)
+	}
+	err = server.SetContainerChanges("logger",
+		docker.Change{Path: "/tmp", Kind: docker.ChangeModify},
+		docker.Change{Path: "/etc/hosts", Kind: docker.ChangeDelete},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "file", Mode: 0o644})
+	tw.Close()
+	if err := client.UploadToContainer(container.ID, docker.UploadToContainerOptions{Path: "/tmp", InputStream: &buf}); err != nil {
+		t.Fatal(err)
+	}
+	changes, err = client.ContainerChanges(container.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []docker.Change{
+		{Path: "/etc/hosts", Kind: docker.ChangeDelete},
+		{Path: "/tmp", Kind: docker.ChangeModify},
+		{Path: "/tmp/file", Kind: docker.ChangeAdd},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("ContainerChanges: wrong changes. Want %#v. Got %#v.", expected, changes)
+	}
+}
+
+func TestContainerChangesNotFound(t *testing.T) {
+	t.Parallel()
+	server, client, _ := newLogsTestServer(t)
+	if _, err := client.ContainerChanges("missing"); err == nil {
+		t.Error("ContainerChanges: expected non-nil error, got <nil>")
+	}
+	if err := server.SetContainerChanges("missing"); err == nil {
+		t.Error("SetContainerChanges: expected non-nil error, got <nil>")
+	}
+}
@@ -0,0 +1,104 @@
+package testing
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// normalizeImageRef returns the canonical form of an image reference, so
+// that references to the same repository and tag, like "busybox",
+// "busybox:latest" and "docker.io/library/busybox", compare equal.
+func normalizeImageRef(ref string) string {
+	ref = strings.TrimPrefix(ref, "docker.io/")
+	ref = strings.TrimPrefix(ref, "library/")
+	if strings.Contains(ref, "@") {
+		return ref
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref
+	}
+	return ref + ":latest"
+}
+
+// lookupImageRefLocked returns the key of imgIDs that refers to the same
+// repository and tag, or digest, as the given reference. References are
+// stored as given by the client, so they're compared in their canonical
+// form. The caller must hold iMut.
+func (s *DockerServer) lookupImageRefLocked(ref string) (string, bool) {
+	if _, ok := s.imgIDs[ref]; ok {
+		return ref, true
+	}
+	normalized := normalizeImageRef(ref)
+	for key := range s.imgIDs {
+		if normalizeImageRef(key) == normalized {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// setImageRefLocked points the given reference to the image with the given
+// ID, removing it from the image it previously pointed to, as tagging does.
+// The caller must hold iMut.
+func (s *DockerServer) setImageRefLocked(ref, id string) {
+	if key, ok := s.lookupImageRefLocked(ref); ok {
+		delete(s.imgIDs, key)
+	}
+	s.imgIDs[ref] = id
+}
+
+// imageRefsLocked returns the references to the image with the given ID,
+// split in tags and digests and sorted. The caller must hold iMut.
+func (s *DockerServer) imageRefsLocked(id string) (tags, digests []string) {
+	for ref, refID := range s.imgIDs {
+		if refID != id {
+			continue
+		}
+		if strings.Contains(ref, "@") {
+			digests = append(digests, ref)
+		} else {
+			tags = append(tags, ref)
+		}
+	}
+	sort.Strings(tags)
+	sort.Strings(digests)
+	return tags, digests
+}
+
+// matchImageReference reports whether any of the given tags matches the
+// pattern of a reference filter, which is matched against the repository
+// and against the repository and tag, like the daemon does.
+func matchImageReference(pattern string, tags []string) bool {
+	for _, tag := range tags {
+		for _, candidate := range []string{tag, imageRepository(tag), normalizeImageRef(tag), imageRepository(normalizeImageRef(tag))} {
+			if ok, _ := path.Match(pattern, candidate); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchImageFilters reports whether an image with the given tags matches the
+// reference and dangling filters of an image listing.
+func matchImageFilters(filters map[string][]string, tags []string) bool {
+	if patterns := filters["reference"]; len(patterns) > 0 {
+		var matched bool
+		for _, pattern := range patterns {
+			if matchImageReference(pattern, tags) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for _, dangling := range filters["dangling"] {
+		if (dangling == "true" || dangling == "1") != (len(tags) == 0) {
+			return false
+		}
+	}
+	return true
+}
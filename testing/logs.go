@@ -0,0 +1,246 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// logFollowInterval is the interval between two checks of the state of a
+// container whose logs are followed.
+const logFollowInterval = 10 * time.Millisecond
+
+// LogEntry is a line of the output of a container, returned by the logs
+// endpoint of the server.
+type LogEntry struct {
+	// Time is the time of the entry. It defaults to the time the entry is
+	// appended.
+	Time time.Time
+
+	// Stream is either "stdout" or "stderr". It defaults to "stdout".
+	Stream string
+
+	// Message is the line, a line break is appended if it's missing.
+	Message string
+}
+
+// containerLogs is the output configured for a container, with a channel
+// closed and replaced whenever entries are appended.
+type containerLogs struct {
+	entries []LogEntry
+	changed chan struct{}
+}
+
+// AppendContainerLogs appends entries to the output of the given container,
+// by ID or name. Once a container has entries, its logs endpoint serves
+// them instead of the default canned output, honoring the stdout, stderr,
+// since, until, tail, timestamps and follow parameters. Requests following
+// the logs receive the entries as they're appended, until the container
+// stops or the client goes away.
+func (s *DockerServer) AppendContainerLogs(id string, entries ...LogEntry) error {
+	container, err := s.findContainer(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range entries {
+		if entries[i].Time.IsZero() {
+			entries[i].Time = now
+		}
+		if entries[i].Stream == "" {
+			entries[i].Stream = "stdout"
+		}
+		if entries[i].Stream != "stdout" && entries[i].Stream != "stderr" {
+			return fmt.Errorf("invalid stream %q", entries[i].Stream)
+		}
+		if !strings.HasSuffix(entries[i].Message, "\n") {
+			entries[i].Message += "\n"
+		}
+	}
+	s.logMut.Lock()
+	defer s.logMut.Unlock()
+	if s.logs == nil {
+		s.logs = make(map[string]*containerLogs)
+	}
+	logs, ok := s.logs[container.ID]
+	if !ok {
+		logs = &containerLogs{changed: make(chan struct{})}
+		s.logs[container.ID] = logs
+	}
+	logs.entries = append(logs.entries, entries...)
+	close(logs.changed)
+	logs.changed = make(chan struct{})
+	return nil
+}
+
+// containerLogEntries returns the entries of the container starting at
+// offset, along with a channel closed when more entries are appended. ok is
+// false if the container has no configured output.
+func (s *DockerServer) containerLogEntries(id string, offset int) (entries []LogEntry, changed <-chan struct{}, ok bool) {
+	s.logMut.Lock()
+	defer s.logMut.Unlock()
+	logs, ok := s.logs[id]
+	if !ok {
+		return nil, nil, false
+	}
+	return logs.entries[offset:], logs.changed, true
+}
+
+// logsQuery holds the parameters of a request to the logs endpoint.
+type logsQuery struct {
+	stdout, stderr bool
+	timestamps     bool
+	follow         bool
+	tail           int
+	since, until   time.Time
+}
+
+func parseLogsQuery(r *http.Request) (logsQuery, error) {
+	query := r.URL.Query()
+	isSet := func(name string) bool {
+		value, _ := strconv.ParseBool(query.Get(name))
+		return value
+	}
+	q := logsQuery{
+		stdout:     isSet("stdout"),
+		stderr:     isSet("stderr"),
+		timestamps: isSet("timestamps"),
+		follow:     isSet("follow"),
+		tail:       -1,
+	}
+	if !q.stdout && !q.stderr {
+		return q, fmt.Errorf("bad parameter: you must choose at least one stream")
+	}
+	if tail := query.Get("tail"); tail != "" && tail != "all" {
+		n, err := strconv.Atoi(tail)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("invalid tail %q", tail)
+		}
+		q.tail = n
+	}
+	var err error
+	if q.since, err = parseLogsTime(query.Get("since")); err != nil {
+		return q, err
+	}
+	if q.until, err = parseLogsTime(query.Get("until")); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+// parseLogsTime parses a Unix timestamp, with optional fractional seconds.
+// "0" and "" mean no time.
+func parseLogsTime(value string) (time.Time, error) {
+	if value == "" || value == "0" {
+		return time.Time{}, nil
+	}
+	seconds, fraction, _ := strings.Cut(value, ".")
+	sec, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+	}
+	var nsec int64
+	if fraction != "" {
+		fraction = (fraction + "000000000")[:9]
+		if nsec, err = strconv.ParseInt(fraction, 10, 64); err != nil {
+			return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+		}
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+func (q logsQuery) matches(entry LogEntry) bool {
+	if entry.Stream == "stdout" && !q.stdout || entry.Stream == "stderr" && !q.stderr {
+		return false
+	}
+	if !q.since.IsZero() && entry.Time.Before(q.since) {
+		return false
+	}
+	return q.until.IsZero() || !entry.Time.After(q.until)
+}
+
+// serveContainerLogs serves the configured output of the container,
+// returning false if it has none.
+func (s *DockerServer) serveContainerLogs(w http.ResponseWriter, r *http.Request, container *docker.Container) bool {
+	entries, changed, ok := s.containerLogEntries(container.ID, 0)
+	if !ok {
+		return false
+	}
+	q, err := parseLogsQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return true
+	}
+	s.cMut.RLock()
+	tty := container.Config != nil && container.Config.Tty
+	s.cMut.RUnlock()
+	w.Header().Set("Content-Type", "application/vnd.docker.raw-stream")
+	w.WriteHeader(http.StatusOK)
+	stdout, stderr := io.Writer(w), io.Writer(w)
+	if !tty {
+		stdout = stdcopy.NewStdWriter(w, stdcopy.Stdout)
+		stderr = stdcopy.NewStdWriter(w, stdcopy.Stderr)
+	}
+	write := func(entries []LogEntry) {
+		for _, entry := range entries {
+			if !q.matches(entry) {
+				continue
+			}
+			out := stdout
+			if entry.Stream == "stderr" {
+				out = stderr
+			}
+			line := entry.Message
+			if q.timestamps {
+				line = entry.Time.UTC().Format(time.RFC3339Nano) + " " + line
+			}
+			io.WriteString(out, line)
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+	var matching []LogEntry
+	for _, entry := range entries {
+		if q.matches(entry) {
+			matching = append(matching, entry)
+		}
+	}
+	if q.tail >= 0 && len(matching) > q.tail {
+		matching = matching[len(matching)-q.tail:]
+	}
+	write(matching)
+	if !q.follow {
+		return true
+	}
+	offset := len(entries)
+	for {
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return true
+		case <-time.After(logFollowInterval):
+		}
+		// the state is checked first, so the entries appended before the
+		// container stopped are sent
+		s.cMut.RLock()
+		stopped := !container.State.Running
+		s.cMut.RUnlock()
+		entries, changed, _ = s.containerLogEntries(container.ID, offset)
+		offset += len(entries)
+		write(entries)
+		if stopped || (!q.until.IsZero() && time.Now().After(q.until)) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func newLogsTestServer(t *testing.T) (*DockerServer, *docker.Client, *docker.Container) {
+	t.Helper()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := client.PullImage(docker.PullImageOptions{Repository: "busybox", OutputStream: &buf}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{Name: "logger", Config: &docker.Config{Image: "busybox"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, client, container
+}
+
+func TestContainerLogs(t *testing.T) {
+	t.Parallel()
+	server, client, container := newLogsTestServer(t)
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	err := server.AppendContainerLogs(container.ID,
+		LogEntry{Time: base, Message: "one"},
+		LogEntry{Time: base.Add(time.Second), Stream: "stderr", Message: "two"},
+		LogEntry{Time: base.Add(2 * time.Second), Message: "three"},
+		LogEntry{Time: base.Add(3 * time.Second), Message: "four"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name           string
+		opts           docker.LogsOptions
+		stdout, stderr string
+	}{
+		{
+			name:   "all",
+			opts:   docker.LogsOptions{Stdout: true, Stderr: true},
+			stdout: "one\nthree\nfour\n",
+			stderr: "two\n",
+		},
+		{
+			name:   "stdout only, tail",
+			opts:   docker.LogsOptions{Stdout: true, Tail: "2"},
+			stdout: "three\nfour\n",
+		},
+		{
+			name:   "since",
+			opts:   docker.LogsOptions{Stdout: true, Stderr: true, Since: base.Add(time.Second).Unix()},
+			stdout: "three\nfour\n",
+			stderr: "two\n",
+		},
+		{
+			name:   "timestamps",
+			opts:   docker.LogsOptions{Stderr: true, Timestamps: true},
+			stderr: "2024-05-01T10:00:01Z two\n",
+		},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		tt.opts.Container = "logger"
+		tt.opts.OutputStream = &stdout
+		tt.opts.ErrorStream = &stderr
+		if err := client.Logs(tt.opts); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if stdout.String() != tt.stdout || stderr.String() != tt.stderr {
+			t.Errorf("%s: wrong output. Want %q and %q. Got %q and %q.", tt.name, tt.stdout, tt.stderr, stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestContainerLogsFollow(t *testing.T) {
+	t.Parallel()
+	server, client, container := newLogsTestServer(t)
+	if err := client.StartContainer(container.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.AppendContainerLogs(container.ID, LogEntry{Message: "starting"}); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Logs(docker.LogsOptions{Container: container.ID, Stdout: true, Follow: true, OutputStream: &stdout})
+	}()
+	for i := 0; i < 3; i++ {
+		time.Sleep(5 * time.Millisecond)
+		if err := server.AppendContainerLogs(container.ID, LogEntry{Message: "line " + strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := server.MutateContainer(container.ID, docker.State{Running: false, StartedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Logs: follow didn't stop with the container")
+	}
+	expected := "starting\nline 0\nline 1\nline 2\n"
+	if stdout.String() != expected {
+		t.Errorf("Logs: wrong output. Want %q. Got %q.", expected, stdout.String())
+	}
+}
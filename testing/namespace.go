@@ -0,0 +1,86 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// NamespaceHeader is the header that routes a request to a namespace of the
+// server, as an alternative to the URL prefix of the namespace.
+const NamespaceHeader = "X-Docker-Test-Namespace"
+
+// namespacePrefix is the path prefix of the namespaces of a server.
+const namespacePrefix = "/ns/"
+
+// Namespace returns the namespace of the server with the given name,
+// creating it if needed. A namespace is a fake server with its own
+// containers, images, networks, volumes, failures and handlers, served by
+// the listener of s: parallel tests can use a namespace each, instead of
+// starting a server each.
+//
+// Clients reach the namespace through its URL, which adds a prefix to the
+// URL of s, or by sending the NamespaceHeader header to s. The header is the
+// only option when s listens on a unix socket, as the URL of the namespace
+// is empty then. The namespace uses the hook s has when it's created.
+func (s *DockerServer) Namespace(name string) (*DockerServer, error) {
+	if s.parent != nil {
+		return s.parent.Namespace(name)
+	}
+	if !nameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("invalid namespace name %q", name)
+	}
+	s.nsMut.Lock()
+	defer s.nsMut.Unlock()
+	if ns, ok := s.namespaces[name]; ok {
+		return ns, nil
+	}
+	ns := baseDockerServer()
+	ns.hook = s.hook
+	ns.parent = s
+	ns.namespace = name
+	ns.buildMuxer()
+	if s.namespaces == nil {
+		s.namespaces = make(map[string]*DockerServer)
+	}
+	s.namespaces[name] = &ns
+	return &ns, nil
+}
+
+// RemoveNamespace discards the namespace with the given name and its state.
+// Later requests to the namespace fail with 404 Not Found.
+func (s *DockerServer) RemoveNamespace(name string) {
+	if s.parent != nil {
+		s.parent.RemoveNamespace(name)
+		return
+	}
+	s.nsMut.Lock()
+	defer s.nsMut.Unlock()
+	delete(s.namespaces, name)
+}
+
+// serveNamespace serves the request in its namespace, if it's meant for
+// one, returning whether it did.
+func (s *DockerServer) serveNamespace(w http.ResponseWriter, r *http.Request) bool {
+	name, prefix := r.Header.Get(NamespaceHeader), ""
+	if rest, ok := strings.CutPrefix(r.URL.Path, namespacePrefix); ok {
+		name, _, _ = strings.Cut(rest, "/")
+		prefix = namespacePrefix + name
+	}
+	if name == "" || s.parent != nil {
+		return false
+	}
+	s.nsMut.Lock()
+	ns, ok := s.namespaces[name]
+	s.nsMut.Unlock()
+	if !ok {
+		http.Error(w, "no such namespace: "+name, http.StatusNotFound)
+		return true
+	}
+	http.StripPrefix(prefix, ns).ServeHTTP(w, r)
+	return true
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestNamespaceIsolation(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	ns1, err := server.Namespace("first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := server.Namespace("first"); again != ns1 {
+		t.Error("Namespace: expected the existing namespace to be returned")
+	}
+	if expected := server.URL() + "ns/first/"; ns1.URL() != expected {
+		t.Errorf("Namespace: wrong URL. Want %q. Got %q.", expected, ns1.URL())
+	}
+	client1, err := docker.NewClient(ns1.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := client1.PullImage(docker.PullImageOptions{Repository: "busybox", OutputStream: &buf}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client1.CreateContainer(docker.CreateContainerOptions{Name: "web", Config: &docker.Config{Image: "busybox"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := server.Namespace("second"); err != nil {
+		t.Fatal(err)
+	}
+	client2, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client2.HTTPClient.Transport = headerTransport{name: "second"}
+	containers, err := client2.ListContainers(docker.ListContainersOptions{All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 0 {
+		t.Errorf("Namespace: containers leaked between namespaces: %#v.", containers)
+	}
+	if _, err := client2.InspectImage("busybox"); err == nil {
+		t.Error("Namespace: images leaked between namespaces")
+	}
+	rootClient, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if containers, err := rootClient.ListContainers(docker.ListContainersOptions{All: true}); err != nil || len(containers) != 0 {
+		t.Errorf("Namespace: containers leaked to the root server: %#v, %v.", containers, err)
+	}
+	if containers, err := client1.ListContainers(docker.ListContainersOptions{All: true}); err != nil || len(containers) != 1 {
+		t.Errorf("Namespace: wrong containers: %#v, %v.", containers, err)
+	}
+
+	server.RemoveNamespace("first")
+	_, err = client1.ListContainers(docker.ListContainersOptions{})
+	var apiErr *docker.Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+		t.Errorf("RemoveNamespace: wrong error. Want 404. Got %#v.", err)
+	}
+}
+
+func TestNamespaceInvalidName(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	if _, err := server.Namespace("a/b"); err == nil {
+		t.Error("Namespace: expected an error for an invalid name")
+	}
+}
+
+type headerTransport struct {
+	name string
+}
+
+func (t headerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set(NamespaceHeader, t.name)
+	return http.DefaultTransport.RoundTrip(r)
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// The ephemeral port range used by the server unless another one is set
+// with SetPortRange. It's the default range of Linux.
+const (
+	DefaultPortRangeStart = 32768
+	DefaultPortRangeEnd   = 60999
+)
+
+// SetPortRange sets the range of host ports the server allocates to the
+// container ports published without an explicit host port.
+func (s *DockerServer) SetPortRange(start, end int) error {
+	if start <= 0 || end > 65535 || start > end {
+		return fmt.Errorf("invalid port range %d-%d", start, end)
+	}
+	s.cMut.Lock()
+	defer s.cMut.Unlock()
+	s.portRangeStart, s.portRangeEnd = start, end
+	s.nextPort = 0
+	return nil
+}
+
+// allocatePorts returns the port mappings of a container, honoring the host
+// ports requested in bindings and allocating the other ones from the port
+// range of the server. Exposed ports without bindings are published too.
+// It must be called with cMut held.
+func (s *DockerServer) allocatePorts(exposed map[docker.Port]struct{}, bindings map[docker.Port][]docker.PortBinding) (map[docker.Port][]docker.PortBinding, error) {
+	ports := make(map[docker.Port][]docker.PortBinding)
+	used := s.usedHostPorts("")
+	for port, items := range bindings {
+		mapped := make([]docker.PortBinding, len(items))
+		for i, item := range items {
+			binding := docker.PortBinding{HostIP: item.HostIP, HostPort: item.HostPort}
+			if binding.HostIP == "" {
+				binding.HostIP = "0.0.0.0"
+			}
+			start, end := s.portRange()
+			if binding.HostPort != "" {
+				first, last, isRange := strings.Cut(binding.HostPort, "-")
+				var err error
+				if start, err = strconv.Atoi(first); err != nil {
+					return nil, fmt.Errorf("invalid host port %q", binding.HostPort)
+				}
+				end = start
+				if isRange {
+					if end, err = strconv.Atoi(last); err != nil || end < start {
+						return nil, fmt.Errorf("invalid host port range %q", binding.HostPort)
+					}
+				}
+			}
+			if binding.HostPort == "" || start != end {
+				hostPort, err := s.nextFreePort(start, end, port.Proto(), used)
+				if err != nil {
+					return nil, err
+				}
+				binding.HostPort = strconv.Itoa(hostPort)
+			}
+			used[port.Proto()+"/"+binding.HostPort] = true
+			mapped[i] = binding
+		}
+		ports[port] = mapped
+	}
+	for port := range exposed {
+		if _, ok := ports[port]; ok {
+			continue
+		}
+		start, end := s.portRange()
+		hostPort, err := s.nextFreePort(start, end, port.Proto(), used)
+		if err != nil {
+			return nil, err
+		}
+		used[port.Proto()+"/"+strconv.Itoa(hostPort)] = true
+		ports[port] = []docker.PortBinding{{HostIP: "0.0.0.0", HostPort: strconv.Itoa(hostPort)}}
+	}
+	return ports, nil
+}
+
+func (s *DockerServer) portRange() (start, end int) {
+	if s.portRangeStart == 0 {
+		return DefaultPortRangeStart, DefaultPortRangeEnd
+	}
+	return s.portRangeStart, s.portRangeEnd
+}
+
+// nextFreePort returns a port between start and end that isn't in used.
+// Ports of the server range are allocated sequentially, like the daemon
+// does.
+func (s *DockerServer) nextFreePort(start, end int, proto string, used map[string]bool) (int, error) {
+	rangeStart, rangeEnd := s.portRange()
+	sequential := start == rangeStart && end == rangeEnd
+	first := start
+	if sequential && s.nextPort >= start && s.nextPort <= end {
+		first = s.nextPort
+	}
+	for i := 0; i <= end-start; i++ {
+		port := start + (first-start+i)%(end-start+1)
+		if !used[proto+"/"+strconv.Itoa(port)] {
+			if sequential {
+				s.nextPort = port + 1
+			}
+			return port, nil
+		}
+	}
+	return 0, errors.New("all ports are allocated")
+}
+
+// usedHostPorts returns the host ports bound by the running containers,
+// except the one with the given ID, as proto/port keys. It must be called
+// with cMut held.
+func (s *DockerServer) usedHostPorts(exceptID string) map[string]bool {
+	used := make(map[string]bool)
+	for id, container := range s.containers {
+		if id == exceptID || !container.State.Running || container.NetworkSettings == nil {
+			continue
+		}
+		for port, bindings := range container.NetworkSettings.Ports {
+			for _, binding := range bindings {
+				used[port.Proto()+"/"+binding.HostPort] = true
+			}
+		}
+	}
+	return used
+}
+
+// checkPortConflicts returns an error, like the one returned by the daemon,
+// if a host port of the container is bound by another running container. It
+// must be called with cMut held.
+func (s *DockerServer) checkPortConflicts(container *docker.Container) error {
+	if container.NetworkSettings == nil {
+		return nil
+	}
+	used := s.usedHostPorts(container.ID)
+	for port, bindings := range container.NetworkSettings.Ports {
+		for _, binding := range bindings {
+			if used[port.Proto()+"/"+binding.HostPort] {
+				return fmt.Errorf("driver failed programming external connectivity on endpoint %s (%s): Bind for %s:%s failed: port is already allocated",
+					strings.TrimPrefix(container.Name, "/"), container.ID, binding.HostIP, binding.HostPort)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func newPortsTestServer(t *testing.T) (*DockerServer, *docker.Client) {
+	t.Helper()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := client.PullImage(docker.PullImageOptions{Repository: "busybox", OutputStream: &buf}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	return server, client
+}
+
+func createPortsContainer(t *testing.T, client *docker.Client, name string, bindings map[docker.Port][]docker.PortBinding) *docker.Container {
+	t.Helper()
+	container, err := client.CreateContainer(docker.CreateContainerOptions{
+		Name:       name,
+		Config:     &docker.Config{Image: "busybox", ExposedPorts: map[docker.Port]struct{}{"80/tcp": {}}},
+		HostConfig: &docker.HostConfig{PortBindings: bindings},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return container
+}
+
+func TestContainerPortBindings(t *testing.T) {
+	t.Parallel()
+	server, client := newPortsTestServer(t)
+	if err := server.SetPortRange(40000, 40001); err != nil {
+		t.Fatal(err)
+	}
+	container := createPortsContainer(t, client, "web", map[docker.Port][]docker.PortBinding{
+		"8080/tcp": {{HostIP: "127.0.0.1", HostPort: "18080"}},
+		"53/udp":   {{}},
+	})
+	if err := client.StartContainer(container.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.InspectContainerWithOptions(docker.InspectContainerOptions{ID: container.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ports := container.NetworkSettings.Ports
+	if got := ports["8080/tcp"]; len(got) != 1 || got[0] != (docker.PortBinding{HostIP: "127.0.0.1", HostPort: "18080"}) {
+		t.Errorf("wrong binding of 8080/tcp: %#v", got)
+	}
+	for _, port := range []docker.Port{"53/udp", "80/tcp"} {
+		got := ports[port]
+		if len(got) != 1 || got[0].HostIP != "0.0.0.0" {
+			t.Fatalf("wrong binding of %s: %#v", port, got)
+		}
+		if n, _ := strconv.Atoi(got[0].HostPort); n < 40000 || n > 40001 {
+			t.Errorf("host port of %s out of range: %s", port, got[0].HostPort)
+		}
+	}
+}
+
+func TestContainerPortConflict(t *testing.T) {
+	t.Parallel()
+	_, client := newPortsTestServer(t)
+	bindings := map[docker.Port][]docker.PortBinding{"80/tcp": {{HostPort: "8080"}}}
+	first := createPortsContainer(t, client, "first", bindings)
+	second := createPortsContainer(t, client, "second", bindings)
+	if err := client.StartContainer(first.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	err := client.StartContainer(second.ID, nil)
+	var e *docker.Error
+	if !errors.As(err, &e) || e.Status != http.StatusInternalServerError {
+		t.Fatalf("wrong error: %v", err)
+	}
+	if !strings.Contains(e.Message, "Bind for 0.0.0.0:8080 failed: port is already allocated") {
+		t.Errorf("wrong error message: %q", e.Message)
+	}
+	container, err := client.InspectContainerWithOptions(docker.InspectContainerOptions{ID: second.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if container.State.Running {
+		t.Error("container started despite the conflict")
+	}
+	if err := client.StopContainer(first.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.StartContainer(second.ID, nil); err != nil {
+		t.Errorf("port not released on stop: %v", err)
+	}
+}
+
+func TestContainerPortRangeExhausted(t *testing.T) {
+	t.Parallel()
+	server, client := newPortsTestServer(t)
+	if err := server.SetPortRange(40000, 40000); err != nil {
+		t.Fatal(err)
+	}
+	first := createPortsContainer(t, client, "first", nil)
+	if err := client.StartContainer(first.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err := client.CreateContainer(docker.CreateContainerOptions{
+		Name:   "second",
+		Config: &docker.Config{Image: "busybox", ExposedPorts: map[docker.Port]struct{}{"80/tcp": {}}},
+	})
+	var e *docker.Error
+	if !errors.As(err, &e) || e.Status != http.StatusInternalServerError {
+		t.Errorf("wrong error: %v", err)
+	}
+}
+
+func TestSetPortRangeInvalid(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	for _, r := range [][2]int{{0, 10}, {100, 10}, {1000, 70000}} {
+		if err := server.SetPortRange(r[0], r[1]); err == nil {
+			t.Errorf("SetPortRange(%d, %d): expected error", r[0], r[1])
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// ExpectRegistryAuth makes the server require the given credentials, in the
+// X-Registry-Auth header, to pull or push the given repository (an image
+// name without tag or digest, such as "registry.example.com/team/app").
+// Requests with missing or different credentials fail with 401
+// Unauthorized.
+//
+// The username, password, identity token and registry token must match.
+// The server address must match too, unless it's empty in auth.
+func (s *DockerServer) ExpectRegistryAuth(repository string, auth docker.AuthConfiguration) {
+	s.iMut.Lock()
+	defer s.iMut.Unlock()
+	if s.registryAuth == nil {
+		s.registryAuth = make(map[string]docker.AuthConfiguration)
+	}
+	s.registryAuth[repository] = auth
+}
+
+// ResetRegistryAuth removes the credentials required by the server for all
+// repositories.
+func (s *DockerServer) ResetRegistryAuth() {
+	s.iMut.Lock()
+	defer s.iMut.Unlock()
+	s.registryAuth = nil
+}
+
+// checkRegistryAuth writes a 401 Unauthorized response and returns false
+// when the request doesn't carry the credentials expected for the
+// repository of the image.
+func (s *DockerServer) checkRegistryAuth(w http.ResponseWriter, r *http.Request, image string) bool {
+	repository := imageRepository(image)
+	s.iMut.RLock()
+	expected, ok := s.registryAuth[repository]
+	s.iMut.RUnlock()
+	if !ok {
+		return true
+	}
+	var auth docker.AuthConfiguration
+	if header := r.Header.Get("X-Registry-Auth"); header != "" {
+		data, err := base64.URLEncoding.DecodeString(header)
+		if err == nil {
+			err = json.Unmarshal(data, &auth)
+		}
+		if err != nil {
+			http.Error(w, "invalid X-Registry-Auth header: "+err.Error(), http.StatusBadRequest)
+			return false
+		}
+	}
+	if expected.ServerAddress == "" {
+		auth.ServerAddress = ""
+	}
+	auth.Email, expected.Email = "", ""
+	if auth != expected {
+		http.Error(w, "unauthorized: authentication required for "+repository, http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// imageRepository returns the name of image without its tag or digest.
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestExpectRegistryAuth(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const repository = "registry.example.com:5000/team/app"
+	valid := docker.AuthConfiguration{Username: "user", Password: "secret", ServerAddress: "registry.example.com:5000"}
+	server.ExpectRegistryAuth(repository, docker.AuthConfiguration{Username: "user", Password: "secret"})
+
+	var buf bytes.Buffer
+	pull := docker.PullImageOptions{Repository: repository, Tag: "v1", OutputStream: &buf}
+	for name, auth := range map[string]docker.AuthConfiguration{
+		"missing":        {},
+		"wrong password": {Username: "user", Password: "wrong"},
+	} {
+		err := client.PullImage(pull, auth)
+		var apiErr *docker.Error
+		if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+			t.Errorf("PullImage (%s): wrong error. Want 401. Got %#v.", name, err)
+		}
+	}
+	if err := client.PullImage(pull, valid); err != nil {
+		t.Fatalf("PullImage: unexpected error: %v", err)
+	}
+	// other repositories don't require credentials
+	if err := client.PullImage(docker.PullImageOptions{Repository: "busybox", OutputStream: &buf}, docker.AuthConfiguration{}); err != nil {
+		t.Errorf("PullImage: unexpected error: %v", err)
+	}
+
+	push := docker.PushImageOptions{Name: repository, Tag: "v1", OutputStream: &buf}
+	err = client.PushImage(push, docker.AuthConfiguration{Username: "other", Password: "secret"})
+	var apiErr *docker.Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		t.Errorf("PushImage: wrong error. Want 401. Got %#v.", err)
+	}
+	if err := client.PushImage(push, valid); err != nil {
+		t.Errorf("PushImage: unexpected error: %v", err)
+	}
+
+	server.ResetRegistryAuth()
+	if err := client.PushImage(push, docker.AuthConfiguration{}); err != nil {
+		t.Errorf("PushImage after ResetRegistryAuth: unexpected error: %v", err)
+	}
+}
+
+func TestImageRepository(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"busybox":                          "busybox",
+		"busybox:latest":                   "busybox",
+		"localhost:5000/app":               "localhost:5000/app",
+		"localhost:5000/app:v1":            "localhost:5000/app",
+		"app@sha256:abc":                   "app",
+		"localhost:5000/app:v1@sha256:abc": "localhost:5000/app",
+	}
+	for image, expected := range tests {
+		if got := imageRepository(image); got != expected {
+			t.Errorf("imageRepository(%q): want %q, got %q", image, expected, got)
+		}
+	}
+}
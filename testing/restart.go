@@ -0,0 +1,132 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/gorilla/mux"
+)
+
+// DefaultRestartDelay is how long the server waits before restarting a
+// killed container, according to its restart policy, unless another delay is
+// set with SetRestartDelay.
+const DefaultRestartDelay = 100 * time.Millisecond
+
+// signalNumbers maps the signals accepted by the kill endpoint to their
+// numbers, which define the exit code of the killed container.
+var signalNumbers = map[string]int{
+	"HUP":  1,
+	"INT":  2,
+	"QUIT": 3,
+	"KILL": 9,
+	"USR1": 10,
+	"USR2": 12,
+	"TERM": 15,
+}
+
+// SetRestartDelay sets how long the server waits before restarting a
+// killed container whose restart policy asks for it. Containers are killed
+// through the kill endpoint, manually stopped containers are never
+// restarted.
+func (s *DockerServer) SetRestartDelay(delay time.Duration) {
+	s.cMut.Lock()
+	defer s.cMut.Unlock()
+	s.restartDelay = delay
+}
+
+func (s *DockerServer) killContainer(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	container, err := s.findContainer(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	signal := strings.TrimPrefix(strings.ToUpper(r.URL.Query().Get("signal")), "SIG")
+	number, ok := signalNumbers[signal]
+	if signal == "" {
+		number, ok = signalNumbers["KILL"], true
+	} else if !ok {
+		number, err = strconv.Atoi(signal)
+		ok = err == nil && number > 0
+	}
+	if !ok {
+		http.Error(w, "Invalid signal: "+r.URL.Query().Get("signal"), http.StatusBadRequest)
+		return
+	}
+	s.cMut.Lock()
+	defer s.cMut.Unlock()
+	if !container.State.Running {
+		http.Error(w, "Container not running", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	container.State.Running = false
+	container.State.ExitCode = 128 + number
+	container.State.FinishedAt = time.Now()
+	s.notify(container)
+	if shouldRestart(container) {
+		s.scheduleRestartLocked(container)
+	}
+}
+
+// shouldRestart reports whether the restart policy of the container asks
+// for restarting it after it exited on its own or was killed.
+func shouldRestart(container *docker.Container) bool {
+	if container.HostConfig == nil {
+		return false
+	}
+	policy := container.HostConfig.RestartPolicy
+	switch policy.Name {
+	case "always", "unless-stopped":
+		return true
+	case "on-failure":
+		return container.State.ExitCode != 0 &&
+			(policy.MaximumRetryCount == 0 || container.RestartCount < policy.MaximumRetryCount)
+	}
+	return false
+}
+
+// scheduleRestartLocked marks the container as restarting and starts it
+// again after the restart delay. The caller must hold cMut.
+func (s *DockerServer) scheduleRestartLocked(container *docker.Container) {
+	if s.restartTimers == nil {
+		s.restartTimers = make(map[string]*time.Timer)
+	}
+	s.cancelRestartLocked(container.ID)
+	container.State.Restarting = true
+	var timer *time.Timer
+	timer = time.AfterFunc(s.restartDelay, func() {
+		s.cMut.Lock()
+		defer s.cMut.Unlock()
+		if s.restartTimers[container.ID] != timer {
+			return
+		}
+		delete(s.restartTimers, container.ID)
+		container.State.Restarting = false
+		container.State.Running = true
+		container.State.ExitCode = 0
+		container.State.StartedAt = time.Now()
+		container.RestartCount++
+		s.notify(container)
+	})
+	s.restartTimers[container.ID] = timer
+}
+
+// cancelRestartLocked cancels the pending restart of the container with the
+// given ID, if any. The caller must hold cMut.
+func (s *DockerServer) cancelRestartLocked(id string) {
+	if timer, ok := s.restartTimers[id]; ok {
+		timer.Stop()
+		delete(s.restartTimers, id)
+		if container, ok := s.containers[id]; ok {
+			container.State.Restarting = false
+		}
+	}
+}
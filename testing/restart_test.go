@@ -0,0 +1,144 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func newRestartTestServer(t *testing.T, policy docker.RestartPolicy) (*DockerServer, *docker.Client, *docker.Container) {
+	t.Helper()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+	server.SetRestartDelay(10 * time.Millisecond)
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PullImage(docker.PullImageOptions{Repository: "busybox", OutputStream: io.Discard}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{
+		Config:     &docker.Config{Image: "busybox"},
+		HostConfig: &docker.HostConfig{RestartPolicy: policy},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.StartContainer(container.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	return server, client, container
+}
+
+func waitRunning(t *testing.T, client *docker.Client, id string) *docker.Container {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		container, err := client.InspectContainer(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if container.State.Running {
+			return container
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("container %s not restarted: %#v", id, container.State)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestKillContainerRestartAlways(t *testing.T) {
+	t.Parallel()
+	_, client, container := newRestartTestServer(t, docker.AlwaysRestart())
+	for i := 1; i <= 2; i++ {
+		if err := client.KillContainer(docker.KillContainerOptions{ID: container.ID}); err != nil {
+			t.Fatal(err)
+		}
+		restarted := waitRunning(t, client, container.ID)
+		if restarted.RestartCount != i {
+			t.Errorf("KillContainer: wrong restart count. Want %d. Got %d.", i, restarted.RestartCount)
+		}
+		if restarted.State.Restarting {
+			t.Error("KillContainer: container still restarting after running again")
+		}
+	}
+}
+
+func TestKillContainerRestartOnFailureMaxRetries(t *testing.T) {
+	t.Parallel()
+	server, client, container := newRestartTestServer(t, docker.RestartOnFailure(1))
+	if err := client.KillContainer(docker.KillContainerOptions{ID: container.ID}); err != nil {
+		t.Fatal(err)
+	}
+	waitRunning(t, client, container.ID)
+	server.SetRestartDelay(0)
+	if err := client.KillContainer(docker.KillContainerOptions{ID: container.ID, Signal: docker.SIGTERM}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	killed, err := client.InspectContainer(container.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if killed.State.Running || killed.State.Restarting {
+		t.Errorf("KillContainer: container restarted past the maximum retry count: %#v", killed.State)
+	}
+	if killed.State.ExitCode != 143 {
+		t.Errorf("KillContainer: wrong exit code. Want 143. Got %d.", killed.State.ExitCode)
+	}
+}
+
+func TestStopContainerRestartAlways(t *testing.T) {
+	t.Parallel()
+	server, client, container := newRestartTestServer(t, docker.AlwaysRestart())
+	server.SetRestartDelay(time.Hour)
+	if err := client.KillContainer(docker.KillContainerOptions{ID: container.ID}); err != nil {
+		t.Fatal(err)
+	}
+	killed, err := client.InspectContainer(container.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !killed.State.Restarting || killed.State.ExitCode != 137 {
+		t.Errorf("KillContainer: wrong state. Want restarting with exit code 137. Got %#v.", killed.State)
+	}
+	// stopping a restarting container cancels the restart
+	if err := client.StopContainer(container.ID, 10); err != nil {
+		t.Fatal(err)
+	}
+	stopped, err := client.InspectContainer(container.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped.State.Running || stopped.State.Restarting {
+		t.Errorf("StopContainer: container still restarting: %#v", stopped.State)
+	}
+}
+
+func TestKillContainerNoRestartPolicy(t *testing.T) {
+	t.Parallel()
+	server, client, container := newRestartTestServer(t, docker.NeverRestart())
+	server.SetRestartDelay(0)
+	if err := client.KillContainer(docker.KillContainerOptions{ID: container.ID}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	killed, err := client.InspectContainer(container.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if killed.State.Running || killed.State.Restarting || killed.RestartCount != 0 {
+		t.Errorf("KillContainer: container restarted without restart policy: %#v", killed.State)
+	}
+}
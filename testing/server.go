@@ -45,6 +45,7 @@ type DockerServer struct {
 	containers     map[string]*docker.Container
 	contNameToID   map[string]string
 	uploadedFiles  map[string]string
+	changes        map[string][]docker.Change
 	execs          []*docker.ExecInspect
 	execMut        sync.RWMutex
 	cMut           sync.RWMutex
@@ -74,6 +75,22 @@ type DockerServer struct {
 	services       []*swarm.Service
 	nodeRR         int
 	servicePorts   int
+	nsMut          sync.Mutex
+	namespaces     map[string]*DockerServer
+	parent         *DockerServer
+	namespace      string
+	buildArgs      map[string]map[string]string
+	registryAuth   map[string]docker.AuthConfiguration
+	logMut         sync.Mutex
+	logs           map[string]*containerLogs
+	portRangeStart int
+	portRangeEnd   int
+	nextPort       int
+	versionMut     sync.RWMutex
+	apiVersion     docker.APIVersion
+	minAPIVersion  docker.APIVersion
+	restartDelay   time.Duration
+	restartTimers  map[string]*time.Timer
 }
 
 type volumeCounter struct {
@@ -92,6 +109,7 @@ func baseDockerServer() DockerServer {
 		statsCallbacks: make(map[string]func(string) docker.Stats),
 		customHandlers: make(map[string]http.Handler),
 		uploadedFiles:  make(map[string]string),
+		restartDelay:   DefaultRestartDelay,
 	}
 }
 
@@ -130,6 +148,10 @@ type TLSConfig struct {
 	CertPath    string
 	CertKeyPath string
 	RootCAPath  string
+
+	// RequireClientCert makes the server require clients to present a
+	// certificate signed by the CA in RootCAPath.
+	RequireClientCert bool
 }
 
 // NewTLSServer creates and starts a TLS-enabled testing server.
@@ -152,6 +174,12 @@ func NewTLSServer(bind string, containerChan chan<- *docker.Container, hook func
 		certsPool := x509.NewCertPool()
 		certsPool.AppendCertsFromPEM(rootCertPEM)
 		tlsServerConfig.RootCAs = certsPool
+		if tlsConfig.RequireClientCert {
+			tlsServerConfig.ClientCAs = certsPool
+			tlsServerConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	} else if tlsConfig.RequireClientCert {
+		return nil, errors.New("RequireClientCert requires RootCAPath")
 	}
 	tlsListener := tls.NewListener(listener, tlsServerConfig)
 	server := buildDockerServer(tlsListener, containerChan, hook)
@@ -159,6 +187,20 @@ func NewTLSServer(bind string, containerChan chan<- *docker.Container, hook func
 	return server, nil
 }
 
+// NewUnixServer creates and starts a testing server listening on the unix
+// socket at socketPath, which must not exist. The socket is removed when the
+// server is stopped. Use the method URL to get the unix:// URL of the
+// server.
+func NewUnixServer(socketPath string, containerChan chan<- *docker.Container, hook func(*http.Request)) (*DockerServer, error) {
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	server := buildDockerServer(listener, containerChan, hook)
+	go http.Serve(listener, server)
+	return server, nil
+}
+
 func (s *DockerServer) notify(container *docker.Container) {
 	if s.cChan != nil {
 		s.cChan <- container
@@ -169,6 +211,7 @@ func (s *DockerServer) buildMuxer() {
 	s.mux = mux.NewRouter()
 	s.addMuxerRoutes(s.mux)
 	sub := s.mux.PathPrefix("/{version:v[0-9]+\\.[0-9]+}").Subrouter()
+	sub.Use(s.checkAPIVersion)
 	s.addMuxerRoutes(sub)
 }
 
@@ -178,9 +221,10 @@ func (s *DockerServer) addMuxerRoutes(m *mux.Router) {
 	m.Path("/containers/create").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.createContainer))
 	m.Path("/containers/{id:.*}/json").Methods(http.MethodGet).HandlerFunc(s.handlerWrapper(s.inspectContainer))
 	m.Path("/containers/{id:.*}/rename").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.renameContainer))
+	m.Path("/containers/{id:.*}/changes").Methods(http.MethodGet).HandlerFunc(s.handlerWrapper(s.containerChanges))
 	m.Path("/containers/{id:.*}/top").Methods(http.MethodGet).HandlerFunc(s.handlerWrapper(s.topContainer))
 	m.Path("/containers/{id:.*}/start").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.startContainer))
-	m.Path("/containers/{id:.*}/kill").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.stopContainer))
+	m.Path("/containers/{id:.*}/kill").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.killContainer))
 	m.Path("/containers/{id:.*}/stop").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.stopContainer))
 	m.Path("/containers/{id:.*}/pause").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.pauseContainer))
 	m.Path("/containers/{id:.*}/unpause").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.unpauseContainer))
@@ -211,6 +255,7 @@ func (s *DockerServer) addMuxerRoutes(m *mux.Router) {
 	m.Path("/networks/{id:.*}").Methods(http.MethodDelete).HandlerFunc(s.handlerWrapper(s.removeNetwork))
 	m.Path("/networks/create").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.createNetwork))
 	m.Path("/networks/{id:.*}/connect").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.networksConnect))
+	m.Path("/networks/{id:.*}/disconnect").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.networksDisconnect))
 	m.Path("/volumes").Methods(http.MethodGet).HandlerFunc(s.handlerWrapper(s.listVolumes))
 	m.Path("/volumes/create").Methods(http.MethodPost).HandlerFunc(s.handlerWrapper(s.createVolume))
 	m.Path("/volumes/{name:.*}").Methods(http.MethodGet).HandlerFunc(s.handlerWrapper(s.inspectVolume))
@@ -329,18 +374,37 @@ func (s *DockerServer) Stop() {
 	if s.swarmServer != nil {
 		s.swarmServer.listener.Close()
 	}
+	s.cMut.Lock()
+	for id := range s.restartTimers {
+		s.cancelRestartLocked(id)
+	}
+	s.cMut.Unlock()
 }
 
 // URL returns the HTTP URL of the server.
 func (s *DockerServer) URL() string {
+	if s.parent != nil {
+		if url := s.parent.URL(); strings.HasPrefix(url, "http://") {
+			return url + strings.TrimPrefix(namespacePrefix, "/") + s.namespace + "/"
+		}
+		return ""
+	}
 	if s.listener == nil {
 		return ""
 	}
+	if addr := s.listener.Addr(); addr.Network() == "unix" {
+		return "unix://" + addr.String()
+	}
 	return "http://" + s.listener.Addr().String() + "/"
 }
 
 // ServeHTTP handles HTTP requests sent to the server.
 func (s *DockerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.serveNamespace(w, r) {
+		return
+	}
+	apiVersion, _, _ := s.reportedAPIVersions()
+	w.Header().Set("Api-Version", apiVersion.String())
 	s.handlerMutex.RLock()
 	defer s.handlerMutex.RUnlock()
 	for re, handler := range s.customHandlers {
@@ -444,22 +508,23 @@ loop:
 }
 
 func (s *DockerServer) listImages(w http.ResponseWriter, r *http.Request) {
-	s.cMut.RLock()
-	result := make([]docker.APIImages, len(s.images))
-	i := 0
+	filters := make(map[string][]string)
+	json.Unmarshal([]byte(r.FormValue("filters")), &filters)
+	s.iMut.RLock()
+	result := make([]docker.APIImages, 0, len(s.images))
 	for _, image := range s.images {
-		result[i] = docker.APIImages{
-			ID:      image.ID,
-			Created: image.Created.Unix(),
-		}
-		for tag, id := range s.imgIDs {
-			if id == image.ID {
-				result[i].RepoTags = append(result[i].RepoTags, tag)
-			}
+		tags, digests := s.imageRefsLocked(image.ID)
+		if !matchImageFilters(filters, tags) {
+			continue
 		}
-		i++
+		result = append(result, docker.APIImages{
+			ID:          image.ID,
+			Created:     image.Created.Unix(),
+			RepoTags:    tags,
+			RepoDigests: digests,
+		})
 	}
-	s.cMut.RUnlock()
+	s.iMut.RUnlock()
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(result)
@@ -468,9 +533,8 @@ func (s *DockerServer) listImages(w http.ResponseWriter, r *http.Request) {
 func (s *DockerServer) findImage(id string) (string, error) {
 	s.iMut.RLock()
 	defer s.iMut.RUnlock()
-	image, ok := s.imgIDs[id]
-	if ok {
-		return image, nil
+	if ref, ok := s.lookupImageRefLocked(id); ok {
+		return s.imgIDs[ref], nil
 	}
 	if _, ok := s.images[id]; ok {
 		return id, nil
@@ -499,14 +563,6 @@ func (s *DockerServer) createContainer(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
-	ports := map[docker.Port][]docker.PortBinding{}
-	for port := range config.ExposedPorts {
-		ports[port] = []docker.PortBinding{{
-			HostIP:   "0.0.0.0",
-			HostPort: strconv.Itoa(mathrand.Int() % 0xffff),
-		}}
-	}
-
 	// the container may not have cmd when using a Dockerfile
 	var path string
 	var args []string
@@ -538,10 +594,19 @@ func (s *DockerServer) createContainer(w http.ResponseWriter, r *http.Request) {
 			IPPrefixLen: 24,
 			Gateway:     "172.16.42.1",
 			Bridge:      "docker0",
-			Ports:       ports,
 		},
 	}
 	s.cMut.Lock()
+	var bindings map[docker.Port][]docker.PortBinding
+	if config.HostConfig != nil {
+		bindings = config.HostConfig.PortBindings
+	}
+	container.NetworkSettings.Ports, err = s.allocatePorts(config.ExposedPorts, bindings)
+	if err != nil {
+		s.cMut.Unlock()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	if val, ok := s.uploadedFiles[imageID]; ok {
 		s.uploadedFiles[container.ID] = val
 	}
@@ -574,6 +639,14 @@ func (s *DockerServer) generateID() string {
 	return fmt.Sprintf("%x", buf)
 }
 
+// generateImageID returns a random image ID in the format of the daemon: 64
+// hex characters, like a sha256 digest.
+func (s *DockerServer) generateImageID() string {
+	var buf [32]byte
+	rand.Read(buf[:])
+	return fmt.Sprintf("%x", buf)
+}
+
 func (s *DockerServer) renameContainer(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 	s.cMut.Lock()
@@ -706,38 +779,36 @@ func (s *DockerServer) startContainer(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "", http.StatusNotModified)
 		return
 	}
+	s.cancelRestartLocked(container.ID)
 	var hostConfig *docker.HostConfig
 	err = json.NewDecoder(r.Body).Decode(&hostConfig)
 	if err != nil && !errors.Is(err, io.EOF) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if container.NetworkSettings == nil {
+		container.NetworkSettings = &docker.NetworkSettings{}
+	}
+	// the ports were allocated on creation, unless the bindings are
+	// changed on start
+	ports := container.NetworkSettings.Ports
 	if hostConfig == nil {
 		hostConfig = container.HostConfig
-	} else {
-		container.HostConfig = hostConfig
-	}
-	if hostConfig != nil && len(hostConfig.PortBindings) > 0 {
-		ports := map[docker.Port][]docker.PortBinding{}
-		for key, items := range hostConfig.PortBindings {
-			bindings := make([]docker.PortBinding, len(items))
-			for i := range items {
-				binding := docker.PortBinding{
-					HostIP:   items[i].HostIP,
-					HostPort: items[i].HostPort,
-				}
-				if binding.HostIP == "" {
-					binding.HostIP = "0.0.0.0"
-				}
-				if binding.HostPort == "" {
-					binding.HostPort = strconv.Itoa(mathrand.Int() % 0xffff)
-				}
-				bindings[i] = binding
-			}
-			ports[key] = bindings
+	} else if len(hostConfig.PortBindings) > 0 {
+		ports, err = s.allocatePorts(nil, hostConfig.PortBindings)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
 		}
-		container.NetworkSettings.Ports = ports
 	}
+	previousPorts := container.NetworkSettings.Ports
+	container.NetworkSettings.Ports = ports
+	if err := s.checkPortConflicts(container); err != nil {
+		container.NetworkSettings.Ports = previousPorts
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	container.HostConfig = hostConfig
 	container.State.Running = true
 	container.State.StartedAt = time.Now()
 	s.notify(container)
@@ -752,11 +823,13 @@ func (s *DockerServer) stopContainer(w http.ResponseWriter, r *http.Request) {
 	}
 	s.cMut.Lock()
 	defer s.cMut.Unlock()
-	if !container.State.Running {
+	if !container.State.Running && !container.State.Restarting {
 		http.Error(w, "Container not running", http.StatusBadRequest)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
+	// manually stopped containers are never restarted
+	s.cancelRestartLocked(container.ID)
 	container.State.Running = false
 	s.notify(container)
 }
@@ -885,8 +958,10 @@ func (s *DockerServer) removeContainer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
+	s.cancelRestartLocked(container.ID)
 	delete(s.containers, container.ID)
 	delete(s.contNameToID, container.Name)
+	delete(s.changes, container.ID)
 }
 
 func (s *DockerServer) commitContainer(w http.ResponseWriter, r *http.Request) {
@@ -907,7 +982,8 @@ func (s *DockerServer) commitContainer(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusOK)
 	image := docker.Image{
-		ID:        "img-" + container.ID,
+		ID:        s.generateImageID(),
+		Created:   time.Now(),
 		Parent:    container.Image,
 		Container: container.ID,
 		Comment:   r.URL.Query().Get("m"),
@@ -922,7 +998,7 @@ func (s *DockerServer) commitContainer(w http.ResponseWriter, r *http.Request) {
 		if tag != "" {
 			repository += ":" + tag
 		}
-		s.imgIDs[repository] = image.ID
+		s.setImageRefLocked(repository, image.ID)
 	}
 	s.iMut.Unlock()
 	s.cMut.Lock()
@@ -958,6 +1034,9 @@ func (s *DockerServer) logContainer(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	if s.serveContainerLogs(w, r, container) {
+		return
+	}
 	w.Header().Set("Content-Type", "application/vnd.docker.raw-stream")
 	w.WriteHeader(http.StatusOK)
 	s.cMut.RLock()
@@ -983,7 +1062,33 @@ func (s *DockerServer) logContainer(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *DockerServer) buildImage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var buildArgs, labels map[string]string
+	if value := query.Get("buildargs"); value != "" {
+		if err := json.Unmarshal([]byte(value), &buildArgs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("labels"); value != "" {
+		if err := json.Unmarshal([]byte(value), &labels); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	// the Dockerfile is only parsed for FROM, ARG, LABEL and EXPOSE, as
+	// we are a fake Docker daemon
+	image := docker.Image{
+		ID:      s.generateImageID(),
+		Created: time.Now(),
+		Config:  &docker.Config{Labels: make(map[string]string)},
+	}
 	if ct := r.Header.Get("Content-Type"); ct == "application/tar" {
+		dockerfileName := query.Get("dockerfile")
+		if dockerfileName == "" {
+			dockerfileName = "Dockerfile"
+		}
+		var dockerfile []byte
 		gotDockerFile := false
 		tr := tar.NewReader(r.Body)
 		for {
@@ -991,8 +1096,9 @@ func (s *DockerServer) buildImage(w http.ResponseWriter, r *http.Request) {
 			if err != nil {
 				break
 			}
-			if header.Name == "Dockerfile" {
+			if header.Name == dockerfileName {
 				gotDockerFile = true
+				dockerfile, _ = io.ReadAll(tr)
 			}
 		}
 		if !gotDockerFile {
@@ -1000,25 +1106,77 @@ func (s *DockerServer) buildImage(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte("miss Dockerfile"))
 			return
 		}
+		stages, err := parseDockerfile(string(dockerfile), buildArgs)
+		if err != nil {
+			http.Error(w, "Dockerfile parse error: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		stage := stages[len(stages)-1]
+		if target := query.Get("target"); target != "" {
+			stage = nil
+			for _, candidate := range stages {
+				if candidate.name == target {
+					stage = candidate
+				}
+			}
+			if stage == nil {
+				http.Error(w, fmt.Sprintf("target stage %q could not be found", target), http.StatusBadRequest)
+				return
+			}
+		}
+		image.Config.Image = stage.from
+		image.Config.Labels = stage.labels
+		if len(stage.exposedPorts) > 0 {
+			image.Config.ExposedPorts = stage.exposedPorts
+		}
+		s.iMut.RLock()
+		if ref, ok := s.lookupImageRefLocked(stage.from); ok {
+			image.Parent = s.imgIDs[ref]
+		}
+		s.iMut.RUnlock()
 	}
-	// we did not use that Dockerfile to build image cause we are a fake Docker daemon
-	image := docker.Image{
-		ID:      s.generateID(),
-		Created: time.Now(),
+	for k, v := range labels {
+		image.Config.Labels[k] = v
 	}
 
-	query := r.URL.Query()
 	repository := image.ID
 	if t := query.Get("t"); t != "" {
 		repository = t
 	}
 	s.iMut.Lock()
 	s.images[image.ID] = image
-	s.imgIDs[repository] = image.ID
+	s.setImageRefLocked(repository, image.ID)
+	if len(buildArgs) > 0 {
+		if s.buildArgs == nil {
+			s.buildArgs = make(map[string]map[string]string)
+		}
+		s.buildArgs[image.ID] = buildArgs
+	}
 	s.iMut.Unlock()
 	fmt.Fprintf(w, "Successfully built %s", image.ID)
 }
 
+// ImageBuildArgs returns the build args sent to the server when building
+// the given image, by name or ID, and whether the image was built by the
+// server.
+func (s *DockerServer) ImageBuildArgs(name string) (map[string]string, bool) {
+	s.iMut.RLock()
+	defer s.iMut.RUnlock()
+	id := name
+	if ref, ok := s.lookupImageRefLocked(name); ok {
+		id = s.imgIDs[ref]
+	}
+	image, ok := s.images[id]
+	if !ok || image.Config == nil {
+		return nil, false
+	}
+	args := make(map[string]string, len(s.buildArgs[id]))
+	for k, v := range s.buildArgs[id] {
+		args[k] = v
+	}
+	return args, true
+}
+
 func (s *DockerServer) pullImage(w http.ResponseWriter, r *http.Request) {
 	fromImageName := r.URL.Query().Get("fromImage")
 	tag := r.URL.Query().Get("tag")
@@ -1030,13 +1188,16 @@ func (s *DockerServer) pullImage(w http.ResponseWriter, r *http.Request) {
 			}
 			fromImageName = fmt.Sprintf("%s%s%s", fromImageName, separator, tag)
 		}
+		if !s.checkRegistryAuth(w, r, fromImageName) {
+			return
+		}
 	}
 	image := docker.Image{
-		ID:     s.generateID(),
+		ID:     s.generateImageID(),
 		Config: &docker.Config{},
 	}
 	s.iMut.Lock()
-	if _, exists := s.imgIDs[fromImageName]; fromImageName == "" || !exists {
+	if _, exists := s.lookupImageRefLocked(fromImageName); fromImageName == "" || !exists {
 		s.images[image.ID] = image
 		if fromImageName != "" {
 			s.imgIDs[fromImageName] = image.ID
@@ -1052,12 +1213,15 @@ func (s *DockerServer) pushImage(w http.ResponseWriter, r *http.Request) {
 		name += ":" + tag
 	}
 	s.iMut.RLock()
-	if _, ok := s.imgIDs[name]; !ok {
+	if _, ok := s.lookupImageRefLocked(name); !ok {
 		s.iMut.RUnlock()
 		http.Error(w, "No such image", http.StatusNotFound)
 		return
 	}
 	s.iMut.RUnlock()
+	if !s.checkRegistryAuth(w, r, name) {
+		return
+	}
 	fmt.Fprintln(w, "Pushing...")
 	fmt.Fprintln(w, "Pushed")
 }
@@ -1076,55 +1240,103 @@ func (s *DockerServer) tagImage(w http.ResponseWriter, r *http.Request) {
 	if newTag != "" {
 		newRepo += ":" + newTag
 	}
-	s.imgIDs[newRepo] = id
+	s.setImageRefLocked(newRepo, id)
 	w.WriteHeader(http.StatusCreated)
 }
 
 func (s *DockerServer) removeImage(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
+	query := r.URL.Query()
+	force, _ := strconv.ParseBool(query.Get("force"))
+	noprune, _ := strconv.ParseBool(query.Get("noprune"))
 	s.iMut.Lock()
 	defer s.iMut.Unlock()
-	var tag string
-	if img, ok := s.imgIDs[id]; ok {
-		id, tag = img, id
-	}
-	var tags []string
-	for tag, taggedID := range s.imgIDs {
-		if taggedID == id {
-			tags = append(tags, tag)
+	var removed []docker.RemovedImage
+	untag := func(ref string) {
+		delete(s.imgIDs, ref)
+		removed = append(removed, docker.RemovedImage{Untagged: ref})
+	}
+	if ref, ok := s.lookupImageRefLocked(id); ok {
+		// removing a reference untags the image, which is only deleted
+		// along with its last tag.
+		id = s.imgIDs[ref]
+		untag(ref)
+		tags, digests := s.imageRefsLocked(id)
+		if len(tags) > 0 {
+			s.writeRemovedImages(w, removed)
+			return
+		}
+		repository := imageRepository(normalizeImageRef(ref))
+		for _, digest := range digests {
+			if imageRepository(normalizeImageRef(digest)) == repository {
+				untag(digest)
+			}
+		}
+		if _, digests = s.imageRefsLocked(id); len(digests) > 0 {
+			s.writeRemovedImages(w, removed)
+			return
+		}
+	} else {
+		if _, ok := s.images[id]; !ok {
+			http.Error(w, "No such image", http.StatusNotFound)
+			return
+		}
+		tags, digests := s.imageRefsLocked(id)
+		refs := append(tags, digests...)
+		repositories := make(map[string]bool)
+		for _, ref := range refs {
+			repositories[imageRepository(normalizeImageRef(ref))] = true
+		}
+		if len(repositories) > 1 && !force {
+			http.Error(w, "image is referenced in multiple repositories", http.StatusConflict)
+			return
+		}
+		for _, ref := range refs {
+			untag(ref)
 		}
 	}
-	_, ok := s.images[id]
-	if !ok {
-		http.Error(w, "No such image", http.StatusNotFound)
-		return
+	parent := s.images[id].Parent
+	delete(s.images, id)
+	removed = append(removed, docker.RemovedImage{Deleted: id})
+	for !noprune && s.isPrunableImageLocked(parent) {
+		next := s.images[parent].Parent
+		delete(s.images, parent)
+		removed = append(removed, docker.RemovedImage{Deleted: parent})
+		parent = next
 	}
-	if tag == "" && len(tags) > 1 {
-		http.Error(w, "image is referenced in multiple repositories", http.StatusConflict)
-		return
+	s.writeRemovedImages(w, removed)
+}
+
+// isPrunableImageLocked reports whether the image with the given ID exists
+// and can be deleted along with its child: it has no references and no other
+// image uses it as parent. The caller must hold iMut.
+func (s *DockerServer) isPrunableImageLocked(id string) bool {
+	if _, ok := s.images[id]; !ok {
+		return false
 	}
-	w.WriteHeader(http.StatusNoContent)
-	if tag == "" {
-		// delete called with image ID
-		for _, t := range tags {
-			delete(s.imgIDs, t)
-		}
-		delete(s.images, id)
-	} else {
-		// delete called with image repository name
-		delete(s.imgIDs, tag)
-		if len(tags) == 1 {
-			delete(s.images, id)
+	if tags, digests := s.imageRefsLocked(id); len(tags)+len(digests) > 0 {
+		return false
+	}
+	for _, image := range s.images {
+		if image.Parent == id {
+			return false
 		}
 	}
+	return true
+}
+
+func (s *DockerServer) writeRemovedImages(w http.ResponseWriter, removed []docker.RemovedImage) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(removed)
 }
 
 func (s *DockerServer) inspectImage(w http.ResponseWriter, r *http.Request) {
 	name := mux.Vars(r)["name"]
 	s.iMut.RLock()
 	defer s.iMut.RUnlock()
-	if id, ok := s.imgIDs[name]; ok {
-		name = id
+	if ref, ok := s.lookupImageRefLocked(name); ok {
+		name = s.imgIDs[ref]
 	}
 	img, ok := s.images[name]
 	if !ok {
@@ -1156,6 +1368,8 @@ func (s *DockerServer) listEvents(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *DockerServer) pingDocker(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Docker-Experimental", "false")
+	w.Header().Set("Ostype", "linux")
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -1353,6 +1567,18 @@ func (s *DockerServer) createNetwork(w http.ResponseWriter, r *http.Request) {
 		ID:         generatedID,
 		Driver:     config.Driver,
 		Containers: map[string]docker.Endpoint{},
+		Internal:   config.Internal,
+		EnableIPv6: config.EnableIPv6,
+		Labels:     config.Labels,
+	}
+	if config.IPAM != nil {
+		for _, ipamConfig := range config.IPAM.Config {
+			if ipamConfig.IsIPv6() && !config.EnableIPv6 {
+				http.Error(w, "IPv6 subnets require EnableIPv6", http.StatusBadRequest)
+				return
+			}
+		}
+		network.IPAM = *config.IPAM
 	}
 	s.netMut.Lock()
 	s.networks = append(s.networks, &network)
@@ -1398,10 +1624,76 @@ func (s *DockerServer) networksConnect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var endpoint docker.Endpoint
+	containerNetwork := docker.ContainerNetwork{NetworkID: network.ID}
+	if config.EndpointConfig != nil {
+		endpoint.MacAddress = config.EndpointConfig.MacAddress
+		containerNetwork.MacAddress = config.EndpointConfig.MacAddress
+		containerNetwork.Aliases = config.EndpointConfig.Aliases
+		if ipam := config.EndpointConfig.IPAMConfig; ipam != nil {
+			endpoint.IPv4Address = ipam.IPv4Address
+			endpoint.IPv6Address = ipam.IPv6Address
+			containerNetwork.IPAddress = ipam.IPv4Address
+			containerNetwork.GlobalIPv6Address = ipam.IPv6Address
+		}
+	}
+	s.netMut.Lock()
+	s.networks[index].Containers[container.ID] = endpoint
+	s.netMut.Unlock()
+
+	s.cMut.Lock()
+	if container.NetworkSettings == nil {
+		container.NetworkSettings = &docker.NetworkSettings{}
+	}
+	if container.NetworkSettings.Networks == nil {
+		container.NetworkSettings.Networks = make(map[string]docker.ContainerNetwork)
+	}
+	container.NetworkSettings.Networks[network.Name] = containerNetwork
+	s.cMut.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *DockerServer) networksDisconnect(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var config *docker.NetworkConnectionOptions
+	defer r.Body.Close()
+	err := json.NewDecoder(r.Body).Decode(&config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	network, index, _ := s.findNetwork(id)
+	if network == nil {
+		http.Error(w, "network not found", http.StatusNotFound)
+		return
+	}
+	containerID := config.Container
+	container, _ := s.findContainer(config.Container)
+	if container != nil {
+		containerID = container.ID
+	} else if !config.Force {
+		http.Error(w, "container not found", http.StatusNotFound)
+		return
+	}
+
 	s.netMut.Lock()
-	s.networks[index].Containers[config.Container] = docker.Endpoint{}
+	_, found := s.networks[index].Containers[containerID]
+	delete(s.networks[index].Containers, containerID)
 	s.netMut.Unlock()
+	if !found && !config.Force {
+		http.Error(w, "container is not connected to the network", http.StatusForbidden)
+		return
+	}
 
+	if container != nil {
+		s.cMut.Lock()
+		if container.NetworkSettings != nil {
+			delete(container.NetworkSettings.Networks, network.Name)
+		}
+		s.cMut.Unlock()
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -1598,6 +1890,7 @@ func (s *DockerServer) infoDocker(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *DockerServer) versionDocker(w http.ResponseWriter, r *http.Request) {
+	apiVersion, minAPIVersion, _ := s.reportedAPIVersions()
 	envs := map[string]any{
 		"Version":       "1.10.1",
 		"Os":            "linux",
@@ -1605,7 +1898,8 @@ func (s *DockerServer) versionDocker(w http.ResponseWriter, r *http.Request) {
 		"GoVersion":     "go1.4.2",
 		"GitCommit":     "9e83765",
 		"Arch":          "amd64",
-		"ApiVersion":    "1.22",
+		"ApiVersion":    apiVersion.String(),
+		"MinAPIVersion": minAPIVersion.String(),
 		"BuildTime":     "2015-12-01T07:09:13.444803460+00:00",
 		"Experimental":  false,
 	}
@@ -17,7 +17,9 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"slices"
 	"sort"
 	"strings"
@@ -70,6 +72,79 @@ func TestNewTLSServer(t *testing.T) {
 	}
 }
 
+func TestNewTLSServerRequireClientCert(t *testing.T) {
+	t.Parallel()
+	tlsConfig := TLSConfig{
+		CertPath:          "./data/server.pem",
+		CertKeyPath:       "./data/serverkey.pem",
+		RootCAPath:        "./data/ca.pem",
+		RequireClientCert: true,
+	}
+	server, err := NewTLSServer("127.0.0.1:0", nil, nil, tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewTLSClient(server.URL(), "./data/cert.pem", "./data/key.pem", "./data/ca.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	client, err = docker.NewTLSClientFromBytes(server.URL(), nil, nil, mustReadFile(t, "./data/ca.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(); err == nil {
+		t.Error("Ping: expected an error for a client without certificate")
+	}
+}
+
+func TestNewTLSServerRequireClientCertWithoutCA(t *testing.T) {
+	t.Parallel()
+	tlsConfig := TLSConfig{
+		CertPath:          "./data/server.pem",
+		CertKeyPath:       "./data/serverkey.pem",
+		RequireClientCert: true,
+	}
+	if _, err := NewTLSServer("127.0.0.1:0", nil, nil, tlsConfig); err == nil {
+		t.Error("NewTLSServer: expected an error without RootCAPath")
+	}
+}
+
+func TestNewUnixServer(t *testing.T) {
+	t.Parallel()
+	socketPath := filepath.Join(t.TempDir(), "docker.sock")
+	server, err := NewUnixServer(socketPath, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "unix://" + socketPath; server.URL() != expected {
+		t.Errorf("URL: wrong URL. Want %q. Got %q.", expected, server.URL())
+	}
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	server.Stop()
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Errorf("Stop: the socket should be removed, got %v", err)
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
 func TestServerStop(t *testing.T) {
 	t.Parallel()
 	const retries = 3
@@ -516,11 +591,7 @@ func TestCommitContainer(t *testing.T) {
 	if len(server.images) != 1 {
 		t.Errorf("CommitContainer: wrong images len in server. Want 1. Got %q.", len(server.images))
 	}
-	imgID := fmt.Sprintf("img-%s", containers[0].ID)
-	expected := fmt.Sprintf(`{"ID":"%s"}`, imgID)
-	if got := recorder.Body.String(); got != expected {
-		t.Errorf("CommitContainer: wrong response body. Want %q. Got %q.", expected, got)
-	}
+	imgID := committedImageID(t, recorder)
 	if server.images[imgID].Config == nil {
 		t.Error("CommitContainer: image Config should not be nil.")
 	}
@@ -531,6 +602,20 @@ func TestCommitContainer(t *testing.T) {
 	}
 }
 
+// committedImageID returns the ID of the image in the response of a commit,
+// checking it's in the format of the daemon.
+func committedImageID(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	t.Helper()
+	var result struct{ ID string }
+	if err := json.NewDecoder(recorder.Body).Decode(&result); err != nil {
+		t.Fatalf("CommitContainer: invalid response body: %v", err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{64}$`).MatchString(result.ID) {
+		t.Errorf("CommitContainer: invalid image ID %q", result.ID)
+	}
+	return result.ID
+}
+
 func TestCommitContainerComplete(t *testing.T) {
 	t.Parallel()
 	server := baseDockerServer()
@@ -545,7 +630,7 @@ func TestCommitContainerComplete(t *testing.T) {
 	qs.Add("run", `{"Cmd": ["cat", "/world"],"PortSpecs":["22"]}`)
 	request, _ := http.NewRequest(http.MethodPost, "/commit?"+qs.Encode(), nil)
 	server.ServeHTTP(recorder, request)
-	imgID := fmt.Sprintf("img-%s", containers[0].ID)
+	imgID := committedImageID(t, recorder)
 	image := server.images[imgID]
 	if image.Parent != containers[0].Image {
 		t.Errorf("CommitContainer: wrong parent image. Want %q. Got %q.", containers[0].Image, image.Parent)
@@ -583,7 +668,7 @@ func TestCommitContainerWithTag(t *testing.T) {
 	queryString := "container=" + containers[0].ID + "&repo=tsuru/python&tag=v1"
 	request, _ := http.NewRequest(http.MethodPost, "/commit?"+queryString, nil)
 	server.ServeHTTP(recorder, request)
-	imgID := fmt.Sprintf("img-%s", containers[0].ID)
+	imgID := committedImageID(t, recorder)
 	image := server.images[imgID]
 	if image.Parent != containers[0].Image {
 		t.Errorf("CommitContainer: wrong parent image. Want %q. Got %q.", containers[0].Image, image.Parent)
@@ -1701,8 +1786,8 @@ func TestRemoveImage(t *testing.T) {
 	path := fmt.Sprintf("/images/%s", images[0].ID)
 	request, _ := http.NewRequest(http.MethodDelete, path, nil)
 	server.ServeHTTP(recorder, request)
-	if recorder.Code != http.StatusNoContent {
-		t.Errorf("RemoveImage: wrong status. Want %d. Got %d.", http.StatusNoContent, recorder.Code)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("RemoveImage: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
 	}
 	if len(server.images) > 0 {
 		t.Error("RemoveImage: did not remove the image.")
@@ -1719,8 +1804,8 @@ func TestRemoveImageByName(t *testing.T) {
 	path := "/images/" + imgName
 	request, _ := http.NewRequest(http.MethodDelete, path, nil)
 	server.ServeHTTP(recorder, request)
-	if recorder.Code != http.StatusNoContent {
-		t.Errorf("RemoveImage: wrong status. Want %d. Got %d.", http.StatusNoContent, recorder.Code)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("RemoveImage: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
 	}
 	if len(server.images) > 0 {
 		t.Error("RemoveImage: did not remove the image.")
@@ -1788,8 +1873,8 @@ func TestRemoveImageByIDWithSingleTag(t *testing.T) {
 	path := fmt.Sprintf("/images/%s", imgID)
 	request, _ := http.NewRequest(http.MethodDelete, path, nil)
 	server.ServeHTTP(recorder, request)
-	if recorder.Code != http.StatusNoContent {
-		t.Errorf("RemoveImage: wrong status. Want %d. Got %d.", http.StatusNoContent, recorder.Code)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("RemoveImage: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
 	}
 	if len(server.images) > 0 {
 		t.Error("RemoveImage: did not remove the image.")
@@ -1801,6 +1886,241 @@ func TestRemoveImageByIDWithSingleTag(t *testing.T) {
 	}
 }
 
+func TestRemoveImageByNormalizedReference(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	images := addImages(&server, 1, false)
+	server.buildMuxer()
+	imgID := images[0].ID
+	server.imgIDs["busybox"] = imgID
+	server.imgIDs["busybox:1.36"] = imgID
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodDelete, "/images/docker.io/library/busybox:latest", nil)
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("RemoveImage: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	expected := map[string]string{"busybox:1.36": imgID}
+	if !reflect.DeepEqual(server.imgIDs, expected) {
+		t.Errorf("RemoveImage: wrong references. Want %#v. Got %#v.", expected, server.imgIDs)
+	}
+	if _, ok := server.images[imgID]; !ok {
+		t.Error("RemoveImage: removed the image, but should keep it")
+	}
+}
+
+func TestRemoveImageLastTagRemovesDigests(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	images := addImages(&server, 1, false)
+	server.buildMuxer()
+	imgID := images[0].ID
+	server.imgIDs["busybox:1.36"] = imgID
+	server.imgIDs["busybox@sha256:deadc0de"] = imgID
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodDelete, "/images/busybox:1.36", nil)
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("RemoveImage: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	if len(server.imgIDs) > 0 {
+		t.Errorf("RemoveImage: did not remove the references: %#v", server.imgIDs)
+	}
+	if len(server.images) > 0 {
+		t.Error("RemoveImage: did not remove the image.")
+	}
+}
+
+func TestRemoveImageByIDWithMultipleTagsForce(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	images := addImages(&server, 1, true)
+	server.buildMuxer()
+	imgID := images[0].ID
+	server.imgIDs["docker/python-wat"] = imgID
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodDelete, "/images/"+imgID+"?force=1", nil)
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("RemoveImage: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	if len(server.imgIDs) > 0 {
+		t.Errorf("RemoveImage: did not remove the references: %#v", server.imgIDs)
+	}
+	if len(server.images) > 0 {
+		t.Error("RemoveImage: did not remove the image.")
+	}
+}
+
+func TestRemoveImageReport(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	server.buildMuxer()
+	server.images = map[string]docker.Image{
+		"base":   {ID: "base"},
+		"middle": {ID: "middle", Parent: "base"},
+		"top":    {ID: "top", Parent: "middle"},
+	}
+	server.imgIDs = map[string]string{"app:v1": "top", "app@sha256:deadc0de": "top", "base:latest": "base"}
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodDelete, "/images/app:v1", nil)
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("RemoveImage: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	var got []docker.RemovedImage
+	if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	expected := []docker.RemovedImage{
+		{Untagged: "app:v1"},
+		{Untagged: "app@sha256:deadc0de"},
+		{Deleted: "top"},
+		{Deleted: "middle"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("RemoveImage: wrong report.\nWant %#v.\nGot  %#v.", expected, got)
+	}
+	if _, ok := server.images["base"]; !ok {
+		t.Error("RemoveImage: removed a tagged parent image")
+	}
+}
+
+func TestRemoveImageNoPrune(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	server.buildMuxer()
+	server.images = map[string]docker.Image{
+		"middle": {ID: "middle"},
+		"top":    {ID: "top", Parent: "middle"},
+	}
+	server.imgIDs = map[string]string{"app:v1": "top"}
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodDelete, "/images/top?noprune=1", nil)
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("RemoveImage: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	var got []docker.RemovedImage
+	if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	expected := []docker.RemovedImage{{Untagged: "app:v1"}, {Deleted: "top"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("RemoveImage: wrong report.\nWant %#v.\nGot  %#v.", expected, got)
+	}
+	if _, ok := server.images["middle"]; !ok {
+		t.Error("RemoveImage: pruned the parent image with noprune")
+	}
+}
+
+func TestRemoveImageWithResultClient(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PullImage(docker.PullImageOptions{Repository: "busybox", Tag: "1.36", OutputStream: io.Discard}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	image, err := client.InspectImage("busybox:1.36")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.TagImage("busybox:1.36", docker.TagImageOptions{Repo: "busybox", Tag: "stable"}); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := client.RemoveImageWithResult("busybox:1.36", docker.RemoveImageOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []docker.RemovedImage{{Untagged: "busybox:1.36"}}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Errorf("RemoveImageWithResult: wrong report. Want %#v. Got %#v.", expected, removed)
+	}
+	removed, err = client.RemoveImageWithResult("busybox:stable", docker.RemoveImageOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []docker.RemovedImage{{Untagged: "busybox:stable"}, {Deleted: image.ID}}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Errorf("RemoveImageWithResult: wrong report. Want %#v. Got %#v.", expected, removed)
+	}
+}
+
+func TestTagImageMovesTag(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	images := addImages(&server, 2, false)
+	server.buildMuxer()
+	server.imgIDs["tsuru/python"] = images[0].ID
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodPost, "/images/"+images[1].ID+"/tag?repo=tsuru/python&tag=latest", nil)
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("TagImage: wrong status. Want %d. Got %d.", http.StatusCreated, recorder.Code)
+	}
+	expected := map[string]string{"tsuru/python:latest": images[1].ID}
+	if !reflect.DeepEqual(server.imgIDs, expected) {
+		t.Errorf("TagImage: wrong references. Want %#v. Got %#v.", expected, server.imgIDs)
+	}
+}
+
+func TestListImagesFilters(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	images := addImages(&server, 3, false)
+	server.buildMuxer()
+	server.imgIDs["tsuru/python:3.12"] = images[0].ID
+	server.imgIDs["tsuru/python:3.11"] = images[0].ID
+	server.imgIDs["tsuru/python@sha256:deadc0de"] = images[0].ID
+	server.imgIDs["tsuru/ruby"] = images[1].ID
+	tests := []struct {
+		filters  string
+		expected []string
+	}{
+		{`{"reference":["tsuru/python"]}`, []string{images[0].ID}},
+		{`{"reference":["tsuru/*:3.1?"]}`, []string{images[0].ID}},
+		{`{"reference":["tsuru/ruby:latest","tsuru/python:3.12"]}`, []string{images[0].ID, images[1].ID}},
+		{`{"dangling":["true"]}`, []string{images[2].ID}},
+		{`{"dangling":["false"],"reference":["tsuru/*"]}`, []string{images[0].ID, images[1].ID}},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, "/images/json?filters="+url.QueryEscape(tt.filters), nil)
+		server.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("ListImages: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+		}
+		var got []docker.APIImages
+		if err := json.NewDecoder(recorder.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, 0, len(got))
+		for _, image := range got {
+			ids = append(ids, image.ID)
+			if image.ID == images[0].ID {
+				expectedTags := []string{"tsuru/python:3.11", "tsuru/python:3.12"}
+				expectedDigests := []string{"tsuru/python@sha256:deadc0de"}
+				if !reflect.DeepEqual(image.RepoTags, expectedTags) || !reflect.DeepEqual(image.RepoDigests, expectedDigests) {
+					t.Errorf("ListImages: wrong references. Want %v and %v. Got %v and %v.", expectedTags, expectedDigests, image.RepoTags, image.RepoDigests)
+				}
+			}
+		}
+		sort.Strings(ids)
+		expected := append([]string(nil), tt.expected...)
+		sort.Strings(expected)
+		if !reflect.DeepEqual(ids, expected) {
+			t.Errorf("ListImages(%s): wrong images. Want %v. Got %v.", tt.filters, expected, ids)
+		}
+	}
+}
+
 func TestPrepareFailure(t *testing.T) {
 	t.Parallel()
 	server := baseDockerServer()
@@ -2364,6 +2684,43 @@ func TestCreateNetwork(t *testing.T) {
 	}
 }
 
+func TestCreateNetworkDualStack(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	server.buildMuxer()
+	recorder := httptest.NewRecorder()
+	body := `{"Name":"dualstack","EnableIPv6":true,"IPAM":{"Driver":"default","Config":[{"Subnet":"172.28.0.0/16"},{"Subnet":"fd00:dead:beef::/48"}]}}`
+	request, _ := http.NewRequest(http.MethodPost, "/networks/create", strings.NewReader(body))
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("CreateNetwork: wrong status. Want %d. Got %d.", http.StatusCreated, recorder.Code)
+	}
+	stored := server.networks[0]
+	if !stored.EnableIPv6 {
+		t.Error("CreateNetwork: expected EnableIPv6 to be stored")
+	}
+	expectedIPAM := docker.IPAMOptions{
+		Driver: "default",
+		Config: []docker.IPAMConfig{{Subnet: "172.28.0.0/16"}, {Subnet: "fd00:dead:beef::/48"}},
+	}
+	if !reflect.DeepEqual(stored.IPAM, expectedIPAM) {
+		t.Errorf("CreateNetwork: wrong IPAM. Want %#v. Got %#v.", expectedIPAM, stored.IPAM)
+	}
+}
+
+func TestCreateNetworkIPv6SubnetWithoutIPv6(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	server.buildMuxer()
+	recorder := httptest.NewRecorder()
+	body := `{"Name":"v6only","IPAM":{"Config":[{"Subnet":"fd00:dead:beef::/48"}]}}`
+	request, _ := http.NewRequest(http.MethodPost, "/networks/create", strings.NewReader(body))
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("CreateNetwork: wrong status. Want %d. Got %d.", http.StatusBadRequest, recorder.Code)
+	}
+}
+
 func TestCreateNetworkInvalidBody(t *testing.T) {
 	t.Parallel()
 	server := baseDockerServer()
@@ -2440,6 +2797,114 @@ func TestNetworkConnect(t *testing.T) {
 	}
 }
 
+func TestNetworkConnectStaticAddresses(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	server.buildMuxer()
+	addNetworks(&server, 1)
+	server.networks[0].ID = fmt.Sprintf("%x", rand.Int()%10000)
+	server.imgIDs = map[string]string{"base": "a1234"}
+	containers := addContainers(&server, 1)
+	containers[0].ID = fmt.Sprintf("%x", rand.Int()%10000)
+	server.addContainer(containers[0])
+
+	recorder := httptest.NewRecorder()
+	body := fmt.Sprintf(`{"Container":"%s","EndpointConfig":{"MacAddress":"02:42:ac:11:00:02","IPAMConfig":{"IPv4Address":"172.18.0.10","IPv6Address":"fd00::10","LinkLocalIPs":["169.254.0.10"]}}}`, containers[0].ID)
+	request, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/networks/%s/connect", server.networks[0].ID), strings.NewReader(body))
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("NetworkConnect: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	expected := docker.Endpoint{MacAddress: "02:42:ac:11:00:02", IPv4Address: "172.18.0.10", IPv6Address: "fd00::10"}
+	if got := server.networks[0].Containers[containers[0].ID]; got != expected {
+		t.Errorf("NetworkConnect: wrong endpoint. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestNetworkConnectAliases(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	server.buildMuxer()
+	addNetworks(&server, 1)
+	server.imgIDs = map[string]string{"base": "a1234"}
+	containers := addContainers(&server, 1)
+	server.addContainer(containers[0])
+
+	recorder := httptest.NewRecorder()
+	body := fmt.Sprintf(`{"Container":"%s","EndpointConfig":{"Aliases":["web","web-blue"]}}`, containers[0].ID)
+	request, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/networks/%s/connect", server.networks[0].ID), strings.NewReader(body))
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("NetworkConnect: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	expected := []string{"web", "web-blue"}
+	got := containers[0].NetworkSettings.Networks[server.networks[0].Name].Aliases
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("NetworkConnect: wrong aliases. Want %#v. Got %#v.", expected, got)
+	}
+}
+
+func TestNetworkDisconnect(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	server.buildMuxer()
+	addNetworks(&server, 1)
+	server.imgIDs = map[string]string{"base": "a1234"}
+	containers := addContainers(&server, 1)
+	server.addContainer(containers[0])
+	network := server.networks[0]
+
+	recorder := httptest.NewRecorder()
+	body := fmt.Sprintf(`{"Container":"%s"}`, containers[0].ID)
+	request, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/networks/%s/connect", network.ID), strings.NewReader(body))
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("NetworkConnect: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/networks/%s/disconnect", network.ID), strings.NewReader(body))
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("NetworkDisconnect: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	if _, ok := network.Containers[containers[0].ID]; ok {
+		t.Error("NetworkDisconnect: container still connected to the network")
+	}
+	if _, ok := containers[0].NetworkSettings.Networks[network.Name]; ok {
+		t.Error("NetworkDisconnect: network still in the container settings")
+	}
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/networks/%s/disconnect", network.ID), strings.NewReader(body))
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("NetworkDisconnect: wrong status. Want %d. Got %d.", http.StatusForbidden, recorder.Code)
+	}
+}
+
+func TestNetworkDisconnectForce(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	server.buildMuxer()
+	addNetworks(&server, 1)
+	server.networks[0].Containers["gone"] = docker.Endpoint{}
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/networks/%s/disconnect", server.networks[0].ID), strings.NewReader(`{"Container":"gone"}`))
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("NetworkDisconnect: wrong status. Want %d. Got %d.", http.StatusNotFound, recorder.Code)
+	}
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest(http.MethodPost, fmt.Sprintf("/networks/%s/disconnect", server.networks[0].ID), strings.NewReader(`{"Container":"gone","Force":true}`))
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("NetworkDisconnect: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	if _, ok := server.networks[0].Containers["gone"]; ok {
+		t.Error("NetworkDisconnect: container still connected to the network")
+	}
+}
+
 func TestListVolumes(t *testing.T) {
 	t.Parallel()
 	server := baseDockerServer()
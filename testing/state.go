@@ -0,0 +1,110 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// stateVersion is the version of the format written by SaveState.
+const stateVersion = 1
+
+// serverState is the serialized state of a DockerServer.
+type serverState struct {
+	Version        int                     `json:"version"`
+	Containers     []*docker.Container     `json:"containers"`
+	ContainerNames map[string]string       `json:"containerNames"`
+	UploadedFiles  map[string]string       `json:"uploadedFiles"`
+	Images         map[string]docker.Image `json:"images"`
+	ImageIDs       map[string]string       `json:"imageIDs"`
+	Networks       []*docker.Network       `json:"networks"`
+	Volumes        []volumeState           `json:"volumes"`
+}
+
+type volumeState struct {
+	Volume docker.Volume `json:"volume"`
+	Count  int           `json:"count"`
+}
+
+// SaveState writes the containers, images, networks and volumes of the
+// server to w, in a format that LoadState can read. It allows capturing a
+// complex fixture once, and restoring it in other servers.
+//
+// Execs, failures, callbacks and the swarm state aren't saved.
+func (s *DockerServer) SaveState(w io.Writer) error {
+	state := serverState{Version: stateVersion}
+	s.cMut.RLock()
+	for _, container := range s.containers {
+		state.Containers = append(state.Containers, container)
+	}
+	state.ContainerNames = s.contNameToID
+	state.UploadedFiles = s.uploadedFiles
+	s.iMut.RLock()
+	state.Images = s.images
+	state.ImageIDs = s.imgIDs
+	s.netMut.RLock()
+	state.Networks = s.networks
+	s.volMut.RLock()
+	for _, volume := range s.volStore {
+		state.Volumes = append(state.Volumes, volumeState{Volume: volume.volume, Count: volume.count})
+	}
+	data, err := json.Marshal(state)
+	s.volMut.RUnlock()
+	s.netMut.RUnlock()
+	s.iMut.RUnlock()
+	s.cMut.RUnlock()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// LoadState replaces the containers, images, networks and volumes of the
+// server with the ones read from r, previously written by SaveState.
+func (s *DockerServer) LoadState(r io.Reader) error {
+	var state serverState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+	if state.Version != stateVersion {
+		return fmt.Errorf("unsupported state version %d", state.Version)
+	}
+	containers := make(map[string]*docker.Container, len(state.Containers))
+	for _, container := range state.Containers {
+		containers[container.ID] = container
+	}
+	volumes := make(map[string]*volumeCounter, len(state.Volumes))
+	for _, volume := range state.Volumes {
+		volumes[volume.Volume.Name] = &volumeCounter{volume: volume.Volume, count: volume.Count}
+	}
+	s.cMut.Lock()
+	defer s.cMut.Unlock()
+	s.iMut.Lock()
+	defer s.iMut.Unlock()
+	s.netMut.Lock()
+	defer s.netMut.Unlock()
+	s.volMut.Lock()
+	defer s.volMut.Unlock()
+	s.containers = containers
+	s.contNameToID = nonNilMap(state.ContainerNames)
+	s.uploadedFiles = nonNilMap(state.UploadedFiles)
+	s.images = nonNilMap(state.Images)
+	s.imgIDs = nonNilMap(state.ImageIDs)
+	s.networks = state.Networks
+	s.volStore = volumes
+	return nil
+}
+
+func nonNilMap[V any](m map[string]V) map[string]V {
+	if m == nil {
+		return make(map[string]V)
+	}
+	return m
+}
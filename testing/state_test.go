@@ -0,0 +1,89 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestSaveLoadState(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := client.PullImage(docker.PullImageOptions{Repository: "busybox", OutputStream: &buf}, docker.AuthConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+	container, err := client.CreateContainer(docker.CreateContainerOptions{Name: "fixture", Config: &docker.Config{Image: "busybox"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	network, err := client.CreateNetwork(docker.CreateNetworkOptions{Name: "fixture-net"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateVolume(docker.CreateVolumeOptions{Name: "fixture-vol"}); err != nil {
+		t.Fatal(err)
+	}
+	var state bytes.Buffer
+	if err := server.SaveState(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restored.Stop()
+	if err := restored.LoadState(&state); err != nil {
+		t.Fatal(err)
+	}
+	client, err = docker.NewClient(restored.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.InspectContainerWithOptions(docker.InspectContainerOptions{ID: "fixture"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != container.ID || got.Config.Image != "busybox" {
+		t.Errorf("LoadState: wrong container: %#v.", got)
+	}
+	if _, err := client.InspectImage("busybox"); err != nil {
+		t.Errorf("LoadState: image not restored: %v", err)
+	}
+	if gotNetwork, err := client.NetworkInfo(network.ID); err != nil || gotNetwork.Name != "fixture-net" {
+		t.Errorf("LoadState: network not restored: %#v, %v", gotNetwork, err)
+	}
+	if volume, err := client.InspectVolume("fixture-vol"); err != nil || volume.Name != "fixture-vol" {
+		t.Errorf("LoadState: volume not restored: %#v, %v", volume, err)
+	}
+	// the restored server must keep working
+	if _, err := client.CreateContainer(docker.CreateContainerOptions{Name: "other", Config: &docker.Config{Image: "busybox"}}); err != nil {
+		t.Errorf("CreateContainer after LoadState: %v", err)
+	}
+}
+
+func TestLoadStateInvalid(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	if err := server.LoadState(strings.NewReader(`{"version":42}`)); err == nil {
+		t.Error("LoadState: expected an error for an unknown version")
+	}
+	if err := server.LoadState(strings.NewReader(`not json`)); err == nil {
+		t.Error("LoadState: expected an error for invalid input")
+	}
+}
@@ -173,15 +173,31 @@ func (s *DockerServer) swarmLeave(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *DockerServer) containerForService(srv *swarm.Service, name string) *docker.Container {
+// containerForService returns the container running the given task of a
+// service. Like in a real cluster, the container is labeled with the
+// service, task and node it belongs to, besides the labels of the container
+// spec.
+func (s *DockerServer) containerForService(srv *swarm.Service, task *swarm.Task, name string) *docker.Container {
 	hostConfig := docker.HostConfig{}
+	labels := map[string]string{
+		"com.docker.swarm.service.id":   srv.ID,
+		"com.docker.swarm.service.name": srv.Spec.Name,
+		"com.docker.swarm.task":         "",
+		"com.docker.swarm.task.id":      task.ID,
+		"com.docker.swarm.task.name":    name,
+		"com.docker.swarm.node.id":      task.NodeID,
+	}
+	for k, v := range srv.Spec.TaskTemplate.ContainerSpec.Labels {
+		labels[k] = v
+	}
 	dockerConfig := docker.Config{
 		Entrypoint: srv.Spec.TaskTemplate.ContainerSpec.Command,
 		Cmd:        srv.Spec.TaskTemplate.ContainerSpec.Args,
 		Env:        srv.Spec.TaskTemplate.ContainerSpec.Env,
+		Labels:     labels,
 	}
 	return &docker.Container{
-		ID:         s.generateID(),
+		ID:         task.Status.ContainerStatus.ContainerID,
 		Name:       name,
 		Image:      srv.Spec.TaskTemplate.ContainerSpec.Image,
 		Created:    time.Now(),
@@ -270,7 +286,6 @@ func (s *DockerServer) addTasks(service *swarm.Service, update bool) {
 		if update {
 			name = fmt.Sprintf("%s-%d-updated", service.Spec.Name, i)
 		}
-		container := s.containerForService(service, name)
 		chosenNode := s.nodes[s.nodeRR]
 		s.nodeRR = (s.nodeRR + 1) % len(s.nodes)
 		task := swarm.Task{
@@ -280,12 +295,13 @@ func (s *DockerServer) addTasks(service *swarm.Service, update bool) {
 			Status: swarm.TaskStatus{
 				State: swarm.TaskStateReady,
 				ContainerStatus: &swarm.ContainerStatus{
-					ContainerID: container.ID,
+					ContainerID: s.generateID(),
 				},
 			},
 			DesiredState: swarm.TaskStateReady,
 			Spec:         service.Spec.TaskTemplate,
 		}
+		container := s.containerForService(service, &task, name)
 		s.tasks = append(s.tasks, &task)
 		s.addContainer(container)
 		s.notify(container)
@@ -386,6 +386,7 @@ func TestServiceCreate(t *testing.T) {
 		t.Fatalf("ServiceCreate: wrong item count. Want 1. Got services: %d, tasks: %d, containers: %d.", len(server.services), len(server.tasks), len(server.containers))
 	}
 	cont := getContainer(server)
+	task := server.tasks[0]
 	expectedContainer := &docker.Container{
 		ID:      cont.ID,
 		Created: cont.Created,
@@ -395,6 +396,14 @@ func TestServiceCreate(t *testing.T) {
 			Entrypoint: []string{"sh"},
 			Cmd:        []string{"--test"},
 			Env:        []string{"ENV=1"},
+			Labels: map[string]string{
+				"com.docker.swarm.service.id":   server.services[0].ID,
+				"com.docker.swarm.service.name": "test",
+				"com.docker.swarm.task":         "",
+				"com.docker.swarm.task.id":      task.ID,
+				"com.docker.swarm.task.name":    "test-0",
+				"com.docker.swarm.node.id":      task.NodeID,
+			},
 		},
 		HostConfig: &docker.HostConfig{},
 		State: docker.State{
@@ -419,7 +428,6 @@ func TestServiceCreate(t *testing.T) {
 	if !reflect.DeepEqual(srv, expectedService) {
 		t.Fatalf("ServiceCreate: wrong service. Want\n%#v\nGot\n%#v", expectedService, srv)
 	}
-	task := server.tasks[0]
 	expectedTask := &swarm.Task{
 		ID:        task.ID,
 		ServiceID: srv.ID,
@@ -438,6 +446,59 @@ func TestServiceCreate(t *testing.T) {
 	}
 }
 
+func TestServiceCreateContainerLabels(t *testing.T) {
+	t.Parallel()
+	server, unused := setUpSwarm(t)
+	defer server.Stop()
+	defer unused.Stop()
+	replicas := uint64(2)
+	for _, name := range []string{"web", "worker"} {
+		spec := swarm.ServiceSpec{
+			Annotations: swarm.Annotations{Name: name},
+			TaskTemplate: swarm.TaskSpec{
+				ContainerSpec: &swarm.ContainerSpec{Image: "test/" + name, Labels: map[string]string{"app": name}},
+			},
+			Mode: swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+		}
+		buf, err := json.Marshal(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodPost, "/services/create", bytes.NewReader(buf))
+		server.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("ServiceCreate: wrong status code. Want %d. Got %d.", http.StatusOK, recorder.Code)
+		}
+	}
+	filters := url.Values{"filters": {`{"label":["com.docker.swarm.service.name=web"]}`}}
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodGet, "/containers/json?all=1&"+filters.Encode(), nil)
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("ListContainers: wrong status code. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	var containers []docker.APIContainers
+	if err := json.NewDecoder(recorder.Body).Decode(&containers); err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 2 {
+		t.Fatalf("ListContainers: wrong number of containers. Want 2. Got %d.", len(containers))
+	}
+	tasks := make(map[string]bool)
+	for _, container := range containers {
+		if container.Labels["app"] != "web" || container.Labels["com.docker.swarm.service.name"] != "web" {
+			t.Errorf("ListContainers: wrong labels: %#v", container.Labels)
+		}
+		tasks[container.Labels["com.docker.swarm.task.id"]] = true
+	}
+	for _, task := range server.tasks {
+		if task.ServiceID == server.services[0].ID && !tasks[task.ID] {
+			t.Errorf("ListContainers: missing container of task %s", task.ID)
+		}
+	}
+}
+
 func TestServiceCreateDynamicPort(t *testing.T) {
 	t.Parallel()
 	server, unused := setUpSwarm(t)
@@ -1088,6 +1149,7 @@ func TestServiceUpdate(t *testing.T) {
 		t.Fatalf("ServiceUpdate: wrong item count. Want 1. Got services: %d, tasks: %d, containers: %d.", len(server.services), len(server.tasks), len(server.containers))
 	}
 	cont := getContainer(server)
+	task := server.tasks[0]
 	expectedContainer := &docker.Container{
 		ID:      cont.ID,
 		Created: cont.Created,
@@ -1096,6 +1158,14 @@ func TestServiceUpdate(t *testing.T) {
 		Config: &docker.Config{
 			Cmd: []string{"--test2"},
 			Env: []string{"ENV=2"},
+			Labels: map[string]string{
+				"com.docker.swarm.service.id":   srv.ID,
+				"com.docker.swarm.service.name": "test",
+				"com.docker.swarm.task":         "",
+				"com.docker.swarm.task.id":      task.ID,
+				"com.docker.swarm.task.name":    "test-0-updated",
+				"com.docker.swarm.node.id":      task.NodeID,
+			},
 		},
 		HostConfig: &docker.HostConfig{},
 		State: docker.State{
@@ -1125,7 +1195,6 @@ func TestServiceUpdate(t *testing.T) {
 	if !reflect.DeepEqual(srv, expectedService) {
 		t.Fatalf("ServiceUpdate: wrong service. Want\n%#v\nGot\n%#v", expectedService, srv)
 	}
-	task := server.tasks[0]
 	expectedTask := &swarm.Task{
 		ID:        task.ID,
 		ServiceID: srv.ID,
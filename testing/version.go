@@ -0,0 +1,91 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/gorilla/mux"
+)
+
+// DefaultAPIVersion is the API version reported by the server, unless
+// another one is set with SetAPIVersion.
+const DefaultAPIVersion = "1.22"
+
+// DefaultMinAPIVersion is the minimum API version reported by the server,
+// unless another one is set with SetAPIVersion.
+const DefaultMinAPIVersion = "1.12"
+
+// SetAPIVersion sets the API version, and the minimum API version, reported
+// by the server in /version and in the Api-Version header of the responses.
+//
+// Once it's called, the server also rejects the requests with a versioned
+// path (/v1.xx/...) outside of the range, with 400 Bad Request, like the
+// Docker daemon does. Unversioned paths are always accepted. minimum may be
+// empty, in which case it defaults to DefaultMinAPIVersion.
+func (s *DockerServer) SetAPIVersion(version, minimum string) error {
+	if minimum == "" {
+		minimum = DefaultMinAPIVersion
+	}
+	maxVersion, err := docker.NewAPIVersion(version)
+	if err != nil {
+		return err
+	}
+	minVersion, err := docker.NewAPIVersion(minimum)
+	if err != nil {
+		return err
+	}
+	if maxVersion.LessThan(minVersion) {
+		return fmt.Errorf("API version %s is lower than the minimum API version %s", version, minimum)
+	}
+	s.versionMut.Lock()
+	defer s.versionMut.Unlock()
+	s.apiVersion = maxVersion
+	s.minAPIVersion = minVersion
+	return nil
+}
+
+// reportedAPIVersions returns the API version range of the server, and
+// whether it's enforced.
+func (s *DockerServer) reportedAPIVersions() (maxVersion, minVersion docker.APIVersion, enforced bool) {
+	s.versionMut.RLock()
+	defer s.versionMut.RUnlock()
+	if s.apiVersion == nil {
+		maxVersion, _ = docker.NewAPIVersion(DefaultAPIVersion)
+		minVersion, _ = docker.NewAPIVersion(DefaultMinAPIVersion)
+		return maxVersion, minVersion, false
+	}
+	return s.apiVersion, s.minAPIVersion, true
+}
+
+// checkAPIVersion rejects the versioned requests outside of the API version
+// range of the server.
+func (s *DockerServer) checkAPIVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		maxVersion, minVersion, enforced := s.reportedAPIVersions()
+		if !enforced {
+			next.ServeHTTP(w, r)
+			return
+		}
+		requested := strings.TrimPrefix(mux.Vars(r)["version"], "v")
+		version, err := docker.NewAPIVersion(requested)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if version.GreaterThan(maxVersion) {
+			http.Error(w, fmt.Sprintf("client version %s is too new. Maximum supported API version is %s", requested, maxVersion), http.StatusBadRequest)
+			return
+		}
+		if version.LessThan(minVersion) {
+			http.Error(w, fmt.Sprintf("client version %s is too old. Minimum supported API version is %s, please upgrade your client to a newer version", requested, minVersion), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
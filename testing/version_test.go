@@ -0,0 +1,114 @@
+// Copyright 2024 go-dockerclient authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestSetAPIVersion(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	server.buildMuxer()
+	if err := server.SetAPIVersion("1.40", "1.24"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path    string
+		status  int
+		message string
+	}{
+		{"/_ping", http.StatusOK, ""},
+		{"/v1.40/_ping", http.StatusOK, ""},
+		{"/v1.24/_ping", http.StatusOK, ""},
+		{"/v1.41/_ping", http.StatusBadRequest, "client version 1.41 is too new. Maximum supported API version is 1.40"},
+		{"/v1.23/_ping", http.StatusBadRequest, "client version 1.23 is too old. Minimum supported API version is 1.24"},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		server.ServeHTTP(recorder, request)
+		if recorder.Code != tt.status {
+			t.Errorf("%s: wrong status. Want %d. Got %d.", tt.path, tt.status, recorder.Code)
+		}
+		if !strings.Contains(recorder.Body.String(), tt.message) {
+			t.Errorf("%s: wrong message. Want %q. Got %q.", tt.path, tt.message, recorder.Body.String())
+		}
+		if got := recorder.Header().Get("Api-Version"); got != "1.40" {
+			t.Errorf("%s: wrong Api-Version header. Want %q. Got %q.", tt.path, "1.40", got)
+		}
+	}
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodGet, "/version", nil)
+	server.ServeHTTP(recorder, request)
+	var version map[string]any
+	if err := json.NewDecoder(recorder.Body).Decode(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version["ApiVersion"] != "1.40" || version["MinAPIVersion"] != "1.24" {
+		t.Errorf("Version: wrong API versions: %#v.", version)
+	}
+}
+
+func TestSetAPIVersionInvalid(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	if err := server.SetAPIVersion("1.24", "1.40"); err == nil {
+		t.Error("SetAPIVersion: expected an error for an inverted range")
+	}
+	if err := server.SetAPIVersion("latest", ""); err == nil {
+		t.Error("SetAPIVersion: expected an error for an invalid version")
+	}
+}
+
+func TestDefaultAPIVersionNotEnforced(t *testing.T) {
+	t.Parallel()
+	server := baseDockerServer()
+	server.buildMuxer()
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest(http.MethodGet, "/v1.99/_ping", nil)
+	server.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Ping: wrong status. Want %d. Got %d.", http.StatusOK, recorder.Code)
+	}
+	if got := recorder.Header().Get("Api-Version"); got != DefaultAPIVersion {
+		t.Errorf("Ping: wrong Api-Version header. Want %q. Got %q.", DefaultAPIVersion, got)
+	}
+}
+
+func TestSetAPIVersionWithClient(t *testing.T) {
+	t.Parallel()
+	server, err := NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	if err := server.SetAPIVersion("1.40", ""); err != nil {
+		t.Fatal(err)
+	}
+	client, err := docker.NewVersionedClient(server.URL(), "1.41")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.ListContainers(docker.ListContainersOptions{})
+	var apiErr *docker.Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusBadRequest {
+		t.Errorf("ListContainers: wrong error. Want 400. Got %#v.", err)
+	}
+	client, err = docker.NewVersionedClient(server.URL(), "1.40")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListContainers(docker.ListContainersOptions{}); err != nil {
+		t.Errorf("ListContainers: unexpected error: %v", err)
+	}
+}
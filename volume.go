@@ -51,23 +51,13 @@ func (c *Client) ListVolumes(opts ListVolumesOptions) ([]Volume, error) {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	m := make(map[string]any)
-	if err = json.NewDecoder(resp.Body).Decode(&m); err != nil {
-		return nil, err
-	}
-	var volumes []Volume
-	volumesJSON, ok := m["Volumes"]
-	if !ok {
-		return volumes, nil
-	}
-	data, err := json.Marshal(volumesJSON)
-	if err != nil {
-		return nil, err
+	var result struct {
+		Volumes []Volume
 	}
-	if err := json.Unmarshal(data, &volumes); err != nil {
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, err
 	}
-	return volumes, nil
+	return result.Volumes, nil
 }
 
 // CreateVolumeOptions specify parameters to the CreateVolume function.
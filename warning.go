@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Warning is a warning sent by the daemon along with the result of an
+// operation, like the use of a deprecated HostConfig field or of a
+// deprecated API version.
+type Warning struct {
+	// Method and Path identify the request that got the warning.
+	Method string
+	Path   string
+
+	Message string
+}
+
+func (w Warning) String() string {
+	return w.Method + " " + w.Path + ": " + w.Message
+}
+
+// apiVersionPrefix matches the API version at the start of the path of a
+// versioned request.
+var apiVersionPrefix = regexp.MustCompile(`^/v[0-9]+\.[0-9]+`)
+
+// warn passes the given messages to the WarningHandler of the client, if
+// any.
+func (c *Client) warn(method, path string, messages ...string) {
+	if c.WarningHandler == nil {
+		return
+	}
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	path = apiVersionPrefix.ReplaceAllString(path, "")
+	for _, message := range messages {
+		if message != "" {
+			c.WarningHandler(Warning{Method: method, Path: path, Message: message})
+		}
+	}
+}
+
+// warnHeaders passes the warnings in the Warning headers of resp to the
+// WarningHandler of the client.
+func (c *Client) warnHeaders(method, path string, resp *http.Response) {
+	if c.WarningHandler == nil {
+		return
+	}
+	for _, value := range resp.Header.Values("Warning") {
+		c.warn(method, path, parseWarningHeader(value))
+	}
+}
+
+// parseWarningHeader returns the text of a Warning header, which is in the
+// format defined by RFC 7234 (299 - "text"), or just the text.
+func parseWarningHeader(value string) string {
+	value = strings.TrimSpace(value)
+	code, rest, ok := strings.Cut(value, " ")
+	if _, err := strconv.Atoi(code); !ok || err != nil || len(code) != 3 {
+		return value
+	}
+	_, text, ok := strings.Cut(rest, " ")
+	if !ok {
+		return value
+	}
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, `"`) {
+		return text
+	}
+	if unquoted, err := strconv.Unquote(quotedPrefix(text)); err == nil {
+		return unquoted
+	}
+	return strings.Trim(text, `"`)
+}
+
+// quotedPrefix returns the quoted string at the start of s, dropping what
+// follows it, like the date of a Warning header.
+func quotedPrefix(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return s[:i+1]
+		}
+	}
+	return s
+}
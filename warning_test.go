@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestParseWarningHeader(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{`299 - "Deprecated API version"`, "Deprecated API version"},
+		{`299 docker "quoted \"text\""`, `quoted "text"`},
+		{`299 - "text" "Sat, 25 Aug 2012 23:34:45 GMT"`, "text"},
+		{`299 - unquoted text`, "unquoted text"},
+		{"plain warning", "plain warning"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := parseWarningHeader(tt.value); got != tt.expected {
+			t.Errorf("parseWarningHeader(%q): want %q, got %q", tt.value, tt.expected, got)
+		}
+	}
+}
+
+func newWarningTestClient(t *testing.T, body string) (*Client, *[]Warning) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Add("Warning", `299 - "Deprecated API version"`)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	client, _ := NewVersionedClient(server.URL, "1.41")
+	client.SkipServerVersionCheck = true
+	var mu sync.Mutex
+	var warnings []Warning
+	client.WarningHandler = func(w Warning) {
+		mu.Lock()
+		defer mu.Unlock()
+		warnings = append(warnings, w)
+	}
+	return client, &warnings
+}
+
+func TestCreateContainerWarnings(t *testing.T) {
+	t.Parallel()
+	client, warnings := newWarningTestClient(t, `{"Id":"4fa6e0f0","Warnings":["KernelMemory is deprecated"]}`)
+	container, err := client.CreateContainer(CreateContainerOptions{Config: &Config{Image: "busybox"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"KernelMemory is deprecated"}; !reflect.DeepEqual(container.Warnings, expected) {
+		t.Errorf("CreateContainer: wrong warnings. Want %#v. Got %#v.", expected, container.Warnings)
+	}
+	expected := []Warning{
+		{Method: http.MethodPost, Path: "/containers/create", Message: "Deprecated API version"},
+		{Method: http.MethodPost, Path: "/containers/create", Message: "KernelMemory is deprecated"},
+	}
+	if !reflect.DeepEqual(*warnings, expected) {
+		t.Errorf("CreateContainer: wrong handled warnings. Want %#v. Got %#v.", expected, *warnings)
+	}
+}
+
+func TestCreateNetworkWarnings(t *testing.T) {
+	t.Parallel()
+	client, warnings := newWarningTestClient(t, `{"Id":"8dfafdbc3a40","Warning":"overlapping subnet"}`)
+	network, err := client.CreateNetwork(CreateNetworkOptions{Name: "net"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"overlapping subnet"}; !reflect.DeepEqual(network.Warnings, expected) {
+		t.Errorf("CreateNetwork: wrong warnings. Want %#v. Got %#v.", expected, network.Warnings)
+	}
+	if len(*warnings) != 2 || (*warnings)[1].Message != "overlapping subnet" {
+		t.Errorf("CreateNetwork: wrong handled warnings: %#v", *warnings)
+	}
+}
+
+func TestStreamWarnings(t *testing.T) {
+	t.Parallel()
+	client, warnings := newWarningTestClient(t, "")
+	if err := client.Logs(LogsOptions{Container: "4fa6e0f0", Stdout: true, RawTerminal: true}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []Warning{{Method: http.MethodGet, Path: "/containers/4fa6e0f0/logs", Message: "Deprecated API version"}}
+	if !reflect.DeepEqual(*warnings, expected) {
+		t.Errorf("Logs: wrong handled warnings. Want %#v. Got %#v.", expected, *warnings)
+	}
+}